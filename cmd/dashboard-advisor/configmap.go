@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/dashboard-advisor/pkg/output"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// runConfigMap analyzes every dashboard in the ConfigMap manifest at path and
+// exits 1 if any of them has a finding at or above failOn. Profiling, if
+// requested, covers the analysis of all dashboards.
+func runConfigMap(path string, out outputOptions, failOn string, opts engineOptions, cardClient *cardinality.Client, promURL string, prof profileOptions) {
+	dashboards, err := extractor.LoadConfigMap(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	engine := buildEngine(opts, cardClient, promURL)
+	formatter, err := newFormatter(out, engine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	stop, err := startProfiling(prof)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	reports, err := lintConfigMap(engine, dashboards, formatter, out.format == "text", os.Stdout)
+	if perr := stop(); perr != nil && err == nil {
+		err = perr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if failOn != "" {
+		threshold := parseSeverity(failOn)
+		if threshold < 0 {
+			fmt.Fprintf(os.Stderr, "Unknown severity: %s\n", failOn)
+			os.Exit(2)
+		}
+		for _, report := range reports {
+			for _, f := range report.Findings {
+				if int(f.Severity) >= threshold {
+					os.Exit(1)
+				}
+			}
+		}
+	}
+}
+
+// lintConfigMap analyzes each dashboard in filename order and writes its
+// report to w. With header set, each report is preceded by a "== name =="
+// line; JSON reports are written back to back so the output stays a stream
+// of JSON documents (one per line with --compact).
+func lintConfigMap(engine *analyzer.Engine, dashboards map[string][]byte, formatter output.Formatter, header bool, w io.Writer) ([]*rules.Report, error) {
+	names := make([]string, 0, len(dashboards))
+	for name := range dashboards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	reports := make([]*rules.Report, 0, len(names))
+	for i, name := range names {
+		report, err := engine.AnalyzeBytes(dashboards[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if header {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "== %s ==\n", name)
+		}
+		if err := formatter.Format(w, report); err != nil {
+			return nil, fmt.Errorf("writing output: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
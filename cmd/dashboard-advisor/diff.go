@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// runDiff analyzes two dashboard files with the same engine and writes how
+// the second differs from the first. Profiling, if requested, covers both
+// analyses.
+func runDiff(beforePath, afterPath string, out outputOptions, opts engineOptions, cardClient *cardinality.Client, promURL string, prof profileOptions) {
+	engine := buildEngine(opts, cardClient, promURL)
+	stop, err := startProfiling(prof)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	_, err = diffDashboards(engine, beforePath, afterPath, out, os.Stdout)
+	if perr := stop(); perr != nil && err == nil {
+		err = perr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+// diffDashboards analyzes both files and writes their rules.ReportDiff to w:
+// as a JSON document with --format json, otherwise as text listing the
+// score change, resolved and introduced findings, and per-rule counts.
+func diffDashboards(engine *analyzer.Engine, beforePath, afterPath string, out outputOptions, w io.Writer) (rules.ReportDiff, error) {
+	before, err := engine.AnalyzeFile(beforePath)
+	if err != nil {
+		return rules.ReportDiff{}, fmt.Errorf("%s: %w", beforePath, err)
+	}
+	after, err := engine.AnalyzeFile(afterPath)
+	if err != nil {
+		return rules.ReportDiff{}, fmt.Errorf("%s: %w", afterPath, err)
+	}
+	diff := rules.DiffReports(before, after)
+
+	if out.format == "json" {
+		enc := json.NewEncoder(w)
+		if !out.compact {
+			enc.SetIndent("", "  ")
+		}
+		return diff, enc.Encode(diff)
+	}
+
+	delta := diff.ScoreAfter - diff.ScoreBefore
+	verdict := "unchanged"
+	switch {
+	case delta > 0:
+		verdict = "improved"
+	case delta < 0:
+		verdict = "regressed"
+	}
+	fmt.Fprintf(w, "Before: %s (%s)\n", beforePath, before.DashboardTitle)
+	fmt.Fprintf(w, "After:  %s (%s)\n", afterPath, after.DashboardTitle)
+	fmt.Fprintf(w, "Score:  %d → %d (%+d, %s)\n", diff.ScoreBefore, diff.ScoreAfter, delta, verdict)
+	fmt.Fprintln(w, strings.Repeat("─", 70))
+
+	writeDiffFindings(w, "Resolved", diff.Resolved)
+	writeDiffFindings(w, "Introduced", diff.Introduced)
+
+	if len(diff.RuleChanges) > 0 {
+		fmt.Fprintln(w, "Per-rule changes:")
+		for _, c := range diff.RuleChanges {
+			fmt.Fprintf(w, "  %-4s %3d → %-3d (%+d)\n", c.RuleID, c.Before, c.After, c.After-c.Before)
+		}
+	}
+	return diff, nil
+}
+
+// writeDiffFindings writes a heading with the count and one line per finding.
+func writeDiffFindings(w io.Writer, heading string, findings []rules.Finding) {
+	fmt.Fprintf(w, "%s (%d):\n", heading, len(findings))
+	for _, f := range findings {
+		line := fmt.Sprintf("  %-4s %-8s %s", f.RuleID, f.Severity, f.Title)
+		if len(f.PanelTitles) > 0 {
+			line += " — " + strings.Join(f.PanelTitles, ", ")
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// explainRule writes the description of the rule with the given ID (matched
+// case-insensitively) for --explain. Rules that do not implement
+// rules.Describer, typically third-party ones, get their ID and severity only.
+func explainRule(w io.Writer, registered []rules.Rule, id string) error {
+	var rule rules.Rule
+	for _, r := range registered {
+		if strings.EqualFold(r.ID(), id) {
+			rule = r
+			break
+		}
+	}
+	if rule == nil {
+		return fmt.Errorf("unknown rule %q (rule IDs look like Q1, D5, B2)", id)
+	}
+
+	describer, ok := rule.(rules.Describer)
+	if !ok {
+		fmt.Fprintf(w, "%s\nDefault severity: %s\n\nNo description available for this rule.\n", rule.ID(), rule.RuleSeverity())
+		return nil
+	}
+	d := describer.Describe()
+	autoFix := "no"
+	if d.AutoFixable {
+		autoFix = "yes (--fix)"
+	}
+	fmt.Fprintf(w, "%s — %s\n", rule.ID(), d.Title)
+	fmt.Fprintf(w, "Default severity: %s\n", rule.RuleSeverity())
+	fmt.Fprintf(w, "Auto-fixable:     %s\n\n", autoFix)
+	fmt.Fprintf(w, "%s\n\n", d.Summary)
+	fmt.Fprintf(w, "Why it matters:\n  %s\n\n", d.Rationale)
+	fmt.Fprintf(w, "Bad:\n  %s\n", d.Bad)
+	fmt.Fprintf(w, "Good:\n  %s\n", d.Good)
+	return nil
+}
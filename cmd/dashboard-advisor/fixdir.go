@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/dashboard-advisor/pkg/fixer"
+)
+
+// fixDirOptions holds the --dir/--output-dir settings for --fix.
+type fixDirOptions struct {
+	inDir         string
+	outDir        string
+	copyUnchanged bool // also write dashboards with zero fixes to outDir
+}
+
+// fixDir applies auto-fixes to every .json, .yaml and .yml file under
+// opts.inDir and writes the patched dashboards to the same relative path
+// under opts.outDir. YAML dashboards are converted and, like --fix, written
+// as JSON, under the same name with a .json extension; copies of unchanged
+// dashboards keep their original name and format. A summary line per file
+// and a total are written to w. Files that fail to
+// analyze or patch are reported and skipped; the returned error counts them
+// so the caller can exit non-zero after the rest of the tree is processed.
+func fixDir(engine *analyzer.Engine, opts fixDirOptions, w io.Writer) error {
+	inAbs, err := filepath.Abs(opts.inDir)
+	if err != nil {
+		return err
+	}
+	outAbs, err := filepath.Abs(opts.outDir)
+	if err != nil {
+		return err
+	}
+	if outAbs == inAbs || strings.HasPrefix(outAbs, inAbs+string(filepath.Separator)) {
+		return fmt.Errorf("--output-dir %s must not be inside --dir %s", opts.outDir, opts.inDir)
+	}
+
+	var files, patchedFiles, totalFixes, failed int
+	err = filepath.WalkDir(opts.inDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		isYAML := extractor.IsYAMLPath(path)
+		if d.IsDir() || (filepath.Ext(path) != ".json" && !isYAML) {
+			return nil
+		}
+		rel, err := filepath.Rel(opts.inDir, path)
+		if err != nil {
+			return err
+		}
+		files++
+
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dashJSON := raw
+		if isYAML {
+			if dashJSON, err = extractor.YAMLToJSON(raw); err != nil {
+				fmt.Fprintf(w, "%s: error: %v\n", rel, err)
+				failed++
+				return nil
+			}
+		}
+		patched, fixCount, err := fixDashboard(engine, dashJSON)
+		if err != nil {
+			fmt.Fprintf(w, "%s: error: %v\n", rel, err)
+			failed++
+			return nil
+		}
+		if fixCount == 0 {
+			if !opts.copyUnchanged {
+				fmt.Fprintf(w, "%s: no auto-fixable issues, skipped\n", rel)
+				return nil
+			}
+			patched = raw
+		}
+
+		dst := filepath.Join(opts.outDir, rel)
+		jsonRel := strings.TrimSuffix(rel, filepath.Ext(rel)) + ".json"
+		if isYAML && fixCount > 0 {
+			if _, err := os.Stat(filepath.Join(opts.inDir, jsonRel)); err == nil {
+				fmt.Fprintf(w, "%s: error: patched JSON would overwrite %s\n", rel, jsonRel)
+				failed++
+				return nil
+			}
+			dst = filepath.Join(opts.outDir, jsonRel)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, patched, 0644); err != nil {
+			return err
+		}
+		if fixCount == 0 {
+			fmt.Fprintf(w, "%s: no auto-fixable issues, copied unchanged\n", rel)
+			return nil
+		}
+		if isYAML {
+			fmt.Fprintf(w, "%s: applied %d fixes, written as JSON to %s\n", rel, fixCount, jsonRel)
+		} else {
+			fmt.Fprintf(w, "%s: applied %d fixes\n", rel, fixCount)
+		}
+		patchedFiles++
+		totalFixes += fixCount
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Applied %d fixes to %d of %d dashboards, wrote to %s\n", totalFixes, patchedFiles, files, opts.outDir)
+	if failed > 0 {
+		return fmt.Errorf("%d dashboards could not be fixed", failed)
+	}
+	return nil
+}
+
+// fixDashboard analyzes raw dashboard JSON and applies the auto-fixable
+// findings, returning the patched JSON and the number of fixes applied.
+func fixDashboard(engine *analyzer.Engine, raw []byte) ([]byte, int, error) {
+	report, err := engine.AnalyzeBytes(raw)
+	if err != nil {
+		return nil, 0, fmt.Errorf("analyzing: %w", err)
+	}
+	patched, fixCount, err := fixer.ApplyFixes(raw, report.Findings)
+	if err != nil {
+		return nil, 0, fmt.Errorf("applying fixes: %w", err)
+	}
+	return patched, fixCount, nil
+}
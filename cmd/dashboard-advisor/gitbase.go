@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// errNotInBase reports that the dashboard file does not exist at the base
+// ref, e.g. because it is being added in this commit.
+var errNotInBase = errors.New("file does not exist at base ref")
+
+// baseOptions holds the --git-base/--fail-on-regression settings. data is the
+// dashboard JSON at ref, or nil if the file is new.
+type baseOptions struct {
+	ref              string
+	data             []byte
+	failOnRegression bool
+}
+
+// readGitBase returns the contents of the file at path as of ref, using
+// `git show ref:./file` from the file's directory so the repository root
+// does not need to be known. Returns errNotInBase if the ref exists but the
+// file does not. The ref and the path are checked with `git rev-parse` and
+// `git cat-file -e` exit codes rather than by matching git's messages, which
+// change with the user's locale and git version.
+func readGitBase(ref, path string) ([]byte, error) {
+	dir, name := splitGitPath(path)
+	if _, err := runGit(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	if _, err := runGit(dir, "cat-file", "-e", ref+":./"+name); err != nil {
+		return nil, errNotInBase
+	}
+	return runGit(dir, "show", ref+":./"+name)
+}
+
+// readGitStaged returns the staged (index) contents of the file at path,
+// i.e. the file as it will be committed, which differs from the working
+// tree when changes are unstaged or only partly staged.
+func readGitStaged(path string) ([]byte, error) {
+	dir, name := splitGitPath(path)
+	if _, err := runGit(dir, "cat-file", "-e", ":./"+name); err != nil {
+		return nil, fmt.Errorf("%s is not staged", path)
+	}
+	return runGit(dir, "show", ":./"+name)
+}
+
+// splitGitPath splits path into the directory to run git in and the file
+// name relative to it.
+func splitGitPath(path string) (dir, name string) {
+	dir, name = filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	return dir, name
+}
+
+// runGit runs git with args in dir and returns its stdout. The error
+// includes git's stderr; LC_ALL=C keeps that message in English.
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %v: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
+
+// compareWithBase analyzes the base dashboard with the same engine and
+// writes the score change to w. It reports whether the score dropped.
+func compareWithBase(engine *analyzer.Engine, base baseOptions, report *rules.Report, w io.Writer) (bool, error) {
+	if base.data == nil {
+		fmt.Fprintf(w, "Score: %d (new file, not in %s)\n", report.Score, base.ref)
+		return false, nil
+	}
+	baseReport, err := engine.AnalyzeBytes(base.data)
+	if err != nil {
+		return false, fmt.Errorf("analyzing %s version: %w", base.ref, err)
+	}
+	delta := report.Score - baseReport.Score
+	verdict := "unchanged"
+	switch {
+	case delta > 0:
+		verdict = "improved"
+	case delta < 0:
+		verdict = "regressed"
+	}
+	fmt.Fprintf(w, "Score: %d → %d vs %s (%+d, %s)\n", baseReport.Score, report.Score, base.ref, delta, verdict)
+	return delta < 0, nil
+}
@@ -1,49 +1,164 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"runtime/pprof"
+	"strings"
 	"time"
 
 	"github.com/dashboard-advisor/pkg/analyzer"
 	"github.com/dashboard-advisor/pkg/cardinality"
 	"github.com/dashboard-advisor/pkg/fixer"
 	"github.com/dashboard-advisor/pkg/output"
+	"github.com/dashboard-advisor/pkg/rules"
 	"github.com/dashboard-advisor/pkg/server"
 )
 
 func main() {
-	format := flag.String("format", "text", "Output format: text, json")
+	format := flag.String("format", "text", "Output format: text, json, jsonl (one JSON object per finding, then a summary line), prometheus")
+	compact := flag.Bool("compact", false, "Write JSON on a single line instead of indented (with --format json)")
+	ruleCatalog := flag.Bool("rule-catalog", false, "Add a \"rules\" array describing every rule with findings (with --format json)")
+	debugExprs := flag.Bool("debug-exprs", false, "Add each expression's template-substituted form, as parsed, to metadata (with --format json)")
 	failOn := flag.String("fail-on", "", "Exit code 1 if findings at this severity or above: low, medium, high, critical")
+	dedupeScore := flag.Bool("dedupe-score", false, "Score only the highest-severity finding per panel (all findings are still reported)")
+	gitBase := flag.String("git-base", "", "Compare the score against the dashboard file as of this git ref (e.g. HEAD)")
+	staged := flag.Bool("staged", false, "Analyze the staged (index) version of the file, as it will be committed, instead of the working tree (with --git-base)")
+	failOnRegression := flag.Bool("fail-on-regression", false, "Exit code 1 if the score is lower than at --git-base")
+	maxPanels := flag.Int("max-panels", 25, "Visible panel count above which D1 (too many panels) fires")
+	severityOverride := flag.String("severity-override", "", "Comma-separated RULE=severity pairs replacing rules' severities in output and score (e.g. D5=high,Q7=low)")
+	scrapeInterval := flag.Duration("scrape-interval", 0, "Scrape interval of the dashboard's targets; Q7 then flags rate windows below 4x it as High, Q16 checks window alignment against it instead of 30s, and B8 estimates samples at it instead of 15s (0 = unknown)")
+	maxExprs := flag.Int("max-exprs", analyzer.DefaultMaxExprs, "Maximum targets plus annotation queries per dashboard, repeated expressions included, before failing (0 disables)")
+	metricTypesFile := flag.String("metric-types", "", "YAML/JSON file mapping metric names or prefixes (name_*) to counter, gauge, histogram or summary, consulted by Q11 and Q24")
+	var verbose bool
+	flag.BoolVar(&verbose, "verbose", false, "Print skipped (unparseable) expressions and per-rule timing to stderr")
+	flag.BoolVar(&verbose, "v", false, "Shorthand for --verbose")
 	fix := flag.Bool("fix", false, "Apply auto-fixes and write patched dashboard JSON to stdout")
 	fixOutput := flag.String("output", "", "Write patched JSON to this file instead of stdout (requires --fix)")
+	fixInDir := flag.String("dir", "", "Fix every .json, .yaml and .yml dashboard under this directory; YAML is written back as .json (requires --fix and --output-dir)")
+	fixOutDir := flag.String("output-dir", "", "Write patched dashboards to mirrored paths under this directory (with --dir)")
+	copyUnchanged := flag.Bool("copy-unchanged", false, "Also copy dashboards with no auto-fixable issues to --output-dir")
+	diff := flag.Bool("diff", false, "Compare two dashboards given as arguments: score change, resolved and introduced findings, per-rule counts")
+	inputFormat := flag.String("input-format", "auto", "Dashboard input format: json, yaml, or auto (YAML for .yaml/.yml files); YAML is converted to JSON, and --fix writes JSON")
+	configMap := flag.String("configmap", "", "Analyze every .json entry in the data of this Kubernetes ConfigMap manifest (YAML or JSON)")
+	explain := flag.String("explain", "", "Describe the rule with this ID (e.g. Q4) and exit; no dashboard needed")
 	serve := flag.Bool("serve", false, "Start web UI server")
 	addr := flag.String("addr", ":8080", "Server listen address (with --serve)")
+	analyzeTimeout := flag.Duration("analyze-timeout", 30*time.Second, "Per-request analysis timeout (with --serve, 0 disables)")
+	maxBody := flag.Int64("max-body", 10<<20, "Maximum request body size in bytes (with --serve)")
+	maxConcurrent := flag.Int("max-concurrent", 8, "Maximum concurrent API requests before returning 429 (with --serve, 0 disables)")
+	rateLimit := flag.Float64("rate-limit", 0, "API requests per second per client address before returning 429 (with --serve, 0 disables)")
+	rateBurst := flag.Int("rate-burst", 10, "Requests each client address may make above --rate-limit in a burst (with --serve)")
 	promURL := flag.String("prometheus-url", "", "Prometheus/Thanos URL for live cardinality enrichment and B-series checks")
-	promTimeout := flag.Duration("timeout", 10*time.Second, "Timeout for Prometheus API requests (with --prometheus-url)")
+	promTimeout := flag.Duration("timeout", 10*time.Second, "Timeout for Prometheus API requests and dashboard URL fetches")
+	promAttempts := flag.Int("prometheus-attempts", 3, "Maximum attempts for the TSDB status request on connection errors, 5xx or 429")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile of the analysis to this file")
+	memProfile := flag.String("memprofile", "", "Write an allocation profile of the analysis to this file")
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: dashboard-advisor [flags] <dashboard.json>\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: dashboard-advisor [flags] <dashboard.json | dashboard.yaml | http(s)://url>\n\n")
 		fmt.Fprintf(os.Stderr, "Analyze a Grafana dashboard JSON file for performance anti-patterns.\n\n")
 		fmt.Fprintf(os.Stderr, "Modes:\n")
 		fmt.Fprintf(os.Stderr, "  lint (default)  Analyze and report findings\n")
 		fmt.Fprintf(os.Stderr, "  --fix           Apply auto-fixes and output patched JSON\n")
-		fmt.Fprintf(os.Stderr, "  --serve         Start web UI server\n\n")
+		fmt.Fprintf(os.Stderr, "  --serve         Start web UI server\n")
+		fmt.Fprintf(os.Stderr, "  --configmap F   Analyze each dashboard in a ConfigMap manifest\n")
+		fmt.Fprintf(os.Stderr, "  --diff A B      Compare two versions of a dashboard\n")
+		fmt.Fprintf(os.Stderr, "  --explain ID    Describe a rule and exit\n\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
+	overrides, err := parseSeverityOverrides(*severityOverride)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	var metricTypes rules.MetricTypes
+	if *metricTypesFile != "" {
+		metricTypes, err = analyzer.LoadMetricTypes(*metricTypesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	opts := engineOptions{maxPanels: *maxPanels, verbose: verbose, dedupeScore: *dedupeScore, severityOverrides: overrides, metricTypes: metricTypes, scrapeInterval: *scrapeInterval, maxExprs: *maxExprs}
+
+	if *explain != "" {
+		if err := explainRule(os.Stdout, analyzer.NewEngineWithRegistered().Rules(), *explain); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
 	// Build cardinality client if Prometheus URL is provided
 	var cardClient *cardinality.Client
 	if *promURL != "" {
 		cardClient = cardinality.NewClient(*promURL, *promTimeout)
+		cardClient.MaxAttempts = *promAttempts
 		log.Printf("Cardinality enrichment enabled: %s (timeout: %s)", *promURL, *promTimeout)
 	}
 
+	prof := profileOptions{cpuPath: *cpuProfile, memPath: *memProfile}
+
 	if *serve {
-		runServe(*addr, cardClient, *promURL)
+		if prof.cpuPath != "" || prof.memPath != "" {
+			fmt.Fprintf(os.Stderr, "Error: --cpuprofile and --memprofile cannot be combined with --serve\n")
+			os.Exit(2)
+		}
+		runServe(*addr, cardClient, *promURL, server.Options{
+			AnalyzeTimeout: *analyzeTimeout,
+			MaxBodyBytes:   *maxBody,
+			MaxConcurrent:  *maxConcurrent,
+			RateLimit:      *rateLimit,
+			RateBurst:      *rateBurst,
+			MaxExprs:       serveMaxExprs(*maxExprs),
+			NewEngine:      func() *analyzer.Engine { return buildEngine(opts, nil, "") },
+		})
+		return
+	}
+
+	if *fixInDir != "" {
+		if !*fix || *fixOutDir == "" {
+			fmt.Fprintf(os.Stderr, "Error: --dir requires --fix and --output-dir\n")
+			os.Exit(2)
+		}
+		engine := buildEngine(opts, cardClient, *promURL)
+		stop, err := startProfiling(prof)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		err = fixDir(engine, fixDirOptions{inDir: *fixInDir, outDir: *fixOutDir, copyUnchanged: *copyUnchanged}, os.Stderr)
+		if perr := stop(); perr != nil && err == nil {
+			err = perr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if *configMap != "" {
+		if *fix || *gitBase != "" || *format == "prometheus" {
+			fmt.Fprintf(os.Stderr, "Error: --configmap supports lint mode with --format text, json or jsonl, without --fix or --git-base\n")
+			os.Exit(2)
+		}
+		runConfigMap(*configMap, outputOptions{format: *format, compact: *compact, ruleCatalog: *ruleCatalog, debugExprs: *debugExprs}, *failOn, opts, cardClient, *promURL, prof)
+		return
+	}
+
+	if *diff {
+		if flag.NArg() != 2 || *fix || *gitBase != "" || (*format != "text" && *format != "json") {
+			fmt.Fprintf(os.Stderr, "Error: --diff takes two dashboard files and supports --format text or json, without --fix or --git-base\n")
+			os.Exit(2)
+		}
+		runDiff(flag.Arg(0), flag.Arg(1), outputOptions{format: *format, compact: *compact}, opts, cardClient, *promURL, prof)
 		return
 	}
 
@@ -52,25 +167,104 @@ func main() {
 		os.Exit(2)
 	}
 
-	path := flag.Arg(0)
+	var data []byte
+	if *staged {
+		if *gitBase == "" {
+			fmt.Fprintf(os.Stderr, "Error: --staged requires --git-base\n")
+			os.Exit(2)
+		}
+		data, err = readGitStaged(flag.Arg(0))
+	} else {
+		data, err = readDashboard(flag.Arg(0), *promTimeout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	data, fromYAML, err := dashboardJSON(data, flag.Arg(0), *inputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	var base baseOptions
+	if *failOnRegression && *gitBase == "" {
+		fmt.Fprintf(os.Stderr, "Error: --fail-on-regression requires --git-base\n")
+		os.Exit(2)
+	}
+	if *gitBase != "" {
+		if *fix || strings.HasPrefix(flag.Arg(0), "http://") || strings.HasPrefix(flag.Arg(0), "https://") {
+			fmt.Fprintf(os.Stderr, "Error: --git-base needs a local dashboard file and cannot be combined with --fix\n")
+			os.Exit(2)
+		}
+		base = baseOptions{ref: *gitBase, failOnRegression: *failOnRegression}
+		base.data, err = readGitBase(*gitBase, flag.Arg(0))
+		if err != nil && !errors.Is(err, errNotInBase) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		if base.data != nil {
+			if base.data, _, err = dashboardJSON(base.data, flag.Arg(0), *inputFormat); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s at %s: %v\n", flag.Arg(0), *gitBase, err)
+				os.Exit(2)
+			}
+		}
+	}
 
 	if *fix {
-		runFix(path, *fixOutput, cardClient, *promURL)
+		runFix(data, *fixOutput, opts, cardClient, *promURL, prof)
 	} else {
-		runLint(path, *format, *failOn, cardClient, *promURL)
+		runLint(data, outputOptions{format: *format, compact: *compact, ruleCatalog: *ruleCatalog, debugExprs: *debugExprs, noPositions: fromYAML}, *failOn, base, opts, cardClient, *promURL, prof)
 	}
 }
 
-func buildEngine(cardClient *cardinality.Client, promURL string) *analyzer.Engine {
-	engine := analyzer.DefaultEngine()
+// engineOptions carries the CLI flags that configure the analysis engine.
+type engineOptions struct {
+	maxPanels         int
+	verbose           bool
+	dedupeScore       bool
+	severityOverrides map[string]rules.Severity
+	metricTypes       rules.MetricTypes
+	scrapeInterval    time.Duration
+	maxExprs          int
+}
+
+// outputOptions carries the CLI flags that select the lint output format.
+type outputOptions struct {
+	format      string
+	compact     bool
+	ruleCatalog bool
+	debugExprs  bool
+	noPositions bool // drop finding line/col, which would point into converted YAML
+}
+
+func buildEngine(opts engineOptions, cardClient *cardinality.Client, promURL string) *analyzer.Engine {
+	engine := analyzer.NewEngineWithRegistered()
+	engine.ReplaceRule(&rules.TooManyPanels{MaxPanels: opts.maxPanels})
+	engine.WithDedupeScore(opts.dedupeScore)
+	engine.WithSeverityOverrides(opts.severityOverrides)
+	engine.WithMetricTypes(opts.metricTypes)
+	engine.WithScrapeInterval(opts.scrapeInterval)
+	engine.WithMaxExprs(opts.maxExprs)
+	if opts.verbose {
+		engine.WithLogger(log.New(os.Stderr, "", 0))
+	}
 	if cardClient != nil {
 		engine.WithCardinality(cardClient, promURL)
 	}
 	return engine
 }
 
-func runServe(addr string, cardClient *cardinality.Client, promURL string) {
-	handler := server.Handler(cardClient, promURL)
+// serveMaxExprs converts the --max-exprs value, where 0 disables the cap,
+// to server.Options.MaxExprs, where 0 means the default.
+func serveMaxExprs(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+func runServe(addr string, cardClient *cardinality.Client, promURL string, opts server.Options) {
+	handler := server.Handler(cardClient, promURL, opts)
 	log.Printf("Dashboard Advisor web UI: http://localhost%s\n", addr)
 	if err := http.ListenAndServe(addr, handler); err != nil {
 		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
@@ -78,22 +272,22 @@ func runServe(addr string, cardClient *cardinality.Client, promURL string) {
 	}
 }
 
-func runLint(path, format, failOn string, cardClient *cardinality.Client, promURL string) {
-	engine := buildEngine(cardClient, promURL)
-	report, err := engine.AnalyzeFile(path)
+func runLint(data []byte, out outputOptions, failOn string, base baseOptions, opts engineOptions, cardClient *cardinality.Client, promURL string, prof profileOptions) {
+	engine := buildEngine(opts, cardClient, promURL)
+	report, err := analyzeProfiled(engine, data, prof)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
+	if out.noPositions {
+		for i := range report.Findings {
+			report.Findings[i].Line, report.Findings[i].Col = 0, 0
+		}
+	}
 
-	var formatter output.Formatter
-	switch format {
-	case "json":
-		formatter = &output.JSONFormatter{Indent: true}
-	case "text":
-		formatter = &output.TextFormatter{}
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", format)
+	formatter, err := newFormatter(out, engine)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
 
@@ -102,6 +296,15 @@ func runLint(path, format, failOn string, cardClient *cardinality.Client, promUR
 		os.Exit(2)
 	}
 
+	regressed := false
+	if base.ref != "" {
+		regressed, err = compareWithBase(engine, base, report, os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
 	if failOn != "" {
 		threshold := parseSeverity(failOn)
 		if threshold < 0 {
@@ -114,18 +317,31 @@ func runLint(path, format, failOn string, cardClient *cardinality.Client, promUR
 			}
 		}
 	}
+	if regressed && base.failOnRegression {
+		os.Exit(1)
+	}
 }
 
-func runFix(path, outputPath string, cardClient *cardinality.Client, promURL string) {
-	rawJSON, err := os.ReadFile(path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		os.Exit(2)
+// newFormatter returns the output formatter selected by --format.
+func newFormatter(out outputOptions, engine *analyzer.Engine) (output.Formatter, error) {
+	switch out.format {
+	case "json":
+		return &output.JSONFormatter{Indent: !out.compact, IncludeRuleCatalog: out.ruleCatalog, Rules: engine.Rules(), IncludeNormalizedExprs: out.debugExprs}, nil
+	case "jsonl":
+		return &output.JSONLFormatter{}, nil
+	case "text":
+		return &output.TextFormatter{}, nil
+	case "prometheus":
+		return &output.PrometheusFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s", out.format)
 	}
+}
 
+func runFix(rawJSON []byte, outputPath string, opts engineOptions, cardClient *cardinality.Client, promURL string, prof profileOptions) {
 	// Analyze to get findings
-	engine := buildEngine(cardClient, promURL)
-	report, err := engine.AnalyzeFile(path)
+	engine := buildEngine(opts, cardClient, promURL)
+	report, err := analyzeProfiled(engine, rawJSON, prof)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error analyzing: %v\n", err)
 		os.Exit(2)
@@ -155,6 +371,87 @@ func runFix(path, outputPath string, cardClient *cardinality.Client, promURL str
 	}
 }
 
+// profileOptions holds the --cpuprofile/--memprofile destinations.
+// An empty path disables that profile.
+type profileOptions struct {
+	cpuPath string
+	memPath string
+}
+
+// analyzeProfiled runs engine.AnalyzeBytes with profiling enabled for the
+// whole run. Profiles are finished before returning because the callers use
+// os.Exit, which skips deferred calls.
+func analyzeProfiled(engine *analyzer.Engine, data []byte, prof profileOptions) (*rules.Report, error) {
+	stop, err := startProfiling(prof)
+	if err != nil {
+		return nil, err
+	}
+	report, analyzeErr := engine.AnalyzeBytes(data)
+	if err := stop(); err != nil {
+		return nil, err
+	}
+	return report, analyzeErr
+}
+
+// startProfiling starts the CPU profile, if requested, and returns a
+// function that stops it and writes the allocation profile. Modes other
+// than single-dashboard lint and fix wrap their whole run in it; stop must
+// be called before os.Exit, which skips deferred calls.
+func startProfiling(prof profileOptions) (stop func() error, err error) {
+	var cpuFile *os.File
+	if prof.cpuPath != "" {
+		cpuFile, err = os.Create(prof.cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("creating CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("starting CPU profile: %w", err)
+		}
+	}
+	return func() error {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return fmt.Errorf("writing CPU profile: %w", err)
+			}
+		}
+		if prof.memPath != "" {
+			f, err := os.Create(prof.memPath)
+			if err != nil {
+				return fmt.Errorf("creating allocation profile: %w", err)
+			}
+			defer f.Close()
+			if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+				return fmt.Errorf("writing allocation profile: %w", err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// parseSeverityOverrides parses the --severity-override value, e.g.
+// "D5=high,Q7=low", into a rule ID → severity map. Rule IDs are not checked
+// against the registered rules so overrides can name third-party rules.
+func parseSeverityOverrides(s string) (map[string]rules.Severity, error) {
+	if s == "" {
+		return nil, nil
+	}
+	overrides := make(map[string]rules.Severity)
+	for _, pair := range strings.Split(s, ",") {
+		id, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid --severity-override %q: want RULE=severity", pair)
+		}
+		sev := parseSeverity(strings.ToLower(level))
+		if sev < 0 {
+			return nil, fmt.Errorf("invalid --severity-override %q: unknown severity %q", pair, level)
+		}
+		overrides[id] = rules.Severity(sev)
+	}
+	return overrides, nil
+}
+
 func parseSeverity(s string) int {
 	switch s {
 	case "low":
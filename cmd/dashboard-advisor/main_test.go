@@ -0,0 +1,597 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/output"
+	"github.com/dashboard-advisor/pkg/rules"
+	"go.yaml.in/yaml/v2"
+)
+
+func testdataPath(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "demo", "dashboards", name)
+}
+
+func TestAnalyzeFileProfiled_WritesProfiles(t *testing.T) {
+	dir := t.TempDir()
+	prof := profileOptions{
+		cpuPath: filepath.Join(dir, "cpu.pprof"),
+		memPath: filepath.Join(dir, "mem.pprof"),
+	}
+
+	data, err := readDashboard(testdataPath("slow-by-design.json"), 0)
+	if err != nil {
+		t.Fatalf("reading dashboard: %v", err)
+	}
+	report, err := analyzeProfiled(analyzer.DefaultEngine(), data, prof)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if len(report.Findings) == 0 {
+		t.Error("expected findings on slow dashboard")
+	}
+
+	for _, path := range []string{prof.cpuPath, prof.memPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("profile %s not written: %v", filepath.Base(path), err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("profile %s is empty", filepath.Base(path))
+		}
+	}
+}
+
+func TestAnalyzeFileProfiled_NoProfilesByDefault(t *testing.T) {
+	data, err := readDashboard(testdataPath("fixed-by-advisor.json"), 0)
+	if err != nil {
+		t.Fatalf("reading dashboard: %v", err)
+	}
+	report, err := analyzeProfiled(analyzer.DefaultEngine(), data, profileOptions{})
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if report.Score != 100 {
+		t.Errorf("fixed dashboard score = %d, want 100", report.Score)
+	}
+}
+
+// --dir, --configmap and --diff wrap their whole run in startProfiling.
+func TestStartProfiling_WrapsRun(t *testing.T) {
+	dir := t.TempDir()
+	prof := profileOptions{
+		cpuPath: filepath.Join(dir, "cpu.pprof"),
+		memPath: filepath.Join(dir, "mem.pprof"),
+	}
+	stop, err := startProfiling(prof)
+	if err != nil {
+		t.Fatalf("startProfiling: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := diffDashboards(analyzer.DefaultEngine(), testdataPath("slow-by-design.json"), testdataPath("fixed-by-advisor.json"), outputOptions{format: "text"}, &buf); err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	for _, path := range []string{prof.cpuPath, prof.memPath} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("profile %s not written: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestReadDashboard_URL(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dashboards/slow.json" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, testdataPath("slow-by-design.json"))
+	}))
+	defer ts.Close()
+
+	data, err := readDashboard(ts.URL+"/dashboards/slow.json", 5*time.Second)
+	if err != nil {
+		t.Fatalf("readDashboard failed: %v", err)
+	}
+	report, err := analyzer.DefaultEngine().AnalyzeBytes(data)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if report.DashboardUID != "slow-by-design" || len(report.Findings) == 0 {
+		t.Errorf("report = %q with %d findings, want slow-by-design with findings", report.DashboardUID, len(report.Findings))
+	}
+
+	_, err = readDashboard(ts.URL+"/missing.json", 5*time.Second)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v, want a 404 status error", err)
+	}
+}
+
+func TestReadDashboard_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	if _, err := readDashboard(ts.URL, 20*time.Millisecond); err == nil {
+		t.Error("expected a timeout error")
+	}
+}
+
+const brokenExprDashboard = `{
+	"uid": "broken-expr",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "OK",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Broken",
+		 "targets": [{"expr": "rate(sum(http_requests_total)[5m])", "refId": "A"}]}
+	]
+}`
+
+// captureStderr runs fn with os.Stderr and the standard logger redirected to
+// a pipe and returns everything written.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	log.SetOutput(w)
+	defer func() {
+		os.Stderr = orig
+		log.SetOutput(orig)
+	}()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestVerbose_ParseWarnings(t *testing.T) {
+	for _, verbose := range []bool{false, true} {
+		stderr := captureStderr(t, func() {
+			engine := buildEngine(engineOptions{verbose: verbose}, nil, "")
+			report, err := analyzeProfiled(engine, []byte(brokenExprDashboard), profileOptions{})
+			if err != nil {
+				t.Fatalf("analysis failed: %v", err)
+			}
+			if report.Metadata.ParseErrors != 1 {
+				t.Errorf("ParseErrors = %d, want 1", report.Metadata.ParseErrors)
+			}
+		})
+
+		if !verbose {
+			if stderr != "" {
+				t.Errorf("non-verbose run wrote to stderr:\n%s", stderr)
+			}
+			continue
+		}
+		for _, want := range []string{"rate(sum(http_requests_total)[5m])", "rule Q1:", "rule D1:"} {
+			if !strings.Contains(stderr, want) {
+				t.Errorf("verbose stderr missing %q:\n%s", want, stderr)
+			}
+		}
+	}
+}
+
+// gitRepo creates a temporary git repository and returns a helper that runs
+// git commands in it. The test is skipped if git is not installed.
+func gitRepo(t *testing.T) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	return dir, run
+}
+
+func copyDashboard(t *testing.T, name, dst string) {
+	t.Helper()
+	data, err := os.ReadFile(testdataPath(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGitBase_ScoreDelta(t *testing.T) {
+	dir, git := gitRepo(t)
+	if err := os.Mkdir(filepath.Join(dir, "dashboards"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "dashboards", "api.json")
+	copyDashboard(t, "slow-by-design.json", path)
+	git("add", ".")
+	git("commit", "-q", "-m", "slow")
+
+	// Working copy improves on HEAD.
+	copyDashboard(t, "fixed-by-advisor.json", path)
+	baseData, err := readGitBase("HEAD", path)
+	if err != nil {
+		t.Fatalf("readGitBase: %v", err)
+	}
+	engine := buildEngine(engineOptions{maxPanels: 25}, nil, "")
+	current, _ := os.ReadFile(path)
+	report, err := engine.AnalyzeBytes(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	regressed, err := compareWithBase(engine, baseOptions{ref: "HEAD", data: baseData}, report, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regressed || !strings.Contains(buf.String(), "improved") {
+		t.Errorf("fixed vs slow HEAD: regressed=%v, output %q", regressed, buf.String())
+	}
+
+	// Committing the fix and reverting the working copy is a regression.
+	git("commit", "-q", "-am", "fix")
+	copyDashboard(t, "slow-by-design.json", path)
+	baseData, err = readGitBase("HEAD", path)
+	if err != nil {
+		t.Fatalf("readGitBase: %v", err)
+	}
+	current, _ = os.ReadFile(path)
+	report, _ = engine.AnalyzeBytes(current)
+	buf.Reset()
+	regressed, err = compareWithBase(engine, baseOptions{ref: "HEAD", data: baseData}, report, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regressed || !strings.Contains(buf.String(), "regressed") {
+		t.Errorf("slow vs fixed HEAD: regressed=%v, output %q", regressed, buf.String())
+	}
+}
+
+func TestGitBase_NewFile(t *testing.T) {
+	dir, git := gitRepo(t)
+	copyDashboard(t, "fixed-by-advisor.json", filepath.Join(dir, "old.json"))
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+
+	path := filepath.Join(dir, "new.json")
+	copyDashboard(t, "slow-by-design.json", path)
+	// Detection must not depend on the language of git's messages.
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if _, err := readGitBase("HEAD", path); !errors.Is(err, errNotInBase) {
+		t.Fatalf("expected errNotInBase for untracked file, got %v", err)
+	}
+	if _, err := readGitBase("no-such-ref", path); err == nil || errors.Is(err, errNotInBase) {
+		t.Errorf("expected a git error for an unknown ref, got %v", err)
+	}
+}
+
+func TestGitStaged_ReadsIndex(t *testing.T) {
+	dir, git := gitRepo(t)
+	path := filepath.Join(dir, "api.json")
+	copyDashboard(t, "slow-by-design.json", path)
+	if _, err := readGitStaged(path); err == nil {
+		t.Error("expected an error for a file that is not staged")
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "slow")
+
+	// Stage the fix, then break the working copy again: the staged version
+	// is what will be committed.
+	copyDashboard(t, "fixed-by-advisor.json", path)
+	git("add", ".")
+	copyDashboard(t, "slow-by-design.json", path)
+
+	staged, err := readGitStaged(path)
+	if err != nil {
+		t.Fatalf("readGitStaged: %v", err)
+	}
+	want, _ := os.ReadFile(testdataPath("fixed-by-advisor.json"))
+	if !bytes.Equal(staged, want) {
+		t.Error("readGitStaged should return the staged fixed dashboard, not the working copy")
+	}
+}
+
+func TestFixDir_MirrorsTree(t *testing.T) {
+	in := t.TempDir()
+	if err := os.Mkdir(filepath.Join(in, "team"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	copyDashboard(t, "slow-by-design.json", filepath.Join(in, "slow.json"))
+	copyDashboard(t, "fixed-by-advisor.json", filepath.Join(in, "team", "fixed.json"))
+	if err := os.WriteFile(filepath.Join(in, "README.txt"), []byte("not a dashboard"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	engine := buildEngine(engineOptions{maxPanels: 25}, nil, "")
+
+	out := filepath.Join(t.TempDir(), "out")
+	var buf bytes.Buffer
+	if err := fixDir(engine, fixDirOptions{inDir: in, outDir: out}, &buf); err != nil {
+		t.Fatalf("fixDir: %v", err)
+	}
+	summary := buf.String()
+	if !strings.Contains(summary, "slow.json: applied") || !strings.Contains(summary, "of 2 dashboards") {
+		t.Errorf("unexpected summary:\n%s", summary)
+	}
+	patched, err := os.ReadFile(filepath.Join(out, "slow.json"))
+	if err != nil {
+		t.Fatalf("patched slow.json not written: %v", err)
+	}
+	original, _ := os.ReadFile(filepath.Join(in, "slow.json"))
+	if bytes.Equal(patched, original) {
+		t.Error("slow.json was written unchanged")
+	}
+	if _, err := os.Stat(filepath.Join(out, "team", "fixed.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dashboard with no fixes should be skipped, stat err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "README.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("non-JSON files should be ignored, stat err = %v", err)
+	}
+
+	buf.Reset()
+	if err := fixDir(engine, fixDirOptions{inDir: in, outDir: out, copyUnchanged: true}, &buf); err != nil {
+		t.Fatalf("fixDir with copyUnchanged: %v", err)
+	}
+	copied, err := os.ReadFile(filepath.Join(out, "team", "fixed.json"))
+	if err != nil {
+		t.Fatalf("unchanged dashboard not copied: %v", err)
+	}
+	original, _ = os.ReadFile(filepath.Join(in, "team", "fixed.json"))
+	if !bytes.Equal(copied, original) {
+		t.Error("copied dashboard differs from input")
+	}
+}
+
+func TestFixDir_YAML(t *testing.T) {
+	in := t.TempDir()
+	for name, fixture := range map[string]string{"slow.yaml": "slow-by-design.json", "fixed.yml": "fixed-by-advisor.json"} {
+		raw, err := os.ReadFile(testdataPath(fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			t.Fatal(err)
+		}
+		// JSON is valid YAML, but a real YAML file exercises the conversion.
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(in, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine := buildEngine(engineOptions{maxPanels: 25}, nil, "")
+
+	out := filepath.Join(t.TempDir(), "out")
+	var buf bytes.Buffer
+	if err := fixDir(engine, fixDirOptions{inDir: in, outDir: out, copyUnchanged: true}, &buf); err != nil {
+		t.Fatalf("fixDir: %v\n%s", err, buf.String())
+	}
+	summary := buf.String()
+	if !strings.Contains(summary, "slow.yaml: applied") || !strings.Contains(summary, "of 2 dashboards") {
+		t.Errorf("YAML dashboards should be fixed and counted, summary:\n%s", summary)
+	}
+	patched, err := os.ReadFile(filepath.Join(out, "slow.json"))
+	if err != nil {
+		t.Fatalf("patched slow.yaml not written as slow.json: %v", err)
+	}
+	if !json.Valid(patched) {
+		t.Error("patched YAML dashboard should be written as JSON")
+	}
+	copied, _ := os.ReadFile(filepath.Join(out, "fixed.yml"))
+	original, _ := os.ReadFile(filepath.Join(in, "fixed.yml"))
+	if !bytes.Equal(copied, original) {
+		t.Error("unchanged YAML dashboard should be copied as is")
+	}
+
+	// A JSON sibling with the same name must not be overwritten.
+	copyDashboard(t, "fixed-by-advisor.json", filepath.Join(in, "slow.json"))
+	buf.Reset()
+	if err := fixDir(engine, fixDirOptions{inDir: in, outDir: filepath.Join(t.TempDir(), "out")}, &buf); err == nil || !strings.Contains(buf.String(), "would overwrite slow.json") {
+		t.Errorf("expected a conflict error for slow.yaml next to slow.json, got %v:\n%s", err, buf.String())
+	}
+}
+
+func TestFixDir_RejectsOutputInsideInput(t *testing.T) {
+	in := t.TempDir()
+	engine := buildEngine(engineOptions{maxPanels: 25}, nil, "")
+	if err := fixDir(engine, fixDirOptions{inDir: in, outDir: filepath.Join(in, "out")}, io.Discard); err == nil {
+		t.Error("expected an error when --output-dir is inside --dir")
+	}
+}
+
+func TestParseSeverityOverrides(t *testing.T) {
+	got, err := parseSeverityOverrides("D5=high, Q7=LOW")
+	if err != nil {
+		t.Fatalf("parseSeverityOverrides: %v", err)
+	}
+	if len(got) != 2 || got["D5"] != rules.High || got["Q7"] != rules.Low {
+		t.Errorf("overrides = %v, want D5:High Q7:Low", got)
+	}
+
+	for _, bad := range []string{"D5", "=high", "D5=severe"} {
+		if _, err := parseSeverityOverrides(bad); err == nil {
+			t.Errorf("parseSeverityOverrides(%q) should fail", bad)
+		}
+	}
+	if got, err := parseSeverityOverrides(""); err != nil || got != nil {
+		t.Errorf("empty flag = %v, %v; want no overrides", got, err)
+	}
+}
+
+func TestExplainRule(t *testing.T) {
+	registered := analyzer.DefaultEngine().Rules()
+	for _, tc := range []struct {
+		id   string
+		want []string
+	}{
+		{"Q4", []string{"Q4 — High-cardinality grouping", "Default severity: High", "Auto-fixable:     no", "Bad:\n  sum by (pod"}},
+		{"q3", []string{"Q3 — Regex matcher where equality suffices", "Auto-fixable:     yes", "Good:\n  up{job=\"api\"}"}},
+		{"D5", []string{"D5 — Auto-refresh interval too frequent", "Default severity: Medium", `"refresh": "5s"`}},
+		{"B9", []string{"B9 — Loki query without stream selector labels", "Default severity: Critical"}},
+	} {
+		var out bytes.Buffer
+		if err := explainRule(&out, registered, tc.id); err != nil {
+			t.Errorf("explain %s: %v", tc.id, err)
+			continue
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("explain %s output missing %q:\n%s", tc.id, want, out.String())
+			}
+		}
+	}
+
+	if err := explainRule(io.Discard, registered, "Z99"); err == nil {
+		t.Error("explain of an unknown rule ID should fail")
+	}
+}
+
+func TestLintConfigMap_ReportsEachDashboard(t *testing.T) {
+	dashboards := map[string][]byte{}
+	for _, name := range []string{"slow-by-design.json", "fixed-by-advisor.json"} {
+		data, err := os.ReadFile(testdataPath(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dashboards[name] = data
+	}
+
+	var buf bytes.Buffer
+	reports, err := lintConfigMap(analyzer.DefaultEngine(), dashboards, &output.TextFormatter{}, true, &buf)
+	if err != nil {
+		t.Fatalf("lintConfigMap: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+	if reports[0].DashboardUID != "fixed-by-advisor" || reports[1].DashboardUID != "slow-by-design" {
+		t.Errorf("reports = %s, %s; want filename order", reports[0].DashboardUID, reports[1].DashboardUID)
+	}
+	fixed := strings.Index(buf.String(), "== fixed-by-advisor.json ==")
+	slow := strings.Index(buf.String(), "== slow-by-design.json ==")
+	if fixed < 0 || slow < fixed {
+		t.Errorf("expected a header per dashboard in filename order, got:\n%s", buf.String())
+	}
+
+	dashboards["broken.json"] = []byte("{not json")
+	if _, err := lintConfigMap(analyzer.DefaultEngine(), dashboards, &output.TextFormatter{}, true, io.Discard); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Errorf("expected an error naming broken.json, got %v", err)
+	}
+}
+
+func TestDiffDashboards_SlowVsFixed(t *testing.T) {
+	engine := analyzer.DefaultEngine()
+	var buf bytes.Buffer
+	diff, err := diffDashboards(engine, testdataPath("slow-by-design.json"), testdataPath("fixed-by-advisor.json"), outputOptions{format: "text"}, &buf)
+	if err != nil {
+		t.Fatalf("diffDashboards: %v", err)
+	}
+	slow, err := engine.AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff.ScoreAfter != 100 || diff.ScoreBefore >= diff.ScoreAfter {
+		t.Errorf("scores %d → %d, want an improvement to 100", diff.ScoreBefore, diff.ScoreAfter)
+	}
+	if len(diff.Resolved) != len(slow.Findings) || len(diff.Resolved) < 50 {
+		t.Errorf("resolved %d findings, want all %d of the slow dashboard", len(diff.Resolved), len(slow.Findings))
+	}
+	if len(diff.Introduced) != 0 {
+		t.Errorf("introduced %d findings, want 0", len(diff.Introduced))
+	}
+	out := buf.String()
+	for _, want := range []string{
+		fmt.Sprintf("Score:  %d → 100 (+%d, improved)", diff.ScoreBefore, 100-diff.ScoreBefore),
+		fmt.Sprintf("Resolved (%d):", len(diff.Resolved)),
+		"Introduced (0):",
+		"Per-rule changes:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if _, err := diffDashboards(engine, testdataPath("fixed-by-advisor.json"), testdataPath("slow-by-design.json"), outputOptions{format: "json"}, &buf); err != nil {
+		t.Fatalf("diffDashboards json: %v", err)
+	}
+	var reversed rules.ReportDiff
+	if err := json.Unmarshal(buf.Bytes(), &reversed); err != nil {
+		t.Fatalf("decoding JSON diff: %v", err)
+	}
+	if len(reversed.Introduced) != len(slow.Findings) || len(reversed.Resolved) != 0 {
+		t.Errorf("reversed diff: %d introduced, %d resolved; want %d and 0", len(reversed.Introduced), len(reversed.Resolved), len(slow.Findings))
+	}
+}
+
+func TestDashboardJSON_InputFormat(t *testing.T) {
+	yamlDash := []byte("uid: yaml-dash\ntitle: From YAML\nrefresh: 10s\npanels:\n  - id: 1\n    type: timeseries\n    targets:\n      - refId: A\n        expr: up\n")
+
+	for _, tc := range []struct {
+		name, src, format string
+		wantConverted     bool
+	}{
+		{"auto by extension", "dash.yaml", "auto", true},
+		{"auto .yml", "dash.yml", "auto", true},
+		{"forced yaml", "https://example.com/dash", "yaml", true},
+	} {
+		data, converted, err := dashboardJSON(yamlDash, tc.src, tc.format)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if converted != tc.wantConverted {
+			t.Errorf("%s: converted = %v, want %v", tc.name, converted, tc.wantConverted)
+		}
+		report, err := analyzer.DefaultEngine().AnalyzeBytes(data)
+		if err != nil {
+			t.Fatalf("%s: analyzing converted YAML: %v", tc.name, err)
+		}
+		if report.DashboardUID != "yaml-dash" || report.Metadata.TotalPanels != 1 {
+			t.Errorf("%s: got UID %q with %d panels, want yaml-dash with 1", tc.name, report.DashboardUID, report.Metadata.TotalPanels)
+		}
+	}
+
+	jsonDash := []byte(`{"uid": "json-dash"}`)
+	if data, converted, err := dashboardJSON(jsonDash, "dash.json", "auto"); err != nil || converted || !bytes.Equal(data, jsonDash) {
+		t.Errorf("JSON input should pass through unchanged, got %q converted=%v err=%v", data, converted, err)
+	}
+	if _, _, err := dashboardJSON(jsonDash, "dash.json", "xml"); err == nil {
+		t.Error("expected an error for an unknown input format")
+	}
+}
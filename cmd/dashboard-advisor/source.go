@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// maxFetchBytes caps dashboard JSON fetched over HTTP.
+const maxFetchBytes = 50 << 20
+
+// readDashboard returns the raw dashboard JSON named by src: fetched with a
+// plain GET when src is an http:// or https:// URL, read from disk otherwise.
+// URL fetches send no Grafana API auth and expect the dashboard JSON itself
+// as the response body.
+func readDashboard(src string, timeout time.Duration) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("reading file: %w", err)
+		}
+		return data, nil
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(src)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, fmt.Errorf("fetching %s: unexpected status %s: %s", src, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", src, err)
+	}
+	if len(data) > maxFetchBytes {
+		return nil, fmt.Errorf("fetching %s: response exceeds %d bytes", src, maxFetchBytes)
+	}
+	return data, nil
+}
+
+// dashboardJSON returns data as dashboard JSON for --input-format: converted
+// from YAML for "yaml", or for "auto" when src has a .yaml/.yml extension,
+// and unchanged for "json". converted reports whether it was YAML.
+func dashboardJSON(data []byte, src, format string) (out []byte, converted bool, err error) {
+	switch format {
+	case "json":
+		return data, false, nil
+	case "yaml":
+	case "auto":
+		if !extractor.IsYAMLPath(src) {
+			return data, false, nil
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown input format %q (want auto, json or yaml)", format)
+	}
+	out, err = extractor.YAMLToJSON(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
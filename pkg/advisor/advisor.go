@@ -0,0 +1,149 @@
+// Package advisor is the entry point for embedding Dashboard Advisor in
+// another Go program. Analyze runs the full analysis of one dashboard with
+// a single call; NewEngine builds the underlying analyzer.Engine from the
+// same Options for callers that analyze many dashboards and want to reuse
+// it, keeping the cardinality cache warm between calls.
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// defaultPrometheusTimeout matches the CLI's --timeout default.
+const defaultPrometheusTimeout = 10 * time.Second
+
+// Options configures an analysis. The zero value runs every built-in rule,
+// plus any added with rules.Register, without cardinality data.
+type Options struct {
+	// PrometheusURL enables live cardinality enrichment and the B-series
+	// checks that query Prometheus or Thanos. Empty analyzes the dashboard
+	// JSON alone.
+	PrometheusURL string
+	// PrometheusTimeout bounds each Prometheus API request. Defaults to 10s
+	// if zero.
+	PrometheusTimeout time.Duration
+
+	// Rules limits the analysis to these rule IDs (e.g. "Q1", "D5").
+	// Empty runs every rule.
+	Rules []string
+	// ExcludeRules drops these rule IDs after Rules is applied.
+	ExcludeRules []string
+
+	// SeverityOverrides replaces the severity of findings by rule ID
+	// before scoring; see analyzer.Engine.WithSeverityOverrides.
+	SeverityOverrides map[string]rules.Severity
+	// DedupeScore scores only the highest-severity finding per panel.
+	DedupeScore bool
+	// MetricTypes classifies custom metrics for rules that otherwise guess
+	// a metric's type from its name (Q11, Q24); see analyzer.LoadMetricTypes.
+	MetricTypes rules.MetricTypes
+	// ScrapeInterval is the scrape interval of the dashboard's targets, if
+	// known. Q7 then measures hardcoded rate windows against it, Q16 checks
+	// window alignment against it and B8 estimates samples at it.
+	ScrapeInterval time.Duration
+	// MaxExprs caps the targets and annotation queries a dashboard may contain;
+	// larger ones fail with analyzer.ErrTooManyExprs. Zero means no cap.
+	MaxExprs int
+}
+
+// Analyze runs every selected rule against the dashboard JSON and returns
+// the scored report. Unknown rule IDs in opts are an error.
+func Analyze(dashboardJSON []byte, opts Options) (*rules.Report, error) {
+	return AnalyzeContext(context.Background(), dashboardJSON, opts)
+}
+
+// AnalyzeContext is Analyze with cancellation. If ctx is done before every
+// rule has run, it returns the partial report with an error wrapping
+// ctx.Err(); see analyzer.Engine.AnalyzeDashboardContext.
+func AnalyzeContext(ctx context.Context, dashboardJSON []byte, opts Options) (*rules.Report, error) {
+	engine, err := NewEngine(opts)
+	if err != nil {
+		return nil, err
+	}
+	return engine.AnalyzeBytesContext(ctx, dashboardJSON)
+}
+
+// NewEngine builds an analyzer.Engine configured by opts. Reuse it across
+// dashboards to share the cardinality client and its cache.
+func NewEngine(opts Options) (*analyzer.Engine, error) {
+	registered := analyzer.NewEngineWithRegistered().Rules()
+	selected, err := selectRules(registered, opts.Rules, opts.ExcludeRules)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := analyzer.NewEngine()
+	for _, r := range selected {
+		engine.RegisterRule(r)
+	}
+	engine.WithDedupeScore(opts.DedupeScore)
+	engine.WithSeverityOverrides(opts.SeverityOverrides)
+	engine.WithMetricTypes(opts.MetricTypes)
+	engine.WithScrapeInterval(opts.ScrapeInterval)
+	engine.WithMaxExprs(opts.MaxExprs)
+	if opts.PrometheusURL != "" {
+		timeout := opts.PrometheusTimeout
+		if timeout <= 0 {
+			timeout = defaultPrometheusTimeout
+		}
+		engine.WithCardinality(cardinality.NewClient(opts.PrometheusURL, timeout), opts.PrometheusURL)
+	}
+	return engine, nil
+}
+
+// selectRules returns the rules of registered whose IDs are in include (all
+// of them if include is empty) and not in exclude, in registration order.
+// IDs are matched case-insensitively.
+func selectRules(registered []rules.Rule, include, exclude []string) ([]rules.Rule, error) {
+	known := make(map[string]bool, len(registered))
+	for _, r := range registered {
+		known[strings.ToUpper(r.ID())] = true
+	}
+	normalize := func(ids []string) (map[string]bool, error) {
+		set := make(map[string]bool, len(ids))
+		var unknown []string
+		for _, id := range ids {
+			id = strings.ToUpper(strings.TrimSpace(id))
+			if !known[id] {
+				unknown = append(unknown, id)
+				continue
+			}
+			set[id] = true
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("unknown rule IDs: %s", strings.Join(unknown, ", "))
+		}
+		return set, nil
+	}
+
+	includeSet, err := normalize(include)
+	if err != nil {
+		return nil, err
+	}
+	excludeSet, err := normalize(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []rules.Rule
+	for _, r := range registered {
+		id := strings.ToUpper(r.ID())
+		if len(includeSet) > 0 && !includeSet[id] {
+			continue
+		}
+		if excludeSet[id] {
+			continue
+		}
+		selected = append(selected, r)
+	}
+	return selected, nil
+}
@@ -0,0 +1,61 @@
+package advisor_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/advisor"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+const exampleDashboard = `{
+	"uid": "api-overview",
+	"title": "API overview",
+	"refresh": "5s",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests",
+		 "targets": [{"expr": "sum(rate(http_requests_total[5m]))", "refId": "A"}]}
+	]
+}`
+
+func ExampleAnalyze() {
+	report, err := advisor.Analyze([]byte(exampleDashboard), advisor.Options{
+		Rules:             []string{"Q1", "D5"},
+		SeverityOverrides: map[string]rules.Severity{"D5": rules.High},
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	for _, f := range report.Findings {
+		fmt.Printf("%s %s: %s\n", f.RuleID, f.Severity, f.Title)
+	}
+	// Output:
+	// Q1 Critical: Missing label filters
+	// D5 High: Auto-refresh interval too frequent
+}
+
+func TestAnalyze_RuleSelection(t *testing.T) {
+	all, err := advisor.Analyze([]byte(exampleDashboard), advisor.Options{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	excluded, err := advisor.Analyze([]byte(exampleDashboard), advisor.Options{ExcludeRules: []string{"q1"}})
+	if err != nil {
+		t.Fatalf("Analyze with ExcludeRules: %v", err)
+	}
+	if len(excluded.Findings) == 0 || len(excluded.Findings) >= len(all.Findings) {
+		t.Errorf("excluding Q1 should drop some findings: %d of %d left", len(excluded.Findings), len(all.Findings))
+	}
+	for _, f := range excluded.Findings {
+		if f.RuleID == "Q1" {
+			t.Errorf("excluded rule Q1 still reported: %s", f.Title)
+		}
+	}
+
+	_, err = advisor.Analyze([]byte(exampleDashboard), advisor.Options{Rules: []string{"Q1", "Z9", "X1"}})
+	if err == nil || !strings.Contains(err.Error(), "X1, Z9") {
+		t.Errorf("expected an error naming the unknown rule IDs, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// largeQueryShapes are the query templates repeated across the synthetic
+// dashboard. They mix cheap and expensive shapes so most rules have work to
+// do: plain rates, histogram quantiles, high-cardinality groupings, regex
+// matchers, binary expressions and subqueries. %[1]d varies the metric name
+// and %[2]s the job so the dashboard is not one query deduplicated away.
+var largeQueryShapes = []string{
+	`sum(rate(http_requests_total_%[1]d{job="%[2]s", namespace=~"$namespace"}[5m]))`,
+	`histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="%[2]s"}[5m])))`,
+	`sum by (pod, le) (rate(rpc_latency_seconds_bucket_%[1]d{job="%[2]s"}[1m]))`,
+	`sum(rate(errors_total_%[1]d{job="%[2]s"}[5m])) / sum(rate(requests_total_%[1]d{job="%[2]s"}[5m]))`,
+	`max_over_time(rate(queue_depth_%[1]d{job=~".*%[2]s.*"}[5m])[1h:1m])`,
+	`count({__name__=~"node_.*", job="%[2]s", instance=~"$instance"})`,
+	`avg by (instance) (node_load1{job="%[2]s"}) > bool 4`,
+	`sum(increase(jobs_processed_total_%[1]d{job="%[2]s"}[$__range]))`,
+}
+
+// largeDashboard generates a dashboard with rows collapsed rows of
+// panelsPerRow panels each, two targets per panel and a few template
+// variables. The output depends only on its arguments.
+func largeDashboard(rows, panelsPerRow int) []byte {
+	jobs := []string{"api", "worker", "gateway", "scheduler"}
+	panelTypes := []string{"timeseries", "timeseries", "stat", "table"}
+
+	var panels []map[string]any
+	id := 1
+	for r := 0; r < rows; r++ {
+		row := map[string]any{
+			"id": id, "type": "row", "title": fmt.Sprintf("Row %d", r), "collapsed": true,
+			"gridPos": map[string]int{"x": 0, "y": r, "w": 24, "h": 1},
+		}
+		id++
+		var nested []map[string]any
+		for p := 0; p < panelsPerRow; p++ {
+			n := r*panelsPerRow + p
+			job := jobs[n%len(jobs)]
+			targets := make([]map[string]any, 2)
+			for t := range targets {
+				shape := largeQueryShapes[(n+t)%len(largeQueryShapes)]
+				targets[t] = map[string]any{
+					"refId":        string(rune('A' + t)),
+					"expr":         fmt.Sprintf(shape, n, job),
+					"legendFormat": "{{instance}}",
+				}
+			}
+			nested = append(nested, map[string]any{
+				"id": id, "type": panelTypes[n%len(panelTypes)], "title": fmt.Sprintf("Panel %d", n),
+				"gridPos": map[string]int{"x": (p % 3) * 8, "y": r, "w": 8, "h": 8},
+				"targets": targets,
+			})
+			id++
+		}
+		row["panels"] = nested
+		panels = append(panels, row)
+	}
+
+	dash := map[string]any{
+		"uid":     "synthetic-large",
+		"title":   fmt.Sprintf("Synthetic %dx%d", rows, panelsPerRow),
+		"refresh": "30s",
+		"time":    map[string]string{"from": "now-6h", "to": "now"},
+		"templating": map[string]any{"list": []map[string]any{
+			{"name": "namespace", "type": "query", "multi": true, "includeAll": true, "refresh": 2,
+				"query": `label_values(up, namespace)`},
+			{"name": "instance", "type": "query", "multi": true, "includeAll": true, "refresh": 1,
+				"query": `label_values(up{namespace=~"$namespace"}, instance)`},
+		}},
+		"panels": panels,
+	}
+	data, err := json.Marshal(dash)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func benchmarkAnalyzeDashboard(b *testing.B, dash *extractor.DashboardModel) {
+	engine := DefaultEngine()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.AnalyzeDashboard(dash)
+	}
+}
+
+func BenchmarkAnalyzeDashboard_Slow(b *testing.B) {
+	dash, err := extractor.LoadDashboard(testdataPath("slow-by-design.json"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkAnalyzeDashboard(b, dash)
+}
+
+func BenchmarkAnalyzeDashboard_Large(b *testing.B) {
+	dash, err := extractor.ParseDashboard(largeDashboard(20, 15))
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkAnalyzeDashboard(b, dash)
+}
+
+// BenchmarkAnalyzeBytes_Large covers the whole pipeline, including JSON
+// parsing and the source map used for finding positions.
+func BenchmarkAnalyzeBytes_Large(b *testing.B) {
+	data := largeDashboard(20, 15)
+	engine := DefaultEngine()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.AnalyzeBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseAllExprs(b *testing.B) {
+	dash, err := extractor.ParseDashboard(largeDashboard(20, 15))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var exprs []string
+	for _, p := range extractor.AllPanels(dash) {
+		for _, t := range p.Targets {
+			exprs = append(exprs, t.Expr)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParseAllExprs(exprs)
+	}
+}
+
+func BenchmarkEstimateQueryCost(b *testing.B) {
+	// ParseAllExprs substitutes the Grafana variables the shapes use.
+	raw := make([]string, len(largeQueryShapes))
+	for n, shape := range largeQueryShapes {
+		raw[n] = fmt.Sprintf(shape, n, "api")
+	}
+	parsed, errs := ParseAllExprs(raw)
+	if len(errs) > 0 {
+		b.Fatalf("parse errors: %+v", errs)
+	}
+	var exprs []parser.Expr
+	for _, expr := range parsed {
+		exprs = append(exprs, expr)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, expr := range exprs {
+			EstimateQueryCost(expr, nil, 15)
+		}
+	}
+}
+
+// TestAnalyzeLargeDashboard_Budget guards against rules that scale badly
+// with dashboard size. The budget is far above the benchmark baseline so it
+// only trips on pathological slowdowns, not on a busy CI machine; use the
+// benchmarks above to compare smaller changes.
+func TestAnalyzeLargeDashboard_Budget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large dashboard analysis in -short mode")
+	}
+	data := largeDashboard(20, 15)
+
+	start := time.Now()
+	report, err := DefaultEngine().AnalyzeBytes(data)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Metadata.TotalTargets != 20*15*2 {
+		t.Errorf("TotalTargets = %d, want %d", report.Metadata.TotalTargets, 20*15*2)
+	}
+	if report.Metadata.ParseErrors != 0 {
+		t.Errorf("synthetic dashboard has %d parse errors", report.Metadata.ParseErrors)
+	}
+	if budget := 5 * time.Second; elapsed > budget {
+		t.Errorf("analyzing %d panels took %v, budget %v", report.Metadata.TotalPanels, elapsed, budget)
+	}
+}
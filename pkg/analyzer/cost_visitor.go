@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/dashboard-advisor/pkg/rules"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
@@ -65,11 +66,7 @@ func walkCost(node parser.Node, card *cardinality.CardinalityData, stepSeconds f
 	case *parser.MatrixSelector:
 		// Matrix selector: series × (range / step)
 		inner := walkCost(n.VectorSelector, card, stepSeconds, depth)
-		rangeSeconds := n.Range.Seconds()
-		if rangeSeconds <= 0 {
-			rangeSeconds = stepSeconds
-		}
-		return inner * (rangeSeconds / stepSeconds)
+		return inner * rules.RangeSamplesPerSeries(n.Range, stepSeconds)
 
 	case *parser.AggregateExpr:
 		innerCost := walkCost(n.Expr, card, stepSeconds, depth+1)
@@ -95,16 +92,7 @@ func walkCost(node parser.Node, card *cardinality.CardinalityData, stepSeconds f
 
 	case *parser.SubqueryExpr:
 		innerCost := walkCost(n.Expr, card, stepSeconds, depth)
-		rangeSeconds := n.Range.Seconds()
-		subStep := n.Step.Seconds()
-		if subStep <= 0 {
-			subStep = stepSeconds
-		}
-		evaluations := rangeSeconds / subStep
-		if evaluations < 1 {
-			evaluations = 1
-		}
-		return innerCost * evaluations
+		return innerCost * rules.SubqueryEvaluations(n, stepSeconds)
 
 	case *parser.UnaryExpr:
 		return walkCost(n.Expr, card, stepSeconds, depth)
@@ -1,20 +1,51 @@
 package analyzer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/dashboard-advisor/pkg/cardinality"
 	"github.com/dashboard-advisor/pkg/extractor"
 	"github.com/dashboard-advisor/pkg/rules"
 )
 
+// Version is the analyzer version reported in ReportMetadata.AnalyzerVersion
+// and by the server's /version endpoint.
+const Version = "0.2.0"
+
 // Engine orchestrates the full analysis pipeline:
 // load dashboard → extract → parse → run rules → score → report.
 type Engine struct {
 	rules             []rules.Rule
 	cardinalityClient *cardinality.Client // nil when --prometheus-url not provided
 	prometheusURL     string              // passed through to AnalysisContext for B-rules
+	dedupeScore       bool                // score only the highest-severity finding per panel
+	logger            Logger              // nil keeps per-expression diagnostics quiet
+	// severityOverrides maps rule ID → severity replacing the one its
+	// findings carry; nil leaves every rule's own severity.
+	severityOverrides map[string]rules.Severity
+	metricTypes       rules.MetricTypes // user-supplied metric classification for Q11
+	scrapeInterval    time.Duration     // known scrape interval for Q7; 0 when unknown
+	maxExprs          int               // cap on targets plus annotation queries; 0 is unlimited
+}
+
+// DefaultMaxExprs is the expression cap the server applies when
+// server.Options.MaxExprs is zero, and the CLI's --max-exprs default.
+const DefaultMaxExprs = 10000
+
+// ErrTooManyExprs is returned, wrapped, when a dashboard has more targets
+// and annotation queries than the limit set with WithMaxExprs.
+var ErrTooManyExprs = errors.New("too many expressions")
+
+// Logger receives the engine's verbose diagnostics: skipped expressions and
+// per-rule timing. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
 }
 
 // NewEngine creates an Engine with no rules registered.
@@ -27,6 +58,24 @@ func (e *Engine) RegisterRule(r rules.Rule) {
 	e.rules = append(e.rules, r)
 }
 
+// Rules returns the registered rules in execution order.
+func (e *Engine) Rules() []rules.Rule {
+	return append([]rules.Rule(nil), e.rules...)
+}
+
+// ReplaceRule swaps the registered rule that has the same ID as r, e.g. to
+// run a built-in rule with non-default thresholds. r is appended if no rule
+// with that ID is registered.
+func (e *Engine) ReplaceRule(r rules.Rule) {
+	for i, existing := range e.rules {
+		if existing.ID() == r.ID() {
+			e.rules[i] = r
+			return
+		}
+	}
+	e.rules = append(e.rules, r)
+}
+
 // WithCardinality configures live cardinality enrichment via a Prometheus TSDB
 // status API client. When set, the engine fetches cardinality data and passes
 // it to rules through AnalysisContext.Cardinality.
@@ -35,6 +84,51 @@ func (e *Engine) WithCardinality(c *cardinality.Client, prometheusURL string) {
 	e.prometheusURL = prometheusURL
 }
 
+// WithDedupeScore switches scoring to rules.ComputeDedupedScore, which counts
+// only the highest-severity finding per panel. All findings are still reported.
+func (e *Engine) WithDedupeScore(enabled bool) {
+	e.dedupeScore = enabled
+}
+
+// WithLogger enables verbose diagnostics. Each unparseable expression and a
+// per-rule summary (findings and duration) are written to l. Pass nil to
+// disable them again.
+func (e *Engine) WithLogger(l Logger) {
+	e.logger = l
+}
+
+// WithSeverityOverrides rewrites the Severity of every finding from the
+// given rule IDs, e.g. {"D5": rules.High} for teams that treat frequent
+// refresh as serious. Overrides apply before scoring, so they change both
+// the reported severity and the score contribution. Pass nil to clear them.
+func (e *Engine) WithSeverityOverrides(overrides map[string]rules.Severity) {
+	e.severityOverrides = overrides
+}
+
+// WithMetricTypes supplies a classification of metrics by name or prefix
+// (see LoadMetricTypes). Rules that guess a metric's type from its name,
+// such as Q11 and Q24, consult it first. Pass nil to clear it.
+func (e *Engine) WithMetricTypes(types rules.MetricTypes) {
+	e.metricTypes = types
+}
+
+// WithScrapeInterval sets the scrape interval of the targets the dashboard
+// queries, passed to rules as AnalysisContext.ScrapeInterval. Q7 then
+// measures hardcoded rate windows against it, Q16 checks window alignment
+// against it and B8 estimates samples at it. Zero means unknown.
+func (e *Engine) WithScrapeInterval(d time.Duration) {
+	e.scrapeInterval = d
+}
+
+// WithMaxExprs caps the targets and enabled annotation queries a dashboard
+// may contain; analysis of a larger one fails with ErrTooManyExprs before
+// anything is parsed. Every target counts, repeated expressions included:
+// rules and the report do work per target, not per distinct expression.
+// Zero or negative removes the cap.
+func (e *Engine) WithMaxExprs(n int) {
+	e.maxExprs = n
+}
+
 // DefaultEngine returns an Engine with all built-in rules registered.
 func DefaultEngine() *Engine {
 	e := NewEngine()
@@ -51,6 +145,38 @@ func DefaultEngine() *Engine {
 	e.RegisterRule(&rules.IncorrectAggregation{})       // Q10
 	e.RegisterRule(&rules.RateOnGauge{})                // Q11
 	e.RegisterRule(&rules.ImpossibleVectorMatching{})   // Q12
+	e.RegisterRule(&rules.HistogramQuantileWithoutRate{}) // Q15
+	e.RegisterRule(&rules.RateWindowAlignment{})        // Q16
+	e.RegisterRule(&rules.SortOnTimeSeries{})           // Q17
+	e.RegisterRule(&rules.DeltaOnCounter{})             // Q18
+	e.RegisterRule(&rules.RangeAsRateWindow{})          // Q19
+	e.RegisterRule(&rules.NegativeOffset{})             // Q20
+	e.RegisterRule(&rules.RecordingRuleCandidate{})     // Q21
+	e.RegisterRule(&rules.InfoMetricAggregation{})      // Q22
+	e.RegisterRule(&rules.QuantileWindowMismatch{})     // Q23
+	e.RegisterRule(&rules.ResetsOnGauge{})              // Q24
+	e.RegisterRule(&rules.AtModifier{})                 // Q25
+	e.RegisterRule(&rules.BucketGroupingExplosion{})    // Q26
+	e.RegisterRule(&rules.RateOfRecordedRate{})         // Q27
+	e.RegisterRule(&rules.MetricNameRegex{})            // Q28
+	e.RegisterRule(&rules.VectorZeroFallback{})         // Q29
+	e.RegisterRule(&rules.BoolComparisonOnTimeSeries{}) // Q30
+	e.RegisterRule(&rules.IncreaseOnRatePanel{})        // Q31
+	e.RegisterRule(&rules.RateMixedWithRawCounter{})    // Q32
+	e.RegisterRule(&rules.DoubleSmoothingSubquery{})    // Q33
+	e.RegisterRule(&rules.QuantileAggregation{})        // Q34
+	e.RegisterRule(&rules.IntervalMsArithmetic{})       // Q35
+	e.RegisterRule(&rules.RepeatedJoin{})               // Q36
+	e.RegisterRule(&rules.SumWithoutCount{})            // Q37
+	e.RegisterRule(&rules.ForeignMacro{})               // Q38
+	e.RegisterRule(&rules.LongExpression{})             // Q39
+	e.RegisterRule(&rules.ComparisonAsSeries{})         // Q40
+	e.RegisterRule(&rules.TopologyGrouping{})           // Q41
+	e.RegisterRule(&rules.RedundantClampMin{})          // Q42
+	e.RegisterRule(&rules.CPUModeUnhandled{})           // Q43
+	e.RegisterRule(&rules.WindowExceedsRange{})         // Q44
+	e.RegisterRule(&rules.LongIgnoringList{})           // Q45
+	e.RegisterRule(&rules.MetricNameAlternation{})      // Q46
 	// D-series: Dashboard design rules
 	e.RegisterRule(&rules.TooManyPanels{})              // D1
 	e.RegisterRule(&rules.RepeatWithAll{})              // D2
@@ -62,6 +188,35 @@ func DefaultEngine() *Engine {
 	e.RegisterRule(&rules.DuplicateQueries{})           // D8
 	e.RegisterRule(&rules.DatasourceMixing{})           // D9
 	e.RegisterRule(&rules.NoCollapsedRows{})            // D10
+	e.RegisterRule(&rules.SlowDatasourceMixing{})       // D11
+	e.RegisterRule(&rules.MissingTimeRange{})           // D12
+	e.RegisterRule(&rules.NestedRepeat{})               // D13
+	e.RegisterRule(&rules.ExcessiveAnnotations{})       // D14
+	e.RegisterRule(&rules.AlertRefMismatch{})           // D15
+	e.RegisterRule(&rules.UnusedRepeatVariable{})       // D16
+	e.RegisterRule(&rules.VariableChain{})              // D17
+	e.RegisterRule(&rules.OldSchemaVersion{})           // D18
+	e.RegisterRule(&rules.LiveNowManyPanels{})          // D19
+	e.RegisterRule(&rules.HiddenExpensiveTarget{})      // D20
+	e.RegisterRule(&rules.UnscopedLabelValues{})        // D21
+	e.RegisterRule(&rules.TooManyQueryVariables{})      // D22
+	e.RegisterRule(&rules.PanelTimeOverride{})          // D23
+	e.RegisterRule(&rules.ManualLabelRepeat{})          // D24
+	e.RegisterRule(&rules.ExplicitAllValue{})           // D25
+	e.RegisterRule(&rules.BroadAllValue{})              // D26
+	e.RegisterRule(&rules.TimezoneTruncatedRange{})     // D27
+	e.RegisterRule(&rules.HiddenDrillDownTargets{})     // D28
+	e.RegisterRule(&rules.OversizedMaxDataPoints{})     // D29
+	e.RegisterRule(&rules.TooManyTimeseriesPanels{})    // D30
+	e.RegisterRule(&rules.LegendLabelDropped{})         // D31
+	e.RegisterRule(&rules.HardcodedDatasource{})        // D32
+	e.RegisterRule(&rules.KioskLoad{})                  // D33
+	e.RegisterRule(&rules.FineIntervalVariable{})       // D34
+	e.RegisterRule(&rules.HeatmapWithoutLe{})           // D35
+	e.RegisterRule(&rules.HighCardinalityVariable{})    // D36
+	e.RegisterRule(&rules.DuplicateNestedPanel{})       // D37
+	e.RegisterRule(&rules.StaleRepeatOptions{})         // D38
+	e.RegisterRule(&rules.RateIrateMix{})               // D39
 	// B-series: Backend/infrastructure rules
 	e.RegisterRule(&rules.NoQueryFrontend{})            // B1
 	e.RegisterRule(&rules.CacheMisconfigured{})         // B2
@@ -70,91 +225,327 @@ func DefaultEngine() *Engine {
 	e.RegisterRule(&rules.DeduplicationOverhead{})      // B5
 	e.RegisterRule(&rules.HighCardinality{})            // B6
 	e.RegisterRule(&rules.QueryLogNotEnabled{})         // B7
+	e.RegisterRule(&rules.MaxSamplesExceeded{})         // B8
+	e.RegisterRule(&rules.UnboundedLogQuery{})          // B9
+	e.RegisterRule(&rules.NoRecordingRules{})           // B10
 	return e
 }
 
-// AnalyzeBytes parses raw dashboard JSON bytes and runs the full analysis pipeline.
+// NewEngineWithRegistered returns DefaultEngine plus every rule added with
+// rules.Register, in registration order. A registered rule whose ID matches
+// a built-in rule replaces it.
+func NewEngineWithRegistered() *Engine {
+	e := DefaultEngine()
+	for _, r := range rules.Registered() {
+		e.ReplaceRule(r)
+	}
+	return e
+}
+
+// AnalyzeBytes parses raw dashboard JSON bytes and runs the full analysis
+// pipeline. Findings are annotated with their source line and column.
 func (e *Engine) AnalyzeBytes(data []byte) (*rules.Report, error) {
 	dash, err := extractor.ParseDashboard(data)
 	if err != nil {
 		return nil, fmt.Errorf("parsing dashboard: %w", err)
 	}
-	return e.AnalyzeDashboard(dash), nil
+	report, err := e.AnalyzeDashboardContext(context.Background(), dash)
+	if err != nil {
+		return nil, err
+	}
+	annotatePositions(report, data)
+	return report, nil
 }
 
-// AnalyzeFile loads a dashboard JSON file and runs the full analysis pipeline.
+// AnalyzeBytesContext is AnalyzeBytes with cancellation; see
+// AnalyzeDashboardContext for how ctx is honored. Source positions are
+// only added when the analysis completed before ctx was done: a partial
+// report is returned as is.
+func (e *Engine) AnalyzeBytesContext(ctx context.Context, data []byte) (*rules.Report, error) {
+	dash, err := extractor.ParseDashboard(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing dashboard: %w", err)
+	}
+	report, err := e.AnalyzeDashboardContext(ctx, dash)
+	if err == nil && ctx.Err() == nil {
+		annotatePositions(report, data)
+	}
+	return report, err
+}
+
+// AnalyzeFile loads a dashboard file and runs the full analysis pipeline.
+// A .yaml or .yml file is converted to JSON first (see
+// extractor.YAMLToJSON); its findings carry no source positions.
 func (e *Engine) AnalyzeFile(path string) (*rules.Report, error) {
-	dash, err := extractor.LoadDashboard(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading dashboard: reading dashboard file: %w", err)
+	}
+	isYAML := extractor.IsYAMLPath(path)
+	if isYAML {
+		if data, err = extractor.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("loading dashboard: %w", err)
+		}
+	}
+	dash, err := extractor.ParseDashboard(data)
 	if err != nil {
 		return nil, fmt.Errorf("loading dashboard: %w", err)
 	}
-	return e.AnalyzeDashboard(dash), nil
+	report, err := e.AnalyzeDashboardContext(context.Background(), dash)
+	if err != nil {
+		return nil, err
+	}
+	if !isYAML {
+		annotatePositions(report, data)
+	}
+	return report, nil
+}
+
+// annotatePositions sets Line and Col on each finding from the raw JSON:
+// the finding's TargetExpr within its first panel, or that panel itself.
+// Dashboard-level findings keep zero positions. Analysis from a parsed
+// DashboardModel has no source, so AnalyzeDashboard leaves them unset.
+func annotatePositions(report *rules.Report, data []byte) {
+	located := false
+	for _, f := range report.Findings {
+		located = located || len(f.PanelIDs) > 0
+	}
+	if !located {
+		return
+	}
+	sm, err := extractor.BuildSourceMap(data)
+	if err != nil {
+		return
+	}
+	for i := range report.Findings {
+		f := &report.Findings[i]
+		if len(f.PanelIDs) == 0 {
+			continue
+		}
+		if pos, ok := sm.Locate(f.PanelIDs[0], f.TargetExpr); ok {
+			f.Line, f.Col = pos.Line, pos.Col
+		}
+	}
 }
 
 // AnalyzeDashboard runs all registered rules against a parsed dashboard.
+// It returns nil if dash exceeds the WithMaxExprs cap; use
+// AnalyzeDashboardContext to get the error.
 func (e *Engine) AnalyzeDashboard(dash *extractor.DashboardModel) *rules.Report {
+	report, _ := e.AnalyzeDashboardContext(context.Background(), dash)
+	return report
+}
+
+// AnalyzeDashboardContext runs all registered rules against a parsed dashboard,
+// checking ctx between rules. If ctx is done before every rule has run, it
+// returns a partial report built from the findings so far together with an
+// error wrapping ctx.Err(). A dashboard over the WithMaxExprs cap yields no
+// report and an error wrapping ErrTooManyExprs.
+func (e *Engine) AnalyzeDashboardContext(ctx context.Context, dash *extractor.DashboardModel) (*rules.Report, error) {
+	actx, parseErrors, normalizedExprs, err := e.buildAnalysisContext(ctx, dash)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []rules.Finding
+	var runErr error
+	for i, r := range e.rules {
+		if err := ctx.Err(); err != nil {
+			runErr = fmt.Errorf("analysis stopped after %d of %d rules: %w", i, len(e.rules), err)
+			break
+		}
+		start := time.Now()
+		ruleFindings := r.Check(actx)
+		if e.logger != nil {
+			e.logger.Printf("rule %s: %d findings in %s", r.ID(), len(ruleFindings), time.Since(start))
+		}
+		findings = append(findings, ruleFindings...)
+	}
+
+	e.applySeverityOverrides(findings)
+	linkRelatedFindings(findings)
+
+	score := rules.ComputeScore(findings)
+	if e.dedupeScore {
+		score = rules.ComputeDedupedScore(findings)
+	}
+	panelScores := computePanelScores(findings, e.dedupeScore)
+
+	// Count total targets
+	totalTargets := 0
+	for _, p := range extractor.AllPanels(dash) {
+		totalTargets += len(p.Targets)
+	}
+
+	// Estimate queries fired per refresh: only visible panels run on load,
+	// and Include All variables multiply them in the worst case.
+	visibleTargets := 0
+	for _, p := range extractor.VisiblePanels(dash) {
+		for _, t := range p.Targets {
+			if t.Expr != "" {
+				visibleTargets++
+			}
+		}
+	}
+	estimatedQueries := visibleTargets * rules.EstimateVariableFanOut(dash.Templating.List)
+
+	autoFixable := 0
+	for _, f := range findings {
+		if f.AutoFixable {
+			autoFixable++
+		}
+	}
+	autoFixablePct := 0.0
+	if len(findings) > 0 {
+		autoFixablePct = 100 * float64(autoFixable) / float64(len(findings))
+	}
+
+	panelTitles := make(map[int]string)
+	for _, p := range extractor.AllPanels(dash) {
+		panelTitles[p.ID] = p.Title
+	}
+	panelCosts := make(map[int]float64)
+	for _, p := range actx.Panels {
+		for _, t := range p.Targets {
+			if cost, ok := actx.QueryCosts[t.Expr]; ok {
+				panelCosts[p.ID] += cost
+			}
+		}
+	}
+
+	return &rules.Report{
+		DashboardUID:    dash.UID,
+		DashboardTitle:  dash.Title,
+		Score:           score,
+		Findings:        findings,
+		PanelScores:     panelScores,
+		PanelTitlesByID: panelTitles,
+		PanelCosts:      panelCosts,
+		Metadata: rules.ReportMetadata{
+			TotalPanels:                len(extractor.AllPanels(dash)),
+			TotalTargets:               totalTargets,
+			ParseErrors:                len(parseErrors),
+			AnalyzerVersion:            Version,
+			CardinalityAvailable:       actx.Cardinality != nil,
+			QueryCosts:                 actx.QueryCosts,
+			EstimatedQueriesPerRefresh: estimatedQueries,
+			AutoFixableCount:           autoFixable,
+			AutoFixablePct:             autoFixablePct,
+			Tags:                       dash.Tags,
+			NormalizedExprs:            normalizedExprs,
+		},
+	}, runErr
+}
+
+// buildAnalysisContext extracts panels and variables from dash, parses every
+// target and annotation expression, and estimates query costs. Unparseable
+// expressions are returned rather than failing the analysis, along with the
+// template-substituted text of every expression (see
+// ReportMetadata.NormalizedExprs). Cardinality data is fetched within ctx.
+// It fails only when dash has more
+// targets and annotation queries than the WithMaxExprs cap.
+func (e *Engine) buildAnalysisContext(ctx context.Context, dash *extractor.DashboardModel) (*rules.AnalysisContext, []ParseResult, map[string]string, error) {
+	if e.maxExprs > 0 {
+		if n := countQueries(dash); n > e.maxExprs {
+			return nil, nil, nil, fmt.Errorf("%w: dashboard has %d, limit is %d", ErrTooManyExprs, n, e.maxExprs)
+		}
+	}
 	allPanels := extractor.PanelsWithTargets(dash)
-	allExprs := extractor.AllTargetExprs(dash)
-	parsed, parseErrors := ParseAllExprs(allExprs)
+	// Annotation queries are parsed alongside targets so rules can inspect them.
+	allExprs := append(extractor.AllTargetExprs(dash), extractor.AnnotationExprs(dash)...)
+	parsed, normalizedExprs, parseErrors := parseExprs(allExprs)
+	if e.logger != nil {
+		for _, pe := range parseErrors {
+			e.logger.Printf("skipped unparseable PromQL: %q — %v", pe.RawExpr, pe.ParseErr)
+		}
+	}
 
 	// Optionally fetch cardinality data from Prometheus TSDB status API
 	var cardData *cardinality.CardinalityData
 	if e.cardinalityClient != nil {
 		var err error
-		cardData, err = e.cardinalityClient.Fetch()
+		cardData, err = e.cardinalityClient.FetchContext(ctx)
 		if err != nil {
 			log.Printf("WARN: cardinality enrichment unavailable: %v", err)
 		}
 	}
 
-	ctx := &rules.AnalysisContext{
-		Dashboard:     dash,
-		Panels:        allPanels,
-		Variables:     dash.Templating.List,
-		ParsedExprs:   parsed,
-		Cardinality:   cardData,
-		PrometheusURL: e.prometheusURL,
+	// Compute query costs for ranking panels by expense; rules see them too
+	queryCosts := make(map[string]float64, len(parsed))
+	for rawExpr, expr := range parsed {
+		queryCosts[rawExpr] = EstimateQueryCost(expr, cardData, 15.0)
 	}
 
-	var findings []rules.Finding
-	for _, r := range e.rules {
-		findings = append(findings, r.Check(ctx)...)
+	actx := &rules.AnalysisContext{
+		Dashboard:      dash,
+		Panels:         allPanels,
+		Variables:      dash.Templating.List,
+		ParsedExprs:    parsed,
+		Cardinality:    cardData,
+		PrometheusURL:  e.prometheusURL,
+		QueryCosts:     queryCosts,
+		MetricTypes:    e.metricTypes,
+		ScrapeInterval: e.scrapeInterval,
 	}
+	return actx, parseErrors, normalizedExprs, nil
+}
 
-	score := rules.ComputeScore(findings)
-	panelScores := computePanelScores(findings)
-
-	// Count total targets
-	totalTargets := 0
+// countQueries returns the number of targets across all panels plus the
+// enabled annotation queries, counting repeated expressions every time.
+func countQueries(dash *extractor.DashboardModel) int {
+	n := 0
 	for _, p := range extractor.AllPanels(dash) {
-		totalTargets += len(p.Targets)
+		n += len(p.Targets)
 	}
+	for _, a := range dash.Annotations.List {
+		if a.Enable && a.Expr != "" {
+			n++
+		}
+	}
+	return n
+}
 
-	// Compute query costs for ranking panels by expense
-	queryCosts := make(map[string]float64, len(parsed))
-	for rawExpr, expr := range parsed {
-		queryCosts[rawExpr] = EstimateQueryCost(expr, cardData, 15.0)
+// AnalyzePanelsContext runs only the per-panel rules (see rules.IsPanelRule)
+// against the panels of dash with the given IDs and returns their findings.
+// It is the incremental counterpart of AnalyzeDashboardContext for editors
+// that re-check a panel after each change: dash supplies the variables the
+// panels' queries refer to, but dashboard-wide rules are skipped. ctx is
+// honored between rules as in AnalyzeDashboardContext.
+func (e *Engine) AnalyzePanelsContext(ctx context.Context, dash *extractor.DashboardModel, panelIDs ...int) ([]rules.Finding, error) {
+	actx, _, _, err := e.buildAnalysisContext(ctx, dash)
+	if err != nil {
+		return nil, err
 	}
+	actx = actx.ForPanels(panelIDs...)
 
-	return &rules.Report{
-		DashboardUID:   dash.UID,
-		DashboardTitle: dash.Title,
-		Score:          score,
-		Findings:       findings,
-		PanelScores:    panelScores,
-		Metadata: rules.ReportMetadata{
-			TotalPanels:          len(extractor.AllPanels(dash)),
-			TotalTargets:         totalTargets,
-			ParseErrors:          len(parseErrors),
-			AnalyzerVersion:      "0.2.0",
-			CardinalityAvailable: cardData != nil,
-			QueryCosts:           queryCosts,
-		},
+	var findings []rules.Finding
+	for _, r := range e.rules {
+		if !rules.IsPanelRule(r) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return findings, fmt.Errorf("panel analysis stopped: %w", err)
+		}
+		findings = append(findings, r.Check(actx)...)
+	}
+	e.applySeverityOverrides(findings)
+	linkRelatedFindings(findings)
+	return findings, nil
+}
+
+// applySeverityOverrides sets the configured severity on findings from
+// overridden rules.
+func (e *Engine) applySeverityOverrides(findings []rules.Finding) {
+	for i := range findings {
+		if sev, ok := e.severityOverrides[findings[i].RuleID]; ok {
+			findings[i].Severity = sev
+		}
 	}
 }
 
 // computePanelScores calculates a score for each panel that has findings.
-func computePanelScores(findings []rules.Finding) map[int]int {
+// With dedupe set, each panel is scored on its highest-severity finding only.
+func computePanelScores(findings []rules.Finding, dedupe bool) map[int]int {
 	// Group findings by panel ID
 	panelFindings := make(map[int][]rules.Finding)
 	for _, f := range findings {
@@ -165,7 +556,47 @@ func computePanelScores(findings []rules.Finding) map[int]int {
 
 	scores := make(map[int]int, len(panelFindings))
 	for pid, pf := range panelFindings {
-		scores[pid] = rules.ComputeScore(pf)
+		if dedupe {
+			scores[pid] = rules.ComputeDedupedScore(pf)
+		} else {
+			scores[pid] = rules.ComputeScore(pf)
+		}
 	}
 	return scores
 }
+
+// linkRelatedFindings sets RelatedRuleIDs on each panel-level finding to the
+// sorted IDs of other rules that flag at least one of the same panels.
+// Findings are kept as-is; the linkage only lets consumers group overlapping
+// advice (e.g. Q1 and Q5 on the same bare selector).
+func linkRelatedFindings(findings []rules.Finding) {
+	panelRules := make(map[int]map[string]bool)
+	for _, f := range findings {
+		for _, pid := range f.PanelIDs {
+			if panelRules[pid] == nil {
+				panelRules[pid] = make(map[string]bool)
+			}
+			panelRules[pid][f.RuleID] = true
+		}
+	}
+
+	for i := range findings {
+		related := make(map[string]bool)
+		for _, pid := range findings[i].PanelIDs {
+			for id := range panelRules[pid] {
+				if id != findings[i].RuleID {
+					related[id] = true
+				}
+			}
+		}
+		if len(related) == 0 {
+			continue
+		}
+		ids := make([]string, 0, len(related))
+		for id := range related {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		findings[i].RelatedRuleIDs = ids
+	}
+}
@@ -1,7 +1,16 @@
 package analyzer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/dashboard-advisor/pkg/rules"
 )
 
 func TestAnalyzeSlowDashboard(t *testing.T) {
@@ -75,6 +84,121 @@ func TestAnalyzePanelScores(t *testing.T) {
 	}
 }
 
+func TestAnalyzePanelTitlesAndCosts(t *testing.T) {
+	report, err := DefaultEngine().AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	for pid := range report.PanelScores {
+		if _, ok := report.PanelTitlesByID[pid]; !ok {
+			t.Errorf("panel %d has a score but no entry in PanelTitlesByID", pid)
+		}
+	}
+	for _, f := range report.Findings {
+		for i, pid := range f.PanelIDs {
+			if got := report.PanelTitlesByID[pid]; got != f.PanelTitles[i] {
+				t.Errorf("PanelTitlesByID[%d] = %q, finding %s says %q", pid, got, f.RuleID, f.PanelTitles[i])
+			}
+		}
+	}
+
+	// Annotation queries are costed but belong to no panel.
+	dash, err := extractor.LoadDashboard(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotation := make(map[string]bool)
+	for _, expr := range extractor.AnnotationExprs(dash) {
+		annotation[expr] = true
+	}
+	if len(annotation) == 0 {
+		t.Fatal("slow-by-design.json should have an annotation query")
+	}
+
+	var panelTotal, exprTotal float64
+	for _, c := range report.PanelCosts {
+		panelTotal += c
+	}
+	for expr, c := range report.Metadata.QueryCosts {
+		if !annotation[expr] {
+			exprTotal += c
+		}
+	}
+	if panelTotal < exprTotal {
+		t.Errorf("summed panel costs %.0f < summed panel expression costs %.0f; every parsed target expression belongs to a panel", panelTotal, exprTotal)
+	}
+}
+
+func TestAutoFixableCount(t *testing.T) {
+	report, err := DefaultEngine().AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	want := 0
+	for _, f := range report.Findings {
+		if f.AutoFixable {
+			want++
+		}
+	}
+	if want == 0 {
+		t.Fatal("slow dashboard should have auto-fixable findings")
+	}
+	if report.Metadata.AutoFixableCount != want {
+		t.Errorf("AutoFixableCount = %d, want %d", report.Metadata.AutoFixableCount, want)
+	}
+	wantPct := 100 * float64(want) / float64(len(report.Findings))
+	if report.Metadata.AutoFixablePct != wantPct {
+		t.Errorf("AutoFixablePct = %.2f, want %.2f", report.Metadata.AutoFixablePct, wantPct)
+	}
+}
+
+func TestFindingSourcePositions(t *testing.T) {
+	data := []byte(`{
+  "uid": "positions",
+  "panels": [
+    {
+      "id": 7,
+      "type": "timeseries",
+      "title": "Filtered",
+      "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"}]
+    },
+    {
+      "id": 8,
+      "type": "timeseries",
+      "title": "Unfiltered",
+      "targets": [
+        {"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"},
+        {"refId": "B", "expr": "sum(rate(http_requests_total[5m]))"}
+      ]
+    }
+  ]
+}`)
+	report, err := DefaultEngine().AnalyzeBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var q1 *rules.Finding
+	for i, f := range report.Findings {
+		if f.RuleID == "Q1" {
+			q1 = &report.Findings[i]
+		}
+	}
+	if q1 == nil {
+		t.Fatal("expected a Q1 finding for the unfiltered target")
+	}
+	if q1.Line != 16 || q1.Col != 32 {
+		t.Errorf("Q1 finding at %d:%d, want 16:32 (panel 8, target B expr)", q1.Line, q1.Col)
+	}
+
+	for _, f := range report.Findings {
+		if len(f.PanelIDs) > 0 && f.Line == 0 {
+			t.Errorf("%s finding on panel %v has no source position", f.RuleID, f.PanelIDs)
+		}
+	}
+}
+
 func TestAnalyzeNonexistentFile(t *testing.T) {
 	engine := DefaultEngine()
 	_, err := engine.AnalyzeFile("/nonexistent/dashboard.json")
@@ -82,3 +206,390 @@ func TestAnalyzeNonexistentFile(t *testing.T) {
 		t.Error("expected error for nonexistent file")
 	}
 }
+
+func TestEstimatedQueriesPerRefresh(t *testing.T) {
+	engine := DefaultEngine()
+	slow, err := engine.AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	fixed, err := engine.AnalyzeFile(testdataPath("fixed-by-advisor.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	t.Logf("estimated queries per refresh: slow=%d fixed=%d",
+		slow.Metadata.EstimatedQueriesPerRefresh, fixed.Metadata.EstimatedQueriesPerRefresh)
+
+	// Fixed: 10 visible panels with one target each, no Include All variables.
+	if fixed.Metadata.EstimatedQueriesPerRefresh != 10 {
+		t.Errorf("fixed dashboard estimate = %d, want 10", fixed.Metadata.EstimatedQueriesPerRefresh)
+	}
+	// Slow: two multi-select Include All variables fan out every visible target 100×100.
+	if slow.Metadata.EstimatedQueriesPerRefresh%10_000 != 0 {
+		t.Errorf("slow dashboard estimate = %d, want a multiple of the 10000 variable fan-out", slow.Metadata.EstimatedQueriesPerRefresh)
+	}
+	if slow.Metadata.EstimatedQueriesPerRefresh <= fixed.Metadata.EstimatedQueriesPerRefresh*1000 {
+		t.Errorf("slow estimate %d should dwarf fixed estimate %d",
+			slow.Metadata.EstimatedQueriesPerRefresh, fixed.Metadata.EstimatedQueriesPerRefresh)
+	}
+}
+
+// countingRule records how many times it ran and cancels the analysis
+// context on its first run.
+type countingRule struct {
+	runs   int
+	cancel context.CancelFunc
+}
+
+func (r *countingRule) ID() string                  { return "T1" }
+func (r *countingRule) RuleSeverity() rules.Severity { return rules.Low }
+
+func (r *countingRule) Check(_ *rules.AnalysisContext) []rules.Finding {
+	r.runs++
+	if r.cancel != nil {
+		r.cancel()
+	}
+	return []rules.Finding{{RuleID: "T1", Severity: rules.Low}}
+}
+
+func TestAnalyzeDashboardContext_Canceled(t *testing.T) {
+	dash, err := extractor.LoadDashboard(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	report, err := DefaultEngine().AnalyzeDashboardContext(ctx, dash)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled analysis took %s, expected prompt return", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if report == nil {
+		t.Fatal("expected a partial report alongside the error")
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("no rule should run on a canceled context, got %d findings", len(report.Findings))
+	}
+	if report.Metadata.TotalPanels == 0 {
+		t.Error("partial report should still carry dashboard metadata")
+	}
+}
+
+func TestAnalyzeDashboardContext_StopsBetweenRules(t *testing.T) {
+	dash, err := extractor.LoadDashboard(testdataPath("fixed-by-advisor.json"))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := &countingRule{cancel: cancel}
+	second := &countingRule{}
+
+	engine := NewEngine()
+	engine.RegisterRule(first)
+	engine.RegisterRule(second)
+
+	report, err := engine.AnalyzeDashboardContext(ctx, dash)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if first.runs != 1 || second.runs != 0 {
+		t.Errorf("runs = %d/%d, want 1/0", first.runs, second.runs)
+	}
+	if len(report.Findings) != 1 {
+		t.Errorf("partial report has %d findings, want the 1 from the first rule", len(report.Findings))
+	}
+}
+
+func TestRelatedRuleIDs_SlowDashboard(t *testing.T) {
+	report, err := DefaultEngine().AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	panelRules := map[int]map[string]bool{}
+	for _, f := range report.Findings {
+		for _, pid := range f.PanelIDs {
+			if panelRules[pid] == nil {
+				panelRules[pid] = map[string]bool{}
+			}
+			panelRules[pid][f.RuleID] = true
+		}
+	}
+
+	var q1Panel1 *rules.Finding
+	for i, f := range report.Findings {
+		if f.RuleID == "Q1" && len(f.PanelIDs) == 1 && f.PanelIDs[0] == 1 {
+			q1Panel1 = &report.Findings[i]
+		}
+		for _, rel := range f.RelatedRuleIDs {
+			if rel == f.RuleID {
+				t.Errorf("%s finding lists itself as related", f.RuleID)
+			}
+			shared := false
+			for _, pid := range f.PanelIDs {
+				shared = shared || panelRules[pid][rel]
+			}
+			if !shared {
+				t.Errorf("%s finding on panels %v lists %s, which flags none of them", f.RuleID, f.PanelIDs, rel)
+			}
+		}
+		if len(f.PanelIDs) == 0 && len(f.RelatedRuleIDs) > 0 {
+			t.Errorf("dashboard-level %s finding should have no related rules", f.RuleID)
+		}
+	}
+
+	// Panel 1 is a bare selector aggregated late: Q1 and Q5 overlap there.
+	if q1Panel1 == nil {
+		t.Fatal("expected a Q1 finding on panel 1")
+	}
+	hasQ5 := false
+	for _, rel := range q1Panel1.RelatedRuleIDs {
+		hasQ5 = hasQ5 || rel == "Q5"
+	}
+	if !hasQ5 {
+		t.Errorf("Q1 on panel 1 related = %v, want it to include Q5", q1Panel1.RelatedRuleIDs)
+	}
+}
+
+func TestDedupeScore(t *testing.T) {
+	engine := DefaultEngine()
+	plain, err := engine.AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	engine.WithDedupeScore(true)
+	deduped, err := engine.AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	if len(deduped.Findings) != len(plain.Findings) {
+		t.Errorf("dedupe should keep all findings: %d vs %d", len(deduped.Findings), len(plain.Findings))
+	}
+	if deduped.Score <= plain.Score {
+		t.Errorf("deduped score %d should exceed plain score %d on overlapping findings", deduped.Score, plain.Score)
+	}
+
+	fixed, err := engine.AnalyzeFile(testdataPath("fixed-by-advisor.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if fixed.Score != 100 {
+		t.Errorf("fixed dashboard deduped score = %d, want 100", fixed.Score)
+	}
+}
+
+func TestReplaceRule(t *testing.T) {
+	engine := DefaultEngine()
+	before := len(engine.rules)
+	engine.ReplaceRule(&rules.TooManyPanels{MaxPanels: 1000})
+	if len(engine.rules) != before {
+		t.Fatalf("ReplaceRule changed rule count: %d -> %d", before, len(engine.rules))
+	}
+
+	report, err := engine.AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.RuleID == "D1" {
+			t.Errorf("D1 should not fire with MaxPanels 1000: %s", f.Why)
+		}
+	}
+}
+
+func TestAnalyzePanelsContext_MatchesFullAnalysis(t *testing.T) {
+	dash, err := extractor.LoadDashboard(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	engine := DefaultEngine()
+	full := engine.AnalyzeDashboard(dash)
+
+	// The full analysis, restricted to per-panel rules on panel 1, is what
+	// the incremental path must reproduce.
+	want := map[string]int{}
+	for _, f := range full.Findings {
+		if len(f.PanelIDs) == 1 && f.PanelIDs[0] == 1 && rules.IsPanelRule(ruleByID(engine, f.RuleID)) {
+			want[f.RuleID+" "+f.TargetExpr]++
+		}
+	}
+	if len(want) == 0 {
+		t.Fatal("fixture panel 1 should have per-panel findings")
+	}
+
+	findings, err := engine.AnalyzePanelsContext(context.Background(), dash, 1)
+	if err != nil {
+		t.Fatalf("AnalyzePanelsContext: %v", err)
+	}
+	got := map[string]int{}
+	for _, f := range findings {
+		if len(f.PanelIDs) != 1 || f.PanelIDs[0] != 1 {
+			t.Errorf("%s finding on panels %v, want only panel 1", f.RuleID, f.PanelIDs)
+		}
+		got[f.RuleID+" "+f.TargetExpr]++
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Errorf("finding %q: got %d, want %d", k, got[k], n)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d distinct findings, want %d", len(got), len(want))
+	}
+}
+
+func ruleByID(e *Engine, id string) rules.Rule {
+	for _, r := range e.Rules() {
+		if r.ID() == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestSeverityOverrides_ChangeScore(t *testing.T) {
+	// A dashboard with few findings: the slow demo's score is already at the
+	// floor, so one more Critical finding would not move it.
+	dashboard := []byte(`{"uid": "refresh", "refresh": "5s", "time": {"from": "now-1h", "to": "now"}, "panels": []}`)
+	baseline, err := DefaultEngine().AnalyzeBytes(dashboard)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	engine := DefaultEngine()
+	engine.WithSeverityOverrides(map[string]rules.Severity{"D5": rules.Critical})
+	report, err := engine.AnalyzeBytes(dashboard)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	var sawD5 bool
+	for _, f := range report.Findings {
+		if f.RuleID == "D5" {
+			sawD5 = true
+			if f.Severity != rules.Critical {
+				t.Errorf("D5 severity = %s, want the Critical override", f.Severity)
+			}
+		}
+	}
+	if !sawD5 {
+		t.Fatal("dashboard should have a D5 finding")
+	}
+	if report.Score >= baseline.Score {
+		t.Errorf("score with D5 as Critical = %d, want below the baseline %d", report.Score, baseline.Score)
+	}
+}
+
+func TestReportMetadata_Tags(t *testing.T) {
+	report, err := DefaultEngine().AnalyzeFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	want := []string{"slow-by-design", "performance-test", "anti-patterns"}
+	if !reflect.DeepEqual(report.Metadata.Tags, want) {
+		t.Errorf("Metadata.Tags = %v, want %v", report.Metadata.Tags, want)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded rules.Report
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Metadata.Tags, want) {
+		t.Errorf("tags after JSON round trip = %v, want %v", decoded.Metadata.Tags, want)
+	}
+
+	report, err = DefaultEngine().AnalyzeBytes([]byte(`{"uid": "untagged", "panels": []}`))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if report.Metadata.Tags != nil {
+		t.Errorf("untagged dashboard: Metadata.Tags = %v, want nil", report.Metadata.Tags)
+	}
+}
+
+func TestReportMetadata_NormalizedExprs(t *testing.T) {
+	raw := `sum(rate(http_requests_total{job="$job"}[$__rate_interval]))`
+	report, err := DefaultEngine().AnalyzeBytes([]byte(`{"uid": "debug", "panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests", "targets": [{"expr": ` + strconv.Quote(raw) + `, "refId": "A"}]}
+	]}`))
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	want := `sum(rate(http_requests_total{job="placeholder"}[5m]))`
+	if got := report.Metadata.NormalizedExprs[raw]; got != want {
+		t.Errorf("NormalizedExprs[%q] = %q, want %q", raw, got, want)
+	}
+}
+
+// manyExprsDashboard builds a dashboard with one timeseries panel per
+// distinct expression.
+func manyExprsDashboard(n int) *extractor.DashboardModel {
+	dash := &extractor.DashboardModel{Title: "many exprs"}
+	for i := 0; i < n; i++ {
+		dash.Panels = append(dash.Panels, extractor.PanelModel{
+			ID:   i + 1,
+			Type: "timeseries",
+			Targets: []extractor.TargetModel{
+				{RefID: "A", Expr: `up{job="job-` + strconv.Itoa(i) + `"}`},
+			},
+		})
+	}
+	return dash
+}
+
+func TestWithMaxExprs(t *testing.T) {
+	engine := DefaultEngine()
+	engine.WithMaxExprs(50)
+
+	report, err := engine.AnalyzeDashboardContext(context.Background(), manyExprsDashboard(51))
+	if !errors.Is(err, ErrTooManyExprs) {
+		t.Fatalf("err = %v, want ErrTooManyExprs", err)
+	}
+	if report != nil {
+		t.Error("no report expected for a dashboard over the cap")
+	}
+	if _, err := engine.AnalyzePanelsContext(context.Background(), manyExprsDashboard(51), 1); !errors.Is(err, ErrTooManyExprs) {
+		t.Errorf("AnalyzePanelsContext err = %v, want ErrTooManyExprs", err)
+	}
+
+	if _, err := engine.AnalyzeDashboardContext(context.Background(), manyExprsDashboard(50)); err != nil {
+		t.Errorf("dashboard exactly at the cap: %v", err)
+	}
+
+	engine.WithMaxExprs(0)
+	if _, err := engine.AnalyzeDashboardContext(context.Background(), manyExprsDashboard(51)); err != nil {
+		t.Errorf("cap disabled: %v", err)
+	}
+}
+
+// Repeated expressions count once per target: every copy is still checked
+// by the rules and reported.
+func TestWithMaxExprs_RepeatedExpression(t *testing.T) {
+	dash := &extractor.DashboardModel{Title: "repeated"}
+	for i := 0; i < 60; i++ {
+		dash.Panels = append(dash.Panels, extractor.PanelModel{
+			ID:      i + 1,
+			Type:    "timeseries",
+			Targets: []extractor.TargetModel{{RefID: "A", Expr: `up{job="api"}`}},
+		})
+	}
+	engine := DefaultEngine()
+	engine.WithMaxExprs(50)
+	if _, err := engine.AnalyzeDashboardContext(context.Background(), dash); !errors.Is(err, ErrTooManyExprs) {
+		t.Fatalf("err = %v, want ErrTooManyExprs for 60 copies of one expression", err)
+	}
+}
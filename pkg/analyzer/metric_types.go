@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/rules"
+	"go.yaml.in/yaml/v2"
+)
+
+// LoadMetricTypes reads a metric classification file, a YAML or JSON
+// mapping of metric name or prefix to type:
+//
+//	my_custom_temp: gauge
+//	acme_queue_*: gauge
+//	acme_jobs_processed: counter
+//
+// See rules.MetricTypes for how names and prefixes are matched.
+func LoadMetricTypes(path string) (rules.MetricTypes, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading metric types file: %w", err)
+	}
+	return ParseMetricTypes(data)
+}
+
+// ParseMetricTypes parses the contents of a metric classification file.
+// Types are case-insensitive; an unknown type is an error naming every
+// offending entry.
+func ParseMetricTypes(data []byte) (rules.MetricTypes, error) {
+	var raw map[string]string
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing metric types: %w", err)
+	}
+
+	types := make(rules.MetricTypes, len(raw))
+	var invalid []string
+	for name, typ := range raw {
+		t := rules.MetricType(strings.ToLower(strings.TrimSpace(typ)))
+		if !t.Valid() {
+			invalid = append(invalid, fmt.Sprintf("%s: %q", name, typ))
+			continue
+		}
+		types[strings.TrimSpace(name)] = t
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, fmt.Errorf("parsing metric types: unknown type for %s (want counter, gauge, histogram or summary)", strings.Join(invalid, ", "))
+	}
+	return types, nil
+}
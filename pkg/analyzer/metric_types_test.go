@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+func TestParseMetricTypes(t *testing.T) {
+	yamlTypes, err := ParseMetricTypes([]byte("my_custom_temp: gauge\nacme_queue_*: Gauge\nacme_jobs_processed: counter\n"))
+	if err != nil {
+		t.Fatalf("YAML: %v", err)
+	}
+	jsonTypes, err := ParseMetricTypes([]byte("{\n\t\"my_custom_temp\": \"gauge\",\n\t\"acme_queue_*\": \"gauge\",\n\t\"acme_jobs_processed\": \"counter\"\n}"))
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	for _, types := range []rules.MetricTypes{yamlTypes, jsonTypes} {
+		if got, _ := types.Lookup("acme_queue_depth"); got != rules.MetricTypeGauge {
+			t.Errorf("acme_queue_depth: got %q, want gauge", got)
+		}
+		if got, _ := types.Lookup("acme_jobs_processed"); got != rules.MetricTypeCounter {
+			t.Errorf("acme_jobs_processed: got %q, want counter", got)
+		}
+	}
+
+	_, err = ParseMetricTypes([]byte("a: gauge\nb: meter\n"))
+	if err == nil || !strings.Contains(err.Error(), `b: "meter"`) {
+		t.Errorf("unknown type: got error %v, want one naming b", err)
+	}
+}
+
+func TestWithMetricTypes_Q11(t *testing.T) {
+	dash := `{"uid": "custom", "panels": [{"id": 1, "type": "timeseries", "title": "Temp",
+		"targets": [{"expr": "rate(my_custom_temp{job=\"sensor\"}[5m])", "refId": "A"}]}]}`
+	countQ11 := func(e *Engine) int {
+		report, err := e.AnalyzeBytes([]byte(dash))
+		if err != nil {
+			t.Fatalf("analysis failed: %v", err)
+		}
+		n := 0
+		for _, f := range report.Findings {
+			if f.RuleID == "Q11" {
+				n++
+			}
+		}
+		return n
+	}
+
+	engine := DefaultEngine()
+	if n := countQ11(engine); n != 0 {
+		t.Fatalf("Q11 without metric types: got %d findings, want 0", n)
+	}
+	engine.WithMetricTypes(rules.MetricTypes{"my_custom_temp": rules.MetricTypeGauge})
+	if n := countQ11(engine); n != 1 {
+		t.Errorf("Q11 with my_custom_temp classified as a gauge: got %d findings, want 1", n)
+	}
+}
@@ -1,7 +1,6 @@
 package analyzer
 
 import (
-	"log"
 	"strings"
 
 	"github.com/prometheus/prometheus/promql/parser"
@@ -18,31 +17,51 @@ type ParseResult struct {
 // Returns a map from raw expression string to parsed AST.
 // Grafana template variables ($__rate_interval, $variable, etc.) are replaced
 // with parseable placeholders before parsing.
-// Unparseable expressions are logged and skipped — never crash.
+// Unparseable expressions are skipped and returned in errors — never crash.
 func ParseAllExprs(exprs []string) (parsed map[string]parser.Expr, errors []ParseResult) {
+	parsed, _, errors = parseExprs(exprs)
+	return parsed, errors
+}
+
+// parseExprs is ParseAllExprs that also returns, for every non-empty raw
+// expression, the text actually handed to the parser after
+// ReplaceTemplateVars — including expressions that then failed to parse.
+func parseExprs(exprs []string) (parsed map[string]parser.Expr, normalizedExprs map[string]string, errors []ParseResult) {
 	parsed = make(map[string]parser.Expr, len(exprs))
+	normalizedExprs = make(map[string]string, len(exprs))
 	for _, raw := range exprs {
 		if raw == "" {
 			continue
 		}
 		normalized := ReplaceTemplateVars(raw)
+		normalizedExprs[raw] = normalized
 		expr, err := parser.ParseExpr(normalized)
 		if err != nil {
-			log.Printf("WARN: unparseable PromQL (skipped): %q — %v", raw, err)
 			errors = append(errors, ParseResult{RawExpr: raw, ParseErr: err})
 			continue
 		}
 		// Key by the original raw expression so rules can map back to panels
 		parsed[raw] = expr
 	}
-	return parsed, errors
+	return parsed, normalizedExprs, errors
 }
 
 // ReplaceTemplateVars replaces Grafana template variables with parseable
 // PromQL-compatible placeholders so the Prometheus parser can handle them.
 //
 // Duration variables ($__rate_interval, $__interval, $__range) → "5m"
+// Millisecond and second variables ($__interval_ms, $__range_ms, $__range_s)
+// → the same 5m as a number
 // Label value variables ($variable) → "placeholder"
+var grafanaNumberVars = []struct{ name, value string }{
+	{"$__interval_ms", "300000"},
+	{"$__range_ms", "300000"},
+	{"$__range_s", "300"},
+	{"${__interval_ms}", "300000"},
+	{"${__range_ms}", "300000"},
+	{"${__range_s}", "300"},
+}
+
 var grafanaDurationVars = []string{
 	"$__rate_interval",
 	"$__interval",
@@ -55,6 +74,12 @@ var grafanaDurationVars = []string{
 func ReplaceTemplateVars(expr string) string {
 	result := expr
 
+	// Replace numeric variables first: $__interval is a prefix of
+	// $__interval_ms.
+	for _, v := range grafanaNumberVars {
+		result = strings.ReplaceAll(result, v.name, v.value)
+	}
+
 	// Replace Grafana duration variables with a parseable duration
 	for _, v := range grafanaDurationVars {
 		result = strings.ReplaceAll(result, v, "5m")
@@ -69,6 +94,10 @@ func ReplaceTemplateVars(expr string) string {
 
 // replaceVariableRefs replaces $var and ${var} references with "placeholder".
 // Only replaces in label value positions (inside quotes or as bare values).
+// A reference that makes up a whole range ("[$interval]", "[$interval:]") is
+// an interval variable and becomes "5m" like the built-in duration variables.
+// A run of "$" before a reference ("$$var") is consumed with it, so the
+// output never has a "$" directly in front of a replacement.
 func replaceVariableRefs(expr string) string {
 	var b strings.Builder
 	b.Grow(len(expr))
@@ -80,39 +109,66 @@ func replaceVariableRefs(expr string) string {
 			continue
 		}
 
-		// Found $, check what follows
-		if i+1 >= len(expr) {
-			b.WriteByte(expr[i])
-			i++
-			continue
+		// Found $, skip any further $ and check what follows
+		j := i
+		for j < len(expr) && expr[j] == '$' {
+			j++
 		}
-
-		if expr[i+1] == '{' {
-			// ${var} form
-			end := strings.IndexByte(expr[i:], '}')
-			if end == -1 {
-				b.WriteByte(expr[i])
-				i++
-				continue
-			}
-			b.WriteString("placeholder")
-			i += end + 1
-		} else if isIdentStart(expr[i+1]) {
+		end := -1
+		if j < len(expr) && expr[j] == '{' {
+			// ${var} or ${var:format} form
+			end = bracedRefEnd(expr, j)
+		} else if j < len(expr) && isIdentStart(expr[j]) {
 			// $var form — consume identifier chars
-			j := i + 1
-			for j < len(expr) && isIdentChar(expr[j]) {
-				j++
+			end = j + 1
+			for end < len(expr) && isIdentChar(expr[end]) {
+				end++
 			}
-			b.WriteString("placeholder")
+		}
+		if end == -1 {
+			b.WriteString(expr[i:j])
 			i = j
+			continue
+		}
+		if i > 0 && expr[i-1] == '[' && end < len(expr) && (expr[end] == ']' || expr[end] == ':') {
+			b.WriteString("5m")
 		} else {
-			b.WriteByte(expr[i])
-			i++
+			b.WriteString("placeholder")
 		}
+		i = end
 	}
 	return b.String()
 }
 
+// bracedRefEnd returns the index just past the "}" closing the braced
+// reference whose "{" is at expr[open], or -1 if it is not a reference.
+// Nested references ("${a${b}}") are consumed whole; otherwise replacing
+// the inner one would turn the outer text into a new reference. An empty
+// reference, or one containing a quote, bare brace or whitespace, is
+// rejected: its "}" belongs to the surrounding PromQL.
+func bracedRefEnd(expr string, open int) int {
+	depth := 0
+	for k := open + 1; k < len(expr); k++ {
+		switch c := expr[k]; {
+		case c == '$' && k+1 < len(expr) && expr[k+1] == '{':
+			depth++
+			k++
+		case c == '}':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if k == open+1 {
+				return -1
+			}
+			return k + 1
+		case c == '{' || c == '"' || c == '\'' || c == '`' || c <= ' ':
+			return -1
+		}
+	}
+	return -1
+}
+
 func isIdentStart(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
 }
@@ -122,6 +122,21 @@ func TestReplaceTemplateVars(t *testing.T) {
 			`rate(http_requests_total[$__interval])`,
 			`rate(http_requests_total[5m])`,
 		},
+		{
+			"interval_ms",
+			`increase(x[$__interval]) / $__interval_ms * 1000`,
+			`increase(x[5m]) / 300000 * 1000`,
+		},
+		{
+			"range_s",
+			`increase(x[${__range}]) / ${__range_s}`,
+			`increase(x[5m]) / 300`,
+		},
+		{
+			"interval_variable",
+			`rate(x[$interval]) + max_over_time(y[${interval}:])`,
+			`rate(x[5m]) + max_over_time(y[5m:])`,
+		},
 		{
 			"dollar_var",
 			`up{namespace="$namespace"}`,
@@ -142,6 +157,31 @@ func TestReplaceTemplateVars(t *testing.T) {
 			`up{job="$job", namespace="$namespace"}`,
 			`up{job="placeholder", namespace="placeholder"}`,
 		},
+		{
+			"format_spec",
+			`up{instance=~"${instance:regex}"}`,
+			`up{instance=~"placeholder"}`,
+		},
+		{
+			"double_dollar",
+			`up{job="$$job"}`,
+			`up{job="placeholder"}`,
+		},
+		{
+			"nested_braced",
+			`up{job="${a${b}}"}`,
+			`up{job="placeholder"}`,
+		},
+		{
+			"unterminated_braced_keeps_matcher",
+			`up{x="${foo"} + rate(y[5m])`,
+			`up{x="${foo"} + rate(y[5m])`,
+		},
+		{
+			"trailing_dollar",
+			`up{job="api"} $`,
+			`up{job="api"} $`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -152,3 +192,39 @@ func TestReplaceTemplateVars(t *testing.T) {
 		})
 	}
 }
+
+func FuzzReplaceTemplateVars(f *testing.F) {
+	for _, seed := range []string{
+		`rate(http_requests_total[$__rate_interval])`,
+		`up{namespace="${namespace}", pod=~"$pod"}`,
+		`up{instance=~"${instance:regex}"}`,
+		`sum(rate(x[${__range}]))`,
+		`$`, `$$`, `$$foo`, `${`, `${}`, `${a`, `${a${b}}`, `{${a}}`,
+		`up{x="${foo"} + rate(y[5m])`,
+		`$__rate$__interval_interval`,
+		`x / $__interval_ms`, `$$__range_s`,
+		`rate(x[$interval])`, `y[$$a:$b]`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		out := ReplaceTemplateVars(expr)
+
+		// The longest expansion is "$a" (2 bytes) → "placeholder" (11 bytes).
+		if len(out) > 6*len(expr) {
+			t.Errorf("output grew from %d to %d bytes", len(expr), len(out))
+		}
+		// Everything that looked like a variable was replaced, so a second
+		// pass must not find anything new.
+		if again := ReplaceTemplateVars(out); again != out {
+			t.Errorf("not idempotent for %q:\n  once  %q\n  twice %q", expr, out, again)
+		}
+		for i := 0; i+1 < len(out); i++ {
+			if out[i] == '$' && isIdentStart(out[i+1]) {
+				t.Errorf("unreplaced variable at byte %d in %q (input %q)", i, out, expr)
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// noTitleRule stands in for an org-specific rule defined outside the
+// rules package: it flags dashboards without a title.
+type noTitleRule struct{}
+
+func (r *noTitleRule) ID() string                   { return "ORG1" }
+func (r *noTitleRule) RuleSeverity() rules.Severity { return rules.Low }
+
+func (r *noTitleRule) Check(ctx *rules.AnalysisContext) []rules.Finding {
+	if ctx.Dashboard.Title != "" {
+		return nil
+	}
+	return []rules.Finding{{
+		RuleID:   "ORG1",
+		Severity: rules.Low,
+		Title:    "Dashboard has no title",
+	}}
+}
+
+func init() {
+	rules.Register("org/no-title", func() rules.Rule { return &noTitleRule{} })
+}
+
+func TestNewEngineWithRegistered(t *testing.T) {
+	engine := analyzer.NewEngineWithRegistered()
+
+	registered := false
+	for _, r := range engine.Rules() {
+		if r.ID() == "ORG1" {
+			registered = true
+		}
+	}
+	if !registered {
+		t.Fatal("registered rule ORG1 missing from engine.Rules()")
+	}
+	if got, want := len(engine.Rules()), len(analyzer.DefaultEngine().Rules())+1; got != want {
+		t.Errorf("engine has %d rules, want built-ins plus one (%d)", got, want)
+	}
+
+	report, err := engine.AnalyzeBytes([]byte(`{"uid": "untitled", "panels": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, f := range report.Findings {
+		if f.RuleID == "ORG1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("ORG1 finding missing from report: %+v", report.Findings)
+	}
+
+	report, err = engine.AnalyzeBytes([]byte(`{"uid": "titled", "title": "API", "panels": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range report.Findings {
+		if f.RuleID == "ORG1" {
+			t.Errorf("ORG1 should not fire on a titled dashboard")
+		}
+	}
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering the same name twice should panic")
+		}
+	}()
+	rules.Register("org/no-title", func() rules.Rule { return &noTitleRule{} })
+}
@@ -1,9 +1,12 @@
 package cardinality
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -11,14 +14,33 @@ import (
 
 const cacheTTL = 5 * time.Minute
 
+// failureTTL is how long a failed fetch is remembered, so a Prometheus that
+// is down costs one round of retries per interval rather than per analysis.
+const failureTTL = 30 * time.Second
+
+const (
+	defaultMaxAttempts = 3
+	defaultBackoff     = 200 * time.Millisecond
+	maxRetryAfter      = 10 * time.Second
+)
+
 // Client fetches cardinality data from the Prometheus TSDB status API.
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
 
+	// MaxAttempts bounds how many requests a single fetch makes when the API
+	// fails with a retryable error. Zero means defaultMaxAttempts.
+	MaxAttempts int
+
+	// backoff is the delay before the first retry; it doubles per attempt.
+	backoff time.Duration
+
 	mu       sync.Mutex
 	cached   *CardinalityData
 	cachedAt time.Time
+	failed   error // last fetch error, returned as is until failureTTL passes
+	failedAt time.Time
 }
 
 // NewClient creates a cardinality client for the given Prometheus base URL.
@@ -26,28 +48,54 @@ func NewClient(baseURL string, timeout time.Duration) *Client {
 	return &Client{
 		baseURL:    strings.TrimRight(baseURL, "/"),
 		httpClient: &http.Client{Timeout: timeout},
+		backoff:    defaultBackoff,
 	}
 }
 
+func (c *Client) maxAttempts() int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
 // Fetch retrieves cardinality data, using cache if fresh.
 // Returns (nil, error) if the API is unreachable — caller should log and continue.
 func (c *Client) Fetch() (*CardinalityData, error) {
+	return c.FetchContext(context.Background())
+}
+
+// FetchContext is Fetch bounded by ctx: requests and the waits between
+// retries stop when ctx is done. A failure other than ctx's own is
+// remembered for failureTTL and returned without contacting the API again.
+func (c *Client) FetchContext(ctx context.Context) (*CardinalityData, error) {
 	c.mu.Lock()
 	if c.cached != nil && time.Since(c.cachedAt) < cacheTTL {
 		data := c.cached
 		c.mu.Unlock()
 		return data, nil
 	}
+	if c.failed != nil && time.Since(c.failedAt) < failureTTL {
+		err := c.failed
+		c.mu.Unlock()
+		return nil, err
+	}
 	c.mu.Unlock()
 
-	data, err := c.fetchFromAPI()
+	data, err := c.fetchFromAPI(ctx)
 	if err != nil {
+		if ctx.Err() == nil {
+			c.mu.Lock()
+			c.failed, c.failedAt = err, time.Now()
+			c.mu.Unlock()
+		}
 		return nil, err
 	}
 
 	c.mu.Lock()
 	c.cached = data
 	c.cachedAt = time.Now()
+	c.failed = nil
 	c.mu.Unlock()
 
 	return data, nil
@@ -75,16 +123,67 @@ type nameValuePair struct {
 	Value int    `json:"value"`
 }
 
-func (c *Client) fetchFromAPI() (*CardinalityData, error) {
+// retryableError marks a failure worth another attempt: connection errors,
+// 5xx responses and 429s. after carries a server-requested delay, if any.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// fetchFromAPI calls the TSDB status API, retrying retryable failures with
+// exponential backoff. 4xx responses (other than 429) and decode errors are
+// returned immediately, and so is ctx's error once it is done.
+func (c *Client) fetchFromAPI(ctx context.Context) (*CardinalityData, error) {
+	delay := c.backoff
+	attempts := c.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		data, err := c.fetchOnce(ctx)
+		if err == nil {
+			return data, nil
+		}
+		var re *retryableError
+		if !errors.As(err, &re) || attempt >= attempts || ctx.Err() != nil {
+			return nil, err
+		}
+		wait := delay
+		if re.after > 0 {
+			wait = re.after
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("fetching TSDB status: %w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) fetchOnce(ctx context.Context) (*CardinalityData, error) {
 	url := c.baseURL + "/api/v1/status/tsdb"
-	resp, err := c.httpClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("fetching TSDB status from %s: %w", url, err)
 	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &retryableError{err: fmt.Errorf("fetching TSDB status from %s: %w", url, err)}
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TSDB status API returned %d from %s", resp.StatusCode, url)
+		err := fmt.Errorf("TSDB status API returned %d from %s", resp.StatusCode, url)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return nil, &retryableError{err: err, after: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		case resp.StatusCode >= 500:
+			return nil, &retryableError{err: err}
+		}
+		return nil, err
 	}
 
 	var tsdb tsdbStatusResponse
@@ -115,3 +214,22 @@ func (c *Client) fetchFromAPI() (*CardinalityData, error) {
 
 	return data, nil
 }
+
+// parseRetryAfter reads a Retry-After header given either as delay seconds or
+// as an HTTP date. The result is capped so a misbehaving server cannot stall
+// analysis; zero means the header was absent or unparseable.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	}
+	if d < 0 {
+		return 0
+	}
+	return min(d, maxRetryAfter)
+}
@@ -1,6 +1,8 @@
 package cardinality
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -68,6 +70,7 @@ func TestFetch_ServerError(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient(srv.URL, 5*time.Second)
+	client.backoff = time.Millisecond
 	data, err := client.Fetch()
 	if err == nil {
 		t.Fatal("expected error for 500 response")
@@ -145,6 +148,7 @@ func TestFetch_Caching(t *testing.T) {
 
 func TestFetch_Unreachable(t *testing.T) {
 	client := NewClient("http://127.0.0.1:1", 1*time.Second)
+	client.backoff = time.Millisecond
 	data, err := client.Fetch()
 	if err == nil {
 		t.Fatal("expected error for unreachable server")
@@ -197,3 +201,124 @@ func TestLabelCardinality(t *testing.T) {
 		t.Errorf("nil receiver: got %d, want 100", got)
 	}
 }
+
+func TestFetch_RetriesThenSucceeds(t *testing.T) {
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if callCount <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(validTSDBResponse))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, 5*time.Second)
+	client.backoff = time.Millisecond
+	data, err := client.Fetch()
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if data == nil || data.HeadSeriesCount != 54321 {
+		t.Errorf("unexpected data after retries: %+v", data)
+	}
+	if callCount != 3 {
+		t.Errorf("expected 3 API calls, got %d", callCount)
+	}
+}
+
+func TestFetch_NoRetryOnClientError(t *testing.T) {
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, 5*time.Second)
+	client.backoff = time.Millisecond
+	if _, err := client.Fetch(); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if callCount != 1 {
+		t.Errorf("expected 1 API call for 400, got %d", callCount)
+	}
+}
+
+func TestFetch_MaxAttempts(t *testing.T) {
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, 5*time.Second)
+	client.backoff = time.Millisecond
+	client.MaxAttempts = 5
+	if _, err := client.Fetch(); err == nil {
+		t.Fatal("expected error when every attempt is rate limited")
+	}
+	if callCount != 5 {
+		t.Errorf("expected 5 API calls, got %d", callCount)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"2", 2 * time.Second},
+		{"-1", 0},
+		{"garbage", 0},
+		{"3600", maxRetryAfter},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.in); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFetchContext_DeadlineBoundsRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.FetchContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("FetchContext took %s, want it bounded by the 50ms deadline, not Retry-After", elapsed)
+	}
+}
+
+func TestFetch_CachesFailure(t *testing.T) {
+	callCount := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, 5*time.Second)
+	for i := 0; i < 3; i++ {
+		if _, err := client.Fetch(); err == nil {
+			t.Fatal("expected error for 400 response")
+		}
+	}
+	if callCount != 1 {
+		t.Errorf("expected 1 API call (failure cached), got %d", callCount)
+	}
+}
@@ -0,0 +1,70 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// configMap is the subset of a Kubernetes ConfigMap manifest that carries
+// dashboards.
+type configMap struct {
+	Kind string            `json:"kind" yaml:"kind"`
+	Data map[string]string `json:"data" yaml:"data"`
+}
+
+// LoadConfigMap reads a Kubernetes ConfigMap manifest (YAML or JSON) and
+// returns the dashboard JSON of each data entry whose key ends in .json,
+// keyed by that filename.
+func LoadConfigMap(path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading ConfigMap file: %w", err)
+	}
+	return ParseConfigMap(data)
+}
+
+// ParseConfigMap extracts the .json data entries of a ConfigMap manifest.
+// JSON manifests are decoded with encoding/json, since YAML rejects the tab
+// indentation JSON files often use. A value that is itself a JSON string
+// literal — a dashboard encoded twice, as some generators emit — is
+// unquoted once more. Values are not validated as dashboards.
+func ParseConfigMap(data []byte) (map[string][]byte, error) {
+	var cm configMap
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(trimmed, &cm)
+	} else {
+		err = yaml.Unmarshal(data, &cm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing ConfigMap: %w", err)
+	}
+	if cm.Kind != "" && cm.Kind != "ConfigMap" {
+		return nil, fmt.Errorf("parsing ConfigMap: kind is %q, want ConfigMap", cm.Kind)
+	}
+
+	dashboards := make(map[string][]byte)
+	for key, value := range cm.Data {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			var unquoted string
+			if err := json.Unmarshal([]byte(value), &unquoted); err != nil {
+				return nil, fmt.Errorf("decoding ConfigMap entry %s: %w", key, err)
+			}
+			value = unquoted
+		}
+		dashboards[key] = []byte(value)
+	}
+	if len(dashboards) == 0 {
+		return nil, fmt.Errorf("ConfigMap has no .json entries in data")
+	}
+	return dashboards, nil
+}
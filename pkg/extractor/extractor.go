@@ -1,20 +1,76 @@
 package extractor
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
 )
 
-// LoadDashboard reads a Grafana dashboard JSON file and returns a DashboardModel.
+// LoadDashboard reads a Grafana dashboard file and returns a DashboardModel.
+// Files with a .yaml or .yml extension are converted from YAML first; any
+// other file is parsed as JSON.
 func LoadDashboard(path string) (*DashboardModel, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading dashboard file: %w", err)
 	}
+	if IsYAMLPath(path) {
+		if data, err = YAMLToJSON(data); err != nil {
+			return nil, err
+		}
+	}
 	return ParseDashboard(data)
 }
 
+// IsYAMLPath reports whether path names a YAML file by its extension.
+func IsYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// YAMLToJSON converts a YAML dashboard, as some GitOps tools store them, to
+// the equivalent JSON. Mapping keys become strings; the result is compact,
+// so source positions computed from it do not point into the YAML.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing dashboard YAML: %w", err)
+	}
+	out, err := json.Marshal(jsonCompatible(doc))
+	if err != nil {
+		return nil, fmt.Errorf("converting dashboard YAML: %w", err)
+	}
+	return out, nil
+}
+
+// jsonCompatible replaces the map[interface{}]interface{} values the YAML
+// decoder produces, which encoding/json rejects, with map[string]interface{}.
+func jsonCompatible(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonCompatible(v[i])
+		}
+		return v
+	}
+	return v
+}
+
 // ParseDashboard parses raw JSON bytes into a DashboardModel.
 func ParseDashboard(data []byte) (*DashboardModel, error) {
 	var dash DashboardModel
@@ -25,18 +81,61 @@ func ParseDashboard(data []byte) (*DashboardModel, error) {
 }
 
 // AllPanels returns all panels in the dashboard, including panels nested
-// inside collapsed rows. The row panels themselves are included.
+// inside collapsed rows. The row panels themselves are included. A nested
+// panel that repeats a panel with the same ID at the top level, or in an
+// earlier row, is a malformed duplicate and is left out, so it is not
+// counted twice. A nested panel that reuses an ID but differs from the
+// first panel with it (see SamePanel) is kept, so its queries are analyzed.
 func AllPanels(dash *DashboardModel) []PanelModel {
+	first := make(map[int]PanelModel, len(dash.Panels))
+	for _, p := range dash.Panels {
+		if _, dup := first[p.ID]; !dup {
+			first[p.ID] = p
+		}
+	}
 	var all []PanelModel
 	for _, p := range dash.Panels {
 		all = append(all, p)
 		for _, nested := range p.NestedPanels {
+			// Panels without an ID cannot be told apart; keep them all.
+			if nested.ID != 0 {
+				prev, dup := first[nested.ID]
+				if dup && SamePanel(prev, nested) {
+					continue
+				}
+				if !dup {
+					first[nested.ID] = nested
+				}
+			}
 			all = append(all, nested)
 		}
 	}
 	return all
 }
 
+// SamePanel reports whether a and b are copies of one panel: equal apart
+// from their grid position and nested panels, which differ between a
+// panel's top-level and in-row copies, and from JSON formatting.
+func SamePanel(a, b PanelModel) bool {
+	a.GridPos, b.GridPos = nil, nil
+	a.NestedPanels, b.NestedPanels = nil, nil
+	a.Options, b.Options = compactJSON(a.Options), compactJSON(b.Options)
+	return reflect.DeepEqual(a, b)
+}
+
+// compactJSON returns raw without insignificant whitespace, or raw itself
+// if it is not valid JSON.
+func compactJSON(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
 // VisiblePanels returns panels that fire queries on dashboard load.
 // This excludes row-type panels and panels inside collapsed rows.
 func VisiblePanels(dash *DashboardModel) []PanelModel {
@@ -83,6 +182,20 @@ func AllTargetExprs(dash *DashboardModel) []string {
 	return exprs
 }
 
+// AnnotationExprs returns the unique PromQL expressions of enabled annotation
+// queries.
+func AnnotationExprs(dash *DashboardModel) []string {
+	seen := make(map[string]bool)
+	var exprs []string
+	for _, a := range dash.Annotations.List {
+		if a.Enable && a.Expr != "" && !seen[a.Expr] {
+			seen[a.Expr] = true
+			exprs = append(exprs, a.Expr)
+		}
+	}
+	return exprs
+}
+
 // AllDatasourceUIDs returns all distinct datasource UIDs used across panels.
 // Excludes template variable references (UIDs starting with "$").
 func AllDatasourceUIDs(dash *DashboardModel) []string {
@@ -114,3 +227,25 @@ func AllDatasourceUIDs(dash *DashboardModel) []string {
 	}
 	return uids
 }
+
+// AllDatasourceTypes returns all distinct datasource types (e.g. "prometheus",
+// "mysql", "loki") referenced by panels and their targets. Rows and panels
+// with an empty type are skipped.
+func AllDatasourceTypes(dash *DashboardModel) []string {
+	seen := make(map[string]bool)
+	var types []string
+	add := func(ds *DatasourceRef) {
+		if ds == nil || ds.Type == "" || seen[ds.Type] {
+			return
+		}
+		seen[ds.Type] = true
+		types = append(types, ds.Type)
+	}
+	for _, p := range AllPanels(dash) {
+		add(p.Datasource)
+		for _, t := range p.Targets {
+			add(t.Datasource)
+		}
+	}
+	return types
+}
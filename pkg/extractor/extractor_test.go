@@ -1,9 +1,17 @@
 package extractor
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v2"
 )
 
 func testdataPath(name string) string {
@@ -57,6 +65,75 @@ func TestLoadFixedDashboard(t *testing.T) {
 	}
 }
 
+func TestLoadDashboard_YAML(t *testing.T) {
+	jsonPath := testdataPath("slow-by-design.json")
+	raw, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("decoding fixture: %v", err)
+	}
+	yamlData, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encoding YAML: %v", err)
+	}
+	yamlPath := filepath.Join(t.TempDir(), "slow-by-design.yaml")
+	if err := os.WriteFile(yamlPath, yamlData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromJSON, err := LoadDashboard(jsonPath)
+	if err != nil {
+		t.Fatalf("loading JSON: %v", err)
+	}
+	fromYAML, err := LoadDashboard(yamlPath)
+	if err != nil {
+		t.Fatalf("loading YAML: %v", err)
+	}
+
+	if !reflect.DeepEqual(AllTargetExprs(fromYAML), AllTargetExprs(fromJSON)) {
+		t.Error("target expressions differ between the YAML and JSON dashboards")
+	}
+	if !reflect.DeepEqual(fromYAML.Templating, fromJSON.Templating) {
+		t.Error("variables differ between the YAML and JSON dashboards")
+	}
+	jsonPanels, yamlPanels := AllPanels(fromJSON), AllPanels(fromYAML)
+	if len(yamlPanels) != len(jsonPanels) {
+		t.Fatalf("YAML dashboard has %d panels, JSON %d", len(yamlPanels), len(jsonPanels))
+	}
+	for i := range jsonPanels {
+		jp, yp := jsonPanels[i], yamlPanels[i]
+		jg, _ := jp.Grid()
+		yg, _ := yp.Grid()
+		if jp.ID != yp.ID || jp.Title != yp.Title || jg != yg || !reflect.DeepEqual(jp.Targets, yp.Targets) {
+			t.Errorf("panel %d differs between the YAML and JSON dashboards", jp.ID)
+		}
+	}
+	// Raw JSON fields are compacted by the conversion; everything else,
+	// including nested panels, decodes identically.
+	strip := func(d *DashboardModel) {
+		for i := range d.Panels {
+			d.Panels[i].GridPos, d.Panels[i].Options = nil, nil
+			for j := range d.Panels[i].NestedPanels {
+				d.Panels[i].NestedPanels[j].GridPos, d.Panels[i].NestedPanels[j].Options = nil, nil
+			}
+		}
+	}
+	strip(fromJSON)
+	strip(fromYAML)
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Error("YAML and JSON dashboards decode differently")
+	}
+}
+
+func TestYAMLToJSON_Invalid(t *testing.T) {
+	if _, err := YAMLToJSON([]byte("panels: [unclosed")); err == nil {
+		t.Error("expected an error for malformed YAML")
+	}
+}
+
 func TestSlowDashboardPanelCount(t *testing.T) {
 	dash, err := LoadDashboard(testdataPath("slow-by-design.json"))
 	if err != nil {
@@ -227,6 +304,68 @@ func TestPanelsWithTargets(t *testing.T) {
 	}
 }
 
+func TestAllPanels_DuplicateNestedPanel(t *testing.T) {
+	// Panel 2 is listed both at the top level and inside the collapsed
+	// row; panel 4 is nested in two rows. The two text panels have no ID.
+	dash, err := ParseDashboard([]byte(`{"panels": [
+		{"id": 1, "type": "stat", "title": "Up"},
+		{"id": 2, "type": "timeseries", "title": "Requests"},
+		{"id": 10, "type": "row", "title": "Details", "collapsed": true, "panels": [
+			{"id": 2, "type": "timeseries", "title": "Requests"},
+			{"id": 3, "type": "timeseries", "title": "Errors"},
+			{"id": 4, "type": "timeseries", "title": "Latency"},
+			{"type": "text", "title": "Notes"}
+		]},
+		{"id": 11, "type": "row", "title": "More", "collapsed": true, "panels": [
+			{"id": 4, "type": "timeseries", "title": "Latency"},
+			{"type": "text", "title": "Notes"}
+		]}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseDashboard: %v", err)
+	}
+
+	var ids []int
+	for _, p := range AllPanels(dash) {
+		ids = append(ids, p.ID)
+	}
+	want := []int{1, 2, 10, 3, 4, 0, 11, 0}
+	if len(ids) != len(want) {
+		t.Fatalf("AllPanels IDs = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("AllPanels IDs = %v, want %v", ids, want)
+		}
+	}
+	if n := len(VisiblePanels(dash)); n != 2 {
+		t.Errorf("VisiblePanels = %d panels, want 2", n)
+	}
+}
+
+// A nested panel that reuses a top-level ID but has different queries is
+// kept, so both copies are analyzed.
+func TestAllPanels_DuplicateIDDifferentPanel(t *testing.T) {
+	dash, err := ParseDashboard([]byte(`{"panels": [
+		{"id": 2, "type": "timeseries", "title": "Requests", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total[5m]))"}]},
+		{"id": 10, "type": "row", "title": "Details", "collapsed": true, "panels": [
+			{"id": 2, "type": "timeseries", "title": "Requests", "gridPos": {"x": 0, "y": 9},
+			 "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total[5m]))"}]},
+			{"id": 2, "type": "timeseries", "title": "Errors", "targets": [{"refId": "A", "expr": "sum(rate(http_errors_total[5m]))"}]}
+		]}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseDashboard: %v", err)
+	}
+	var titles []string
+	for _, p := range AllPanels(dash) {
+		titles = append(titles, p.Title)
+	}
+	if got, want := strings.Join(titles, ","), "Requests,Details,Errors"; got != want {
+		t.Errorf("AllPanels titles = %s, want %s", got, want)
+	}
+}
+
 func TestVariableQueryString(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -248,3 +387,283 @@ func TestVariableQueryString(t *testing.T) {
 		})
 	}
 }
+
+func TestVariableDefaultValue(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"current value", `{"current": {"text": "30s", "value": "30s"}, "options": [{"value": "1m", "selected": true}]}`, "30s"},
+		{"multi-value current", `{"current": {"text": ["a", "b"], "value": ["a", "b"]}}`, "a"},
+		{"selected option", `{"current": {}, "options": [{"value": "1m"}, {"value": "5m", "selected": true}]}`, "5m"},
+		{"nothing saved", `{"options": [{"value": "1m"}]}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dash, err := ParseDashboard([]byte(`{"templating": {"list": [` + tt.json + `]}}`))
+			if err != nil {
+				t.Fatalf("ParseDashboard: %v", err)
+			}
+			if got := dash.Templating.List[0].DefaultValue(); got != tt.want {
+				t.Errorf("DefaultValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllDatasourceTypes(t *testing.T) {
+	dash, err := ParseDashboard([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"},
+			 "targets": [{"expr": "up", "datasource": {"type": "prometheus", "uid": "prom"}}]},
+			{"id": 2, "type": "table", "datasource": {"type": "mysql", "uid": "orders-db"},
+			 "targets": [{"datasource": {"type": "mysql", "uid": "orders-db"}}]},
+			{"id": 3, "type": "row", "collapsed": true, "panels": [
+				{"id": 4, "type": "logs", "datasource": {"type": "loki", "uid": "logs"}}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	types := AllDatasourceTypes(dash)
+	want := []string{"prometheus", "mysql", "loki"}
+	if len(types) != len(want) {
+		t.Fatalf("AllDatasourceTypes() = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("AllDatasourceTypes()[%d] = %q, want %q", i, types[i], want[i])
+		}
+	}
+}
+
+func TestAnnotationExprs(t *testing.T) {
+	dash, err := ParseDashboard([]byte(`{
+		"annotations": {"list": [
+			{"builtIn": 1, "enable": true, "name": "Annotations & Alerts",
+			 "datasource": {"type": "grafana", "uid": "-- Grafana --"}},
+			{"enable": true, "name": "Deploys", "expr": "changes(deploy_generation{job=\"x\"}[5m]) > 0"},
+			{"enable": true, "name": "Deploys again", "expr": "changes(deploy_generation{job=\"x\"}[5m]) > 0"},
+			{"enable": false, "name": "Disabled", "expr": "ALERTS"}
+		]}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	if n := len(dash.Annotations.List); n != 4 {
+		t.Fatalf("parsed %d annotations, want 4", n)
+	}
+	if a := dash.Annotations.List[0]; a.BuiltIn != 1 || !a.Enable || a.Datasource == nil {
+		t.Errorf("built-in annotation parsed as %+v", a)
+	}
+
+	exprs := AnnotationExprs(dash)
+	if len(exprs) != 1 || exprs[0] != `changes(deploy_generation{job="x"}[5m]) > 0` {
+		t.Errorf("AnnotationExprs() = %q, want the single enabled, de-duplicated expr", exprs)
+	}
+}
+
+func TestPanelAlert(t *testing.T) {
+	dash, err := ParseDashboard([]byte(`{
+		"panels": [
+			{"id": 1, "type": "graph", "targets": [{"expr": "up", "refId": "A"}],
+			 "alert": {"name": "Down", "conditions": [
+				{"type": "query", "query": {"params": ["A", "5m", "now"]}},
+				{"type": "query", "query": {"params": [1, "5m", "now"]}},
+				{"type": "query", "query": {}}
+			 ]}},
+			{"id": 2, "type": "graph", "targets": [{"expr": "up", "refId": "A"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	alert := dash.Panels[0].Alert
+	if alert == nil || alert.Name != "Down" || len(alert.Conditions) != 3 {
+		t.Fatalf("alert parsed as %+v", alert)
+	}
+	for i, want := range []string{"A", "", ""} {
+		if got := alert.Conditions[i].RefID(); got != want {
+			t.Errorf("condition %d RefID() = %q, want %q", i, got, want)
+		}
+	}
+	if dash.Panels[1].Alert != nil {
+		t.Error("panel without an alert block should have a nil Alert")
+	}
+}
+
+const sourceMapFixture = `{
+  "uid": "src",
+  "title": "Grüße",
+  "panels": [
+    {
+      "id": 1,
+      "title": "Requests",
+      "targets": [
+        {"refId": "A", "expr": "sum(rate(http_requests_total[5m]))"},
+        {"refId": "B", "expr": "up"}
+      ]
+    },
+    {"type": "row", "id": 10, "collapsed": true, "panels": [
+      {"title": "Nested", "targets": [{"expr": "node_load1"}], "id": 11}
+    ]}
+  ],
+  "templating": {"list": [{"name": "x", "panels": [{"id": 99}]}]}
+}`
+
+func TestBuildSourceMap(t *testing.T) {
+	sm, err := BuildSourceMap([]byte(sourceMapFixture))
+	if err != nil {
+		t.Fatalf("BuildSourceMap: %v", err)
+	}
+	tests := []struct {
+		panelID int
+		expr    string
+		want    Position
+	}{
+		{1, "sum(rate(http_requests_total[5m]))", Position{Line: 9, Col: 32}},
+		{1, "up", Position{Line: 10, Col: 32}},
+		{1, "", Position{Line: 5, Col: 5}},
+		{1, "not in this panel", Position{Line: 5, Col: 5}},
+		{10, "", Position{Line: 13, Col: 5}},
+		// id after targets, inside a row
+		{11, "node_load1", Position{Line: 14, Col: 48}},
+	}
+	for _, tt := range tests {
+		got, ok := sm.Locate(tt.panelID, tt.expr)
+		if !ok || got != tt.want {
+			t.Errorf("Locate(%d, %q) = %+v, %v; want %+v", tt.panelID, tt.expr, got, ok, tt.want)
+		}
+	}
+	if _, ok := sm.Locate(99, ""); ok {
+		t.Error("objects outside panel arrays should not be mapped")
+	}
+}
+
+// Minified JSON puts every panel on line 1; columns still count characters.
+func TestBuildSourceMap_SingleLine(t *testing.T) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(sourceMapFixture)); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.String()
+	sm, err := BuildSourceMap(buf.Bytes())
+	if err != nil {
+		t.Fatalf("BuildSourceMap: %v", err)
+	}
+	for _, tt := range []struct {
+		panelID int
+		expr    string
+		marker  string // source text the position must point at
+	}{
+		{1, "up", `"up"`},
+		{1, "", `{"id":1`},
+		{11, "node_load1", `"node_load1"`},
+	} {
+		want := Position{Line: 1, Col: utf8.RuneCountInString(data[:strings.Index(data, tt.marker)]) + 1}
+		if got, ok := sm.Locate(tt.panelID, tt.expr); !ok || got != want {
+			t.Errorf("Locate(%d, %q) = %+v, %v; want %+v", tt.panelID, tt.expr, got, ok, want)
+		}
+	}
+}
+
+// A panel ID used both inside a row and at the top level points at the
+// top-level copy, even when the row comes first.
+func TestBuildSourceMap_DuplicateID(t *testing.T) {
+	sm, err := BuildSourceMap([]byte(`{"panels": [
+  {"id": 10, "type": "row", "collapsed": true, "panels": [
+    {"id": 2, "targets": [{"expr": "sum(rate(http_errors_total[5m]))"}]}
+  ]},
+  {"id": 2, "targets": [{"expr": "sum(rate(http_requests_total[5m]))"}]}
+]}`))
+	if err != nil {
+		t.Fatalf("BuildSourceMap: %v", err)
+	}
+	for _, tt := range []struct {
+		expr string
+		want Position
+	}{
+		{"", Position{Line: 5, Col: 3}},
+		{"sum(rate(http_requests_total[5m]))", Position{Line: 5, Col: 34}},
+		// only in the nested copy
+		{"sum(rate(http_errors_total[5m]))", Position{Line: 3, Col: 36}},
+	} {
+		if got, ok := sm.Locate(2, tt.expr); !ok || got != tt.want {
+			t.Errorf("Locate(2, %q) = %+v, %v; want %+v", tt.expr, got, ok, tt.want)
+		}
+	}
+}
+
+func TestBuildSourceMap_Invalid(t *testing.T) {
+	if _, err := BuildSourceMap([]byte(`{"panels": [`)); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
+
+func TestLoadConfigMap_YAML(t *testing.T) {
+	raw, err := os.ReadFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest strings.Builder
+	manifest.WriteString("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: dashboards\ndata:\n  README.md: not a dashboard\n  slow.json: |\n")
+	for _, line := range strings.Split(strings.TrimRight(string(raw), "\n"), "\n") {
+		manifest.WriteString("    " + line + "\n")
+	}
+	path := filepath.Join(t.TempDir(), "cm.yaml")
+	if err := os.WriteFile(path, []byte(manifest.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dashboards, err := LoadConfigMap(path)
+	if err != nil {
+		t.Fatalf("LoadConfigMap: %v", err)
+	}
+	if len(dashboards) != 1 {
+		t.Fatalf("got %d dashboards, want only slow.json", len(dashboards))
+	}
+	dash, err := ParseDashboard(dashboards["slow.json"])
+	if err != nil {
+		t.Fatalf("slow.json: %v", err)
+	}
+	if dash.UID != "slow-by-design" {
+		t.Errorf("UID = %q, want %q", dash.UID, "slow-by-design")
+	}
+}
+
+func TestParseConfigMap_JSONEncodedValue(t *testing.T) {
+	// The value of twice.json is a JSON string literal holding the dashboard.
+	manifest := `{
+	"apiVersion": "v1",
+	"kind": "ConfigMap",
+	"data": {
+		"once.json": "{\"uid\": \"once\"}",
+		"twice.json": "\"{\\\"uid\\\": \\\"twice\\\"}\""
+	}
+}`
+	dashboards, err := ParseConfigMap([]byte(manifest))
+	if err != nil {
+		t.Fatalf("ParseConfigMap: %v", err)
+	}
+	for name, uid := range map[string]string{"once.json": "once", "twice.json": "twice"} {
+		dash, err := ParseDashboard(dashboards[name])
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if dash.UID != uid {
+			t.Errorf("%s: UID = %q, want %q", name, dash.UID, uid)
+		}
+	}
+
+	if _, err := ParseConfigMap([]byte("kind: Secret\ndata:\n  a.json: '{}'\n")); err == nil {
+		t.Error("expected an error for a non-ConfigMap manifest")
+	}
+	if _, err := ParseConfigMap([]byte("kind: ConfigMap\ndata:\n  a.txt: hello\n")); err == nil {
+		t.Error("expected an error for a ConfigMap without .json entries")
+	}
+}
@@ -6,11 +6,15 @@ import "encoding/json"
 type DashboardModel struct {
 	UID          string          `json:"uid"`
 	Title        string          `json:"title"`
+	Tags         []string        `json:"tags,omitempty"` // free-form labels, often used for team ownership
 	Refresh      string          `json:"refresh"`
+	Timezone     string          `json:"timezone,omitempty"` // "browser", "utc" or an IANA zone; "" follows the browser
+	LiveNow      bool            `json:"liveNow,omitempty"` // continuously redraw panels as "now" advances
 	SchemaVersion int            `json:"schemaVersion"`
 	Time         TimeRange       `json:"time"`
 	Panels       []PanelModel    `json:"panels"`
 	Templating   TemplatingModel `json:"templating"`
+	Annotations  AnnotationsModel `json:"annotations"`
 }
 
 type TimeRange struct {
@@ -22,6 +26,22 @@ type TemplatingModel struct {
 	List []VariableModel `json:"list"`
 }
 
+type AnnotationsModel struct {
+	List []AnnotationModel `json:"list"`
+}
+
+// AnnotationModel represents an annotation query. Enabled annotations run on
+// every load and refresh, like a hidden panel.
+type AnnotationModel struct {
+	Name       string         `json:"name"`
+	Enable     bool           `json:"enable"`
+	Hide       bool           `json:"hide,omitempty"`
+	BuiltIn    int            `json:"builtIn,omitempty"` // 1 for Grafana's own "Annotations & Alerts"
+	Expr       string         `json:"expr,omitempty"`    // PromQL for Prometheus annotations
+	Step       string         `json:"step,omitempty"`
+	Datasource *DatasourceRef `json:"datasource,omitempty"`
+}
+
 // PanelModel represents a single panel extracted from dashboard JSON.
 type PanelModel struct {
 	ID              int               `json:"id"`
@@ -33,11 +53,81 @@ type PanelModel struct {
 	MaxPerRow       int               `json:"maxPerRow,omitempty"`
 	MaxDataPoints   *int              `json:"maxDataPoints,omitempty"`
 	Interval        string            `json:"interval,omitempty"`
+	// TimeFrom and TimeShift override the dashboard time range for this
+	// panel, e.g. "1h" (last hour) and "1d" (shifted back one day).
+	TimeFrom        string            `json:"timeFrom,omitempty"`
+	TimeShift       string            `json:"timeShift,omitempty"`
 	Targets         []TargetModel     `json:"targets"`
 	Datasource      *DatasourceRef    `json:"datasource,omitempty"`
 	// NestedPanels holds panels inside collapsed rows.
 	NestedPanels    []PanelModel      `json:"panels,omitempty"`
 	GridPos         json.RawMessage   `json:"gridPos,omitempty"`
+	// Alert holds a legacy (dashboard-embedded) alert rule, if any.
+	Alert           *AlertModel       `json:"alert,omitempty"`
+	// Options holds the visualization's options, whose shape depends on
+	// the panel type.
+	Options         json.RawMessage   `json:"options,omitempty"`
+}
+
+// GridPos is a panel's position and size on the dashboard grid, which is 24
+// columns wide.
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Grid decodes the panel's gridPos. ok is false when it is absent or
+// malformed.
+func (p PanelModel) Grid() (pos GridPos, ok bool) {
+	if len(p.GridPos) == 0 {
+		return GridPos{}, false
+	}
+	if err := json.Unmarshal(p.GridPos, &pos); err != nil {
+		return GridPos{}, false
+	}
+	return pos, true
+}
+
+// HeatmapCalculates reports whether a heatmap panel buckets raw values
+// itself (options.calculate), rather than plotting pre-bucketed series.
+func (p PanelModel) HeatmapCalculates() bool {
+	if len(p.Options) == 0 {
+		return false
+	}
+	var opts struct {
+		Calculate bool `json:"calculate"`
+	}
+	if err := json.Unmarshal(p.Options, &opts); err != nil {
+		return false
+	}
+	return opts.Calculate
+}
+
+// AlertModel represents a legacy panel alert rule. Each condition evaluates
+// one of the panel's targets, referenced by RefID.
+type AlertModel struct {
+	Name       string           `json:"name"`
+	Conditions []AlertCondition `json:"conditions"`
+}
+
+// AlertCondition is one condition of a legacy alert rule.
+type AlertCondition struct {
+	Type  string `json:"type"`
+	Query struct {
+		// Params is [refID, from, to], e.g. ["A", "5m", "now"].
+		Params []interface{} `json:"params"`
+	} `json:"query"`
+}
+
+// RefID returns the target RefID the condition queries, or "" if absent.
+func (c AlertCondition) RefID() string {
+	if len(c.Query.Params) == 0 {
+		return ""
+	}
+	ref, _ := c.Query.Params[0].(string)
+	return ref
 }
 
 // TargetModel represents a single query target within a panel.
@@ -46,6 +136,10 @@ type TargetModel struct {
 	LegendFormat string         `json:"legendFormat,omitempty"`
 	Datasource   *DatasourceRef `json:"datasource,omitempty"`
 	RefID        string         `json:"refId,omitempty"`
+	Hide         bool           `json:"hide,omitempty"`
+	// Expression is the formula of a server-side expression target
+	// (datasource type "__expr__"), e.g. "$A / $B".
+	Expression   string         `json:"expression,omitempty"`
 }
 
 // DatasourceRef identifies a datasource.
@@ -68,6 +162,57 @@ type VariableModel struct {
 	Sort       int            `json:"sort,omitempty"`
 	Datasource *DatasourceRef `json:"datasource,omitempty"`
 	Hide       int            `json:"hide,omitempty"`
+	// Current is the saved selection, used as the default when the
+	// dashboard opens; Options are the saved choices (custom and interval
+	// variables).
+	Current *VariableOption  `json:"current,omitempty"`
+	Options []VariableOption `json:"options,omitempty"`
+	// Auto is set on interval variables offering an "auto" option, whose
+	// value ($__auto_interval_<name>) Grafana derives from the time range.
+	Auto bool `json:"auto,omitempty"`
+}
+
+// VariableOption is one saved option of a variable, or its current
+// selection. Value is a string, or a list of strings for multi-value
+// selections.
+type VariableOption struct {
+	Text     interface{} `json:"text"`
+	Value    interface{} `json:"value"`
+	Selected bool        `json:"selected,omitempty"`
+}
+
+// Values returns Value as a list of strings.
+func (o VariableOption) Values() []string {
+	switch v := o.Value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// DefaultValue returns the value the variable takes when the dashboard
+// opens: the current selection, else the first selected option. It returns
+// "" when neither is saved, and the first value of a multi-value selection.
+func (v *VariableModel) DefaultValue() string {
+	if v.Current != nil {
+		if values := v.Current.Values(); len(values) > 0 {
+			return values[0]
+		}
+	}
+	for _, o := range v.Options {
+		if values := o.Values(); o.Selected && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
 }
 
 // QueryString returns the variable query as a string.
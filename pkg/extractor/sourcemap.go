@@ -0,0 +1,219 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+// Position is a 1-based line and column in the dashboard JSON source.
+// Columns count characters, not bytes.
+type Position struct {
+	Line int
+	Col  int
+}
+
+// SourceMap locates panels and their target expressions in the raw
+// dashboard JSON, for pointing findings at the offending source.
+type SourceMap struct {
+	panels map[int]Position            // panel ID → opening brace of the panel object
+	exprs  map[int]map[string]Position // panel ID → raw expr → opening quote of its value
+}
+
+// Locate returns the position of expr within the panel with the given ID,
+// or of the panel itself if expr is empty or not found in that panel.
+func (m *SourceMap) Locate(panelID int, expr string) (Position, bool) {
+	if expr != "" {
+		if pos, ok := m.exprs[panelID][expr]; ok {
+			return pos, true
+		}
+	}
+	pos, ok := m.panels[panelID]
+	return pos, ok
+}
+
+// sourceFrame is one open JSON object or array during BuildSourceMap.
+type sourceFrame struct {
+	object    bool
+	expectKey bool   // object frames: the next string token is a key
+	key       string // object frames: the most recent key
+	kind      sourceKind
+	panel     *sourcePanel // set on panel, targets array and target frames, and on a row's panels array
+}
+
+type sourceKind int
+
+const (
+	kindOther sourceKind = iota
+	kindPanelsArray
+	kindPanel
+	kindTargetsArray
+	kindTarget
+)
+
+type sourcePanel struct {
+	id     *int
+	start  int
+	nested bool           // inside a row's "panels" array
+	exprs  map[string]int // raw expr → byte offset of its value
+}
+
+// BuildSourceMap scans raw dashboard JSON token by token and records where
+// each panel (top-level and inside rows) and each targets[i].expr value
+// starts. It mirrors the structure ParseDashboard reads: "panels" arrays at
+// the top level or inside a panel, and "targets" arrays inside a panel.
+func BuildSourceMap(data []byte) (*SourceMap, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var stack []*sourceFrame
+	var done []*sourcePanel
+	for {
+		start := tokenStart(data, int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if len(stack) > 0 {
+				return nil, fmt.Errorf("scanning dashboard JSON: %w", io.ErrUnexpectedEOF)
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scanning dashboard JSON: %w", err)
+		}
+
+		var top *sourceFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				frame := &sourceFrame{object: t == '{', expectKey: t == '{'}
+				if top != nil {
+					frame.kind, frame.panel = childKind(top, t, len(stack) == 1, start)
+					if top.object {
+						top.expectKey = true
+					}
+				}
+				stack = append(stack, frame)
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if top.kind == kindPanel && top.panel.id != nil {
+					done = append(done, top.panel)
+				}
+			}
+		default:
+			if top == nil || !top.object {
+				continue
+			}
+			if top.expectKey {
+				top.key, _ = t.(string)
+				top.expectKey = false
+				continue
+			}
+			top.expectKey = true
+			switch {
+			case top.kind == kindPanel && top.key == "id":
+				if n, ok := t.(json.Number); ok {
+					if id, err := n.Int64(); err == nil {
+						v := int(id)
+						top.panel.id = &v
+					}
+				}
+			case top.kind == kindTarget && top.key == "expr":
+				if s, ok := t.(string); ok && s != "" {
+					if _, dup := top.panel.exprs[s]; !dup {
+						top.panel.exprs[s] = start
+					}
+				}
+			}
+		}
+	}
+
+	var offsets []int
+	for _, p := range done {
+		offsets = append(offsets, p.start)
+		for _, off := range p.exprs {
+			offsets = append(offsets, off)
+		}
+	}
+	pos := offsetPositions(data, offsets)
+
+	// A panel ID used more than once points at its top-level copy, the one
+	// AllPanels always keeps; expressions found only in a later copy still
+	// resolve to that copy.
+	sort.SliceStable(done, func(i, j int) bool { return !done[i].nested && done[j].nested })
+	m := &SourceMap{panels: make(map[int]Position), exprs: make(map[int]map[string]Position)}
+	for _, p := range done {
+		id := *p.id
+		if _, seen := m.panels[id]; !seen {
+			m.panels[id] = pos[p.start]
+			m.exprs[id] = make(map[string]Position, len(p.exprs))
+		}
+		for expr, off := range p.exprs {
+			if _, seen := m.exprs[id][expr]; !seen {
+				m.exprs[id][expr] = pos[off]
+			}
+		}
+	}
+	return m, nil
+}
+
+// childKind classifies a container opened inside parent. root is true when
+// parent is the top-level dashboard object.
+func childKind(parent *sourceFrame, open json.Delim, root bool, start int) (sourceKind, *sourcePanel) {
+	switch {
+	case parent.object && open == '[' && parent.key == "panels" && (root || parent.kind == kindPanel):
+		return kindPanelsArray, parent.panel
+	case parent.object && open == '[' && parent.key == "targets" && parent.kind == kindPanel:
+		return kindTargetsArray, parent.panel
+	case !parent.object && open == '{' && parent.kind == kindPanelsArray:
+		return kindPanel, &sourcePanel{start: start, nested: parent.panel != nil, exprs: make(map[string]int)}
+	case !parent.object && open == '{' && parent.kind == kindTargetsArray:
+		return kindTarget, parent.panel
+	}
+	return kindOther, nil
+}
+
+// tokenStart skips whitespace and separators from off to the first byte of
+// the next token. Decoder.InputOffset points just past the previous token.
+func tokenStart(data []byte, off int) int {
+	for off < len(data) {
+		switch data[off] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
+
+// offsetPositions converts byte offsets into data to positions in a single
+// forward pass, so the cost stays linear in the size of data even when the
+// whole dashboard is on one line, as API exports are.
+func offsetPositions(data []byte, offsets []int) map[int]Position {
+	sorted := append([]int(nil), offsets...)
+	sort.Ints(sorted)
+	pos := make(map[int]Position, len(sorted))
+	line, col, i := 1, 1, 0
+	for _, off := range sorted {
+		for ; i < off && i < len(data); i++ {
+			switch b := data[i]; {
+			case b == '\n':
+				line, col = line+1, 1
+			case !utf8.RuneStart(b):
+				// Continuation byte: same character as the previous byte.
+			default:
+				col++
+			}
+		}
+		pos[off] = Position{Line: line, Col: col}
+	}
+	return pos
+}
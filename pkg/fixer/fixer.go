@@ -3,10 +3,11 @@ package fixer
 import (
 	"encoding/json"
 	"fmt"
-	"regexp"
-	"strings"
 
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/rewrite"
 	"github.com/dashboard-advisor/pkg/rules"
+	"github.com/prometheus/prometheus/promql/parser"
 )
 
 // ApplyFixes takes raw dashboard JSON and a list of findings, applies
@@ -30,12 +31,18 @@ func ApplyFixes(dashboardJSON []byte, findings []rules.Finding) ([]byte, int, er
 			dash, err = fixQ3(dash, f)
 		case "Q7":
 			dash, err = fixQ7(dash, f)
+		case "Q17":
+			dash, err = fixQ17(dash, f)
+		case "Q18":
+			dash, err = fixQ18(dash, f)
 		case "D5":
 			dash, err = fixD5(dash)
 		case "D6":
 			dash, err = fixD6(dash)
 		case "D7":
 			dash, err = fixD7(dash, f)
+		case "D38":
+			dash, err = fixD38(dash, f)
 		default:
 			continue
 		}
@@ -77,40 +84,60 @@ func fixQ3(dash map[string]interface{}, f rules.Finding) (map[string]interface{}
 				continue
 			}
 			// Replace =~"simplevalue" with ="simplevalue" for non-regex values
-			target["expr"] = fixRegexEquality(expr)
+			target["expr"] = rewrite.RegexEquality(expr)
 		}
 	}
 	return dash, nil
 }
 
-var regexMatcherRe = regexp.MustCompile(`(=~)"([^"]*)"`)
-
-func fixRegexEquality(expr string) string {
-	return regexMatcherRe.ReplaceAllStringFunc(expr, func(match string) string {
-		sub := regexMatcherRe.FindStringSubmatch(match)
-		if len(sub) < 3 {
-			return match
+// fixQ7 replaces hardcoded durations in rate/irate/increase with $__rate_interval.
+func fixQ7(dash map[string]interface{}, f rules.Finding) (map[string]interface{}, error) {
+	panels, ok := dash["panels"].([]interface{})
+	if !ok {
+		return dash, nil
+	}
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
 		}
-		value := sub[2]
-		if !containsRegexMeta(value) {
-			return `="` + value + `"`
+		fixTargetsQ7(panel)
+		// Also fix nested panels in rows
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			for _, np := range nested {
+				if nestedPanel, ok := np.(map[string]interface{}); ok {
+					fixTargetsQ7(nestedPanel)
+				}
+			}
 		}
-		return match
-	})
+	}
+	return dash, nil
 }
 
-func containsRegexMeta(s string) bool {
-	for _, c := range s {
-		switch c {
-		case '.', '*', '+', '?', '(', ')', '[', ']', '{', '}', '|', '^', '$', '\\':
-			return true
+func fixTargetsQ7(panel map[string]interface{}) {
+	targets, ok := panel["targets"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, t := range targets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if expr, ok := target["expr"].(string); ok {
+			target["expr"] = rewrite.RateInterval(expr)
 		}
 	}
-	return false
 }
 
-// fixQ7 replaces hardcoded durations in rate/irate/increase with $__rate_interval.
-func fixQ7(dash map[string]interface{}, f rules.Finding) (map[string]interface{}, error) {
+// fixQ17 strips an outermost sort()/sort_desc() wrapper from targets of the
+// panels named in the finding. Other panels are left alone because sort is
+// meaningful on table and bar gauge panels.
+func fixQ17(dash map[string]interface{}, f rules.Finding) (map[string]interface{}, error) {
+	flagged := make(map[int]bool, len(f.PanelIDs))
+	for _, id := range f.PanelIDs {
+		flagged[id] = true
+	}
 	panels, ok := dash["panels"].([]interface{})
 	if !ok {
 		return dash, nil
@@ -120,12 +147,11 @@ func fixQ7(dash map[string]interface{}, f rules.Finding) (map[string]interface{}
 		if !ok {
 			continue
 		}
-		fixTargetsQ7(panel)
-		// Also fix nested panels in rows
+		fixTargetsQ17(panel, flagged)
 		if nested, ok := panel["panels"].([]interface{}); ok {
 			for _, np := range nested {
 				if nestedPanel, ok := np.(map[string]interface{}); ok {
-					fixTargetsQ7(nestedPanel)
+					fixTargetsQ17(nestedPanel, flagged)
 				}
 			}
 		}
@@ -133,9 +159,11 @@ func fixQ7(dash map[string]interface{}, f rules.Finding) (map[string]interface{}
 	return dash, nil
 }
 
-var hardcodedIntervalRe = regexp.MustCompile(`((?:rate|irate|increase)\s*\([^[]*)\[(\d+[smhd])\]`)
-
-func fixTargetsQ7(panel map[string]interface{}) {
+func fixTargetsQ17(panel map[string]interface{}, flagged map[int]bool) {
+	id, _ := panel["id"].(float64)
+	if !flagged[int(id)] {
+		return
+	}
 	targets, ok := panel["targets"].([]interface{})
 	if !ok {
 		return
@@ -145,12 +173,72 @@ func fixTargetsQ7(panel map[string]interface{}) {
 		if !ok {
 			continue
 		}
-		expr, ok := target["expr"].(string)
-		if !ok || strings.Contains(expr, "$__rate_interval") || strings.Contains(expr, "$__interval") {
+		if expr, ok := target["expr"].(string); ok {
+			target["expr"] = stripSortWrapper(expr)
+		}
+	}
+}
+
+// stripSortWrapper returns the argument of an outermost sort()/sort_desc()
+// call, or expr unchanged. The AST confirms the wrapper is the outermost node;
+// the rewrite slices the raw string so Grafana template variables survive.
+func stripSortWrapper(expr string) string {
+	parsed, err := parser.ParseExpr(analyzer.ReplaceTemplateVars(expr))
+	if err != nil {
+		return expr
+	}
+	call, ok := parsed.(*parser.Call)
+	if !ok || (call.Func.Name != "sort" && call.Func.Name != "sort_desc") {
+		return expr
+	}
+	return rewrite.StripOuterCall(expr)
+}
+
+// fixQ18 rewrites delta() on _total counters to increase() in targets of
+// the panels named in the finding.
+func fixQ18(dash map[string]interface{}, f rules.Finding) (map[string]interface{}, error) {
+	flagged := make(map[int]bool, len(f.PanelIDs))
+	for _, id := range f.PanelIDs {
+		flagged[id] = true
+	}
+	panels, ok := dash["panels"].([]interface{})
+	if !ok {
+		return dash, nil
+	}
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fixTargetsQ18(panel, flagged)
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			for _, np := range nested {
+				if nestedPanel, ok := np.(map[string]interface{}); ok {
+					fixTargetsQ18(nestedPanel, flagged)
+				}
+			}
+		}
+	}
+	return dash, nil
+}
+
+func fixTargetsQ18(panel map[string]interface{}, flagged map[int]bool) {
+	id, _ := panel["id"].(float64)
+	if !flagged[int(id)] {
+		return
+	}
+	targets, ok := panel["targets"].([]interface{})
+	if !ok {
+		return
+	}
+	for _, t := range targets {
+		target, ok := t.(map[string]interface{})
+		if !ok {
 			continue
 		}
-		// Use $$ to produce a literal $ in Go regex replacement
-		target["expr"] = hardcodedIntervalRe.ReplaceAllString(expr, "${1}[$$__rate_interval]")
+		if expr, ok := target["expr"].(string); ok {
+			target["expr"] = rewrite.DeltaToIncrease(expr)
+		}
 	}
 }
 
@@ -211,3 +299,43 @@ func fixD7(dash map[string]interface{}, f rules.Finding) (map[string]interface{}
 	}
 	return dash, nil
 }
+
+// fixD38 removes repeatDirection and maxPerRow from the panels named in the
+// finding that have no repeat variable.
+func fixD38(dash map[string]interface{}, f rules.Finding) (map[string]interface{}, error) {
+	flagged := make(map[int]bool, len(f.PanelIDs))
+	for _, id := range f.PanelIDs {
+		flagged[id] = true
+	}
+	panels, ok := dash["panels"].([]interface{})
+	if !ok {
+		return dash, nil
+	}
+	for _, p := range panels {
+		panel, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		removeStaleRepeatOptions(panel, flagged)
+		if nested, ok := panel["panels"].([]interface{}); ok {
+			for _, np := range nested {
+				if nestedPanel, ok := np.(map[string]interface{}); ok {
+					removeStaleRepeatOptions(nestedPanel, flagged)
+				}
+			}
+		}
+	}
+	return dash, nil
+}
+
+func removeStaleRepeatOptions(panel map[string]interface{}, flagged map[int]bool) {
+	id, _ := panel["id"].(float64)
+	if !flagged[int(id)] {
+		return
+	}
+	if repeat, _ := panel["repeat"].(string); repeat != "" {
+		return
+	}
+	delete(panel, "repeatDirection")
+	delete(panel, "maxPerRow")
+}
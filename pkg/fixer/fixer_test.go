@@ -8,6 +8,7 @@ import (
 
 	"github.com/dashboard-advisor/pkg/analyzer"
 	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/dashboard-advisor/pkg/rewrite"
 	"github.com/dashboard-advisor/pkg/rules"
 )
 
@@ -143,9 +144,9 @@ func TestFixQ3_ReplacesRegexWithEquality(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := fixRegexEquality(tt.input)
+		got := rewrite.RegexEquality(tt.input)
 		if got != tt.want {
-			t.Errorf("fixRegexEquality(%q)\n  got  %q\n  want %q", tt.input, got, tt.want)
+			t.Errorf("RegexEquality(%q)\n  got  %q\n  want %q", tt.input, got, tt.want)
 		}
 	}
 }
@@ -171,3 +172,168 @@ func TestPatchedJSONIsValid(t *testing.T) {
 		t.Fatalf("patched JSON is invalid: %v", err)
 	}
 }
+
+func TestStripSortWrapper(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`sort_desc(sum by(status) (rate(http_requests_total[5m])))`, `sum by(status) (rate(http_requests_total[5m]))`},
+		{` sort( up{job="$job"} ) `, `up{job="$job"}`},
+		{`sum(sort(up))`, `sum(sort(up))`},             // sort is not outermost
+		{`sort(up) + sort(up)`, `sort(up) + sort(up)`}, // binary expr at the root
+		{`rate(sum(`, `rate(sum(`},                     // unparseable, left alone
+	}
+	for _, tt := range tests {
+		got := stripSortWrapper(tt.input)
+		if got != tt.want {
+			t.Errorf("stripSortWrapper(%q)\n  got  %q\n  want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFixQ17_OnlyFlaggedPanels(t *testing.T) {
+	rawJSON := []byte(`{"panels": [
+		{"id": 1, "type": "timeseries", "targets": [{"expr": "sort_desc(up{job=\"api\"})"}]},
+		{"id": 2, "type": "table", "targets": [{"expr": "sort_desc(up{job=\"api\"})"}]}
+	]}`)
+	findings := []rules.Finding{{RuleID: "Q17", PanelIDs: []int{1}, AutoFixable: true}}
+
+	patchedJSON, count, err := ApplyFixes(rawJSON, findings)
+	if err != nil {
+		t.Fatalf("ApplyFixes failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("fix count = %d, want 1", count)
+	}
+
+	dash, err := extractor.ParseDashboard(patchedJSON)
+	if err != nil {
+		t.Fatalf("patched JSON is invalid: %v", err)
+	}
+	if got := dash.Panels[0].Targets[0].Expr; got != `up{job="api"}` {
+		t.Errorf("panel 1 expr = %q, want sort wrapper stripped", got)
+	}
+	if got := dash.Panels[1].Targets[0].Expr; got != `sort_desc(up{job="api"})` {
+		t.Errorf("panel 2 (table) expr = %q, want unchanged", got)
+	}
+}
+
+func TestFixQ18_DeltaToIncrease(t *testing.T) {
+	rawJSON := []byte(`{"panels": [
+		{"id": 1, "type": "timeseries", "targets": [
+			{"expr": "sum(delta(http_requests_total{job=\"api\"}[$__rate_interval]))"},
+			{"expr": "idelta(http_requests_total[5m]) + delta(node_load1[5m])"}
+		]},
+		{"id": 2, "type": "timeseries", "targets": [{"expr": "delta(errors_total[5m])"}]}
+	]}`)
+	findings := []rules.Finding{{RuleID: "Q18", PanelIDs: []int{1}, AutoFixable: true}}
+
+	patchedJSON, count, err := ApplyFixes(rawJSON, findings)
+	if err != nil {
+		t.Fatalf("ApplyFixes failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("fix count = %d, want 1", count)
+	}
+
+	dash, err := extractor.ParseDashboard(patchedJSON)
+	if err != nil {
+		t.Fatalf("patched JSON is invalid: %v", err)
+	}
+	want := []string{
+		`sum(increase(http_requests_total{job="api"}[$__rate_interval]))`,
+		`idelta(http_requests_total[5m]) + delta(node_load1[5m])`, // idelta and gauges untouched
+	}
+	for i, w := range want {
+		if got := dash.Panels[0].Targets[i].Expr; got != w {
+			t.Errorf("panel 1 target %d = %q, want %q", i, got, w)
+		}
+	}
+	if got := dash.Panels[1].Targets[0].Expr; got != "delta(errors_total[5m])" {
+		t.Errorf("panel 2 expr = %q, want unchanged (not flagged)", got)
+	}
+}
+
+func TestFixD38_RemovesStaleRepeatOptions(t *testing.T) {
+	rawJSON := []byte(`{"panels": [
+		{"id": 1, "type": "timeseries", "repeat": "instance", "repeatDirection": "h", "maxPerRow": 4},
+		{"id": 2, "type": "timeseries", "repeatDirection": "h", "maxPerRow": 4},
+		{"id": 10, "type": "row", "collapsed": true, "panels": [
+			{"id": 3, "type": "timeseries", "repeatDirection": "v"}
+		]}
+	]}`)
+	findings := []rules.Finding{
+		{RuleID: "D38", PanelIDs: []int{2}, AutoFixable: true},
+		{RuleID: "D38", PanelIDs: []int{3}, AutoFixable: true},
+	}
+
+	patchedJSON, count, err := ApplyFixes(rawJSON, findings)
+	if err != nil {
+		t.Fatalf("ApplyFixes failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("fix count = %d, want 2", count)
+	}
+
+	dash, err := extractor.ParseDashboard(patchedJSON)
+	if err != nil {
+		t.Fatalf("patched JSON is invalid: %v", err)
+	}
+	if p := dash.Panels[0]; p.RepeatDirection != "h" || p.MaxPerRow != 4 {
+		t.Errorf("panel 1 (repeats) = %q/%d, want options kept", p.RepeatDirection, p.MaxPerRow)
+	}
+	if p := dash.Panels[1]; p.RepeatDirection != "" || p.MaxPerRow != 0 {
+		t.Errorf("panel 2 = %q/%d, want options removed", p.RepeatDirection, p.MaxPerRow)
+	}
+	if p := dash.Panels[2].NestedPanels[0]; p.RepeatDirection != "" {
+		t.Errorf("nested panel 3 repeatDirection = %q, want removed", p.RepeatDirection)
+	}
+}
+
+// Every suggestion must equal what --fix writes when that finding alone is
+// applied, since both go through the same pkg/rewrite transforms.
+func TestSuggestionsMatchFixOutput(t *testing.T) {
+	rawJSON, err := os.ReadFile(testdataPath("slow-by-design.json"))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	dash, _ := extractor.ParseDashboard(rawJSON)
+	report := analyzer.DefaultEngine().AnalyzeDashboard(dash)
+
+	checked := map[string]int{}
+	for _, f := range report.Findings {
+		if f.Suggestion == "" {
+			continue
+		}
+		if !f.AutoFixable {
+			t.Errorf("%s finding has a suggestion but is not auto-fixable", f.RuleID)
+			continue
+		}
+		patchedJSON, _, err := ApplyFixes(rawJSON, []rules.Finding{f})
+		if err != nil {
+			t.Fatalf("ApplyFixes(%s) failed: %v", f.RuleID, err)
+		}
+		patched, _ := extractor.ParseDashboard(patchedJSON)
+
+		found := false
+		for _, p := range extractor.AllPanels(patched) {
+			if p.ID != f.PanelIDs[0] {
+				continue
+			}
+			for _, tgt := range p.Targets {
+				found = found || tgt.Expr == f.Suggestion
+			}
+		}
+		if !found {
+			t.Errorf("%s on panel %v: suggestion %q not found in --fix output", f.RuleID, f.PanelIDs, f.Suggestion)
+		}
+		checked[f.RuleID]++
+	}
+
+	for _, id := range []string{"Q3", "Q7", "Q17", "Q18"} {
+		if checked[id] == 0 {
+			t.Errorf("expected at least one %s finding with a suggestion on the slow dashboard", id)
+		}
+	}
+}
@@ -7,9 +7,31 @@ import (
 	"github.com/dashboard-advisor/pkg/rules"
 )
 
-// JSONFormatter renders the report as JSON.
+// JSONFormatter renders the report as JSON, encoding straight to the writer
+// without an intermediate copy of the output. The zero value writes compact
+// single-line JSON.
 type JSONFormatter struct {
+	// Indent pretty-prints the report with two-space indentation.
 	Indent bool
+	// IncludeRuleCatalog adds a top-level "rules" array describing every
+	// rule that has findings, so one-shot consumers need no second lookup.
+	IncludeRuleCatalog bool
+	// Rules supplies default severities for the catalog, typically
+	// Engine.Rules(). Rules missing from it are listed without one.
+	Rules []rules.Rule
+	// IncludeNormalizedExprs keeps Metadata.NormalizedExprs, the
+	// template-substituted form of each expression, in the output. It is
+	// dropped by default: it repeats every query and is only useful for
+	// debugging why a rule did or didn't fire.
+	IncludeNormalizedExprs bool
+}
+
+// RuleInfo is one entry of the JSON rule catalog.
+type RuleInfo struct {
+	ID              string `json:"id"`
+	DefaultSeverity string `json:"defaultSeverity,omitempty"`
+	Title           string `json:"title"`    // title of the rule's first finding
+	Findings        int    `json:"findings"` // number of findings in this report
 }
 
 func (f *JSONFormatter) Format(w io.Writer, report *rules.Report) error {
@@ -17,5 +39,41 @@ func (f *JSONFormatter) Format(w io.Writer, report *rules.Report) error {
 	if f.Indent {
 		enc.SetIndent("", "  ")
 	}
-	return enc.Encode(report)
+	if !f.IncludeNormalizedExprs && report.Metadata.NormalizedExprs != nil {
+		trimmed := *report
+		trimmed.Metadata.NormalizedExprs = nil
+		report = &trimmed
+	}
+	if !f.IncludeRuleCatalog {
+		return enc.Encode(report)
+	}
+	return enc.Encode(struct {
+		*rules.Report
+		Rules []RuleInfo `json:"rules"`
+	}{report, ruleCatalog(report.Findings, f.Rules)})
+}
+
+// ruleCatalog describes each rule with findings, in order of first finding.
+func ruleCatalog(findings []rules.Finding, registered []rules.Rule) []RuleInfo {
+	severities := make(map[string]string, len(registered))
+	for _, r := range registered {
+		severities[r.ID()] = r.RuleSeverity().String()
+	}
+
+	catalog := []RuleInfo{}
+	index := make(map[string]int)
+	for _, finding := range findings {
+		i, ok := index[finding.RuleID]
+		if !ok {
+			i = len(catalog)
+			index[finding.RuleID] = i
+			catalog = append(catalog, RuleInfo{
+				ID:              finding.RuleID,
+				DefaultSeverity: severities[finding.RuleID],
+				Title:           finding.Title,
+			})
+		}
+		catalog[i].Findings++
+	}
+	return catalog
 }
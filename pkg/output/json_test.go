@@ -0,0 +1,160 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+func TestJSONFormatter_CompactMatchesIndented(t *testing.T) {
+	report := &rules.Report{
+		DashboardUID: "slow-by-design",
+		Score:        42,
+		Findings: []rules.Finding{
+			{RuleID: "Q1", Severity: rules.Critical, PanelIDs: []int{1, 2}, Why: "no filters"},
+		},
+		PanelScores: map[int]int{1: 50, 2: 50},
+		Metadata: rules.ReportMetadata{
+			TotalPanels: 2,
+			QueryCosts:  map[string]float64{`up{job="api"}`: 1, `sum(rate(x[5m]))`: 20},
+		},
+	}
+
+	var compact, indented bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&compact, report); err != nil {
+		t.Fatalf("compact Format failed: %v", err)
+	}
+	if err := (&JSONFormatter{Indent: true}).Format(&indented, report); err != nil {
+		t.Fatalf("indented Format failed: %v", err)
+	}
+
+	if n := strings.Count(compact.String(), "\n"); n != 1 {
+		t.Errorf("compact output has %d newlines, want 1 (trailing)", n)
+	}
+	if compact.Len() >= indented.Len() {
+		t.Errorf("compact output (%d bytes) should be shorter than indented (%d bytes)", compact.Len(), indented.Len())
+	}
+
+	var a, b interface{}
+	if err := json.Unmarshal(compact.Bytes(), &a); err != nil {
+		t.Fatalf("compact output is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal(indented.Bytes(), &b); err != nil {
+		t.Fatalf("indented output is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Error("compact and indented output decode to different structures")
+	}
+}
+
+type stubRule struct {
+	id  string
+	sev rules.Severity
+}
+
+func (r stubRule) ID() string                                   { return r.id }
+func (r stubRule) RuleSeverity() rules.Severity                 { return r.sev }
+func (r stubRule) Check(*rules.AnalysisContext) []rules.Finding { return nil }
+
+func TestJSONFormatter_RuleCatalog(t *testing.T) {
+	report := &rules.Report{
+		DashboardUID: "catalog",
+		Findings: []rules.Finding{
+			{RuleID: "Q1", Severity: rules.Critical, Title: "Missing label filters"},
+			{RuleID: "D17", Severity: rules.High, Title: "Cyclic variable dependency"},
+			{RuleID: "Q1", Severity: rules.Critical, Title: "Missing label filters"},
+		},
+	}
+	f := &JSONFormatter{
+		IncludeRuleCatalog: true,
+		Rules:              []rules.Rule{stubRule{"Q1", rules.Critical}, stubRule{"D17", rules.Medium}, stubRule{"D1", rules.High}},
+	}
+
+	var buf bytes.Buffer
+	if err := f.Format(&buf, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var out struct {
+		DashboardUID string
+		Findings     []rules.Finding
+		Rules        []RuleInfo `json:"rules"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if out.DashboardUID != "catalog" || len(out.Findings) != 3 {
+		t.Errorf("report fields not preserved: uid %q, %d findings", out.DashboardUID, len(out.Findings))
+	}
+
+	want := []RuleInfo{
+		{ID: "Q1", DefaultSeverity: "Critical", Title: "Missing label filters", Findings: 2},
+		{ID: "D17", DefaultSeverity: "Medium", Title: "Cyclic variable dependency", Findings: 1},
+	}
+	if !reflect.DeepEqual(out.Rules, want) {
+		t.Errorf("catalog = %+v, want %+v", out.Rules, want)
+	}
+
+	buf.Reset()
+	if err := (&JSONFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if strings.Contains(buf.String(), `"rules"`) {
+		t.Error("catalog should be omitted unless IncludeRuleCatalog is set")
+	}
+}
+
+func TestJSONFormatter_FindingPositions(t *testing.T) {
+	report := &rules.Report{
+		Findings: []rules.Finding{
+			{RuleID: "Q1", PanelIDs: []int{1}, Line: 12, Col: 19},
+			{RuleID: "B1"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Findings []map[string]any
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Findings[0]["Line"] != 12.0 || out.Findings[0]["Col"] != 19.0 {
+		t.Errorf("Q1 finding position = %v:%v, want 12:19", out.Findings[0]["Line"], out.Findings[0]["Col"])
+	}
+	if _, ok := out.Findings[1]["Line"]; ok {
+		t.Error("findings without a position should omit Line")
+	}
+}
+
+func TestJSONFormatter_NormalizedExprsOnlyOnRequest(t *testing.T) {
+	report := &rules.Report{
+		DashboardUID: "debug",
+		Metadata: rules.ReportMetadata{
+			NormalizedExprs: map[string]string{`rate(x[$__rate_interval])`: `rate(x[5m])`},
+		},
+	}
+
+	var plain, debug bytes.Buffer
+	if err := (&JSONFormatter{}).Format(&plain, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if err := (&JSONFormatter{IncludeNormalizedExprs: true}).Format(&debug, report); err != nil {
+		t.Fatalf("Format with IncludeNormalizedExprs failed: %v", err)
+	}
+
+	if strings.Contains(plain.String(), "normalizedExprs") {
+		t.Errorf("default output should omit normalizedExprs: %s", plain.String())
+	}
+	if !strings.Contains(debug.String(), `"normalizedExprs":{"rate(x[$__rate_interval])":"rate(x[5m])"}`) {
+		t.Errorf("debug output should include normalizedExprs: %s", debug.String())
+	}
+	if report.Metadata.NormalizedExprs == nil {
+		t.Error("Format must not modify the report")
+	}
+}
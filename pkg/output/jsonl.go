@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+// JSONLFormatter renders the report as JSON Lines for log pipelines: one
+// compact object per finding, each carrying the dashboard UID, then one
+// summary object. Every line is a complete JSON document, so a report can
+// be ingested line by line and several reports appended to one stream.
+type JSONLFormatter struct{}
+
+// JSONLFinding is a finding line of JSONLFormatter output.
+type JSONLFinding struct {
+	Type         string // always "finding"
+	DashboardUID string
+	rules.Finding
+}
+
+// JSONLSummary is the last line of JSONLFormatter output.
+type JSONLSummary struct {
+	Type           string // always "summary"
+	DashboardUID   string
+	DashboardTitle string
+	Score          int
+	Findings       int // number of finding lines written before this one
+	Metadata       rules.ReportMetadata
+}
+
+func (f *JSONLFormatter) Format(w io.Writer, report *rules.Report) error {
+	enc := json.NewEncoder(w)
+	for _, finding := range report.Findings {
+		if err := enc.Encode(JSONLFinding{Type: "finding", DashboardUID: report.DashboardUID, Finding: finding}); err != nil {
+			return err
+		}
+	}
+	meta := report.Metadata
+	meta.NormalizedExprs = nil
+	return enc.Encode(JSONLSummary{
+		Type:           "summary",
+		DashboardUID:   report.DashboardUID,
+		DashboardTitle: report.DashboardTitle,
+		Score:          report.Score,
+		Findings:       len(report.Findings),
+		Metadata:       meta,
+	})
+}
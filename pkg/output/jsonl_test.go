@@ -0,0 +1,81 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+func TestJSONLFormatter_OneObjectPerLine(t *testing.T) {
+	report := &rules.Report{
+		DashboardUID:   "slow-by-design",
+		DashboardTitle: "Slow by design",
+		Score:          42,
+		Findings: []rules.Finding{
+			{RuleID: "Q1", Severity: rules.Critical, PanelIDs: []int{1, 2}, Why: "no filters\nat all"},
+			{RuleID: "D5", Severity: rules.High, Title: "Refresh too frequent"},
+		},
+		Metadata: rules.ReportMetadata{
+			TotalPanels:     2,
+			QueryCosts:      map[string]float64{`up{job="api"}`: 1},
+			NormalizedExprs: map[string]string{`up{job="api"}`: `up{job="api"}`},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&JSONLFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", len(lines)+1, err, scanner.Text())
+		}
+		lines = append(lines, obj)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (2 findings + summary)", len(lines))
+	}
+
+	for i, want := range []string{"Q1", "D5"} {
+		line := lines[i]
+		if line["Type"] != "finding" || line["RuleID"] != want || line["DashboardUID"] != "slow-by-design" {
+			t.Errorf("line %d = %v, want finding %s with the dashboard UID", i+1, line, want)
+		}
+	}
+	if line := lines[0]; line["Why"] != "no filters\nat all" {
+		t.Errorf("line 1 Why = %q, want the newline preserved inside the string", line["Why"])
+	}
+
+	summary := lines[2]
+	if summary["Type"] != "summary" || summary["DashboardUID"] != "slow-by-design" || summary["Score"] != float64(42) || summary["Findings"] != float64(2) {
+		t.Errorf("summary line = %v", summary)
+	}
+	meta, _ := summary["Metadata"].(map[string]interface{})
+	if _, ok := meta["normalizedExprs"]; ok {
+		t.Error("summary metadata should not include normalizedExprs")
+	}
+	if _, ok := meta["queryCosts"]; !ok {
+		t.Error("summary metadata should include queryCosts")
+	}
+}
+
+func TestJSONLFormatter_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JSONLFormatter{}).Format(&buf, &rules.Report{DashboardUID: "clean", Score: 100}); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var summary JSONLSummary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("output is not a single JSON object: %v", err)
+	}
+	if summary.Type != "summary" || summary.Findings != 0 || summary.Score != 100 {
+		t.Errorf("summary = %+v", summary)
+	}
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"container/list"
+	"io"
+	"sync"
+
+	"github.com/dashboard-advisor/pkg/rules"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+var (
+	scoreDesc = prometheus.NewDesc(
+		"dashboard_advisor_score",
+		"Composite dashboard health score (0-100) from the latest analysis.",
+		[]string{"uid"}, nil,
+	)
+	findingsDesc = prometheus.NewDesc(
+		"dashboard_advisor_findings_total",
+		"Number of findings in the latest analysis, by rule and severity.",
+		[]string{"uid", "rule", "severity"}, nil,
+	)
+)
+
+// DefaultMaxDashboards is the number of dashboards a ReportCollector keeps
+// when MaxDashboards is zero.
+const DefaultMaxDashboards = 1000
+
+// ReportCollector is a prometheus.Collector exposing the latest score and
+// finding counts per dashboard UID. It is safe for concurrent use, so serve
+// mode can record reports from request handlers while /metrics is scraped.
+// UIDs are client-supplied there, so the collector keeps only the counts,
+// never the report, and at most MaxDashboards UIDs: the least recently
+// observed is evicted first. Reports without a UID are not recorded.
+type ReportCollector struct {
+	// MaxDashboards is the number of dashboards kept. Defaults to
+	// DefaultMaxDashboards if zero. Set it before the first Observe.
+	MaxDashboards int
+
+	mu    sync.Mutex
+	byUID map[string]*list.Element // UID → element holding *dashboardStats
+	lru   *list.List               // most recently observed first
+}
+
+// dashboardStats is what a ReportCollector keeps of a report.
+type dashboardStats struct {
+	uid    string
+	score  int
+	counts map[findingKey]int
+}
+
+type findingKey struct{ rule, severity string }
+
+// NewReportCollector returns an empty ReportCollector.
+func NewReportCollector() *ReportCollector {
+	return &ReportCollector{byUID: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *ReportCollector) maxDashboards() int {
+	if c.MaxDashboards > 0 {
+		return c.MaxDashboards
+	}
+	return DefaultMaxDashboards
+}
+
+// Observe records report as the latest analysis of its dashboard, replacing
+// any earlier report for the same UID.
+func (c *ReportCollector) Observe(report *rules.Report) {
+	if report.DashboardUID == "" {
+		return
+	}
+	c.record(report)
+}
+
+// record is Observe without the UID check, for PrometheusFormatter, which
+// renders a single report whatever its UID.
+func (c *ReportCollector) record(report *rules.Report) {
+	stats := &dashboardStats{uid: report.DashboardUID, score: report.Score, counts: make(map[findingKey]int)}
+	for _, f := range report.Findings {
+		stats.counts[findingKey{f.RuleID, f.Severity.String()}]++
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.byUID[stats.uid]; ok {
+		el.Value = stats
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.byUID[stats.uid] = c.lru.PushFront(stats)
+	for c.lru.Len() > c.maxDashboards() {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.byUID, oldest.Value.(*dashboardStats).uid)
+	}
+}
+
+func (c *ReportCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- scoreDesc
+	ch <- findingsDesc
+}
+
+func (c *ReportCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.lru.Front(); el != nil; el = el.Next() {
+		stats := el.Value.(*dashboardStats)
+		ch <- prometheus.MustNewConstMetric(scoreDesc, prometheus.GaugeValue, float64(stats.score), stats.uid)
+		for k, n := range stats.counts {
+			ch <- prometheus.MustNewConstMetric(findingsDesc, prometheus.GaugeValue, float64(n), stats.uid, k.rule, k.severity)
+		}
+	}
+}
+
+// PrometheusFormatter renders the report in the Prometheus text exposition
+// format, e.g. for a node_exporter textfile collector or a Pushgateway.
+type PrometheusFormatter struct{}
+
+func (f *PrometheusFormatter) Format(w io.Writer, report *rules.Report) error {
+	collector := NewReportCollector()
+	collector.record(report)
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(collector); err != nil {
+		return err
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		return err
+	}
+	for _, mf := range families {
+		if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/rules"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+func TestPrometheusFormatter(t *testing.T) {
+	report := &rules.Report{
+		DashboardUID: "slow-by-design",
+		Score:        42,
+		Findings: []rules.Finding{
+			{RuleID: "Q1", Severity: rules.Critical},
+			{RuleID: "Q1", Severity: rules.Critical},
+			{RuleID: "D5", Severity: rules.Medium},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&PrometheusFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(&buf)
+	if err != nil {
+		t.Fatalf("output is not valid exposition format: %v", err)
+	}
+
+	score := families["dashboard_advisor_score"]
+	if score == nil || len(score.Metric) != 1 || score.Metric[0].GetGauge().GetValue() != 42 {
+		t.Fatalf("dashboard_advisor_score = %v, want one series with value 42", score)
+	}
+
+	findings := families["dashboard_advisor_findings_total"]
+	if findings == nil {
+		t.Fatal("missing dashboard_advisor_findings_total")
+	}
+	got := map[string]float64{}
+	for _, m := range findings.Metric {
+		labels := map[string]string{}
+		for _, lp := range m.Label {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		if labels["uid"] != "slow-by-design" {
+			t.Errorf("uid label = %q, want slow-by-design", labels["uid"])
+		}
+		got[labels["rule"]+"/"+labels["severity"]] = m.GetGauge().GetValue()
+	}
+	want := map[string]float64{"Q1/Critical": 2, "D5/Medium": 1}
+	if len(got) != len(want) {
+		t.Errorf("findings series = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("findings{%s} = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestReportCollector_Bounded(t *testing.T) {
+	c := NewReportCollector()
+	c.MaxDashboards = 2
+	c.Observe(&rules.Report{DashboardUID: "a", Score: 10})
+	c.Observe(&rules.Report{DashboardUID: "b", Score: 20})
+	c.Observe(&rules.Report{DashboardUID: "a", Score: 11}) // a is now the most recent
+	c.Observe(&rules.Report{DashboardUID: "c", Score: 30}) // evicts b
+	c.Observe(&rules.Report{Score: 40})                    // no UID: not recorded
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	got := map[string]float64{}
+	for _, mf := range families {
+		if mf.GetName() != "dashboard_advisor_score" {
+			continue
+		}
+		for _, m := range mf.Metric {
+			got[m.Label[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+	want := map[string]float64{"a": 11, "c": 30}
+	if len(got) != len(want) || got["a"] != want["a"] || got["c"] != want["c"] {
+		t.Errorf("scores = %v, want %v", got, want)
+	}
+}
@@ -15,9 +15,14 @@ type TextFormatter struct{}
 func (f *TextFormatter) Format(w io.Writer, report *rules.Report) error {
 	// Header
 	fmt.Fprintf(w, "Dashboard: %s (%s)\n", report.DashboardTitle, report.DashboardUID)
+	if len(report.Metadata.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:      %s\n", strings.Join(report.Metadata.Tags, ", "))
+	}
 	fmt.Fprintf(w, "Score:     %s\n", scoreBar(report.Score))
 	fmt.Fprintf(w, "Panels:    %d  |  Targets: %d  |  Parse errors: %d\n",
 		report.Metadata.TotalPanels, report.Metadata.TotalTargets, report.Metadata.ParseErrors)
+	fmt.Fprintf(w, "Queries:   ~%d per refresh (worst case, all variables set to All)\n",
+		report.Metadata.EstimatedQueriesPerRefresh)
 	if report.Metadata.CardinalityAvailable {
 		fmt.Fprintln(w, "Cardinality: enriched (live TSDB data)")
 	} else {
@@ -34,7 +39,11 @@ func (f *TextFormatter) Format(w io.Writer, report *rules.Report) error {
 	grouped := groupByRule(report.Findings)
 	ruleIDs := sortedKeys(grouped)
 
-	fmt.Fprintf(w, "Found %d issue(s):\n\n", len(report.Findings))
+	fmt.Fprintf(w, "Found %d issue(s):\n", len(report.Findings))
+	if n := report.Metadata.AutoFixableCount; n > 0 {
+		fmt.Fprintf(w, "%d of %d findings auto-fixable, run --fix\n", n, len(report.Findings))
+	}
+	fmt.Fprintln(w)
 
 	for _, ruleID := range ruleIDs {
 		findings := grouped[ruleID]
@@ -48,8 +57,17 @@ func (f *TextFormatter) Format(w io.Writer, report *rules.Report) error {
 		if len(panels) > 0 {
 			fmt.Fprintf(w, "       Panels: %s\n", panels)
 		}
+		if related := collectRelated(findings); related != "" {
+			fmt.Fprintf(w, "       Related: %s\n", related)
+		}
+		if locs := collectLocations(findings, 5); locs != "" {
+			fmt.Fprintf(w, "       Lines:  %s\n", locs)
+		}
 		fmt.Fprintf(w, "       Why:    %s\n", first.Why)
 		fmt.Fprintf(w, "       Fix:    %s\n", first.Fix)
+		for _, sug := range collectSuggestions(findings, 3) {
+			fmt.Fprintf(w, "       Suggested: %s\n", sug)
+		}
 		fmt.Fprintf(w, "       Impact: %s\n", first.Impact)
 		if first.AutoFixable {
 			fmt.Fprintf(w, "       Auto-fixable: yes (use --fix)\n")
@@ -139,6 +157,60 @@ func collectPanels(findings []rules.Finding) string {
 	return strings.Join(panels, ", ")
 }
 
+// collectRelated lists the distinct related rule IDs of findings, sorted.
+func collectRelated(findings []rules.Finding) string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, f := range findings {
+		for _, id := range f.RelatedRuleIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ", ")
+}
+
+// collectSuggestions returns up to max distinct suggested expressions, with a
+// trailing "(+N more)" entry when some were left out.
+func collectSuggestions(findings []rules.Finding, max int) []string {
+	seen := make(map[string]bool)
+	var sugs []string
+	for _, f := range findings {
+		if f.Suggestion != "" && !seen[f.Suggestion] {
+			seen[f.Suggestion] = true
+			sugs = append(sugs, f.Suggestion)
+		}
+	}
+	if len(sugs) > max {
+		return append(sugs[:max], fmt.Sprintf("(+%d more)", len(sugs)-max))
+	}
+	return sugs
+}
+
+// collectLocations lists the distinct source positions (line:col) of
+// findings, up to max.
+func collectLocations(findings []rules.Finding, max int) string {
+	seen := make(map[string]bool)
+	var locs []string
+	for _, f := range findings {
+		if f.Line == 0 {
+			continue
+		}
+		loc := fmt.Sprintf("%d:%d", f.Line, f.Col)
+		if !seen[loc] {
+			seen[loc] = true
+			locs = append(locs, loc)
+		}
+	}
+	if len(locs) > max {
+		locs = append(locs[:max], fmt.Sprintf("(+%d more)", len(locs)-max))
+	}
+	return strings.Join(locs, ", ")
+}
+
 func plural(n int) string {
 	if n == 1 {
 		return ""
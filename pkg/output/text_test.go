@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+func TestTextFormatter_RelatedRules(t *testing.T) {
+	report := &rules.Report{
+		DashboardTitle: "Related",
+		Findings: []rules.Finding{
+			{RuleID: "Q1", Severity: rules.Critical, PanelIDs: []int{1}, RelatedRuleIDs: []string{"Q7"}},
+			{RuleID: "Q1", Severity: rules.Critical, PanelIDs: []int{2}, RelatedRuleIDs: []string{"D7", "Q7"}},
+			{RuleID: "D5", Severity: rules.High},
+		},
+	}
+	var buf bytes.Buffer
+	if err := (&TextFormatter{}).Format(&buf, report); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Related: D7, Q7\n") {
+		t.Errorf("Q1 should list its related rules once each, sorted:\n%s", out)
+	}
+	if strings.Count(out, "Related:") != 1 {
+		t.Errorf("findings without related rules should have no Related line:\n%s", out)
+	}
+}
@@ -0,0 +1,76 @@
+package rewrite
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The transforms in this package work on raw target expressions, not ASTs,
+// so Grafana template variables survive the rewrite. They are shared by the
+// rules (to populate Finding.Suggestion) and the fixer (to patch dashboards),
+// which keeps the suggested expression identical to what --fix writes.
+
+var regexMatcherRe = regexp.MustCompile(`(=~)"([^"]*)"`)
+
+// RegexEquality replaces =~"value" with ="value" wherever value contains no
+// regex metacharacters (Q3).
+func RegexEquality(expr string) string {
+	return regexMatcherRe.ReplaceAllStringFunc(expr, func(match string) string {
+		sub := regexMatcherRe.FindStringSubmatch(match)
+		if len(sub) < 3 {
+			return match
+		}
+		value := sub[2]
+		if !ContainsRegexMeta(value) {
+			return `="` + value + `"`
+		}
+		return match
+	})
+}
+
+// ContainsRegexMeta returns true if s contains regex metacharacters.
+func ContainsRegexMeta(s string) bool {
+	for _, c := range s {
+		switch c {
+		case '.', '*', '+', '?', '(', ')', '[', ']', '{', '}', '|', '^', '$', '\\':
+			return true
+		}
+	}
+	return false
+}
+
+var hardcodedIntervalRe = regexp.MustCompile(`((?:rate|irate|increase)\s*\([^[]*)\[(\d+[smhd])\]`)
+
+// RateInterval replaces hardcoded durations in rate/irate/increase with
+// $__rate_interval (Q7). Expressions that already use $__rate_interval or
+// $__interval are returned unchanged.
+func RateInterval(expr string) string {
+	if strings.Contains(expr, "$__rate_interval") || strings.Contains(expr, "$__interval") {
+		return expr
+	}
+	// Use $$ to produce a literal $ in Go regex replacement
+	return hardcodedIntervalRe.ReplaceAllString(expr, "${1}[$$__rate_interval]")
+}
+
+// StripOuterCall returns the argument of the call wrapping expr, e.g.
+// sort_desc(x) → x (Q17). It works on the raw string only: callers must have
+// confirmed via the AST that the outermost node is a single-argument call.
+// Returns expr unchanged if it does not look like a call.
+func StripOuterCall(expr string) string {
+	trimmed := strings.TrimSpace(expr)
+	open := strings.IndexByte(trimmed, '(')
+	if open == -1 || !strings.HasSuffix(trimmed, ")") {
+		return expr
+	}
+	return strings.TrimSpace(trimmed[open+1 : len(trimmed)-1])
+}
+
+// deltaOnCounterRe matches delta( directly followed by a _total metric name.
+// \b keeps idelta( from matching.
+var deltaOnCounterRe = regexp.MustCompile(`\bdelta(\s*\(\s*[a-zA-Z_:][a-zA-Z0-9_:]*_total\b)`)
+
+// DeltaToIncrease rewrites delta() on _total counters to increase() (Q18).
+// idelta() and delta() on other metrics are left alone.
+func DeltaToIncrease(expr string) string {
+	return deltaOnCounterRe.ReplaceAllString(expr, "increase${1}")
+}
@@ -0,0 +1,37 @@
+package rewrite
+
+import "testing"
+
+func TestRateInterval(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`rate(http_requests_total[5m])`, `rate(http_requests_total[$__rate_interval])`},
+		{`sum(increase(errors_total{job="api"}[1h]))`, `sum(increase(errors_total{job="api"}[$__rate_interval]))`},
+		{`rate(a[5m]) / rate(b[$__interval])`, `rate(a[5m]) / rate(b[$__interval])`}, // already templated, left alone
+		{`avg_over_time(up[5m])`, `avg_over_time(up[5m])`},                           // not a rate function
+	}
+	for _, tt := range tests {
+		if got := RateInterval(tt.input); got != tt.want {
+			t.Errorf("RateInterval(%q)\n  got  %q\n  want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDeltaToIncrease(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`delta(http_requests_total[5m])`, `increase(http_requests_total[5m])`},
+		{`sum(delta( errors_total{job="x"}[1h]))`, `sum(increase( errors_total{job="x"}[1h]))`},
+		{`idelta(http_requests_total[5m])`, `idelta(http_requests_total[5m])`},
+		{`delta(node_load1[5m])`, `delta(node_load1[5m])`},
+	}
+	for _, tt := range tests {
+		if got := DeltaToIncrease(tt.input); got != tt.want {
+			t.Errorf("DeltaToIncrease(%q)\n  got  %q\n  want %q", tt.input, got, tt.want)
+		}
+	}
+}
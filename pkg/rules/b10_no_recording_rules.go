@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// NoRecordingRules detects expensive dashboards that compute everything from
+// raw series: the summed estimated cost of their queries is high, yet no
+// query reads a recording rule output (a level:metric:operations name).
+// Every refresh then re-aggregates the raw data that a recording rule would
+// aggregate once per evaluation interval. This holds for Thanos too, where
+// the rules run in the Thanos ruler.
+type NoRecordingRules struct {
+	// MaxTotalCost is the summed query cost tolerated. Defaults to 1000000
+	// if zero.
+	MaxTotalCost float64
+}
+
+func (r *NoRecordingRules) ID() string            { return "B10" }
+func (r *NoRecordingRules) RuleSeverity() Severity { return Medium }
+
+func (r *NoRecordingRules) Describe() Description {
+	return Description{
+		Title:       "Expensive dashboard without recording rules",
+		Summary:     "Dashboards whose summed query cost exceeds 1000000 while no query uses a recording rule.",
+		Rationale:   "Every refresh re-aggregates raw series that recording rules would aggregate once per interval.",
+		Bad:         `sum by (job) (rate(http_requests_total[5m])) in many panels`,
+		Good:        `job:http_requests:rate5m, recorded by a Prometheus rule`,
+		AutoFixable: false,
+	}
+}
+
+func (r *NoRecordingRules) maxTotalCost() float64 {
+	if r.MaxTotalCost > 0 {
+		return r.MaxTotalCost
+	}
+	return 1000000
+}
+
+func (r *NoRecordingRules) Check(ctx *AnalysisContext) []Finding {
+	var total float64
+	for _, cost := range ctx.QueryCosts {
+		total += cost
+	}
+	if total <= r.maxTotalCost() {
+		return nil
+	}
+	for _, expr := range ctx.ParsedExprs {
+		if usesRecordedMetric(expr) {
+			return nil
+		}
+	}
+
+	exprs := make([]string, 0, len(ctx.QueryCosts))
+	for e := range ctx.QueryCosts {
+		exprs = append(exprs, e)
+	}
+	sort.Slice(exprs, func(i, j int) bool {
+		if ctx.QueryCosts[exprs[i]] != ctx.QueryCosts[exprs[j]] {
+			return ctx.QueryCosts[exprs[i]] > ctx.QueryCosts[exprs[j]]
+		}
+		return exprs[i] < exprs[j]
+	})
+	if len(exprs) > 3 {
+		exprs = exprs[:3]
+	}
+	top := make([]string, len(exprs))
+	for i, e := range exprs {
+		top[i] = truncateQuery(e, 80)
+	}
+
+	return []Finding{
+		{
+			RuleID:      "B10",
+			Severity:    Medium,
+			Title:       "Expensive dashboard without recording rules",
+			Why:         fmt.Sprintf("The dashboard's %d queries have a summed estimated cost of %.0f (threshold: %.0f), and none reads a recording rule output (level:metric:operations). Every refresh, for every viewer, re-aggregates the raw series from scratch.", len(ctx.QueryCosts), total, r.maxTotalCost()),
+			Fix:         fmt.Sprintf("Add Prometheus recording rules for the most expensive aggregations and query the recorded series instead. Start with: %s.", strings.Join(top, "; ")),
+			Impact:      "Moves the heaviest aggregations out of the refresh path; recorded series are read, not recomputed",
+			Validate:    "Query Inspector → Stats tab → compare query time of the rewritten panels before/after",
+			AutoFixable: false,
+			Confidence:  0.6,
+		},
+	}
+}
+
+// usesRecordedMetric reports whether expr selects a metric whose name
+// follows the level:metric:operations recording rule convention.
+func usesRecordedMetric(expr parser.Expr) bool {
+	found := false
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if vs, ok := node.(*parser.VectorSelector); ok && strings.Contains(extractMetricName(vs), ":") {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
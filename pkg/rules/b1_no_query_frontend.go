@@ -14,6 +14,17 @@ type NoQueryFrontend struct{}
 func (r *NoQueryFrontend) ID() string            { return "B1" }
 func (r *NoQueryFrontend) RuleSeverity() Severity { return Critical }
 
+func (r *NoQueryFrontend) Describe() Description {
+	return Description{
+		Title:       "No Thanos query-frontend detected",
+		Summary:     "Dashboards querying Thanos without a query-frontend in front of it.",
+		Rationale:   "The query-frontend adds result caching, query splitting and retries that greatly cut latency and backend load.",
+		Bad:         "datasource URL http://thanos-query:9090",
+		Good:        "datasource URL http://thanos-query-frontend:9090",
+		AutoFixable: false,
+	}
+}
+
 func (r *NoQueryFrontend) Check(ctx *AnalysisContext) []Finding {
 	if !dashboardUsesThanos(ctx) {
 		return nil
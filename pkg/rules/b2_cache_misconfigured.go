@@ -7,6 +7,17 @@ type CacheMisconfigured struct{}
 func (r *CacheMisconfigured) ID() string            { return "B2" }
 func (r *CacheMisconfigured) RuleSeverity() Severity { return High }
 
+func (r *CacheMisconfigured) Describe() Description {
+	return Description{
+		Title:       "Query-frontend cache misconfigured",
+		Summary:     "Thanos query-frontend caches with a low hit rate (live check, not yet implemented).",
+		Rationale:   "A misconfigured results cache sends every repeated dashboard query to the backend.",
+		Bad:         "query-frontend with no results cache configured",
+		Good:        "query-frontend with a memcached or Redis results cache",
+		AutoFixable: false,
+	}
+}
+
 func (r *CacheMisconfigured) Check(ctx *AnalysisContext) []Finding {
 	// This rule requires live Prometheus metrics to check cache hit rates.
 	// Without a live endpoint, we cannot determine cache health.
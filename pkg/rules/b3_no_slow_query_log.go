@@ -8,6 +8,17 @@ type NoSlowQueryLog struct{}
 func (r *NoSlowQueryLog) ID() string            { return "B3" }
 func (r *NoSlowQueryLog) RuleSeverity() Severity { return Medium }
 
+func (r *NoSlowQueryLog) Describe() Description {
+	return Description{
+		Title:       "Slow query log disabled",
+		Summary:     "Thanos query-frontend without slow query logging (live check, not yet implemented).",
+		Rationale:   "Without it there is no record of which dashboard queries are slow.",
+		Bad:         "query-frontend without --query-frontend.log-queries-longer-than",
+		Good:        "--query-frontend.log-queries-longer-than=10s",
+		AutoFixable: false,
+	}
+}
+
 func (r *NoSlowQueryLog) Check(ctx *AnalysisContext) []Finding {
 	// This rule requires a live endpoint to check configuration.
 	if ctx.PrometheusURL == "" {
@@ -9,6 +9,17 @@ type StoreGatewayNoCache struct{}
 func (r *StoreGatewayNoCache) ID() string            { return "B4" }
 func (r *StoreGatewayNoCache) RuleSeverity() Severity { return High }
 
+func (r *StoreGatewayNoCache) Describe() Description {
+	return Description{
+		Title:       "Store gateway without cache",
+		Summary:     "Thanos store gateways without an index or bucket cache (live check, not yet implemented).",
+		Rationale:   "Every query on historical data then reads blocks from object storage.",
+		Bad:         "store gateway with the default in-memory index cache only",
+		Good:        "store gateway with a memcached index and bucket cache",
+		AutoFixable: false,
+	}
+}
+
 func (r *StoreGatewayNoCache) Check(ctx *AnalysisContext) []Finding {
 	// This rule requires live Prometheus metrics to check for cache operations.
 	if ctx.PrometheusURL == "" {
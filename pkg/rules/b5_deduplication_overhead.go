@@ -8,6 +8,17 @@ type DeduplicationOverhead struct{}
 func (r *DeduplicationOverhead) ID() string            { return "B5" }
 func (r *DeduplicationOverhead) RuleSeverity() Severity { return Medium }
 
+func (r *DeduplicationOverhead) Describe() Description {
+	return Description{
+		Title:       "Thanos deduplication overhead",
+		Summary:     "Dashboards on Thanos where replica deduplication adds significant cost.",
+		Rationale:   "Deduplication processes every replica series; CPU cost grows with replica count.",
+		Bad:         "high-cardinality panels on a deduplicating Thanos datasource",
+		Good:        "recording rules, or a datasource with dedup disabled for exploratory panels",
+		AutoFixable: false,
+	}
+}
+
 func (r *DeduplicationOverhead) Check(ctx *AnalysisContext) []Finding {
 	if !dashboardUsesThanos(ctx) {
 		return nil
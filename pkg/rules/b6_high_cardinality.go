@@ -10,6 +10,17 @@ type HighCardinality struct{}
 func (r *HighCardinality) ID() string            { return "B6" }
 func (r *HighCardinality) RuleSeverity() Severity { return High }
 
+func (r *HighCardinality) Describe() Description {
+	return Description{
+		Title:       "High cardinality TSDB",
+		Summary:     "Prometheus instances with more than 1M active head series (needs --prometheus-url).",
+		Rationale:   "High cardinality makes every query more expensive and increases memory use.",
+		Bad:         "prometheus_tsdb_head_series 2500000",
+		Good:        "unbounded labels dropped via relabeling; head series below 1M",
+		AutoFixable: false,
+	}
+}
+
 const highCardinalityThreshold = 1_000_000
 
 func (r *HighCardinality) Check(ctx *AnalysisContext) []Finding {
@@ -8,6 +8,17 @@ type QueryLogNotEnabled struct{}
 func (r *QueryLogNotEnabled) ID() string            { return "B7" }
 func (r *QueryLogNotEnabled) RuleSeverity() Severity { return Medium }
 
+func (r *QueryLogNotEnabled) Describe() Description {
+	return Description{
+		Title:       "Query log not enabled",
+		Summary:     "Prometheus without query_log_file (live check, not yet implemented).",
+		Rationale:   "Without a query log there is no history of slow or expensive queries.",
+		Bad:         "global: {} without query_log_file",
+		Good:        "global: {query_log_file: /prometheus/query.log}",
+		AutoFixable: false,
+	}
+}
+
 func (r *QueryLogNotEnabled) Check(ctx *AnalysisContext) []Finding {
 	// This rule requires a live endpoint to check Prometheus configuration.
 	if ctx.PrometheusURL == "" {
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// MaxSamplesExceeded detects queries whose estimated sample count exceeds
+// Prometheus's --query.max-samples limit. Such a query is not just slow: it
+// is rejected outright ("query processing would load too many samples into
+// memory") and the panel shows an error. The estimate needs real series
+// counts, so the rule only runs with cardinality data.
+type MaxSamplesExceeded struct {
+	// MaxSamples is the sample limit queries are checked against.
+	// Defaults to 50M, Prometheus's --query.max-samples default, if zero.
+	MaxSamples int
+	// ScrapeInterval is the assumed scrape interval of the queried targets.
+	// Defaults to AnalysisContext.ScrapeInterval (--scrape-interval) if
+	// zero, and to 15s, the step the engine's cost estimate uses, if that is
+	// unknown too.
+	ScrapeInterval time.Duration
+}
+
+func (r *MaxSamplesExceeded) ID() string             { return "B8" }
+func (r *MaxSamplesExceeded) RuleSeverity() Severity { return Critical }
+
+func (r *MaxSamplesExceeded) Describe() Description {
+	return Description{
+		Title:       "Query exceeds max-samples limit",
+		Summary:     "Targets whose estimated samples exceed --query.max-samples (50M by default; needs --prometheus-url).",
+		Rationale:   "Prometheus rejects such queries outright, so the panel shows an error instead of data.",
+		Bad:         "rate(http_requests_total[1h]) over 1M series",
+		Good:        `sum(rate(http_requests_total{job="api"}[5m])), or a recording rule`,
+		AutoFixable: false,
+	}
+}
+
+func (r *MaxSamplesExceeded) maxSamples() int {
+	if r.MaxSamples > 0 {
+		return r.MaxSamples
+	}
+	return 50_000_000
+}
+
+func (r *MaxSamplesExceeded) scrapeInterval(ctx *AnalysisContext) time.Duration {
+	if r.ScrapeInterval > 0 {
+		return r.ScrapeInterval
+	}
+	if ctx.ScrapeInterval > 0 {
+		return ctx.ScrapeInterval
+	}
+	return 15 * time.Second
+}
+
+func (r *MaxSamplesExceeded) Check(ctx *AnalysisContext) []Finding {
+	// Without measured series counts every metric gets the same heuristic
+	// guess, which says nothing about whether the limit is reached.
+	if ctx.Cardinality == nil {
+		return nil
+	}
+	limit := float64(r.maxSamples())
+	step := r.scrapeInterval(ctx).Seconds()
+
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			// Grafana duration variables are substituted with a short
+			// placeholder window before parsing, so their range is unknown.
+			if strings.Contains(target.Expr, "$__") {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			samples := estimateSamples(expr, ctx.Cardinality, step)
+			if samples <= limit {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "B8",
+				Severity:    Critical,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Query exceeds max-samples limit",
+				Why:         fmt.Sprintf("The query loads an estimated %.0f samples, over the %d sample limit (--query.max-samples). Prometheus aborts it with \"query processing would load too many samples into memory\" and the panel shows an error instead of data.", samples, r.maxSamples()),
+				Fix:         "Add label filters to select fewer series, shorten range windows and subquery ranges, or precompute the expression with a recording rule.",
+				Impact:      "Turns a failing panel into a working one and removes a query that holds gigabytes of samples in memory",
+				Validate:    "Run the query in Explore — it should return data instead of a max-samples error",
+				AutoFixable: false,
+				Confidence:  0.7,
+			})
+		}
+	}
+	return findings
+}
+
+// estimateSamples returns the number of samples expr loads per evaluation:
+// one per series for an instant selector, one per scrape for each series in
+// a range selector, repeated for every subquery step. It shares the selector
+// arithmetic of analyzer.EstimateQueryCost (RangeSamplesPerSeries,
+// SubqueryEvaluations) but leaves out that function's aggregation and
+// function weights, which rank cost rather than count samples.
+func estimateSamples(node parser.Node, card *cardinality.CardinalityData, stepSeconds float64) float64 {
+	switch n := node.(type) {
+	case *parser.VectorSelector:
+		return float64(card.EstimatedSeries(n.Name, cardinality.DefaultHeuristicSeries))
+	case *parser.MatrixSelector:
+		return estimateSamples(n.VectorSelector, card, stepSeconds) * RangeSamplesPerSeries(n.Range, stepSeconds)
+	case *parser.SubqueryExpr:
+		return estimateSamples(n.Expr, card, stepSeconds) * SubqueryEvaluations(n, stepSeconds)
+	}
+	var total float64
+	for _, child := range parser.Children(node) {
+		total += estimateSamples(child, card, stepSeconds)
+	}
+	return total
+}
+
+// RangeSamplesPerSeries returns the samples a range selector window loads
+// per series at one sample every stepSeconds. A window shorter than a step
+// still loads one sample.
+func RangeSamplesPerSeries(window time.Duration, stepSeconds float64) float64 {
+	rangeSeconds := window.Seconds()
+	if rangeSeconds < stepSeconds {
+		rangeSeconds = stepSeconds
+	}
+	return rangeSeconds / stepSeconds
+}
+
+// SubqueryEvaluations returns how many times a subquery evaluates its inner
+// expression: its range divided by its step, or by stepSeconds when the
+// subquery has no explicit step. At least one.
+func SubqueryEvaluations(sq *parser.SubqueryExpr, stepSeconds float64) float64 {
+	subStep := sq.Step.Seconds()
+	if subStep <= 0 {
+		subStep = stepSeconds
+	}
+	evaluations := sq.Range.Seconds() / subStep
+	if evaluations < 1 {
+		evaluations = 1
+	}
+	return evaluations
+}
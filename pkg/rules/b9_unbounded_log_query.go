@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// matchAllLogMatcherRe matches a LogQL label matcher that selects every
+// stream: label=~".*" (or ``-quoted).
+var matchAllLogMatcherRe = regexp.MustCompile("^\\s*[a-zA-Z_][a-zA-Z0-9_]*\\s*=~\\s*[\"`]\\.\\*[\"`]\\s*$")
+
+// UnboundedLogQuery detects Loki targets whose stream selector does not
+// narrow the streams read: {} or only label=~".*" matchers. Loki has no index
+// to fall back on beyond stream labels, so such a query reads and filters
+// every log line in the time range, however selective its line filters
+// (|= "error") are. The PromQL parser cannot read LogQL, so the selector is
+// found with a conservative string check: the first {...} of the expression.
+type UnboundedLogQuery struct{}
+
+func (r *UnboundedLogQuery) ID() string            { return "B9" }
+func (r *UnboundedLogQuery) RuleSeverity() Severity { return Critical }
+
+func (r *UnboundedLogQuery) Describe() Description {
+	return Description{
+		Title:       "Loki query without stream selector labels",
+		Summary:     `Loki targets whose stream selector is {} or only label=~".*" matchers.`,
+		Rationale:   "Loki then reads every stream in the range before line filters run.",
+		Bad:         `{} |= "error"`,
+		Good:        `{namespace="$namespace", app="api"} |= "error"`,
+		AutoFixable: false,
+	}
+}
+
+func (r *UnboundedLogQuery) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if target.Expr == "" || targetDatasourceType(panel, target) != "loki" {
+				continue
+			}
+			if !unboundedStreamSelector(target.Expr) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "B9",
+				Severity:    Critical,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Loki query without stream selector labels",
+				Why:         "The LogQL stream selector does not filter on any label, so Loki must fetch and scan every log stream in the time range. Line filters like |= \"...\" only run after all those chunks are read.",
+				Fix:         "Add at least one equality label matcher to the stream selector, e.g. {namespace=\"$namespace\", app=\"api\"} |= \"error\".",
+				Impact:      "Reduces the chunks Loki reads from every stream to just the matching ones — often by several orders of magnitude",
+				Validate:    "Query inspector → Stats: compare 'Total bytes processed' before/after",
+				AutoFixable: false,
+				Confidence:  0.8,
+			})
+		}
+	}
+	return findings
+}
+
+// targetDatasourceType returns the datasource type a target runs against:
+// its own datasource when set (mixed panels), otherwise the panel's.
+func targetDatasourceType(panel extractor.PanelModel, target extractor.TargetModel) string {
+	if target.Datasource != nil && target.Datasource.Type != "" {
+		return target.Datasource.Type
+	}
+	if panel.Datasource != nil {
+		return panel.Datasource.Type
+	}
+	return ""
+}
+
+// unboundedStreamSelector reports whether the first {...} of a LogQL
+// expression is empty or made only of match-everything matchers. The stream
+// selector always precedes pipeline stages, so template braces like
+// line_format "{{.msg}}" are never the first brace. Expressions without a
+// complete {...} (a whole query in a $variable, or a malformed one) are not
+// reported: there is no selector to judge.
+func unboundedStreamSelector(expr string) bool {
+	open := strings.Index(expr, "{")
+	if open < 0 {
+		return false
+	}
+	end := strings.Index(expr[open:], "}")
+	if end < 0 {
+		return false
+	}
+	selector := strings.TrimSpace(expr[open+1 : open+end])
+	if selector == "" {
+		return true
+	}
+	for _, matcher := range strings.Split(selector, ",") {
+		if !matchAllLogMatcherRe.MatchString(matcher) {
+			return false
+		}
+	}
+	return true
+}
@@ -15,6 +15,17 @@ type NoCollapsedRows struct{}
 func (r *NoCollapsedRows) ID() string            { return "D10" }
 func (r *NoCollapsedRows) RuleSeverity() Severity { return Medium }
 
+func (r *NoCollapsedRows) Describe() Description {
+	return Description{
+		Title:       "No collapsed rows to defer query execution",
+		Summary:     "Dashboards with 5 or more panels and no collapsed rows.",
+		Rationale:   "Panels inside collapsed rows only query when expanded; without rows everything loads at once.",
+		Bad:         "all 20 panels at the top level",
+		Good:        `secondary panels inside rows with "collapsed": true`,
+		AutoFixable: false,
+	}
+}
+
 func (r *NoCollapsedRows) Check(ctx *AnalysisContext) []Finding {
 	allPanels := extractor.AllPanels(ctx.Dashboard)
 
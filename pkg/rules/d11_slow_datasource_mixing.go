@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// defaultSlowDatasourceTypes lists datasource types whose queries are usually
+// much slower than PromQL range queries. Loki is handled separately because
+// it is only slow when the dashboard range is wide.
+var defaultSlowDatasourceTypes = []string{
+	"mysql",
+	"postgres",
+	"grafana-postgresql-datasource",
+	"elasticsearch",
+}
+
+// SlowDatasourceMixing detects dashboards that mix Prometheus panels with
+// panels backed by a known-slow datasource type (SQL, Elasticsearch, Loki over
+// wide ranges). The dashboard only feels loaded when the slowest panel
+// returns, so one SQL panel can make an otherwise fast dashboard slow.
+type SlowDatasourceMixing struct {
+	// SlowTypes overrides the datasource types considered slow.
+	// Defaults to defaultSlowDatasourceTypes if empty.
+	SlowTypes []string
+	// LokiWideRange is the default time range above which Loki panels count
+	// as slow. Defaults to 6h if zero.
+	LokiWideRange time.Duration
+}
+
+func (r *SlowDatasourceMixing) ID() string            { return "D11" }
+func (r *SlowDatasourceMixing) RuleSeverity() Severity { return Low }
+
+func (r *SlowDatasourceMixing) Describe() Description {
+	return Description{
+		Title:       "Prometheus mixed with slow datasource types",
+		Summary:     "Dashboards mixing Prometheus panels with SQL, Elasticsearch or wide-range Loki panels.",
+		Rationale:   "The dashboard only feels loaded when its slowest panel returns.",
+		Bad:         "Prometheus panels next to a MySQL table panel",
+		Good:        "the SQL panel moved to its own dashboard and linked",
+		AutoFixable: false,
+	}
+}
+
+func (r *SlowDatasourceMixing) slowTypes() []string {
+	if len(r.SlowTypes) > 0 {
+		return r.SlowTypes
+	}
+	return defaultSlowDatasourceTypes
+}
+
+func (r *SlowDatasourceMixing) lokiWideRange() time.Duration {
+	if r.LokiWideRange > 0 {
+		return r.LokiWideRange
+	}
+	return 6 * time.Hour
+}
+
+func (r *SlowDatasourceMixing) Check(ctx *AnalysisContext) []Finding {
+	types := extractor.AllDatasourceTypes(ctx.Dashboard)
+
+	slow := make(map[string]bool)
+	for _, t := range r.slowTypes() {
+		slow[t] = true
+	}
+	if d, err := parseRelativeRange(ctx.Dashboard.Time.From); err == nil && d > r.lokiWideRange() {
+		slow["loki"] = true
+	}
+
+	hasPrometheus := false
+	var slowFound []string
+	for _, t := range types {
+		if t == "prometheus" {
+			hasPrometheus = true
+		}
+		if slow[t] {
+			slowFound = append(slowFound, t)
+		}
+	}
+	if !hasPrometheus || len(slowFound) == 0 {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:   "D11",
+			Severity: Low,
+			Title:    "Prometheus mixed with slow datasource types",
+			Why: fmt.Sprintf(
+				"Dashboard mixes Prometheus panels with slower datasource types [%s]. "+
+					"The dashboard only finishes loading when the slowest panel returns, so fast PromQL panels wait on SQL/log queries.",
+				strings.Join(slowFound, ", "),
+			),
+			Fix:         "Move the slow panels to a separate dashboard linked from this one, or into a collapsed row so they only run on demand.",
+			Impact:      "Initial load time is bounded by Prometheus queries instead of the slowest backend",
+			Validate:    "Reload dashboard → compare panel load times in browser DevTools Network tab",
+			AutoFixable: false,
+			Confidence:  0.6,
+		},
+	}
+}
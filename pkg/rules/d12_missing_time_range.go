@@ -0,0 +1,41 @@
+package rules
+
+// MissingTimeRange detects dashboards with no default time range. Grafana then
+// falls back to the instance-wide default, which may be much wider than the
+// dashboard needs, and D6 cannot evaluate a range that isn't there.
+type MissingTimeRange struct{}
+
+func (r *MissingTimeRange) ID() string            { return "D12" }
+func (r *MissingTimeRange) RuleSeverity() Severity { return Low }
+
+func (r *MissingTimeRange) Describe() Description {
+	return Description{
+		Title:       "No default time range",
+		Summary:     "Dashboards without time.from.",
+		Rationale:   "Grafana falls back to the instance default range, which the dashboard does not control.",
+		Bad:         `"time": {}`,
+		Good:        `"time": {"from": "now-6h", "to": "now"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *MissingTimeRange) Check(ctx *AnalysisContext) []Finding {
+	// D6 owns non-empty ranges; this rule only covers the case it skips.
+	if ctx.Dashboard.Time.From != "" {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D12",
+			Severity:    Low,
+			Title:       "No default time range",
+			Why:         "Dashboard does not set time.from, so Grafana uses its global default range. That default is outside the dashboard's control and may be far wider than the panels need.",
+			Fix:         "Set an explicit default time range in dashboard settings (e.g. \"now-1h\" or \"now-6h\").",
+			Impact:      "Makes the per-query data volume predictable regardless of Grafana instance defaults",
+			Validate:    "Open dashboard settings → Time Options → verify the From value is set",
+			AutoFixable: false,
+			Confidence:  1.0,
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NestedRepeat detects repeated rows that contain panels which repeat as
+// well. Grafana instantiates every repeated panel once per row copy, so the
+// panel count — and the query count — grows as rows × panels.
+type NestedRepeat struct{}
+
+func (r *NestedRepeat) ID() string            { return "D13" }
+func (r *NestedRepeat) RuleSeverity() Severity { return High }
+
+func (r *NestedRepeat) Describe() Description {
+	return Description{
+		Title:       "Repeated row contains repeated panels",
+		Summary:     "Repeated rows containing panels that repeat as well.",
+		Rationale:   "Panel count and query count grow as rows × panels.",
+		Bad:         "row repeat: cluster, panel repeat: pod",
+		Good:        "row repeat: cluster; pods aggregated in one panel",
+		AutoFixable: false,
+	}
+}
+
+func (r *NestedRepeat) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	top := ctx.Dashboard.Panels
+	for i, row := range top {
+		if row.Type != "row" || row.Repeat == "" {
+			continue
+		}
+
+		// Collapsed rows carry their panels in NestedPanels; expanded rows own
+		// the top-level panels that follow them up to the next row.
+		children := row.NestedPanels
+		if len(children) == 0 {
+			for _, p := range top[i+1:] {
+				if p.Type == "row" {
+					break
+				}
+				children = append(children, p)
+			}
+		}
+
+		ids := []int{row.ID}
+		titles := []string{row.Title}
+		var repeats []string
+		for _, p := range children {
+			if p.Repeat == "" {
+				continue
+			}
+			ids = append(ids, p.ID)
+			titles = append(titles, p.Title)
+			repeats = append(repeats, fmt.Sprintf("%q by $%s", p.Title, p.Repeat))
+		}
+		if len(repeats) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      "D13",
+			Severity:    High,
+			PanelIDs:    ids,
+			PanelTitles: titles,
+			Title:       "Repeated row contains repeated panels",
+			Why: fmt.Sprintf(
+				"Row %q repeats by $%s and contains panels that also repeat (%s). "+
+					"Every row copy repeats those panels again, so the panel and query count grows as rows × panels.",
+				row.Title, row.Repeat, strings.Join(repeats, ", "),
+			),
+			Fix:         fmt.Sprintf("Keep only one level of repetition: either repeat the row by $%s, or repeat the panels and drop the row repeat.", row.Repeat),
+			Impact:      "Panel instantiations drop from rows × panels to rows + panels",
+			Validate:    "Select several values for both variables → count rendered panels before/after",
+			AutoFixable: false,
+			Confidence:  0.9,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ExcessiveAnnotations detects dashboards whose annotation queries add
+// noticeable load. Enabled annotations run on every load and refresh just
+// like panels, but are easy to forget because they render no panel of their
+// own. Flags too many enabled annotation queries (Low) and individual
+// annotation queries with expensive PromQL (Medium).
+type ExcessiveAnnotations struct {
+	// MaxEnabled is the number of enabled annotation queries tolerated
+	// before flagging. Defaults to 3 if zero.
+	MaxEnabled int
+}
+
+func (r *ExcessiveAnnotations) ID() string            { return "D14" }
+func (r *ExcessiveAnnotations) RuleSeverity() Severity { return Medium }
+
+func (r *ExcessiveAnnotations) Describe() Description {
+	return Description{
+		Title:       "Excessive annotation queries",
+		Summary:     "Too many enabled annotation queries, or annotation queries with expensive PromQL.",
+		Rationale:   "Enabled annotations run on every load and refresh like hidden panels.",
+		Bad:         "annotation expr: changes(kube_pod_container_status_restarts_total[1h]) > 0",
+		Good:        `annotation expr: changes(kube_pod_container_status_restarts_total{namespace="$namespace"}[5m]) > 0`,
+		AutoFixable: false,
+	}
+}
+
+func (r *ExcessiveAnnotations) maxEnabled() int {
+	if r.MaxEnabled > 0 {
+		return r.MaxEnabled
+	}
+	return 3
+}
+
+func (r *ExcessiveAnnotations) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	var enabled []string
+	for _, a := range ctx.Dashboard.Annotations.List {
+		// Grafana's built-in "Annotations & Alerts" reads its own database,
+		// not the metrics backend.
+		if !a.Enable || a.BuiltIn == 1 {
+			continue
+		}
+		enabled = append(enabled, a.Name)
+
+		expr, ok := ctx.ParsedExprs[a.Expr]
+		if !ok {
+			continue
+		}
+		reason := expensiveAnnotationReason(expr)
+		if reason == "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D14",
+			Severity:    Medium,
+			Title:       "Expensive annotation query",
+			Why:         fmt.Sprintf("Annotation %q %s. It runs on every dashboard load and refresh: %s", a.Name, reason, truncateQuery(a.Expr, 120)),
+			Fix:         "Add label filters to the annotation query and keep ranges short, or disable the annotation by default (enable: false) so users toggle it on when needed.",
+			Impact:      "Removes a hidden expensive query from every load and refresh",
+			Validate:    "Reload dashboard → check the annotation request in Query Inspector or browser DevTools",
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+
+	if len(enabled) > r.maxEnabled() {
+		findings = append(findings, Finding{
+			RuleID:   "D14",
+			Severity: Low,
+			Title:    "Too many enabled annotation queries",
+			Why: fmt.Sprintf(
+				"Dashboard has %d enabled annotation queries (threshold %d): %s. Each one runs on every load and refresh like a hidden panel.",
+				len(enabled), r.maxEnabled(), strings.Join(enabled, ", "),
+			),
+			Fix:         "Disable rarely used annotations by default (enable: false); users can still toggle them on from the dashboard controls.",
+			Impact:      fmt.Sprintf("Up to %d fewer queries per load and refresh", len(enabled)-r.maxEnabled()),
+			Validate:    "Dashboard settings → Annotations → check which entries are enabled",
+			AutoFixable: false,
+			Confidence:  0.8,
+		})
+	}
+	return findings
+}
+
+// expensiveAnnotationReason returns why an annotation expression is
+// expensive, or "" if it looks cheap. Checks bare selectors (no label
+// matchers), subqueries, and range windows over 1h.
+func expensiveAnnotationReason(expr parser.Expr) string {
+	var reason string
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if reason != "" {
+			return nil
+		}
+		switch n := node.(type) {
+		case *parser.VectorSelector:
+			for _, m := range n.LabelMatchers {
+				if m.Name != "__name__" {
+					return nil
+				}
+			}
+			reason = fmt.Sprintf("selects every series of %q without label filters", extractMetricName(n))
+		case *parser.SubqueryExpr:
+			reason = "uses a subquery"
+		case *parser.MatrixSelector:
+			if n.Range > time.Hour {
+				reason = fmt.Sprintf("reads a %s range window", n.Range)
+			}
+		}
+		return nil
+	})
+	return reason
+}
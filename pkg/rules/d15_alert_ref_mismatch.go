@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// AlertRefMismatch detects legacy panel alerts whose conditions query a
+// RefID that none of the panel's targets carry. This happens when a target
+// is deleted or re-lettered after the alert was written: the alert then
+// evaluates nothing, or a different query than the panel shows.
+type AlertRefMismatch struct{}
+
+func (r *AlertRefMismatch) ID() string            { return "D15" }
+func (r *AlertRefMismatch) RuleSeverity() Severity { return Low }
+
+func (r *AlertRefMismatch) Describe() Description {
+	return Description{
+		Title:       "Alert condition references a missing query",
+		Summary:     "Legacy panel alerts whose conditions reference a RefID no target has.",
+		Rationale:   "The alert then evaluates nothing, or a different query than the panel shows.",
+		Bad:         `alert condition on refId "B", panel targets A and C`,
+		Good:        `alert condition on refId "A"`,
+		AutoFixable: false,
+	}
+}
+
+func (r *AlertRefMismatch) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, p := range extractor.AllPanels(ctx.Dashboard) {
+		if p.Alert == nil {
+			continue
+		}
+		refIDs := make(map[string]bool, len(p.Targets))
+		var have []string
+		for _, t := range p.Targets {
+			if t.RefID != "" && !refIDs[t.RefID] {
+				refIDs[t.RefID] = true
+				have = append(have, t.RefID)
+			}
+		}
+
+		var missing []string
+		for _, c := range p.Alert.Conditions {
+			ref := c.RefID()
+			if ref != "" && !refIDs[ref] {
+				missing = append(missing, ref)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      "D15",
+			Severity:    Low,
+			PanelIDs:    []int{p.ID},
+			PanelTitles: []string{p.Title},
+			Title:       "Alert condition references a missing query",
+			Why: fmt.Sprintf(
+				"Alert %q evaluates query %s, but the panel's targets are [%s]. The alert has drifted from the panel and no longer checks what the panel shows.",
+				p.Alert.Name, strings.Join(missing, ", "), strings.Join(have, ", "),
+			),
+			Fix:         "Point the alert condition at an existing target RefID, or restore the target it was written against.",
+			Impact:      "Alert evaluates the same query the panel displays",
+			Validate:    "Panel editor → Alert tab → check each condition's query letter matches a target",
+			AutoFixable: false,
+			Confidence:  0.9,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// UnusedRepeatVariable detects panels that repeat over a template variable
+// their queries never reference. Every copy runs the same queries and shows
+// the same data, so the repeat only multiplies load. Complements D2, which
+// flags repeats that are used but can fan out without bound.
+type UnusedRepeatVariable struct{}
+
+func (r *UnusedRepeatVariable) ID() string            { return "D16" }
+func (r *UnusedRepeatVariable) RuleSeverity() Severity { return Medium }
+
+func (r *UnusedRepeatVariable) Describe() Description {
+	return Description{
+		Title:       "Repeat variable not used in panel queries",
+		Summary:     "Panels repeating over a variable their queries never reference.",
+		Rationale:   "Every copy runs identical queries and shows the same data.",
+		Bad:         `"repeat": "pod", expr: sum(up{job="api"})`,
+		Good:        `"repeat": "pod", expr: sum(up{job="api", pod="$pod"})`,
+		AutoFixable: false,
+	}
+}
+
+func (r *UnusedRepeatVariable) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, p := range extractor.AllPanels(ctx.Dashboard) {
+		if p.Repeat == "" || p.Type == "row" {
+			continue
+		}
+		ref := variableRefPattern(p.Repeat)
+		// A repeat over a datasource variable changes what every copy
+		// queries even when the expressions are identical.
+		if p.Datasource != nil && ref.MatchString(p.Datasource.UID) {
+			continue
+		}
+		exprs, referenced := 0, false
+		for _, t := range p.Targets {
+			if t.Expr == "" {
+				continue
+			}
+			exprs++
+			if ref.MatchString(t.Expr) || (t.Datasource != nil && ref.MatchString(t.Datasource.UID)) {
+				referenced = true
+				break
+			}
+		}
+		if exprs == 0 || referenced {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D16",
+			Severity:    Medium,
+			PanelIDs:    []int{p.ID},
+			PanelTitles: []string{p.Title},
+			Title:       "Repeat variable not used in panel queries",
+			Why:         fmt.Sprintf("Panel %q repeats over $%s, but none of its queries reference $%s. Every copy runs the same queries and shows the same data.", p.Title, p.Repeat, p.Repeat),
+			Fix:         fmt.Sprintf("Filter the queries by $%s (e.g. {instance=~\"$%s\"}), or remove the repeat.", p.Repeat, p.Repeat),
+			Impact:      "Removes identical duplicate panels and the queries each copy fires",
+			Validate:    fmt.Sprintf("Select several values of $%s and check each repeated panel shows different data", p.Repeat),
+			AutoFixable: false,
+			Confidence:  0.8,
+		})
+	}
+	return findings
+}
+
+// variableRefPattern matches any Grafana reference to the variable name:
+// $name, ${name}, ${name:format} and the legacy [[name]].
+func variableRefPattern(name string) *regexp.Regexp {
+	q := regexp.QuoteMeta(name)
+	return regexp.MustCompile(`\$` + q + `\b|\$\{` + q + `[}:]|\[\[` + q + `[\]:]`)
+}
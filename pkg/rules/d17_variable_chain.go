@@ -0,0 +1,213 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariableChain detects template variables whose queries depend on each other
+// in long chains ($pod filtered by $namespace filtered by $cluster). Grafana
+// resolves a chain one level at a time, so each level adds a full query round
+// trip before panels can load. Cycles are reported separately: Grafana cannot
+// order them and the variables may refresh repeatedly or never settle.
+//
+// Only query variables are graph nodes. Custom, constant and interval
+// variables resolve without a round trip, so depending on them adds no
+// waterfall step.
+type VariableChain struct {
+	// MaxDepth is the longest acceptable chain of dependent query variables.
+	// Defaults to 2 if zero.
+	MaxDepth int
+}
+
+func (r *VariableChain) ID() string            { return "D17" }
+func (r *VariableChain) RuleSeverity() Severity { return Medium }
+
+func (r *VariableChain) Describe() Description {
+	return Description{
+		Title:       "Long chain of dependent variables",
+		Summary:     "Query variables chained more than 2 levels deep, or in a cycle.",
+		Rationale:   "Grafana resolves each level with a separate round trip before panels can load; cycles may never settle.",
+		Bad:         "$region → $cluster → $namespace → $pod → $container",
+		Good:        "$cluster → $namespace, with $pod filtered in panel queries",
+		AutoFixable: false,
+	}
+}
+
+func (r *VariableChain) maxDepth() int {
+	if r.MaxDepth > 0 {
+		return r.MaxDepth
+	}
+	return 2
+}
+
+func (r *VariableChain) Check(ctx *AnalysisContext) []Finding {
+	deps := variableDependencies(ctx)
+	var findings []Finding
+
+	for _, cycle := range findVariableCycles(deps) {
+		findings = append(findings, Finding{
+			RuleID:      "D17",
+			Severity:    High,
+			Title:       "Cyclic variable dependency",
+			Why:         fmt.Sprintf("Variables %s reference each other in a cycle. Grafana cannot determine a load order, so the variables may refresh repeatedly or stay unresolved.", formatVariableChain(append(cycle, cycle[0]))),
+			Fix:         "Break the cycle by removing the reference from one of the variable queries.",
+			Impact:      "Variables resolve once in a fixed order instead of looping",
+			Validate:    "Reload the dashboard and change each variable — the others should refresh once",
+			AutoFixable: false,
+			Confidence:  0.9,
+		})
+	}
+
+	chain := longestVariableChain(deps)
+	if len(chain) > r.maxDepth() {
+		findings = append(findings, Finding{
+			RuleID:      "D17",
+			Severity:    Medium,
+			Title:       "Long chain of dependent variables",
+			Why:         fmt.Sprintf("Variables load in sequence: %s (%d levels, threshold: %d). Each query waits for the previous variable to resolve, so panels wait on a waterfall of %d round trips.", formatVariableChain(chain), len(chain), r.maxDepth(), len(chain)),
+			Fix:         "Flatten the chain: filter lower variables on fewer parents, or replace stable intermediate query variables with custom values.",
+			Impact:      fmt.Sprintf("Removes up to %d sequential variable queries from dashboard load", len(chain)-r.maxDepth()),
+			Validate:    "Reload dashboard → DevTools Network tab should show variable queries running in parallel, not one after another",
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+	return findings
+}
+
+// variableDependencies maps each query variable to the other query variables
+// its query references, in dashboard order.
+func variableDependencies(ctx *AnalysisContext) map[string][]string {
+	var names []string
+	for _, v := range ctx.Variables {
+		if v.Type == "query" {
+			names = append(names, v.Name)
+		}
+	}
+	deps := make(map[string][]string, len(names))
+	for _, v := range ctx.Variables {
+		if v.Type != "query" {
+			continue
+		}
+		query := v.QueryString()
+		deps[v.Name] = []string{}
+		for _, other := range names {
+			if other != v.Name && variableRefPattern(other).MatchString(query) {
+				deps[v.Name] = append(deps[v.Name], other)
+			}
+		}
+	}
+	return deps
+}
+
+// findVariableCycles returns each distinct dependency cycle once, starting
+// from its alphabetically first variable.
+func findVariableCycles(deps map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int, len(deps))
+	seen := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = onStack
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case onStack:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := rotateToMin(stack[start:])
+				if key := strings.Join(cycle, ","); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+
+	for _, name := range sortedKeys(deps) {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return cycles
+}
+
+// longestVariableChain returns the longest acyclic dependency path, ordered
+// from the variable that loads first to the one that loads last. Edges that
+// close a cycle are ignored; cycles are reported by findVariableCycles.
+func longestVariableChain(deps map[string][]string) []string {
+	memo := make(map[string][]string, len(deps))
+	visiting := make(map[string]bool)
+
+	var chainTo func(name string) []string
+	chainTo = func(name string) []string {
+		if c, ok := memo[name]; ok {
+			return c
+		}
+		visiting[name] = true
+		var best []string
+		for _, dep := range deps[name] {
+			if visiting[dep] {
+				continue
+			}
+			if c := chainTo(dep); len(c) > len(best) {
+				best = c
+			}
+		}
+		visiting[name] = false
+		chain := append(append([]string{}, best...), name)
+		memo[name] = chain
+		return chain
+	}
+
+	var longest []string
+	for _, name := range sortedKeys(deps) {
+		if c := chainTo(name); len(c) > len(longest) {
+			longest = c
+		}
+	}
+	return longest
+}
+
+func rotateToMin(cycle []string) []string {
+	min := 0
+	for i, name := range cycle {
+		if name < cycle[min] {
+			min = i
+		}
+	}
+	return append(append([]string{}, cycle[min:]...), cycle[:min]...)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatVariableChain renders names as "$a → $b → $c".
+func formatVariableChain(names []string) string {
+	refs := make([]string, len(names))
+	for i, n := range names {
+		refs[i] = "$" + n
+	}
+	return strings.Join(refs, " → ")
+}
@@ -0,0 +1,58 @@
+package rules
+
+import "fmt"
+
+// OldSchemaVersion detects dashboards exported with a very old schemaVersion.
+// Grafana migrates such dashboards in the browser on load, but the stored
+// JSON keeps deprecated panel structures (graph panels, legacy alerts,
+// string datasources) that the analyzer may not interpret the way the
+// migrated dashboard behaves.
+type OldSchemaVersion struct {
+	// MinVersion is the lowest schemaVersion accepted without a finding.
+	// Defaults to 30 (Grafana 8.x) if zero.
+	MinVersion int
+}
+
+func (r *OldSchemaVersion) ID() string            { return "D18" }
+func (r *OldSchemaVersion) RuleSeverity() Severity { return Low }
+
+func (r *OldSchemaVersion) Describe() Description {
+	return Description{
+		Title:       "Very old dashboard schema version",
+		Summary:     "Dashboards with a schemaVersion below 30 (Grafana 8).",
+		Rationale:   "Grafana migrates them in the browser on load, and the stored JSON keeps deprecated structures the analyzer may misread.",
+		Bad:         `"schemaVersion": 16`,
+		Good:        `save the dashboard from a current Grafana ("schemaVersion": 39)`,
+		AutoFixable: false,
+	}
+}
+
+func (r *OldSchemaVersion) minVersion() int {
+	if r.MinVersion > 0 {
+		return r.MinVersion
+	}
+	return 30
+}
+
+func (r *OldSchemaVersion) Check(ctx *AnalysisContext) []Finding {
+	version := ctx.Dashboard.SchemaVersion
+	// Zero means the field is missing (hand-written or API-generated JSON),
+	// which says nothing about the panel structures used.
+	if version == 0 || version >= r.minVersion() {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D18",
+			Severity:    Low,
+			Title:       "Very old dashboard schema version",
+			Why:         fmt.Sprintf("Dashboard has schemaVersion %d (minimum: %d). Grafana migrates it on every load, and the stored JSON may use deprecated panel structures that analysis results don't reflect accurately.", version, r.minVersion()),
+			Fix:         "Open the dashboard in a current Grafana, save it, and re-export the JSON so the stored schema is migrated.",
+			Impact:      "Analysis and Grafana both see the same, current panel structure",
+			Validate:    "Re-export and check schemaVersion in the JSON is current",
+			AutoFixable: false,
+			Confidence:  0.9,
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// LiveNowManyPanels detects dashboards with liveNow enabled and many querying
+// panels. With liveNow, Grafana continuously redraws panels as "now" advances
+// and keeps streaming queries subscribed, so every visible panel adds
+// constant load instead of load once per refresh.
+type LiveNowManyPanels struct {
+	// MaxPanels is the number of visible querying panels tolerated with
+	// liveNow enabled. Defaults to 10 if zero.
+	MaxPanels int
+}
+
+func (r *LiveNowManyPanels) ID() string            { return "D19" }
+func (r *LiveNowManyPanels) RuleSeverity() Severity { return High }
+
+func (r *LiveNowManyPanels) Describe() Description {
+	return Description{
+		Title:       "liveNow enabled on a dashboard with many panels",
+		Summary:     "liveNow on dashboards with more than 10 querying panels.",
+		Rationale:   "liveNow keeps redrawing and streaming every visible panel, adding constant load.",
+		Bad:         `"liveNow": true with 20 panels`,
+		Good:        `"liveNow": false and a 1m refresh`,
+		AutoFixable: false,
+	}
+}
+
+func (r *LiveNowManyPanels) maxPanels() int {
+	if r.MaxPanels > 0 {
+		return r.MaxPanels
+	}
+	return 10
+}
+
+func (r *LiveNowManyPanels) Check(ctx *AnalysisContext) []Finding {
+	if !ctx.Dashboard.LiveNow {
+		return nil
+	}
+
+	count := 0
+	for _, p := range extractor.VisiblePanels(ctx.Dashboard) {
+		if len(p.Targets) > 0 {
+			count++
+		}
+	}
+	thresh := r.maxPanels()
+	if count <= thresh {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D19",
+			Severity:    High,
+			Title:       "liveNow enabled on a dashboard with many panels",
+			Why:         fmt.Sprintf("Dashboard has liveNow enabled with %d visible querying panels (threshold: %d). Panels are redrawn continuously and streaming queries stay subscribed, so every panel adds constant backend and browser load.", count, thresh),
+			Fix:         "Disable liveNow and use a periodic refresh, or move the few panels that need live updates to a small dedicated dashboard.",
+			Impact:      fmt.Sprintf("Removes continuous load from %d panels", count),
+			Validate:    "Open dashboard settings → verify \"Refresh live dashboards\" is off, then watch browser CPU and backend query rate",
+			AutoFixable: false,
+			Confidence:  0.8,
+		},
+	}
+}
@@ -10,15 +10,35 @@ import (
 // visible panels. Each visible panel fires queries on load, so too many
 // panels cause slow initial render and excessive backend load.
 type TooManyPanels struct {
-	// Threshold is the max number of visible panels before flagging.
+	// MaxPanels is the max number of visible panels before flagging.
 	// Defaults to 25 if zero.
+	MaxPanels int
+
+	// Threshold is the former name of MaxPanels, used when MaxPanels is
+	// zero.
+	//
+	// Deprecated: use MaxPanels.
 	Threshold int
 }
 
 func (r *TooManyPanels) ID() string            { return "D1" }
 func (r *TooManyPanels) RuleSeverity() Severity { return High }
 
-func (r *TooManyPanels) threshold() int {
+func (r *TooManyPanels) Describe() Description {
+	return Description{
+		Title:       "Too many visible panels",
+		Summary:     "Dashboards with more than 25 visible panels (--max-panels).",
+		Rationale:   "Every visible panel fires its queries on load, so the dashboard renders slowly and loads the backend heavily.",
+		Bad:         "40 panels at the top level",
+		Good:        "12 key panels at the top level; details in collapsed rows",
+		AutoFixable: false,
+	}
+}
+
+func (r *TooManyPanels) maxPanels() int {
+	if r.MaxPanels > 0 {
+		return r.MaxPanels
+	}
 	if r.Threshold > 0 {
 		return r.Threshold
 	}
@@ -28,7 +48,7 @@ func (r *TooManyPanels) threshold() int {
 func (r *TooManyPanels) Check(ctx *AnalysisContext) []Finding {
 	visible := extractor.VisiblePanels(ctx.Dashboard)
 	count := len(visible)
-	thresh := r.threshold()
+	thresh := r.maxPanels()
 
 	if count <= thresh {
 		return nil
@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// HiddenExpensiveTarget detects hidden targets (hide: true) with a non-trivial
+// estimated cost. Hidden queries are not drawn, but depending on the Grafana
+// version and datasource they can still be executed, paying full query cost
+// for nothing. Targets referenced by a server-side expression are skipped:
+// hiding the inputs of an expression is intentional.
+type HiddenExpensiveTarget struct {
+	// MinCost is the estimated query cost above which a hidden target is
+	// reported. Defaults to 10000 if zero: any range query, but not a bare
+	// instant selector, at the static cost estimate.
+	MinCost float64
+}
+
+func (r *HiddenExpensiveTarget) ID() string            { return "D20" }
+func (r *HiddenExpensiveTarget) RuleSeverity() Severity { return Low }
+
+func (r *HiddenExpensiveTarget) Describe() Description {
+	return Description{
+		Title:       "Hidden target with expensive query",
+		Summary:     "Hidden targets with a non-trivial estimated cost.",
+		Rationale:   "Depending on Grafana version and datasource, hidden targets may still execute on every refresh.",
+		Bad:         `target B: "hide": true, expr: sum(rate(http_requests_total[1h]))`,
+		Good:        "delete target B, or move it to Explore",
+		AutoFixable: false,
+	}
+}
+
+func (r *HiddenExpensiveTarget) minCost() float64 {
+	if r.MinCost > 0 {
+		return r.MinCost
+	}
+	return 10000
+}
+
+func (r *HiddenExpensiveTarget) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		var hidden []string
+		var total float64
+		for _, t := range panel.Targets {
+			if !t.Hide || t.Expr == "" || referencedByExpression(panel, t.RefID) {
+				continue
+			}
+			if cost := ctx.QueryCosts[t.Expr]; cost > r.minCost() {
+				hidden = append(hidden, t.RefID)
+				total += cost
+			}
+		}
+		if len(hidden) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D20",
+			Severity:    Low,
+			PanelIDs:    []int{panel.ID},
+			PanelTitles: []string{panel.Title},
+			Title:       "Hidden target with expensive query",
+			Why:         fmt.Sprintf("Panel %q has hidden target(s) %s with an estimated cost of %.0f. They are not displayed, but depending on the Grafana version and datasource they may still be executed on every refresh.", panel.Title, strings.Join(hidden, ", "), total),
+			Fix:         "Delete hidden targets that are no longer needed, or move exploratory queries to Explore.",
+			Impact:      "Avoids paying for queries nobody sees",
+			Validate:    "Open the panel's Query inspector → verify only the visible targets are sent",
+			AutoFixable: false,
+			Confidence:  0.5,
+		})
+	}
+	return findings
+}
+
+// referencedByExpression reports whether a server-side expression target in
+// panel uses refID as an input ($A, ${A}).
+func referencedByExpression(panel extractor.PanelModel, refID string) bool {
+	if refID == "" {
+		return false
+	}
+	ref := variableRefPattern(refID)
+	for _, t := range panel.Targets {
+		if t.Expression != "" && ref.MatchString(t.Expression) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// labelNameRe matches a bare Prometheus label name.
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// UnscopedLabelValues detects query variables using the one-argument
+// label_values(label) form. Without a metric it becomes a label values
+// call with no series matcher, so Prometheus collects the label across
+// every series in the time range instead of only those of one metric.
+type UnscopedLabelValues struct{}
+
+func (r *UnscopedLabelValues) ID() string            { return "D21" }
+func (r *UnscopedLabelValues) RuleSeverity() Severity { return Medium }
+
+func (r *UnscopedLabelValues) Describe() Description {
+	return Description{
+		Title:       "label_values() without a metric",
+		Summary:     "Query variables using the one-argument label_values(label) form.",
+		Rationale:   "Without a metric, Prometheus collects the label across every series in the time range.",
+		Bad:         `"query": "label_values(namespace)"`,
+		Good:        `"query": "label_values(kube_pod_info, namespace)"`,
+		AutoFixable: false,
+	}
+}
+
+func (r *UnscopedLabelValues) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, v := range ctx.Variables {
+		if v.Type != "query" {
+			continue
+		}
+		label := unscopedLabelValuesLabel(v.QueryString())
+		if label == "" {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D21",
+			Severity:    Medium,
+			Title:       "label_values() without a metric",
+			Why:         fmt.Sprintf("Variable $%s uses label_values(%s) without a metric. Prometheus has to scan the %q label across every series in the time range, not just one metric's series.", v.Name, label, label),
+			Fix:         fmt.Sprintf("Scope the query to a metric that carries the label, e.g. label_values(up, %s) or label_values(up{job=\"...\"}, %s).", label, label),
+			Impact:      "Variable lookup reads one metric's series instead of the whole index",
+			Validate:    "Open dashboard → check Network tab: the label values request should include a match[] parameter",
+			AutoFixable: false,
+			Confidence:  0.9,
+		})
+	}
+	return findings
+}
+
+// unscopedLabelValuesLabel returns the label of a one-argument
+// label_values(label) query, or "" for any other query. The two-argument
+// form always starts with a selector followed by a comma, so a lone label
+// name identifies the unscoped form.
+func unscopedLabelValuesLabel(query string) string {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, "label_values(") || !strings.HasSuffix(query, ")") {
+		return ""
+	}
+	arg := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(query, "label_values("), ")"))
+	if !labelNameRe.MatchString(arg) {
+		return ""
+	}
+	return arg
+}
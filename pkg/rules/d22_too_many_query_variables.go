@@ -0,0 +1,61 @@
+package rules
+
+import "fmt"
+
+// TooManyQueryVariables detects dashboards with many query-type template
+// variables. Each one issues its own option-loading query when the dashboard
+// opens (and again whenever a variable it depends on changes), so a long list
+// of variables delays every panel even when their cross-product is small (D3).
+type TooManyQueryVariables struct {
+	// MaxVariables is the number of query variables tolerated. Defaults to
+	// 10 if zero.
+	MaxVariables int
+}
+
+func (r *TooManyQueryVariables) ID() string            { return "D22" }
+func (r *TooManyQueryVariables) RuleSeverity() Severity { return Medium }
+
+func (r *TooManyQueryVariables) Describe() Description {
+	return Description{
+		Title:       "Too many query variables",
+		Summary:     "Dashboards with more than 10 query variables.",
+		Rationale:   "Each variable issues its own option query on open, delaying every panel.",
+		Bad:         "12 query variables",
+		Good:        "a few query variables; the rest custom or constant",
+		AutoFixable: false,
+	}
+}
+
+func (r *TooManyQueryVariables) maxVariables() int {
+	if r.MaxVariables > 0 {
+		return r.MaxVariables
+	}
+	return 10
+}
+
+func (r *TooManyQueryVariables) Check(ctx *AnalysisContext) []Finding {
+	count := 0
+	for _, v := range ctx.Variables {
+		if v.Type == "query" {
+			count++
+		}
+	}
+	thresh := r.maxVariables()
+	if count <= thresh {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D22",
+			Severity:    Medium,
+			Title:       "Too many query variables",
+			Why:         fmt.Sprintf("Dashboard defines %d query variables (threshold: %d). Each one runs an option-loading query when the dashboard opens, and chained variables reload in sequence, delaying every panel.", count, thresh),
+			Fix:         "Remove unused variables, replace rarely changed ones with constant or custom variables, or split the dashboard so each view needs fewer filters.",
+			Impact:      fmt.Sprintf("Removes up to %d variable queries from dashboard load", count-thresh),
+			Validate:    "Browser DevTools → Network tab → count variable option requests issued before the first panel query",
+			AutoFixable: false,
+			Confidence:  0.7,
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// comparisonTitleHints are lower-case title fragments suggesting a panel is a
+// deliberate period-over-period comparison, where a time override is the
+// point of the panel.
+var comparisonTitleHints = []string{"compar", " vs ", " vs.", "versus", " ago", "previous", "yesterday", "last week", "week over week", "day over day"}
+
+// PanelTimeOverride detects panels that override the dashboard time range
+// with timeFrom or timeShift. Side-by-side panels then cover different
+// windows, which is easy to misread, and each override is a separate range
+// the datasource cannot serve from the other panels' cached results.
+type PanelTimeOverride struct{}
+
+func (r *PanelTimeOverride) ID() string            { return "D23" }
+func (r *PanelTimeOverride) RuleSeverity() Severity { return Low }
+
+func (r *PanelTimeOverride) Describe() Description {
+	return Description{
+		Title:       "Panel overrides the dashboard time range",
+		Summary:     "Panels setting timeFrom or timeShift.",
+		Rationale:   "Side-by-side panels then cover different windows and cannot share cached results.",
+		Bad:         `"timeFrom": "7d" on one panel of a 1h dashboard`,
+		Good:        "no override; a separate dashboard or row for the weekly view",
+		AutoFixable: false,
+	}
+}
+
+func (r *PanelTimeOverride) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if len(panel.Targets) == 0 || (panel.TimeFrom == "" && panel.TimeShift == "") {
+			continue
+		}
+		if isComparisonTitle(panel.Title) {
+			continue
+		}
+
+		var overrides []string
+		if panel.TimeFrom != "" {
+			overrides = append(overrides, fmt.Sprintf("timeFrom %q", panel.TimeFrom))
+		}
+		if panel.TimeShift != "" {
+			overrides = append(overrides, fmt.Sprintf("timeShift %q", panel.TimeShift))
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      "D23",
+			Severity:    Low,
+			PanelIDs:    []int{panel.ID},
+			PanelTitles: []string{panel.Title},
+			Title:       "Panel overrides the dashboard time range",
+			Why:         fmt.Sprintf("Panel sets %s, so it shows a different window than the panels around it and issues queries over a range no other panel shares.", strings.Join(overrides, " and ")),
+			Fix:         "Remove the override so the panel follows the dashboard time picker, or make the comparison explicit in the title (e.g. \"Requests vs 1 week ago\").",
+			Impact:      "Consistent windows across panels and one fewer distinct query range",
+			Validate:    "Panel editor → Query options → check Relative time and Time shift are empty",
+			AutoFixable: false,
+			Confidence:  0.5,
+		})
+	}
+	return findings
+}
+
+// isComparisonTitle is a best-effort check for panels whose title says they
+// compare periods, such as "Requests vs last week".
+func isComparisonTitle(title string) bool {
+	t := " " + strings.ToLower(title) + " "
+	for _, hint := range comparisonTitleHints {
+		if strings.Contains(t, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ManualLabelRepeat detects panels with 3 or more targets that are the same
+// query except for the value of one label matcher, e.g. one target each for
+// instance="instance-0", instance="instance-1" and instance="instance-2".
+// That is a hand-written repeat: it issues one query per value, goes stale
+// when the set of values changes, and is what a multi-value variable or a
+// panel repeat does for free.
+type ManualLabelRepeat struct{}
+
+func (r *ManualLabelRepeat) ID() string            { return "D24" }
+func (r *ManualLabelRepeat) RuleSeverity() Severity { return Medium }
+
+func (r *ManualLabelRepeat) Describe() Description {
+	return Description{
+		Title:       "Targets repeat a query per label value",
+		Summary:     "Panels with three or more targets differing only in one label value.",
+		Rationale:   "One query per value goes stale when values change; a variable or one grouped query does it for free.",
+		Bad:         `up{instance="a"}, up{instance="b"}, up{instance="c"}`,
+		Good:        `up{instance=~"$instance"} with a multi-value variable`,
+		AutoFixable: false,
+	}
+}
+
+func (r *ManualLabelRepeat) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		// Group targets by their query with equality matcher values blanked.
+		groups := make(map[string][][]matcherValue)
+		var order []string
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok || target.Hide {
+				continue
+			}
+			skeleton, values := equalityMatcherSkeleton(expr)
+			if len(values) == 0 {
+				continue
+			}
+			if _, seen := groups[skeleton]; !seen {
+				order = append(order, skeleton)
+			}
+			groups[skeleton] = append(groups[skeleton], values)
+		}
+
+		for _, skeleton := range order {
+			label, distinct := singleVaryingLabel(groups[skeleton])
+			if label == "" || len(distinct) < 3 {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "D24",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				Title:       "Targets repeat a query per label value",
+				Why:         fmt.Sprintf("%d targets run the same query and differ only in %s (%s). Each value is a separate query, and the list must be edited by hand when values change.", len(groups[skeleton]), label, strings.Join(distinct, ", ")),
+				Fix:         fmt.Sprintf("Replace the targets with one query using a multi-value variable (%s=~\"$%s\"), or one query grouped by %s, or repeat the panel over $%s.", label, label, label, label),
+				Impact:      fmt.Sprintf("%d queries become one, and new %s values appear without editing the dashboard", len(groups[skeleton]), label),
+				Validate:    "Query Inspector → check the panel issues a single query and still shows every series",
+				AutoFixable: false,
+				Confidence:  0.75,
+			})
+		}
+	}
+	return findings
+}
+
+// matcherValue is one equality matcher found while building a skeleton.
+type matcherValue struct {
+	name, value string
+}
+
+// equalityMatcherSkeleton returns expr's text with the values of all
+// non-__name__ equality matchers blanked, plus those matchers in the order
+// they appear. expr itself is left untouched; the skeleton is built from a
+// re-parsed copy.
+func equalityMatcherSkeleton(expr parser.Expr) (string, []matcherValue) {
+	clone, err := parser.ParseExpr(expr.String())
+	if err != nil {
+		return "", nil
+	}
+	var values []matcherValue
+	parser.Inspect(clone, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		for i, m := range vs.LabelMatchers {
+			if m.Name == "__name__" || m.Type != labels.MatchEqual {
+				continue
+			}
+			values = append(values, matcherValue{m.Name, m.Value})
+			vs.LabelMatchers[i] = &labels.Matcher{Type: m.Type, Name: m.Name, Value: ""}
+		}
+		return nil
+	})
+	return clone.String(), values
+}
+
+// singleVaryingLabel compares the matcher lists of targets sharing a
+// skeleton. If every position whose value differs between targets is the
+// same label, it returns that label and the sorted distinct values it
+// takes. Otherwise it returns "".
+func singleVaryingLabel(targets [][]matcherValue) (string, []string) {
+	if len(targets) < 2 {
+		return "", nil
+	}
+	label := ""
+	var varying []int
+	for i, mv := range targets[0] {
+		if allEqualAt(targets, i) {
+			continue
+		}
+		if label != "" && mv.name != label {
+			return "", nil
+		}
+		label = mv.name
+		varying = append(varying, i)
+	}
+	if label == "" {
+		return "", nil
+	}
+
+	seen := make(map[string]bool)
+	for _, values := range targets {
+		// A target must use one value throughout, e.g. both sides of a ratio.
+		for _, i := range varying[1:] {
+			if values[i].value != values[varying[0]].value {
+				return "", nil
+			}
+		}
+		seen[values[varying[0]].value] = true
+	}
+	distinct := make([]string, 0, len(seen))
+	for v := range seen {
+		distinct = append(distinct, fmt.Sprintf("%q", v))
+	}
+	sort.Strings(distinct)
+	return label, distinct
+}
+
+// allEqualAt reports whether every target has the same value at position i.
+func allEqualAt(targets [][]matcherValue, i int) bool {
+	for _, values := range targets[1:] {
+		if values[i].value != targets[0][i].value {
+			return false
+		}
+	}
+	return true
+}
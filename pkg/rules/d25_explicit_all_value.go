@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// allSentinelRe matches Grafana's internal All value written out by hand.
+var allSentinelRe = regexp.MustCompile(`\$(?:__all\b|\{__all\})`)
+
+// ExplicitAllValue detects targets that spell out $__all, Grafana's internal
+// marker for the All option. Authors use it to special-case All by hand, but
+// it expands as if All were always selected, so the matcher ignores the
+// user's selection and matches everything. All should be handled by the
+// variable itself: includeAll, with a Custom all value only where the
+// expanded value list is too long (see D26 for why .* is a poor choice).
+type ExplicitAllValue struct{}
+
+func (r *ExplicitAllValue) ID() string            { return "D25" }
+func (r *ExplicitAllValue) RuleSeverity() Severity { return Medium }
+
+func (r *ExplicitAllValue) Describe() Description {
+	return Description{
+		Title:       "Query hardcodes $__all",
+		Summary:     "Targets that write Grafana's $__all marker directly.",
+		Rationale:   "It expands as if All were always selected, ignoring the user's selection.",
+		Bad:         `up{namespace=~"$__all"}`,
+		Good:        `up{namespace=~"$namespace"} with includeAll on $namespace`,
+		AutoFixable: false,
+	}
+}
+
+func (r *ExplicitAllValue) Check(ctx *AnalysisContext) []Finding {
+	// Variables offering All without a custom allValue expand to the full
+	// value list; those are the ones to configure.
+	var noAllValue []string
+	for _, v := range ctx.Variables {
+		if v.IncludeAll && v.AllValue == "" {
+			noAllValue = append(noAllValue, v.Name)
+		}
+	}
+	hint := "No variable sets an allValue."
+	if len(noAllValue) > 0 {
+		hint = fmt.Sprintf("Variables offering All without an allValue: %s.", strings.Join(noAllValue, ", "))
+	}
+
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if !allSentinelRe.MatchString(target.Expr) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "D25",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Query hardcodes $__all",
+				Why:         fmt.Sprintf("The query %s uses $__all, Grafana's internal All marker, instead of a variable. It expands as if All were always selected, so the matcher ignores the user's selection and matches every series. %s", truncateQuery(target.Expr, 80), hint),
+				Fix:         "Reference the variable itself (label=~\"$var\") and enable Include All on the variable, so Grafana substitutes the listed values (or a narrow Custom all value) when All is selected.",
+				Impact:      "All is handled once in the variable instead of ad hoc in each query, and the matcher follows the selection",
+				Validate:    "Select All and a single value in turn; Query Inspector should show the allValue and the chosen value in the matcher",
+				AutoFixable: false,
+				Confidence:  0.8,
+			})
+		}
+	}
+	return findings
+}
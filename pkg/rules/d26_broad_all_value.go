@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BroadAllValue detects variables whose Custom all value is .* (or .+) and
+// that are used inside a =~ matcher. Selecting All then turns the matcher
+// into label=~".*", which matches every value of the label, including
+// values the variable's own query would never list, so the panel scans
+// every series of the metric.
+type BroadAllValue struct{}
+
+func (r *BroadAllValue) ID() string            { return "D26" }
+func (r *BroadAllValue) RuleSeverity() Severity { return Medium }
+
+func (r *BroadAllValue) Describe() Description {
+	return Description{
+		Title:       "All value .* used in a regex matcher",
+		Summary:     "Variables with allValue .* used inside =~ matchers.",
+		Rationale:   `Selecting All turns the matcher into label=~".*", scanning every series of the metric, even values the variable never lists.`,
+		Bad:         `"allValue": ".*" with up{namespace=~"$namespace"}`,
+		Good:        "no allValue, so All expands to the listed values",
+		AutoFixable: false,
+	}
+}
+
+func (r *BroadAllValue) Check(ctx *AnalysisContext) []Finding {
+	matchers := make(map[string]*regexp.Regexp)
+	for _, v := range ctx.Variables {
+		if !isMatchAllValue(v.AllValue) {
+			continue
+		}
+		// The reference must sit inside the quoted value of a =~ matcher.
+		ref := variableRefPattern(v.Name).String()
+		matchers[v.Name] = regexp.MustCompile(`=~\s*"[^"]*(?:` + ref + `)`)
+	}
+	if len(matchers) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(matchers))
+	for name := range matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			var used []string
+			for _, name := range names {
+				if matchers[name].MatchString(target.Expr) {
+					used = append(used, "$"+name)
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+			vars := strings.Join(used, ", ")
+			findings = append(findings, Finding{
+				RuleID:      "D26",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "All value .* used in a regex matcher",
+				Why:         fmt.Sprintf("The query %s uses %s in a =~ matcher, and the variable's all value is .*. Selecting All makes the matcher match every value of the label, not just the ones the variable lists, so the query scans every series of the metric.", truncateQuery(target.Expr, 80), vars),
+				Fix:         fmt.Sprintf("Clear the Custom all value of %s so All expands to the listed values, or set it to a narrower pattern (e.g. a namespace prefix). If the matcher is the query's only filter, add another label matcher such as job.", vars),
+				Impact:      "Selecting All only scans the series the variable actually offers",
+				Validate:    "Select All and compare the series count in Query Inspector before and after the change",
+				AutoFixable: false,
+				Confidence:  0.7,
+			})
+		}
+	}
+	return findings
+}
+
+// isMatchAllValue reports whether a variable's allValue matches every label
+// value when substituted into a regex matcher.
+func isMatchAllValue(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == ".*" || v == ".+"
+}
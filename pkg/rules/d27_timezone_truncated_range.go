@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// truncatedRangeRe matches relative time bounds rounded to a calendar unit:
+// "now/d", "now-1d/d", "now/w", "now-1M/M".
+var truncatedRangeRe = regexp.MustCompile(`/[dwM]$`)
+
+// utcTimezones are dashboard timezone values without DST transitions.
+// "browser" and "" (Grafana's default, which follows the browser) are left
+// out of this rule's scope: viewers' zones differ, so nothing is pinned.
+var utcTimezones = map[string]bool{
+	"":        true,
+	"browser": true,
+	"utc":     true,
+	"etc/utc": true,
+	"gmt":     true,
+}
+
+// TimezoneTruncatedRange detects dashboards pinned to a named timezone whose
+// default time range is rounded to a day, week or month ("now/d"). In a zone
+// with daylight saving time, calendar units around a DST change are 23 or 25
+// hours long, so "today" or "this week" covers more (or less) data than the
+// same range the rest of the year. It is an edge case, hence Low.
+type TimezoneTruncatedRange struct{}
+
+func (r *TimezoneTruncatedRange) ID() string            { return "D27" }
+func (r *TimezoneTruncatedRange) RuleSeverity() Severity { return Low }
+
+func (r *TimezoneTruncatedRange) Describe() Description {
+	return Description{
+		Title:       "Calendar-rounded range in a DST timezone",
+		Summary:     "now/d, now/w or now/M default ranges on dashboards pinned to a non-UTC timezone.",
+		Rationale:   "Around a daylight saving change those units are 23 or 25 hours, so the range reads a different amount of data.",
+		Bad:         `"timezone": "Europe/Berlin", "time": {"from": "now/d", "to": "now/d"}`,
+		Good:        `"timezone": "Europe/Berlin", "time": {"from": "now-24h", "to": "now"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *TimezoneTruncatedRange) Check(ctx *AnalysisContext) []Finding {
+	tz := ctx.Dashboard.Timezone
+	if utcTimezones[strings.ToLower(tz)] {
+		return nil
+	}
+	var truncated []string
+	for _, bound := range []string{ctx.Dashboard.Time.From, ctx.Dashboard.Time.To} {
+		if truncatedRangeRe.MatchString(bound) {
+			truncated = append(truncated, bound)
+		}
+	}
+	if len(truncated) == 0 {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D27",
+			Severity:    Low,
+			Title:       "Calendar-rounded range in a DST timezone",
+			Why:         fmt.Sprintf("The default range uses %s, rounded to calendar units in timezone %q. Across a daylight saving change those units are an hour longer or shorter, so the dashboard queries a different amount of data than intended.", strings.Join(truncated, " to "), tz),
+			Fix:         "Use a rolling range (e.g. \"now-24h\" to \"now\"), or set the dashboard timezone to UTC if calendar-aligned ranges are required.",
+			Impact:      "Consistent query ranges year-round; no extra hour of data around DST changes",
+			Validate:    "Dashboard settings → General → check Timezone and the default time range",
+			AutoFixable: false,
+			Confidence:  0.3,
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HiddenDrillDownTargets detects panels that carry more than a few targets
+// of which only one is displayed: the rest are hidden (hide: true) queries
+// kept around for tooltips, data links or drill-downs. Each of them is part
+// of the panel's request on every refresh. Panels whose hidden targets feed
+// a server-side expression are skipped: that is the intended use of hidden
+// inputs.
+type HiddenDrillDownTargets struct {
+	// MaxTargets is the number of targets a panel may have before a
+	// single-visible-series layout is reported. Defaults to 3 if zero.
+	MaxTargets int
+}
+
+func (r *HiddenDrillDownTargets) ID() string            { return "D28" }
+func (r *HiddenDrillDownTargets) RuleSeverity() Severity { return Medium }
+
+func (r *HiddenDrillDownTargets) Describe() Description {
+	return Description{
+		Title:       "Panel with hidden drill-down targets",
+		Summary:     "Panels with more than 3 targets where all but one are hidden.",
+		Rationale:   "Hidden targets kept for tooltips or data links are still part of the panel's request on every refresh.",
+		Bad:         "targets A (visible), B, C, D (hide: true)",
+		Good:        "target A only; drill-down queries moved to a linked dashboard or Explore",
+		AutoFixable: false,
+	}
+}
+
+func (r *HiddenDrillDownTargets) maxTargets() int {
+	if r.MaxTargets > 0 {
+		return r.MaxTargets
+	}
+	return 3
+}
+
+func (r *HiddenDrillDownTargets) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if len(panel.Targets) <= r.maxTargets() {
+			continue
+		}
+		var hidden []string
+		expressionInput := false
+		for _, t := range panel.Targets {
+			if !t.Hide {
+				continue
+			}
+			if referencedByExpression(panel, t.RefID) {
+				expressionInput = true
+				break
+			}
+			hidden = append(hidden, t.RefID)
+		}
+		if expressionInput || len(hidden) != len(panel.Targets)-1 {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D28",
+			Severity:    Medium,
+			PanelIDs:    []int{panel.ID},
+			PanelTitles: []string{panel.Title},
+			Title:       "Panel with hidden drill-down targets",
+			Why:         fmt.Sprintf("Panel %q has %d targets but displays only one; %s are hidden. Hidden queries kept for tooltips, data links or drill-downs are still part of the panel's request on every refresh.", panel.Title, len(panel.Targets), strings.Join(hidden, ", ")),
+			Fix:         "Consolidate the panel to the displayed query. Move drill-down queries to a linked dashboard or Explore, and compute tooltip values from the displayed series with transformations.",
+			Impact:      fmt.Sprintf("Cuts the panel's queries per refresh from %d to 1", len(panel.Targets)),
+			Validate:    "Open the panel's Query inspector → verify only the displayed target is sent",
+			AutoFixable: false,
+			Confidence:  0.6,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// gridColumns is the width of Grafana's dashboard grid in columns.
+const gridColumns = 24
+
+// OversizedMaxDataPoints detects panels whose maxDataPoints is far above the
+// number of pixels the panel has to draw them. The panel's width is
+// estimated from gridPos.w on an assumed dashboard width; points beyond a
+// few per pixel are fetched and transferred but cannot be seen.
+type OversizedMaxDataPoints struct {
+	// DashboardWidth is the assumed rendered width of the dashboard in
+	// pixels, split evenly over the 24 grid columns. Defaults to 1920 if
+	// zero.
+	DashboardWidth int
+	// MaxPointsPerPixel is the ratio of maxDataPoints to estimated panel
+	// pixels above which a panel is reported. Defaults to 4 if zero.
+	MaxPointsPerPixel float64
+}
+
+func (r *OversizedMaxDataPoints) ID() string            { return "D29" }
+func (r *OversizedMaxDataPoints) RuleSeverity() Severity { return Low }
+
+func (r *OversizedMaxDataPoints) Describe() Description {
+	return Description{
+		Title:       "maxDataPoints far above panel width",
+		Summary:     "Panels whose maxDataPoints is more than 4x their estimated pixel width (from gridPos.w on a 1920px dashboard).",
+		Rationale:   "Points beyond a few per pixel are queried and transferred but cannot be drawn.",
+		Bad:         `{"gridPos": {"w": 6}, "maxDataPoints": 10000}`,
+		Good:        `{"gridPos": {"w": 6}, "maxDataPoints": 500}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *OversizedMaxDataPoints) dashboardWidth() int {
+	if r.DashboardWidth > 0 {
+		return r.DashboardWidth
+	}
+	return 1920
+}
+
+func (r *OversizedMaxDataPoints) maxPointsPerPixel() float64 {
+	if r.MaxPointsPerPixel > 0 {
+		return r.MaxPointsPerPixel
+	}
+	return 4
+}
+
+func (r *OversizedMaxDataPoints) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, p := range extractor.AllPanels(ctx.Dashboard) {
+		if p.Type == "row" || p.MaxDataPoints == nil || *p.MaxDataPoints <= 0 {
+			continue
+		}
+		grid, ok := p.Grid()
+		if !ok || grid.W <= 0 {
+			continue
+		}
+		pixels := grid.W * r.dashboardWidth() / gridColumns
+		limit := int(float64(pixels) * r.maxPointsPerPixel())
+		if *p.MaxDataPoints <= limit {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D29",
+			Severity:    Low,
+			PanelIDs:    []int{p.ID},
+			PanelTitles: []string{p.Title},
+			Title:       "maxDataPoints far above panel width",
+			Why:         fmt.Sprintf("Panel %q sets maxDataPoints to %d but is %d of %d grid columns wide, about %dpx on a %dpx dashboard. Points beyond %.0f per pixel are fetched but cannot be drawn.", p.Title, *p.MaxDataPoints, grid.W, gridColumns, pixels, r.dashboardWidth(), r.maxPointsPerPixel()),
+			Fix:         fmt.Sprintf("Lower maxDataPoints to about the panel's pixel width, e.g. %d, or remove it to let Grafana use the panel width.", pixels),
+			Impact:      fmt.Sprintf("Cuts the points returned per series by up to %.0fx at wide time ranges", float64(*p.MaxDataPoints)/float64(pixels)),
+			Validate:    "Query Inspector → Data tab → compare the number of points per series before/after",
+			AutoFixable: false,
+			Confidence:  0.6,
+		})
+	}
+	return findings
+}
@@ -15,6 +15,17 @@ type RepeatWithAll struct{}
 func (r *RepeatWithAll) ID() string            { return "D2" }
 func (r *RepeatWithAll) RuleSeverity() Severity { return Critical }
 
+func (r *RepeatWithAll) Describe() Description {
+	return Description{
+		Title:       "Repeat panel uses variable with Include All",
+		Summary:     "Repeated panels whose variable has Include All enabled.",
+		Rationale:   "Selecting All instantiates one panel copy per value, which can be hundreds of panels each firing queries.",
+		Bad:         `"repeat": "pod" with $pod includeAll: true`,
+		Good:        `"repeat": "pod" with $pod multi-select and includeAll: false`,
+		AutoFixable: false,
+	}
+}
+
 func (r *RepeatWithAll) Check(ctx *AnalysisContext) []Finding {
 	// Build a lookup of variables by name.
 	varByName := make(map[string]*extractor.VariableModel, len(ctx.Variables))
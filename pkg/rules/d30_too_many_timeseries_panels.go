@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// heavyVizTypes are the panel types that render one line per series over the
+// whole time range, the most expensive panels to query and draw.
+var heavyVizTypes = map[string]bool{
+	"timeseries": true,
+	"graph":      true,
+}
+
+// TooManyTimeseriesPanels detects dashboards with more than a threshold
+// number of visible timeseries/graph panels. It overlaps with D1 but only
+// counts the heavy visualizations, so a dashboard of mostly stat panels can
+// pass D1 and still be reported here, and vice versa.
+type TooManyTimeseriesPanels struct {
+	// MaxPanels is the max number of visible timeseries/graph panels before
+	// flagging. Defaults to 20 if zero.
+	MaxPanels int
+}
+
+func (r *TooManyTimeseriesPanels) ID() string            { return "D30" }
+func (r *TooManyTimeseriesPanels) RuleSeverity() Severity { return Medium }
+
+func (r *TooManyTimeseriesPanels) Describe() Description {
+	return Description{
+		Title:       "Too many visible timeseries panels",
+		Summary:     "Dashboards with more than 20 visible timeseries/graph panels.",
+		Rationale:   "Timeseries panels query and draw every series over the whole range, so many of them on one screen make the dashboard slow to load and to render.",
+		Bad:         "30 timeseries panels at the top level",
+		Good:        "an overview dashboard with links to per-service dashboards, or details in collapsed rows",
+		AutoFixable: false,
+	}
+}
+
+func (r *TooManyTimeseriesPanels) maxPanels() int {
+	if r.MaxPanels > 0 {
+		return r.MaxPanels
+	}
+	return 20
+}
+
+func (r *TooManyTimeseriesPanels) Check(ctx *AnalysisContext) []Finding {
+	count := 0
+	for _, p := range extractor.VisiblePanels(ctx.Dashboard) {
+		if heavyVizTypes[p.Type] {
+			count++
+		}
+	}
+	thresh := r.maxPanels()
+
+	if count <= thresh {
+		return nil
+	}
+
+	return []Finding{
+		{
+			RuleID:      "D30",
+			Severity:    Medium,
+			Title:       "Too many visible timeseries panels",
+			Why:         fmt.Sprintf("Dashboard has %d visible timeseries/graph panels (threshold: %d). Each one queries and draws every series over the full time range on load.", count, thresh),
+			Fix:         "Split the dashboard into focused dashboards linked from an overview, or move detail graphs into collapsed rows. Replace graphs that only show a current value with stat panels.",
+			Impact:      fmt.Sprintf("Reducing from %d to ≤%d timeseries panels cuts the range queries and rendering on load proportionally", count, thresh),
+			Validate:    "Reload dashboard → check browser DevTools Network tab for query count and Performance tab for render time",
+			AutoFixable: false,
+			Confidence:  0.8,
+		},
+	}
+}
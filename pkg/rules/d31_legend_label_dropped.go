@@ -0,0 +1,226 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// legendLabelRe matches a {{label}} reference in a legendFormat.
+var legendLabelRe = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// LegendLabelDropped detects targets whose legendFormat references a label
+// that the query's aggregation does not keep, e.g. legend {{job}} on
+// sum(rate(x[5m])). The label is empty on every result series, so the
+// legend shows nothing useful, and the author most likely meant a
+// per-label breakdown (sum by (job)). The labels a query keeps are worked
+// out from the AST; queries whose labels cannot be known statically
+// (label_replace, or, a templated grouping label) are skipped.
+type LegendLabelDropped struct{}
+
+func (r *LegendLabelDropped) ID() string            { return "D31" }
+func (r *LegendLabelDropped) RuleSeverity() Severity { return Low }
+
+func (r *LegendLabelDropped) Describe() Description {
+	return Description{
+		Title:       "Legend references a label the query aggregates away",
+		Summary:     "Targets whose legendFormat uses {{label}} for a label not kept by the query's aggregation.",
+		Rationale:   "The label is empty on every result series, so the legend is blank or identical for every series.",
+		Bad:         `legendFormat "{{job}}" on sum(rate(http_requests_total{job=~"api|web"}[5m]))`,
+		Good:        `legendFormat "{{job}}" on sum by (job) (rate(http_requests_total{job=~"api|web"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *LegendLabelDropped) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if target.LegendFormat == "" {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			out, known := resultLabels(expr)
+			if !known {
+				continue
+			}
+			var missing, refs []string
+			seen := make(map[string]bool)
+			for _, m := range legendLabelRe.FindAllStringSubmatch(target.LegendFormat, -1) {
+				label := m[1]
+				if label == "__name__" || seen[label] || out.has(label) {
+					continue
+				}
+				seen[label] = true
+				missing = append(missing, label)
+				refs = append(refs, "{{"+label+"}}")
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "D31",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Legend references a label the query aggregates away",
+				Why:         fmt.Sprintf("The legend %q uses %s, but the query's aggregation does not keep %s. Missing labels render empty on every result series, so the legend shows nothing useful.", target.LegendFormat, strings.Join(refs, ", "), strings.Join(missing, ", ")),
+				Fix:         fmt.Sprintf("If a per-%s breakdown was intended, add it to the aggregation, e.g. sum by (%s) (...). Otherwise remove it from the legend.", missing[0], strings.Join(missing, ", ")),
+				Impact:      "A legend that tells the series apart",
+				Validate:    "Open the panel → verify each series has a distinct, non-empty legend",
+				AutoFixable: false,
+				Confidence:  0.8,
+			})
+		}
+	}
+	return findings
+}
+
+// labelSet describes the labels an expression's result series carry. When
+// all is true every input label survives except those in dropped;
+// otherwise only those in kept do.
+type labelSet struct {
+	all     bool
+	kept    map[string]bool
+	dropped map[string]bool
+}
+
+func (s labelSet) has(label string) bool {
+	if s.all {
+		return !s.dropped[label]
+	}
+	return s.kept[label]
+}
+
+// only returns the labels of s that are also in labels, as by (...) keeps.
+func (s labelSet) only(labels []string) labelSet {
+	out := labelSet{kept: map[string]bool{}}
+	for _, l := range labels {
+		if s.has(l) {
+			out.kept[l] = true
+		}
+	}
+	return out
+}
+
+// without returns s minus labels, as without (...) keeps.
+func (s labelSet) without(labels []string) labelSet {
+	out := s.clone()
+	for _, l := range labels {
+		delete(out.kept, l)
+		if out.all {
+			out.dropped[l] = true
+		}
+	}
+	return out
+}
+
+// with returns s plus labels, as group_left(...) copies them in.
+func (s labelSet) with(labels []string) labelSet {
+	out := s.clone()
+	for _, l := range labels {
+		if out.all {
+			delete(out.dropped, l)
+		} else {
+			out.kept[l] = true
+		}
+	}
+	return out
+}
+
+func (s labelSet) clone() labelSet {
+	out := labelSet{all: s.all, kept: map[string]bool{}, dropped: map[string]bool{}}
+	for l := range s.kept {
+		out.kept[l] = true
+	}
+	for l := range s.dropped {
+		out.dropped[l] = true
+	}
+	return out
+}
+
+// resultLabels works out which labels the result series of expr carry.
+// known is false when that depends on data or on something the AST does
+// not show: label_replace/label_join, or, and groupings containing a
+// template variable.
+func resultLabels(expr parser.Expr) (labels labelSet, known bool) {
+	switch n := expr.(type) {
+	case *parser.ParenExpr:
+		return resultLabels(n.Expr)
+	case *parser.StepInvariantExpr:
+		return resultLabels(n.Expr)
+	case *parser.VectorSelector, *parser.MatrixSelector, *parser.SubqueryExpr:
+		return labelSet{all: true}, true
+	case *parser.NumberLiteral, *parser.StringLiteral:
+		return labelSet{}, true
+	case *parser.AggregateExpr:
+		for _, g := range n.Grouping {
+			if g == "placeholder" {
+				return labelSet{}, false
+			}
+		}
+		inner, ok := resultLabels(n.Expr)
+		if !ok {
+			return labelSet{}, false
+		}
+		switch n.Op {
+		case parser.TOPK, parser.BOTTOMK, parser.LIMITK, parser.LIMIT_RATIO:
+			// These select series rather than combining them.
+			return inner, true
+		case parser.COUNT_VALUES:
+			return labelSet{}, false
+		}
+		if n.Without {
+			return inner.without(n.Grouping), true
+		}
+		return inner.only(n.Grouping), true
+	case *parser.Call:
+		switch n.Func.Name {
+		case "label_replace", "label_join", "absent", "absent_over_time", "vector", "scalar", "time":
+			return labelSet{}, false
+		}
+		for _, arg := range n.Args {
+			if t := arg.Type(); t == parser.ValueTypeVector || t == parser.ValueTypeMatrix {
+				return resultLabels(arg)
+			}
+		}
+		return labelSet{}, false
+	case *parser.BinaryExpr:
+		lhs, rhs := n.LHS.Type() == parser.ValueTypeVector, n.RHS.Type() == parser.ValueTypeVector
+		switch {
+		case lhs && !rhs:
+			return resultLabels(n.LHS)
+		case rhs && !lhs:
+			return resultLabels(n.RHS)
+		case !lhs && !rhs:
+			return labelSet{}, false
+		}
+		if n.Op == parser.LOR || n.VectorMatching == nil {
+			return labelSet{}, false
+		}
+		side := n.LHS
+		if n.VectorMatching.Card == parser.CardOneToMany {
+			side = n.RHS
+		}
+		out, ok := resultLabels(side)
+		if !ok {
+			return labelSet{}, false
+		}
+		switch {
+		case n.VectorMatching.Card == parser.CardOneToOne && n.VectorMatching.On:
+			// One-to-one matching keeps only the on() labels, or drops
+			// the ignoring() ones.
+			return out.only(n.VectorMatching.MatchingLabels), true
+		case n.VectorMatching.Card == parser.CardOneToOne:
+			return out.without(n.VectorMatching.MatchingLabels), true
+		}
+		return out.with(n.VectorMatching.Include), true
+	}
+	return labelSet{}, false
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// HardcodedDatasource detects panels that pin a concrete datasource UID on
+// a dashboard that defines a datasource variable. The variable exists so the
+// dashboard can be pointed at another Prometheus (another cluster, staging,
+// another org); panels that bypass it keep querying the original datasource,
+// or break when the dashboard is imported where that UID does not exist.
+// Panels of another datasource type than the variable's, and Grafana's
+// built-in pseudo datasources (mixed, dashboard, expressions), are skipped.
+type HardcodedDatasource struct{}
+
+func (r *HardcodedDatasource) ID() string            { return "D32" }
+func (r *HardcodedDatasource) RuleSeverity() Severity { return Low }
+
+func (r *HardcodedDatasource) Describe() Description {
+	return Description{
+		Title:       "Hardcoded datasource UID",
+		Summary:     "Panels with a concrete datasource UID on dashboards that define a datasource variable.",
+		Rationale:   "Such panels ignore the datasource picker and break when the dashboard is imported where that UID does not exist.",
+		Bad:         `"datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"}`,
+		Good:        `"datasource": {"type": "prometheus", "uid": "${datasource}"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *HardcodedDatasource) Check(ctx *AnalysisContext) []Finding {
+	var dsVars []extractor.VariableModel
+	for _, v := range ctx.Variables {
+		if v.Type == "datasource" {
+			dsVars = append(dsVars, v)
+		}
+	}
+	if len(dsVars) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, p := range extractor.AllPanels(ctx.Dashboard) {
+		if p.Type == "row" || p.Datasource == nil || !isConcreteDatasource(p.Datasource) {
+			continue
+		}
+		v, ok := datasourceVariableFor(dsVars, p.Datasource.Type)
+		if !ok {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D32",
+			Severity:    Low,
+			PanelIDs:    []int{p.ID},
+			PanelTitles: []string{p.Title},
+			Title:       "Hardcoded datasource UID",
+			Why:         fmt.Sprintf("Panel %q uses datasource UID %q although the dashboard defines the datasource variable $%s. The panel ignores the variable, and breaks when the dashboard is imported where that UID does not exist.", p.Title, p.Datasource.UID, v.Name),
+			Fix:         fmt.Sprintf("Set the panel datasource to the variable: {\"type\": %q, \"uid\": \"${%s}\"}, and do the same for its targets.", p.Datasource.Type, v.Name),
+			Impact:      "The panel follows the datasource picker and the dashboard stays portable between Grafana instances",
+			Validate:    "Switch the datasource variable → verify the panel's queries go to the selected datasource",
+			AutoFixable: false,
+			Confidence:  0.8,
+		})
+	}
+	return findings
+}
+
+// isConcreteDatasource reports whether ds names a specific datasource by
+// UID: not a variable reference and not one of Grafana's built-in pseudo
+// datasources (-- Mixed --, -- Dashboard --, -- Grafana --, expressions).
+func isConcreteDatasource(ds *extractor.DatasourceRef) bool {
+	switch {
+	case ds.UID == "", strings.HasPrefix(ds.UID, "$"), strings.HasPrefix(ds.UID, "--"):
+		return false
+	case ds.Type == "datasource", ds.Type == "grafana", ds.Type == "__expr__":
+		return false
+	}
+	return true
+}
+
+// datasourceVariableFor returns the datasource variable whose plugin type
+// (its query, e.g. "prometheus") is dsType. A panel without a type matches
+// the first variable.
+func datasourceVariableFor(vars []extractor.VariableModel, dsType string) (extractor.VariableModel, bool) {
+	for _, v := range vars {
+		if dsType == "" || v.QueryString() == dsType {
+			return v, true
+		}
+	}
+	return extractor.VariableModel{}, false
+}
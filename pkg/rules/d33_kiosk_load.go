@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// KioskLoad detects the "kiosk" anti-pattern: a dashboard with many panels,
+// a wide default range and sub-minute auto-refresh. That combination is
+// typical of TV and wall displays, which stay open all day, so every refresh
+// re-reads the whole range for every panel around the clock. D1, D5 and D6
+// may each fire on such a dashboard too; this rule reports the combination
+// as one finding with a remediation plan for all three.
+type KioskLoad struct {
+	// MinPanels is the visible panel count above which a dashboard counts
+	// as busy. Defaults to 15 if zero.
+	MinPanels int
+	// MinRange is the default time range at or above which a dashboard
+	// counts as wide. Defaults to 12h if zero.
+	MinRange time.Duration
+	// MaxRefresh is the refresh interval below which a dashboard counts as
+	// refreshing constantly. Defaults to 1m if zero.
+	MaxRefresh time.Duration
+}
+
+func (r *KioskLoad) ID() string            { return "D33" }
+func (r *KioskLoad) RuleSeverity() Severity { return High }
+
+func (r *KioskLoad) Describe() Description {
+	return Description{
+		Title:       "Kiosk dashboard refreshing a wide range",
+		Summary:     "Dashboards with more than 15 visible panels, a default range of 12h or more, and auto-refresh under 1m.",
+		Rationale:   "Wall displays stay open all day, so every refresh re-reads the whole range for every panel around the clock.",
+		Bad:         `30 panels, "time": {"from": "now-24h"}, "refresh": "10s"`,
+		Good:        `30 panels on recording rules, "time": {"from": "now-3h"}, "refresh": "1m"`,
+		AutoFixable: false,
+	}
+}
+
+func (r *KioskLoad) minPanels() int {
+	if r.MinPanels > 0 {
+		return r.MinPanels
+	}
+	return 15
+}
+
+func (r *KioskLoad) minRange() time.Duration {
+	if r.MinRange > 0 {
+		return r.MinRange
+	}
+	return 12 * time.Hour
+}
+
+func (r *KioskLoad) maxRefresh() time.Duration {
+	if r.MaxRefresh > 0 {
+		return r.MaxRefresh
+	}
+	return time.Minute
+}
+
+func (r *KioskLoad) Check(ctx *AnalysisContext) []Finding {
+	dash := ctx.Dashboard
+	if dash.Refresh == "" || dash.Time.From == "" {
+		return nil
+	}
+	refresh, err := parseGrafanaDuration(dash.Refresh)
+	if err != nil || refresh <= 0 || refresh >= r.maxRefresh() {
+		return nil
+	}
+	window, err := parseRelativeRange(dash.Time.From)
+	if err != nil || window < r.minRange() {
+		return nil
+	}
+	panels := len(extractor.VisiblePanels(dash))
+	if panels <= r.minPanels() {
+		return nil
+	}
+
+	perHour := panels * int(time.Hour/refresh)
+	return []Finding{
+		{
+			RuleID:      "D33",
+			Severity:    High,
+			Title:       "Kiosk dashboard refreshing a wide range",
+			Why:         fmt.Sprintf("Dashboard combines %d visible panels, a %q default range and a %s refresh. Left open on a display, it re-reads the last %s for every panel every %s: about %d panel refreshes per hour per screen.", panels, dash.Time.From, dash.Refresh, strings.TrimPrefix(dash.Time.From, "now-"), dash.Refresh, perHour),
+			Fix:         fmt.Sprintf("Raise the refresh to %s or more, narrow the default range to what the display needs to show (e.g. now-3h), and move the heaviest panels' queries to recording rules. For a wall display, consider a smaller kiosk copy with only the key panels.", r.maxRefresh()),
+			Impact:      fmt.Sprintf("Refreshing every %s instead of %s alone cuts the load by %.0f%%; narrowing the range reduces the data read per refresh proportionally", r.maxRefresh(), dash.Refresh, (1.0-float64(refresh)/float64(r.maxRefresh()))*100),
+			Validate:    "Leave the dashboard open for a few minutes → compare query rate in the Prometheus/Thanos query-frontend metrics before/after",
+			AutoFixable: false,
+			Confidence:  0.8,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+	"github.com/prometheus/common/model"
+)
+
+// FineIntervalVariable detects interval-type template variables whose
+// default is finer than MinInterval. Such a variable ($interval = 10s) is
+// usually the rate window or step of several panels at once, so a fine
+// default makes every one of them query at that resolution whenever the
+// dashboard opens. Variables no target refers to are ignored.
+type FineIntervalVariable struct {
+	// MinInterval is the smallest default interval tolerated. Defaults to
+	// 1m if zero.
+	MinInterval time.Duration
+}
+
+func (r *FineIntervalVariable) ID() string            { return "D34" }
+func (r *FineIntervalVariable) RuleSeverity() Severity { return Medium }
+
+func (r *FineIntervalVariable) Describe() Description {
+	return Description{
+		Title:       "Interval variable defaults too fine",
+		Summary:     "Interval variables used in queries whose default value is below 1m.",
+		Rationale:   "Every panel using the variable as its rate window or step queries at that resolution on open.",
+		Bad:         `"type": "interval", "query": "10s,1m,5m", "current": {"value": "10s"}`,
+		Good:        `"type": "interval", "query": "1m,5m,1h", "current": {"value": "1m"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *FineIntervalVariable) minInterval() time.Duration {
+	if r.MinInterval > 0 {
+		return r.MinInterval
+	}
+	return time.Minute
+}
+
+func (r *FineIntervalVariable) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for i := range ctx.Variables {
+		v := &ctx.Variables[i]
+		if v.Type != "interval" {
+			continue
+		}
+		def := intervalDefault(v)
+		// "auto" is derived from the time range, not a fixed resolution.
+		if def == "" || strings.HasPrefix(def, "$__auto_interval") {
+			continue
+		}
+		d, err := parseGrafanaDuration(def)
+		if err != nil || d <= 0 || d >= r.minInterval() {
+			continue
+		}
+
+		min := model.Duration(r.minInterval())
+		ref := variableRefPattern(v.Name)
+		var panelIDs []int
+		var panelTitles []string
+		for _, p := range extractor.AllPanels(ctx.Dashboard) {
+			for _, t := range p.Targets {
+				if ref.MatchString(t.Expr) {
+					panelIDs = append(panelIDs, p.ID)
+					panelTitles = append(panelTitles, p.Title)
+					break
+				}
+			}
+		}
+		if len(panelIDs) == 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			RuleID:      "D34",
+			Severity:    Medium,
+			PanelIDs:    panelIDs,
+			PanelTitles: panelTitles,
+			Title:       "Interval variable defaults too fine",
+			Why:         fmt.Sprintf("Interval variable $%s defaults to %s (threshold: %s) and is used by %d panel(s). Each of them queries at %s resolution whenever the dashboard opens, before anyone picks a coarser value.", v.Name, def, min, len(panelIDs), def),
+			Fix:         fmt.Sprintf("Set the default of $%s to %s or more, drop the sub-%s options, or enable its \"auto\" option so the interval follows the time range. For rate windows, $__rate_interval is usually the better choice.", v.Name, min, min),
+			Impact:      fmt.Sprintf("Raising the default from %s to %s returns up to %.0fx fewer points per series on every panel using $%s", def, min, float64(r.minInterval())/float64(d), v.Name),
+			Validate:    fmt.Sprintf("Query inspector → compare the step and data points of a panel using $%s before/after", v.Name),
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+	return findings
+}
+
+// intervalDefault returns the interval variable's value when the dashboard
+// opens: its saved selection, else the first entry of its comma-separated
+// query, which Grafana selects when nothing is saved.
+func intervalDefault(v *extractor.VariableModel) string {
+	if def := v.DefaultValue(); def != "" {
+		return def
+	}
+	first, _, _ := strings.Cut(v.QueryString(), ",")
+	return strings.TrimSpace(first)
+}
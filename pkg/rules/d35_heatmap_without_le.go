@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// HeatmapWithoutLe detects heatmap panels whose histogram bucket query
+// aggregates away the le label, e.g. sum(rate(x_bucket[5m])). A heatmap of
+// classic histogram buckets needs one series per le to draw its rows;
+// without it every bucket collapses into one series and the panel renders
+// nothing meaningful. Heatmaps that bucket raw values themselves
+// (options.calculate) and queries reducing buckets with
+// histogram_quantile are not bucket heatmaps and are skipped.
+type HeatmapWithoutLe struct{}
+
+func (r *HeatmapWithoutLe) ID() string            { return "D35" }
+func (r *HeatmapWithoutLe) RuleSeverity() Severity { return High }
+
+func (r *HeatmapWithoutLe) Describe() Description {
+	return Description{
+		Title:       "Heatmap query aggregates away le",
+		Summary:     "Heatmap panels whose _bucket query does not keep the le label.",
+		Rationale:   "Without le every bucket collapses into one series, so the heatmap has no rows to draw.",
+		Bad:         `heatmap: sum(rate(http_request_duration_seconds_bucket{job="api"}[$__rate_interval]))`,
+		Good:        `heatmap: sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[$__rate_interval]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *HeatmapWithoutLe) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if panel.Type != "heatmap" || panel.HeatmapCalculates() {
+			continue
+		}
+		for _, target := range panel.Targets {
+			if target.Hide {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			metric := bucketSelectorName(expr)
+			if metric == "" || callsFunction(expr, "histogram_quantile") {
+				continue
+			}
+			out, known := resultLabels(expr)
+			if !known || out.has("le") {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "D35",
+				Severity:    High,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Heatmap query aggregates away le",
+				Why:         fmt.Sprintf("Heatmap panel %q queries %s but its aggregation does not keep le. A bucket heatmap draws one row per le value; with le aggregated away every bucket is summed into a single series, so the heatmap is empty or one meaningless band.", panel.Title, metric),
+				Fix:         fmt.Sprintf("Keep le in the aggregation, e.g. sum by (le) (rate(%s{...}[$__rate_interval])), and set the target's format to \"Heatmap\".", metric),
+				Impact:      "A heatmap that shows the latency distribution instead of nothing",
+				Validate:    "Open the panel → verify one row per bucket boundary appears",
+				AutoFixable: false,
+				Confidence:  0.85,
+			})
+		}
+	}
+	return findings
+}
+
+// bucketSelectorName returns the name of the first classic histogram
+// bucket series (_bucket) selected in expr, or "" if there is none.
+func bucketSelectorName(expr parser.Expr) string {
+	var metric string
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if metric != "" || !ok {
+			return nil
+		}
+		if name := extractMetricName(vs); strings.HasSuffix(name, "_bucket") {
+			metric = name
+		}
+		return nil
+	})
+	return metric
+}
+
+// callsFunction reports whether expr calls the PromQL function name.
+func callsFunction(expr parser.Expr, name string) bool {
+	found := false
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if call, ok := node.(*parser.Call); ok && call.Func.Name == name {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HighCardinalityVariable detects label_values() variables that would
+// return more values than anyone can pick from, e.g.
+// label_values(up, instance) on a large fleet. The request is slow, the
+// dropdown unusable, and an All selection expands into a huge regex. The
+// value count is estimated from cardinality data: the label's distinct
+// values, capped by the series count of the metric queried. Only runs when
+// cardinality data is available; D4 covers variable query cost in general.
+type HighCardinalityVariable struct {
+	// MaxValues is the number of values tolerated. Defaults to 1000 if
+	// zero.
+	MaxValues int
+}
+
+func (r *HighCardinalityVariable) ID() string            { return "D36" }
+func (r *HighCardinalityVariable) RuleSeverity() Severity { return Medium }
+
+func (r *HighCardinalityVariable) Describe() Description {
+	return Description{
+		Title:       "Variable returns too many label values",
+		Summary:     "label_values() variables estimated to return more than 1000 values (needs cardinality data).",
+		Rationale:   "The lookup is slow on every load, the dropdown is unusable, and All expands into a huge regex.",
+		Bad:         `"query": "label_values(up, instance)" with 20000 instances`,
+		Good:        `"query": "label_values(up{job=\"$job\"}, instance)"`,
+		AutoFixable: false,
+	}
+}
+
+func (r *HighCardinalityVariable) maxValues() int {
+	if r.MaxValues > 0 {
+		return r.MaxValues
+	}
+	return 1000
+}
+
+func (r *HighCardinalityVariable) Check(ctx *AnalysisContext) []Finding {
+	if ctx.Cardinality == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, v := range ctx.Variables {
+		if v.Type != "query" {
+			continue
+		}
+		query := strings.TrimSpace(v.QueryString())
+		label := labelValuesLabel(query)
+		if label == "" {
+			continue
+		}
+		values := ctx.Cardinality.LabelCardinality(label, 0)
+		source := fmt.Sprintf("%q has %d distinct values", label, values)
+		metric, _, scoped := strings.Cut(labelValuesSelector(query), "{")
+		metric = strings.TrimSpace(metric)
+		if series := ctx.Cardinality.EstimatedSeries(metric, 0); metric != "" && series > 0 && series < values {
+			// A metric has at most one value of the label per series.
+			values = series
+			source = fmt.Sprintf("%s has %d series", metric, series)
+		}
+		if values <= r.maxValues() {
+			continue
+		}
+
+		// The estimate ignores matchers, so a scoped selector may return
+		// far fewer values.
+		confidence := 0.8
+		if scoped {
+			confidence = 0.5
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D36",
+			Severity:    Medium,
+			Title:       "Variable returns too many label values",
+			Why:         fmt.Sprintf("Variable $%s (%s) may return up to %d values (threshold: %d): %s. The lookup is slow on every dashboard load, nobody can pick from a dropdown that long, and selecting All expands into a regex over every value.", v.Name, truncateQuery(query, 80), values, r.maxValues(), source),
+			Fix:         fmt.Sprintf("Scope the query with a parent variable or a fixed filter (e.g. label_values(%s{job=\"$job\"}, %s)), or read %s from a dedicated low-cardinality metric. If users type the value anyway, use a textbox variable.", orDefault(metric, "up"), label, label),
+			Impact:      fmt.Sprintf("Cuts the variable lookup from ~%d values to the ones relevant to the dashboard", values),
+			Validate:    "Open dashboard → Network tab → check the variable's label values request time and response size",
+			AutoFixable: false,
+			Confidence:  confidence,
+		})
+	}
+	return findings
+}
+
+// labelValuesSelector returns the series selector argument of a
+// label_values(selector, label) query, or "" for the one-argument form
+// and any other query.
+func labelValuesSelector(query string) string {
+	if !strings.HasPrefix(query, "label_values(") || !strings.HasSuffix(query, ")") {
+		return ""
+	}
+	args := strings.TrimSuffix(strings.TrimPrefix(query, "label_values("), ")")
+	i := strings.LastIndex(args, ",")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(args[:i])
+}
+
+// orDefault returns s, or def if s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
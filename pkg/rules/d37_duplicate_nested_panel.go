@@ -0,0 +1,75 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// DuplicateNestedPanel detects panels listed inside a collapsed row whose ID
+// is also used by a top-level panel or by a panel in an earlier row. This
+// happens when a dashboard's JSON is edited or merged by hand: the panel
+// appears both in the row's "panels" and in the dashboard's, and Grafana's
+// behavior on expanding the row is undefined. Analysis counts an identical
+// copy once and keeps a differing one (see extractor.AllPanels); this rule
+// reports the inconsistency either way.
+type DuplicateNestedPanel struct{}
+
+func (r *DuplicateNestedPanel) ID() string            { return "D37" }
+func (r *DuplicateNestedPanel) RuleSeverity() Severity { return Low }
+
+func (r *DuplicateNestedPanel) Describe() Description {
+	return Description{
+		Title:       "Panel duplicated inside a collapsed row",
+		Summary:     "Panels nested in a collapsed row whose ID is also used at the top level or in another row.",
+		Rationale:   "Malformed JSON from hand edits or merges; expanding the row may duplicate or drop the panel.",
+		Bad:         `"panels": [{"id": 5, ...}, {"type": "row", "collapsed": true, "panels": [{"id": 5, ...}]}]`,
+		Good:        `"panels": [{"type": "row", "collapsed": true, "panels": [{"id": 5, ...}]}]`,
+		AutoFixable: false,
+	}
+}
+
+func (r *DuplicateNestedPanel) Check(ctx *AnalysisContext) []Finding {
+	dash := ctx.Dashboard
+	type seenPanel struct {
+		where string
+		panel extractor.PanelModel
+	}
+	seen := make(map[int]seenPanel, len(dash.Panels))
+	for _, p := range dash.Panels {
+		if _, dup := seen[p.ID]; p.ID != 0 && !dup {
+			seen[p.ID] = seenPanel{"at the top level", p}
+		}
+	}
+	var findings []Finding
+	for _, row := range dash.Panels {
+		for _, p := range row.NestedPanels {
+			if p.ID == 0 {
+				continue
+			}
+			first, dup := seen[p.ID]
+			if !dup {
+				seen[p.ID] = seenPanel{fmt.Sprintf("in row %q", row.Title), p}
+				continue
+			}
+			analyzed := "only the first copy was analyzed"
+			if !extractor.SamePanel(first.panel, p) {
+				analyzed = "the copies differ, so both were analyzed"
+			}
+			findings = append(findings, Finding{
+				RuleID:      "D37",
+				Severity:    Low,
+				PanelIDs:    []int{p.ID},
+				PanelTitles: []string{p.Title},
+				Title:       "Panel duplicated inside a collapsed row",
+				Why:         fmt.Sprintf("Panel %q (id %d) is nested in row %q, but a panel with the same ID is also listed %s. Panel IDs must be unique; Grafana may show the panel twice or drop one copy when the row is expanded, and %s.", p.Title, p.ID, row.Title, first.where, analyzed),
+				Fix:         fmt.Sprintf("Remove the duplicate: keep the panel either %s or in row %q, not both, and give any genuinely different panel a new ID.", first.where, row.Title),
+				Impact:      "A well-formed dashboard that renders and is analyzed predictably",
+				Validate:    "Expand the row → each panel should appear exactly once",
+				AutoFixable: false,
+				Confidence:  0.9,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
+)
+
+// StaleRepeatOptions detects panels that set repeatDirection or maxPerRow
+// but no repeat variable. Grafana only reads those options when the panel
+// repeats, so they are leftovers from a removed repeat or a copied panel.
+// They do nothing today, but mislead readers and silently come back into
+// effect if someone later sets repeat. Auto-fixable: the fixer removes them.
+type StaleRepeatOptions struct{}
+
+func (r *StaleRepeatOptions) ID() string            { return "D38" }
+func (r *StaleRepeatOptions) RuleSeverity() Severity { return Low }
+
+func (r *StaleRepeatOptions) Describe() Description {
+	return Description{
+		Title:       "Repeat options without a repeat variable",
+		Summary:     "Panels with repeatDirection or maxPerRow set but no repeat.",
+		Rationale:   "The options are ignored without repeat; they are leftovers that mislead readers and return if repeat is set again.",
+		Bad:         `{"type": "timeseries", "repeatDirection": "h", "maxPerRow": 4}`,
+		Good:        `{"type": "timeseries"}`,
+		AutoFixable: true,
+	}
+}
+
+func (r *StaleRepeatOptions) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, p := range extractor.AllPanels(ctx.Dashboard) {
+		if p.Repeat != "" {
+			continue
+		}
+		var stale []string
+		if p.RepeatDirection != "" {
+			stale = append(stale, fmt.Sprintf("repeatDirection %q", p.RepeatDirection))
+		}
+		if p.MaxPerRow != 0 {
+			stale = append(stale, fmt.Sprintf("maxPerRow %d", p.MaxPerRow))
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D38",
+			Severity:    Low,
+			PanelIDs:    []int{p.ID},
+			PanelTitles: []string{p.Title},
+			Title:       "Repeat options without a repeat variable",
+			Why:         fmt.Sprintf("Panel %q sets %s but has no repeat variable. Grafana ignores these options unless the panel repeats, so they are leftovers from a removed repeat or a copied panel, and take effect again if repeat is ever set.", p.Title, strings.Join(stale, " and ")),
+			Fix:         "Remove repeatDirection and maxPerRow from the panel JSON, or set repeat if the panel was meant to repeat.",
+			Impact:      "Panel JSON that reflects how the panel actually renders",
+			Validate:    "Open panel edit → Panel options → Repeat options should be empty",
+			AutoFixable: true,
+			Confidence:  0.9,
+		})
+	}
+	return findings
+}
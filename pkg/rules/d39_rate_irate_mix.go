@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RateIrateMix detects metrics queried with rate() in some places of a
+// dashboard and irate() in others. rate() averages over its window while
+// irate() follows the last two samples, so the same metric looks smooth in
+// one panel and spiky in the next; viewers comparing them read the
+// difference in smoothing as a difference in traffic.
+type RateIrateMix struct{}
+
+func (r *RateIrateMix) ID() string            { return "D39" }
+func (r *RateIrateMix) RuleSeverity() Severity { return Low }
+
+func (r *RateIrateMix) Describe() Description {
+	return Description{
+		Title:       "Metric queried with both rate() and irate()",
+		Summary:     "Metrics used with rate() in some panels and irate() in others.",
+		Rationale:   "The same metric looks smooth in one panel and spiky in the next; viewers read the smoothing difference as a traffic difference.",
+		Bad:         `panel 1: rate(http_requests_total[5m]), panel 2: irate(http_requests_total[5m])`,
+		Good:        `rate(http_requests_total[$__rate_interval]) in both panels`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RateIrateMix) Check(ctx *AnalysisContext) []Finding {
+	// metric → function (rate, irate) → panels using it, in panel order.
+	uses := make(map[string]map[string][]int)
+	titles := make(map[int]string)
+	for _, panel := range ctx.Panels {
+		titles[panel.ID] = panel.Title
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				c, ok := node.(*parser.Call)
+				if !ok || (c.Func.Name != "rate" && c.Func.Name != "irate") || len(c.Args) == 0 {
+					return nil
+				}
+				metric := extractMetricName(c.Args[0])
+				if metric == "" {
+					return nil
+				}
+				if uses[metric] == nil {
+					uses[metric] = make(map[string][]int)
+				}
+				ids := uses[metric][c.Func.Name]
+				if len(ids) == 0 || ids[len(ids)-1] != panel.ID {
+					uses[metric][c.Func.Name] = append(ids, panel.ID)
+				}
+				return nil
+			})
+		}
+	}
+
+	metrics := make([]string, 0, len(uses))
+	for m, funcs := range uses {
+		if len(funcs["rate"]) > 0 && len(funcs["irate"]) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	sort.Strings(metrics)
+
+	var findings []Finding
+	for _, metric := range metrics {
+		rateIDs, irateIDs := uses[metric]["rate"], uses[metric]["irate"]
+		var ids []int
+		var panelTitles []string
+		seen := make(map[int]bool)
+		for _, id := range append(append([]int(nil), rateIDs...), irateIDs...) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+				panelTitles = append(panelTitles, titles[id])
+			}
+		}
+		findings = append(findings, Finding{
+			RuleID:      "D39",
+			Severity:    Low,
+			PanelIDs:    ids,
+			PanelTitles: panelTitles,
+			Title:       "Metric queried with both rate() and irate()",
+			Why:         fmt.Sprintf("%s is queried with rate() in %s and with irate() in %s. rate() averages over its window while irate() follows the last two samples, so the same metric looks smooth in one place and spiky in another, and viewers comparing the panels read the difference in smoothing as a difference in traffic.", metric, panelList(rateIDs, titles), panelList(irateIDs, titles)),
+			Fix:         fmt.Sprintf("Use one function for %s across the dashboard: rate(...[$__rate_interval]) for trends, or irate() only in a panel clearly titled as instantaneous.", metric),
+			Impact:      "Consistent smoothing, so panels showing the same metric can be compared",
+			Validate:    "Compare the panels over the same range — the shapes should now agree",
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+	return findings
+}
+
+// panelList formats panel IDs as a quoted title list for finding text,
+// e.g. panels "Requests", "Errors".
+func panelList(ids []int, titles map[int]string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = fmt.Sprintf("%q", titles[id])
+	}
+	if len(ids) == 1 {
+		return "panel " + quoted[0]
+	}
+	return "panels " + strings.Join(quoted, ", ")
+}
@@ -3,6 +3,8 @@ package rules
 import (
 	"fmt"
 	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
 )
 
 // defaultValuesPerVariable is the assumed cardinality of each multi-select
@@ -22,6 +24,17 @@ type VariableExplosion struct {
 func (r *VariableExplosion) ID() string            { return "D3" }
 func (r *VariableExplosion) RuleSeverity() Severity { return Critical }
 
+func (r *VariableExplosion) Describe() Description {
+	return Description{
+		Title:       "Variable cross-product explosion",
+		Summary:     "Multi-select Include All variables whose value cross-product exceeds 50.",
+		Rationale:   "Selecting All on each variable combines into a query or repeat fan-out of every value combination.",
+		Bad:         "$cluster, $namespace and $pod all multi with includeAll",
+		Good:        "only $namespace multi; $cluster and $pod single-value",
+		AutoFixable: false,
+	}
+}
+
 func (r *VariableExplosion) threshold() int {
 	if r.Threshold > 0 {
 		return r.Threshold
@@ -42,16 +55,7 @@ func (r *VariableExplosion) Check(ctx *AnalysisContext) []Finding {
 		return nil
 	}
 
-	// Estimate cross-product: each variable contributes defaultValuesPerVariable.
-	product := 1
-	for range explosiveVars {
-		product *= defaultValuesPerVariable
-		// Guard against integer overflow for many variables.
-		if product > 1_000_000 {
-			product = 1_000_000
-			break
-		}
-	}
+	product := EstimateVariableFanOut(ctx.Variables)
 
 	thresh := r.threshold()
 	if product <= thresh {
@@ -76,3 +80,21 @@ func (r *VariableExplosion) Check(ctx *AnalysisContext) []Finding {
 		},
 	}
 }
+
+// EstimateVariableFanOut returns the worst-case cross-product of values for
+// variables that are both multi-select and include-all, assuming
+// defaultValuesPerVariable values each. Returns 1 when no such variables
+// exist. Capped at 1,000,000 to guard against overflow.
+func EstimateVariableFanOut(vars []extractor.VariableModel) int {
+	product := 1
+	for _, v := range vars {
+		if !v.IncludeAll || !v.Multi {
+			continue
+		}
+		product *= defaultValuesPerVariable
+		if product > 1_000_000 {
+			return 1_000_000
+		}
+	}
+	return product
+}
@@ -15,6 +15,17 @@ type ExpensiveVariableQuery struct{}
 func (r *ExpensiveVariableQuery) ID() string            { return "D4" }
 func (r *ExpensiveVariableQuery) RuleSeverity() Severity { return High }
 
+func (r *ExpensiveVariableQuery) Describe() Description {
+	return Description{
+		Title:       "Variable uses full PromQL query",
+		Summary:     "Query variables that run a full PromQL expression instead of label_values().",
+		Rationale:   "A PromQL variable query evaluates real data on every load; label_values() only reads label metadata.",
+		Bad:         `"query": "query_result(count by (job) (up))"`,
+		Good:        `"query": "label_values(up, job)"`,
+		AutoFixable: false,
+	}
+}
+
 func (r *ExpensiveVariableQuery) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 
@@ -17,6 +17,17 @@ type RefreshTooFrequent struct {
 func (r *RefreshTooFrequent) ID() string            { return "D5" }
 func (r *RefreshTooFrequent) RuleSeverity() Severity { return Medium }
 
+func (r *RefreshTooFrequent) Describe() Description {
+	return Description{
+		Title:       "Auto-refresh interval too frequent",
+		Summary:     "Auto-refresh intervals shorter than 30 seconds.",
+		Rationale:   "Every open tab re-runs every query at that interval, even when nobody is looking.",
+		Bad:         `"refresh": "5s"`,
+		Good:        `"refresh": "1m"`,
+		AutoFixable: true,
+	}
+}
+
 func (r *RefreshTooFrequent) minRefresh() time.Duration {
 	if r.MinRefresh > 0 {
 		return r.MinRefresh
@@ -17,6 +17,17 @@ type RangeTooWide struct {
 func (r *RangeTooWide) ID() string            { return "D6" }
 func (r *RangeTooWide) RuleSeverity() Severity { return Medium }
 
+func (r *RangeTooWide) Describe() Description {
+	return Description{
+		Title:       "Default time range too wide",
+		Summary:     "Default time ranges wider than 24 hours.",
+		Rationale:   "Wide ranges pull more data per query and slow both the datasource and the browser on every open.",
+		Bad:         `"time": {"from": "now-30d", "to": "now"}`,
+		Good:        `"time": {"from": "now-6h", "to": "now"}`,
+		AutoFixable: true,
+	}
+}
+
 func (r *RangeTooWide) maxRange() time.Duration {
 	if r.MaxRange > 0 {
 		return r.MaxRange
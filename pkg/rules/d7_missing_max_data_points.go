@@ -25,6 +25,17 @@ type MissingMaxDataPoints struct{}
 func (r *MissingMaxDataPoints) ID() string            { return "D7" }
 func (r *MissingMaxDataPoints) RuleSeverity() Severity { return Medium }
 
+func (r *MissingMaxDataPoints) Describe() Description {
+	return Description{
+		Title:       "Missing maxDataPoints",
+		Summary:     "Time-series panels without maxDataPoints.",
+		Rationale:   "Without it a wide range can return far more points than the panel has pixels, slowing the query and the browser.",
+		Bad:         `{"type": "timeseries", "targets": [...]}`,
+		Good:        `{"type": "timeseries", "maxDataPoints": 1000, "targets": [...]}`,
+		AutoFixable: true,
+	}
+}
+
 func (r *MissingMaxDataPoints) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 
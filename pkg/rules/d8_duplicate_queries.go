@@ -16,6 +16,17 @@ type DuplicateQueries struct{}
 func (r *DuplicateQueries) ID() string            { return "D8" }
 func (r *DuplicateQueries) RuleSeverity() Severity { return Medium }
 
+func (r *DuplicateQueries) Describe() Description {
+	return Description{
+		Title:       "Duplicate query across panels",
+		Summary:     "Identical queries in several panels.",
+		Rationale:   "Each panel sends its own request; the -- Dashboard -- datasource shares one result.",
+		Bad:         "the same expr in panels 2 and 5",
+		Good:        "panel 5 uses the -- Dashboard -- datasource pointing at panel 2",
+		AutoFixable: false,
+	}
+}
+
 func (r *DuplicateQueries) Check(ctx *AnalysisContext) []Finding {
 	// Map each expression to the panels that use it.
 	type panelRef struct {
@@ -20,6 +20,17 @@ type DatasourceMixing struct {
 func (r *DatasourceMixing) ID() string            { return "D9" }
 func (r *DatasourceMixing) RuleSeverity() Severity { return Low }
 
+func (r *DatasourceMixing) Describe() Description {
+	return Description{
+		Title:       "Too many distinct datasources",
+		Summary:     "Dashboards querying more than 2 distinct datasources.",
+		Rationale:   "Each datasource adds connections and backends that must all respond before the dashboard is complete.",
+		Bad:         "panels spread over 5 Prometheus datasources",
+		Good:        "one datasource, or a $datasource variable",
+		AutoFixable: false,
+	}
+}
+
 func (r *DatasourceMixing) maxDatasources() int {
 	if r.MaxDatasources > 0 {
 		return r.MaxDatasources
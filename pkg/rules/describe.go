@@ -0,0 +1,20 @@
+package rules
+
+// Description documents a rule independently of any dashboard: what it
+// looks for, why that is slow or wrong, and what to write instead. It backs
+// the CLI's --explain mode.
+type Description struct {
+	Title       string // the title the rule's findings carry
+	Summary     string // what the rule detects, in one sentence
+	Rationale   string // why it matters for dashboard speed or correctness
+	Bad         string // example that triggers the rule (PromQL or dashboard JSON)
+	Good        string // the same example written the recommended way
+	AutoFixable bool   // true if --fix can patch at least some findings
+}
+
+// Describer is implemented by rules that document themselves. Every built-in
+// rule does; it is a separate interface so third-party rules written against
+// Rule keep compiling without one.
+type Describer interface {
+	Describe() Description
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ReportDiff is the difference between the reports of two versions of a
+// dashboard, e.g. before and after an optimization pass.
+type ReportDiff struct {
+	ScoreBefore int               `json:"scoreBefore"`
+	ScoreAfter  int               `json:"scoreAfter"`
+	Resolved    []Finding         `json:"resolved"`    // in before, not in after
+	Introduced  []Finding         `json:"introduced"`  // in after, not in before
+	RuleChanges []RuleCountChange `json:"ruleChanges"` // rules whose finding count changed, by rule ID
+}
+
+// RuleCountChange is the number of findings of one rule in each report.
+type RuleCountChange struct {
+	RuleID string `json:"ruleId"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// DiffReports compares two reports by finding fingerprint: the rule ID and
+// the affected panel IDs. Within a fingerprint, findings on the same
+// TargetExpr are paired first, so a rule that still fires on a panel whose
+// query was edited counts as unchanged rather than resolved and introduced.
+// Panel IDs are assumed to be stable between the two versions, as Grafana
+// keeps them when a dashboard is edited.
+func DiffReports(before, after *Report) ReportDiff {
+	diff := ReportDiff{ScoreBefore: before.Score, ScoreAfter: after.Score}
+
+	// unmatched holds, per fingerprint, the indices of before's findings
+	// not yet paired with one of after's.
+	unmatched := make(map[string][]int)
+	for i, f := range before.Findings {
+		key := findingFingerprint(f)
+		unmatched[key] = append(unmatched[key], i)
+	}
+	for _, f := range after.Findings {
+		key := findingFingerprint(f)
+		candidates := unmatched[key]
+		if len(candidates) == 0 {
+			diff.Introduced = append(diff.Introduced, f)
+			continue
+		}
+		match := 0
+		for i, idx := range candidates {
+			if before.Findings[idx].TargetExpr == f.TargetExpr {
+				match = i
+				break
+			}
+		}
+		unmatched[key] = append(candidates[:match:match], candidates[match+1:]...)
+	}
+	resolved := make([]bool, len(before.Findings))
+	for _, indices := range unmatched {
+		for _, idx := range indices {
+			resolved[idx] = true
+		}
+	}
+	for i, f := range before.Findings {
+		if resolved[i] {
+			diff.Resolved = append(diff.Resolved, f)
+		}
+	}
+
+	counts := make(map[string]*RuleCountChange)
+	change := func(id string) *RuleCountChange {
+		if counts[id] == nil {
+			counts[id] = &RuleCountChange{RuleID: id}
+		}
+		return counts[id]
+	}
+	for _, f := range before.Findings {
+		change(f.RuleID).Before++
+	}
+	for _, f := range after.Findings {
+		change(f.RuleID).After++
+	}
+	for _, c := range counts {
+		if c.Before != c.After {
+			diff.RuleChanges = append(diff.RuleChanges, *c)
+		}
+	}
+	sort.Slice(diff.RuleChanges, func(i, j int) bool {
+		return diff.RuleChanges[i].RuleID < diff.RuleChanges[j].RuleID
+	})
+	return diff
+}
+
+// findingFingerprint identifies a finding across two versions of a
+// dashboard: its rule and the panels it is about.
+func findingFingerprint(f Finding) string {
+	return fmt.Sprintf("%s %v", f.RuleID, f.PanelIDs)
+}
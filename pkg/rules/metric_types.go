@@ -0,0 +1,46 @@
+package rules
+
+import "strings"
+
+// MetricType is the Prometheus type of a metric family.
+type MetricType string
+
+const (
+	MetricTypeCounter   MetricType = "counter"
+	MetricTypeGauge     MetricType = "gauge"
+	MetricTypeHistogram MetricType = "histogram"
+	MetricTypeSummary   MetricType = "summary"
+)
+
+// Valid reports whether t is one of the known metric types.
+func (t MetricType) Valid() bool {
+	switch t {
+	case MetricTypeCounter, MetricTypeGauge, MetricTypeHistogram, MetricTypeSummary:
+		return true
+	}
+	return false
+}
+
+// MetricTypes is a user-supplied classification of metrics, used by rules
+// whose built-in naming heuristics cannot know about custom metrics (Q11, Q24).
+// Keys are exact metric names, or prefixes ending in "*" (e.g. "acme_queue_*").
+// A nil MetricTypes classifies nothing.
+type MetricTypes map[string]MetricType
+
+// Lookup returns the type of the metric name: an exact entry if there is
+// one, otherwise the longest matching prefix entry. ok is false when the
+// metric is not classified.
+func (m MetricTypes) Lookup(name string) (t MetricType, ok bool) {
+	if t, ok := m[name]; ok {
+		return t, true
+	}
+	longest := -1
+	for key, kt := range m {
+		prefix, isPrefix := strings.CutSuffix(key, "*")
+		if !isPrefix || !strings.HasPrefix(name, prefix) || len(prefix) <= longest {
+			continue
+		}
+		t, ok, longest = kt, true, len(prefix)
+	}
+	return t, ok
+}
@@ -23,6 +23,17 @@ type IncorrectAggregation struct{}
 func (r *IncorrectAggregation) ID() string            { return "Q10" }
 func (r *IncorrectAggregation) RuleSeverity() Severity { return Medium }
 
+func (r *IncorrectAggregation) Describe() Description {
+	return Description{
+		Title:       "Incorrect aggregation order",
+		Summary:     "rate()/irate()/increase() applied to the output of an aggregation.",
+		Rationale:   "Aggregated series are not counters, so counter resets are not handled and the result is mathematically wrong; PromQL also rejects rate() over an aggregation without a subquery.",
+		Bad:         `rate(sum(http_requests_total{job="api"})[5m:])`,
+		Good:        `sum(rate(http_requests_total{job="api"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
 func (r *IncorrectAggregation) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -38,6 +49,7 @@ func (r *IncorrectAggregation) Check(ctx *AnalysisContext) []Finding {
 					Severity:    Medium,
 					PanelIDs:    []int{panel.ID},
 					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
 					Title:       "Incorrect aggregation order",
 					Why:         fmt.Sprintf("Expression applies %s() over an aggregation. Rate-like functions expect raw counter values, but aggregation output is not a monotonic counter — results will be mathematically incorrect.", outerFunc),
 					Fix:         fmt.Sprintf("Reverse the order: apply %s() first on the raw metric, then aggregate. E.g. sum(rate(metric[5m])) instead of rate(sum(metric)[5m]).", outerFunc),
@@ -74,6 +86,7 @@ func (r *IncorrectAggregation) Check(ctx *AnalysisContext) []Finding {
 							Severity:    Medium,
 							PanelIDs:    []int{panel.ID},
 							PanelTitles: []string{panel.Title},
+							TargetExpr:  target.Expr,
 							Title:       "Incorrect aggregation order",
 							Why:         fmt.Sprintf("Expression applies %s() over a subquery containing an aggregation. Rate-like functions expect raw counter values, but aggregation output is not a monotonic counter.", call.Func.Name),
 							Fix:         fmt.Sprintf("Reverse the order: apply %s() first on the raw metric, then aggregate.", call.Func.Name),
@@ -31,11 +31,24 @@ var knownGaugePrefixes = []string{
 // These functions compute per-second change and only make sense for counters
 // (monotonically increasing values). Applying them to gauges produces
 // meaningless results (often mostly zeros with occasional spikes).
+// Metrics classified in AnalysisContext.MetricTypes are judged by that
+// classification; the naming heuristic only covers the rest.
 type RateOnGauge struct{}
 
 func (r *RateOnGauge) ID() string            { return "Q11" }
 func (r *RateOnGauge) RuleSeverity() Severity { return Medium }
 
+func (r *RateOnGauge) Describe() Description {
+	return Description{
+		Title:       "rate()/irate() on gauge metric",
+		Summary:     "rate() or irate() applied to metrics that look like gauges (no counter suffix).",
+		Rationale:   "rate() assumes a monotonically increasing counter; on a gauge every decrease is treated as a reset, producing mostly zeros with occasional spikes.",
+		Bad:         `rate(node_memory_MemAvailable_bytes{job="node"}[5m])`,
+		Good:        `deriv(node_memory_MemAvailable_bytes{job="node"}[5m])`,
+		AutoFixable: false,
+	}
+}
+
 func (r *RateOnGauge) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -60,21 +73,27 @@ func (r *RateOnGauge) Check(ctx *AnalysisContext) []Finding {
 				if metricName == "" {
 					return nil
 				}
-				if !isLikelyGauge(metricName) {
+				gauge, classified := classifyGauge(ctx.MetricTypes, metricName)
+				if !gauge {
 					return nil
 				}
+				confidence := 0.6
+				if classified {
+					confidence = 0.9
+				}
 				findings = append(findings, Finding{
 					RuleID:      "Q11",
 					Severity:    Medium,
 					PanelIDs:    []int{panel.ID},
 					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
 					Title:       "rate()/irate() on gauge metric",
 					Why:         fmt.Sprintf("%s() is applied to %q, which appears to be a gauge metric. rate/irate compute per-second change and only produce meaningful results on counters (_total, _count, _bucket).", call.Func.Name, metricName),
 					Fix:         fmt.Sprintf("Use the metric directly (%s) or use delta() / deriv() instead of %s() for gauge metrics.", metricName, call.Func.Name),
 					Impact:      "Correct function choice produces accurate visualizations instead of mostly-zero noise",
 					Validate:    "Compare rate() output with raw metric — gauges should show actual values, not per-second derivatives",
 					AutoFixable: false,
-					Confidence:  0.6,
+					Confidence:  confidence,
 				})
 				return nil
 			})
@@ -83,6 +102,16 @@ func (r *RateOnGauge) Check(ctx *AnalysisContext) []Finding {
 	return findings
 }
 
+// classifyGauge reports whether name is a gauge. A metric listed in types
+// is judged by its declared type (classified is true); any other falls back
+// to isLikelyGauge.
+func classifyGauge(types MetricTypes, name string) (gauge, classified bool) {
+	if t, ok := types.Lookup(name); ok {
+		return t == MetricTypeGauge, true
+	}
+	return isLikelyGauge(name), false
+}
+
 // isLikelyGauge returns true if the metric name matches known gauge patterns.
 // Uses conservative matching: only flags metrics that are definitely gauges,
 // not unknown metrics.
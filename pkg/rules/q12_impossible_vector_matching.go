@@ -15,6 +15,17 @@ type ImpossibleVectorMatching struct{}
 func (r *ImpossibleVectorMatching) ID() string            { return "Q12" }
 func (r *ImpossibleVectorMatching) RuleSeverity() Severity { return Medium }
 
+func (r *ImpossibleVectorMatching) Describe() Description {
+	return Description{
+		Title:       "Binary operation without explicit label matching",
+		Summary:     "Binary operations between different metrics without on()/ignoring().",
+		Rationale:   "By default both sides must match on every label; differing label sets silently produce empty or partial results.",
+		Bad:         `http_errors_total{job="api"} / http_requests_total{job="api"}`,
+		Good:        `http_errors_total{job="api"} / on(job, instance) http_requests_total{job="api"}`,
+		AutoFixable: false,
+	}
+}
+
 func (r *ImpossibleVectorMatching) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -50,6 +61,7 @@ func (r *ImpossibleVectorMatching) Check(ctx *AnalysisContext) []Finding {
 					Severity:    Medium,
 					PanelIDs:    []int{panel.ID},
 					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
 					Title:       "Binary operation without explicit label matching",
 					Why:         fmt.Sprintf("Binary %s between %q and %q without on()/ignoring(). Prometheus matches on ALL labels, which may produce empty results if the two metrics have different label sets.", binExpr.Op, leftMetric, rightMetric),
 					Fix:         fmt.Sprintf("Add explicit matching: ... %s on(common_labels) ..., or use ignoring(differing_labels).", binExpr.Op),
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// HistogramQuantileWithoutRate detects histogram_quantile() calls whose
+// bucket selector is not wrapped in rate()/irate()/increase(). Classic
+// histogram buckets are cumulative counters, so the quantile of the raw
+// buckets describes every request since the process started rather than
+// the recent window — almost never what a dashboard intends.
+type HistogramQuantileWithoutRate struct{}
+
+func (r *HistogramQuantileWithoutRate) ID() string            { return "Q15" }
+func (r *HistogramQuantileWithoutRate) RuleSeverity() Severity { return High }
+
+func (r *HistogramQuantileWithoutRate) Describe() Description {
+	return Description{
+		Title:       "histogram_quantile() on raw buckets",
+		Summary:     "histogram_quantile() whose bucket selector is not wrapped in rate()/irate()/increase().",
+		Rationale:   "Buckets are cumulative counters, so the quantile describes every request since the process started, not the recent window.",
+		Bad:         `histogram_quantile(0.99, sum by (le) (http_request_duration_seconds_bucket{job="api"}))`,
+		Good:        `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m])))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *HistogramQuantileWithoutRate) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || call.Func.Name != "histogram_quantile" || len(call.Args) < 2 {
+					return nil
+				}
+				bucketMetric := findUnratedBucket(call.Args[1])
+				if bucketMetric == "" {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q15",
+					Severity:    High,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "histogram_quantile() on raw buckets",
+					Why:         fmt.Sprintf("histogram_quantile() reads %q without rate()/increase(). Bucket counters are cumulative, so the quantile covers all requests since the process started, not the selected time window.", bucketMetric),
+					Fix:         fmt.Sprintf("Wrap the buckets in rate() and aggregate by le, e.g. histogram_quantile(0.9, sum by(le) (rate(%s[$__rate_interval]))).", bucketMetric),
+					Impact:      "Quantiles reflect recent latency instead of an all-time average that barely moves",
+					Validate:    "Compare the panel before/after during a latency spike — the fixed query should react to it",
+					AutoFixable: false,
+					Confidence:  0.8,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// findUnratedBucket returns the name of the first _bucket selector under expr
+// that has no rate()/irate()/increase() call between it and expr. Returns an
+// empty string when every bucket selector is rated.
+func findUnratedBucket(expr parser.Expr) string {
+	var unrated string
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		if unrated != "" {
+			return nil
+		}
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		name := extractMetricName(vs)
+		if !strings.HasSuffix(name, "_bucket") {
+			return nil
+		}
+		for _, ancestor := range path {
+			if call, ok := ancestor.(*parser.Call); ok && rateFuncsForInterval[call.Func.Name] {
+				return nil
+			}
+		}
+		unrated = name
+		return nil
+	})
+	return unrated
+}
@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RateWindowAlignment detects rate-like calls whose range window is not a
+// whole multiple of the scrape interval. A window like [95s] at a 30s scrape
+// interval sometimes holds 3 samples and sometimes 4, so the extrapolated
+// result jitters from one evaluation to the next.
+type RateWindowAlignment struct {
+	// ScrapeInterval is the assumed scrape interval of the queried targets.
+	// Defaults to AnalysisContext.ScrapeInterval (--scrape-interval) if
+	// zero, and to 30s if that is unknown too.
+	ScrapeInterval time.Duration
+}
+
+func (r *RateWindowAlignment) ID() string            { return "Q16" }
+func (r *RateWindowAlignment) RuleSeverity() Severity { return Low }
+
+func (r *RateWindowAlignment) Describe() Description {
+	return Description{
+		Title:       "Rate window not aligned to scrape interval",
+		Summary:     "Rate-like windows that are not a whole multiple of the scrape interval (--scrape-interval, 30s by default).",
+		Rationale:   "The number of samples in the window alternates between evaluations, so the extrapolated rate jitters.",
+		Bad:         `rate(http_requests_total{job="api"}[95s])`,
+		Good:        `rate(http_requests_total{job="api"}[90s])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RateWindowAlignment) scrapeInterval(ctx *AnalysisContext) time.Duration {
+	if r.ScrapeInterval > 0 {
+		return r.ScrapeInterval
+	}
+	if ctx.ScrapeInterval > 0 {
+		return ctx.ScrapeInterval
+	}
+	return 30 * time.Second
+}
+
+func (r *RateWindowAlignment) Check(ctx *AnalysisContext) []Finding {
+	scrape := r.scrapeInterval(ctx)
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			// Template-variable windows are substituted with a placeholder
+			// duration before parsing, so their parsed range is meaningless.
+			if strings.Contains(target.Expr, "$__") {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || !rateFuncNames[call.Func.Name] || len(call.Args) == 0 {
+					return nil
+				}
+				ms, ok := call.Args[0].(*parser.MatrixSelector)
+				if !ok || ms.Range%scrape == 0 {
+					return nil
+				}
+				lower := ms.Range.Truncate(scrape)
+				if lower == 0 {
+					lower = scrape
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q16",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Rate window not aligned to scrape interval",
+					Why:         fmt.Sprintf("%s() uses a %s window, which is not a multiple of the %s scrape interval. The number of samples in the window varies between evaluations, causing uneven extrapolation.", call.Func.Name, ms.Range, scrape),
+					Fix:         fmt.Sprintf("Use a window that is a multiple of the scrape interval (e.g. %s), or $__rate_interval.", lower),
+					Impact:      "Smoother, more consistent rate values with no change in query cost",
+					Validate:    "Compare the panel before/after — sawtooth artifacts should disappear",
+					AutoFixable: false,
+					Confidence:  0.5,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
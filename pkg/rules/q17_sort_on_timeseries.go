@@ -0,0 +1,69 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/dashboard-advisor/pkg/rewrite"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// timeSeriesPanelTypes lists panel types that render range queries over time.
+// Grafana orders their series by legend, not by query result order.
+var timeSeriesPanelTypes = map[string]bool{
+	"timeseries": true,
+	"graph":      true,
+}
+
+// SortOnTimeSeries detects sort()/sort_desc() as the outermost function of a
+// target on a time-series panel. sort only orders instant-query results, so on
+// a range query it is a no-op that still costs an extra evaluation pass.
+type SortOnTimeSeries struct{}
+
+func (r *SortOnTimeSeries) ID() string            { return "Q17" }
+func (r *SortOnTimeSeries) RuleSeverity() Severity { return Low }
+
+func (r *SortOnTimeSeries) Describe() Description {
+	return Description{
+		Title:       "sort() on a time-series panel",
+		Summary:     "sort()/sort_desc() as the outermost function of a time-series panel target.",
+		Rationale:   "sort only orders instant-query results; on a range query it does nothing but add an evaluation pass.",
+		Bad:         `sort_desc(sum by (path) (rate(http_requests_total{job="api"}[5m])))`,
+		Good:        `sum by (path) (rate(http_requests_total{job="api"}[5m]))`,
+		AutoFixable: true,
+	}
+}
+
+func (r *SortOnTimeSeries) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if !timeSeriesPanelTypes[panel.Type] {
+			continue
+		}
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			call, ok := expr.(*parser.Call)
+			if !ok || (call.Func.Name != "sort" && call.Func.Name != "sort_desc") {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q17",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "sort() on a time-series panel",
+				Why:         fmt.Sprintf("Target is wrapped in %s(), but panel type %q renders a range query where sort order is ignored. The wrapper only adds evaluation work.", call.Func.Name, panel.Type),
+				Fix:         fmt.Sprintf("Remove the %s() wrapper, or switch the panel to a table/bar gauge if ordering matters.", call.Func.Name),
+				Impact:      "Removes a redundant sorting pass from every evaluation step",
+				Validate:    "Verify the panel renders identically after removing the wrapper",
+				AutoFixable: true,
+				Confidence:  0.9,
+				Suggestion:  rewrite.StripOuterCall(target.Expr),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/rewrite"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// DeltaOnCounter detects delta()/idelta() applied to counters (metrics
+// ending in _total). delta() is meant for gauges: it does not account for
+// counter resets, so every process restart shows up as a large negative
+// spike. increase() handles resets correctly.
+type DeltaOnCounter struct{}
+
+func (r *DeltaOnCounter) ID() string            { return "Q18" }
+func (r *DeltaOnCounter) RuleSeverity() Severity { return Medium }
+
+func (r *DeltaOnCounter) Describe() Description {
+	return Description{
+		Title:       "delta() on counter",
+		Summary:     "delta()/idelta() applied to _total counters.",
+		Rationale:   "delta() ignores counter resets, so every restart shows up as a large negative spike; increase() handles resets.",
+		Bad:         `delta(http_requests_total{job="api"}[5m])`,
+		Good:        `increase(http_requests_total{job="api"}[5m])`,
+		AutoFixable: true,
+	}
+}
+
+func (r *DeltaOnCounter) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || (call.Func.Name != "delta" && call.Func.Name != "idelta") || len(call.Args) == 0 {
+					return nil
+				}
+				metricName := extractMetricName(call.Args[0])
+				if !strings.HasSuffix(metricName, "_total") {
+					return nil
+				}
+
+				// delta → increase is a drop-in rewrite. idelta has no
+				// reset-aware equivalent with the same units, so it is
+				// reported but left for a human to rewrite.
+				fix := fmt.Sprintf("Replace delta() with increase(), e.g. increase(%s[...]).", metricName)
+				autoFixable := call.Func.Name == "delta"
+				suggestion := ""
+				if autoFixable {
+					suggestion = rewrite.DeltaToIncrease(target.Expr)
+				} else {
+					fix = fmt.Sprintf("Replace idelta() with increase(%s[...]) for a count over the window, or irate() for a per-second instant rate.", metricName)
+				}
+
+				findings = append(findings, Finding{
+					RuleID:      "Q18",
+					Severity:    Medium,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "delta() on counter",
+					Why:         fmt.Sprintf("%s() is applied to counter %q. It does not handle counter resets, so every restart appears as a large negative value.", call.Func.Name, metricName),
+					Fix:         fix,
+					Impact:      "Correct values across process restarts; no change in query cost",
+					Validate:    "Compare the panel before/after across a pod restart — negative spikes should disappear",
+					AutoFixable: autoFixable,
+					Confidence:  0.85,
+					Suggestion:  suggestion,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// rangeWindowRe matches a rate-like call whose range window is Grafana's
+// $__range / ${__range}. It runs on the raw expression because
+// ReplaceTemplateVars substitutes $__range with the same placeholder as
+// $__rate_interval, so the AST cannot tell them apart.
+var rangeWindowRe = regexp.MustCompile(`\b(rate|irate|increase|delta|idelta)\s*\([^[]*\[\s*\$(?:__range|\{__range\})\s*\]`)
+
+// RangeAsRateWindow detects rate-like functions using [$__range] as their
+// window on time-series panels. The window then grows with the dashboard time
+// range: every point averages over the whole visible range, flattening the
+// graph into a near-constant line. Stat-style panels are skipped because
+// increase(x[$__range]) is the idiomatic "total over the selected range".
+type RangeAsRateWindow struct{}
+
+func (r *RangeAsRateWindow) ID() string            { return "Q19" }
+func (r *RangeAsRateWindow) RuleSeverity() Severity { return Medium }
+
+func (r *RangeAsRateWindow) Describe() Description {
+	return Description{
+		Title:       "$__range used as rate window",
+		Summary:     "Rate-like functions using [$__range] as their window on time-series panels.",
+		Rationale:   "Every point then averages over the whole visible range, so the graph flattens into a near-constant line and each point reads the full range of samples.",
+		Bad:         `rate(http_requests_total{job="api"}[$__range])`,
+		Good:        `rate(http_requests_total{job="api"}[$__rate_interval])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RangeAsRateWindow) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if !timeSeriesPanelTypes[panel.Type] {
+			continue
+		}
+		for _, target := range panel.Targets {
+			m := rangeWindowRe.FindStringSubmatch(target.Expr)
+			if m == nil {
+				continue
+			}
+			funcName := m[1]
+			findings = append(findings, Finding{
+				RuleID:      "Q19",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "$__range used as rate window",
+				Why:         fmt.Sprintf("%s() uses [$__range] on a %s panel, so every point covers the whole dashboard time range. The graph flattens into one heavily smoothed value, and widening the range makes each evaluation read more samples.", funcName, panel.Type),
+				Fix:         fmt.Sprintf("Use %s(metric[$__rate_interval]) for a per-point rate, or move the query to a stat panel if a single total over the range is intended.", funcName),
+				Impact:      "Per-point windows stay small regardless of the dashboard time range",
+				Validate:    "Switch the time range from 1h to 7d — the graph should keep its shape instead of flattening",
+				AutoFixable: false,
+				Confidence:  0.85,
+			})
+		}
+	}
+	return findings
+}
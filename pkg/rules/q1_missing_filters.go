@@ -2,7 +2,9 @@ package rules
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/dashboard-advisor/pkg/extractor"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
@@ -14,7 +16,19 @@ type MissingFilters struct{}
 func (r *MissingFilters) ID() string            { return "Q1" }
 func (r *MissingFilters) RuleSeverity() Severity { return Critical }
 
+func (r *MissingFilters) Describe() Description {
+	return Description{
+		Title:       "Missing label filters",
+		Summary:     "Selectors with no label matchers besides the metric name.",
+		Rationale:   "Without filters a query reads every series of the metric across all jobs, namespaces and instances; on a large fleet that is 10-100x more series than the panel needs.",
+		Bad:         "sum(rate(http_requests_total[5m]))",
+		Good:        `sum(rate(http_requests_total{job="api", namespace="$namespace"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
 func (r *MissingFilters) Check(ctx *AnalysisContext) []Finding {
+	varMatchers := variableMatchers(ctx.Variables)
 	var findings []Finding
 	for _, panel := range ctx.Panels {
 		for _, target := range panel.Targets {
@@ -58,14 +72,20 @@ func (r *MissingFilters) Check(ctx *AnalysisContext) []Finding {
 					}
 				}
 
+				fix := fmt.Sprintf("Add label matchers to narrow the selection, e.g. %s{job=\"...\", namespace=\"...\"}", metricName)
+				if len(varMatchers) > 0 {
+					fix = fmt.Sprintf("Filter on the dashboard's existing variables, e.g. %s{%s}", metricName, strings.Join(varMatchers, ", "))
+				}
+
 				findings = append(findings, Finding{
 					RuleID:      "Q1",
 					Severity:    Critical,
 					PanelIDs:    []int{panel.ID},
 					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
 					Title:       "Missing label filters",
 					Why:         why,
-					Fix:         fmt.Sprintf("Add label matchers to narrow the selection, e.g. %s{job=\"...\", namespace=\"...\"}", metricName),
+					Fix:         fix,
 					Impact:      impact,
 					Validate:    "Query Inspector → Stats tab → check 'Series fetched' before/after",
 					AutoFixable: false,
@@ -77,3 +97,42 @@ func (r *MissingFilters) Check(ctx *AnalysisContext) []Finding {
 	}
 	return findings
 }
+
+// variableMatchers builds label matchers from query variables that select
+// label values with label_values(), e.g. namespace=~"$namespace". Other
+// variables (custom lists, textboxes, metrics() or query_result() queries)
+// are skipped: their name says nothing about which label, if any, they hold,
+// and a guessed percentile="$percentile" matcher would match no series.
+func variableMatchers(vars []extractor.VariableModel) []string {
+	var matchers []string
+	for _, v := range vars {
+		if v.Type != "query" {
+			continue
+		}
+		label := labelValuesLabel(v.QueryString())
+		if label == "" {
+			continue
+		}
+		op := "="
+		if v.Multi || v.IncludeAll {
+			op = "=~"
+		}
+		matchers = append(matchers, fmt.Sprintf("%s%s\"$%s\"", label, op, v.Name))
+	}
+	return matchers
+}
+
+// labelValuesLabel returns the label argument of a label_values() variable
+// query — the last argument, for both label_values(label) and
+// label_values(metric, label). Returns "" for any other query.
+func labelValuesLabel(query string) string {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, "label_values(") || !strings.HasSuffix(query, ")") {
+		return ""
+	}
+	args := strings.TrimSuffix(strings.TrimPrefix(query, "label_values("), ")")
+	if i := strings.LastIndex(args, ","); i >= 0 {
+		args = args[i+1:]
+	}
+	return strings.TrimSpace(args)
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// NegativeOffset detects selectors and subqueries with a negative offset,
+// e.g. up offset -1h. A negative offset reads data from the future relative
+// to each evaluation step, so the most recent part of the graph is always
+// empty. It is almost always a sign typo for a positive offset. Prometheus
+// accepts it (negative offsets are enabled by default since 2.33), so
+// confidence stays moderate.
+type NegativeOffset struct{}
+
+func (r *NegativeOffset) ID() string            { return "Q20" }
+func (r *NegativeOffset) RuleSeverity() Severity { return High }
+
+func (r *NegativeOffset) Describe() Description {
+	return Description{
+		Title:       "Negative offset (queries the future)",
+		Summary:     "Selectors and subqueries with a negative offset.",
+		Rationale:   "A negative offset reads data after each evaluation step, so the newest part of the graph is always empty; it is almost always a sign typo.",
+		Bad:         `rate(http_requests_total{job="api"}[5m] offset -1h)`,
+		Good:        `rate(http_requests_total{job="api"}[5m] offset 1h)`,
+		AutoFixable: false,
+	}
+}
+
+func (r *NegativeOffset) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				var offset time.Duration
+				var what string
+				switch n := node.(type) {
+				case *parser.VectorSelector:
+					offset, what = n.OriginalOffset, fmt.Sprintf("selector %q", extractMetricName(n))
+				case *parser.SubqueryExpr:
+					offset, what = n.OriginalOffset, "subquery"
+				default:
+					return nil
+				}
+				if offset >= 0 {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q20",
+					Severity:    High,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Negative offset (queries the future)",
+					Why:         fmt.Sprintf("The %s uses offset %s, which reads data %s after each evaluation step. The latest part of the graph is always empty; this is almost always a typo for offset %s.", what, offset, -offset, -offset),
+					Fix:         fmt.Sprintf("Use offset %s to look back in time, or remove the offset.", -offset),
+					Impact:      "Panel shows data up to now instead of an empty trailing window",
+					Validate:    "Verify the panel has data for the most recent part of the time range",
+					AutoFixable: false,
+					Confidence:  0.7,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,111 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RecordingRuleCandidate detects the same *_over_time() call over a long
+// window appearing in several panels of one dashboard. Each copy re-reads
+// every raw sample in the window on every refresh; a recording rule would
+// evaluate it once per rule interval and let the panels read a single
+// precomputed series.
+type RecordingRuleCandidate struct {
+	// MinRange is the window above which an *_over_time() call counts as
+	// expensive. Defaults to 1h if zero.
+	MinRange time.Duration
+	// MinPanels is the number of distinct panels that must share the call.
+	// Defaults to 3 if zero.
+	MinPanels int
+}
+
+func (r *RecordingRuleCandidate) ID() string            { return "Q21" }
+func (r *RecordingRuleCandidate) RuleSeverity() Severity { return Medium }
+
+func (r *RecordingRuleCandidate) Describe() Description {
+	return Description{
+		Title:       "Long *_over_time() repeated across panels",
+		Summary:     "The same *_over_time() call over a window of an hour or more in three or more panels.",
+		Rationale:   "Each copy re-reads every raw sample in the window on every refresh; a recording rule computes it once per interval.",
+		Bad:         `avg_over_time(node_load1{job="node"}[1d]) in several panels`,
+		Good:        `record node:load1:avg1d once, and query node:load1:avg1d{job="node"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RecordingRuleCandidate) minRange() time.Duration {
+	if r.MinRange > 0 {
+		return r.MinRange
+	}
+	return time.Hour
+}
+
+func (r *RecordingRuleCandidate) minPanels() int {
+	if r.MinPanels > 0 {
+		return r.MinPanels
+	}
+	return 3
+}
+
+func (r *RecordingRuleCandidate) Check(ctx *AnalysisContext) []Finding {
+	minRange := r.minRange()
+	groups := newExprGroups()
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			// Grafana duration variables are substituted with a short
+			// placeholder window before parsing, so their range is unknown.
+			if strings.Contains(target.Expr, "$__") {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || !strings.HasSuffix(call.Func.Name, "_over_time") {
+					return nil
+				}
+				if overTimeRange(call) > minRange {
+					groups.add(call.String(), panel)
+				}
+				return nil
+			})
+		}
+	}
+
+	var findings []Finding
+	for _, g := range groups.shared(r.minPanels()) {
+		findings = append(findings, Finding{
+			RuleID:      "Q21",
+			Severity:    Medium,
+			PanelIDs:    g.ids,
+			PanelTitles: g.titles,
+			Title:       "Long *_over_time() repeated across panels",
+			Why:         fmt.Sprintf("%s appears in %d panels (%s). Each copy re-reads every raw sample in its window on every refresh.", truncateQuery(g.expr, 80), len(g.ids), strings.Join(g.titles, ", ")),
+			Fix:         "Precompute the expression with a Prometheus recording rule and query the recorded series in these panels.",
+			Impact:      fmt.Sprintf("Replaces %d long-window scans per refresh with reads of one precomputed series", len(g.ids)),
+			Validate:    "Compare the recorded series against the original expression over the same range",
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+	return findings
+}
+
+// overTimeRange returns the window of an *_over_time() call: the range of
+// its matrix selector or subquery argument, or zero if it has neither.
+func overTimeRange(call *parser.Call) time.Duration {
+	for _, arg := range call.Args {
+		switch a := arg.(type) {
+		case *parser.MatrixSelector:
+			return a.Range
+		case *parser.SubqueryExpr:
+			return a.Range
+		}
+	}
+	return 0
+}
@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// knownInfoMetrics lists metadata series whose value is always 1 but whose
+// name does not end in _info.
+var knownInfoMetrics = map[string]bool{
+	"kube_pod_labels":        true,
+	"kube_pod_owner":         true,
+	"kube_node_labels":       true,
+	"kube_namespace_labels":  true,
+	"kube_deployment_labels": true,
+}
+
+// infoMisuseAggregations are the aggregations that treat an info metric's
+// constant 1 as a measurement. count() is the idiomatic way to count info
+// series and is not flagged.
+var infoMisuseAggregations = map[string]bool{
+	"sum": true,
+	"avg": true,
+}
+
+// InfoMetricAggregation detects info-style metrics (value always 1, e.g.
+// kube_pod_info) used directly under rate()/irate()/increase() or sum()/avg()
+// without first being joined onto another series. Info metrics carry
+// metadata in their labels and are meant for joins with
+// "* on(...) group_left(...)"; their rate is always 0 and their average
+// always 1.
+type InfoMetricAggregation struct{}
+
+func (r *InfoMetricAggregation) ID() string             { return "Q22" }
+func (r *InfoMetricAggregation) RuleSeverity() Severity { return Low }
+
+func (r *InfoMetricAggregation) Describe() Description {
+	return Description{
+		Title:       "Info metric aggregated instead of joined",
+		Summary:     "Info-style metrics (value always 1) used directly under rate-like functions or sum()/avg().",
+		Rationale:   "Info metrics carry metadata in labels; their rate is always 0 and their average always 1, so the panel shows nothing useful.",
+		Bad:         `sum(kube_pod_info{namespace="prod"})`,
+		Good:        `sum by (node) (kube_pod_container_resource_requests{namespace="prod"} * on(pod) group_left(node) kube_pod_info)`,
+		AutoFixable: false,
+	}
+}
+
+func (r *InfoMetricAggregation) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		flagged := make(map[string]bool)
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+				vs, ok := node.(*parser.VectorSelector)
+				if !ok {
+					return nil
+				}
+				name := extractMetricName(vs)
+				if !isInfoMetric(name) || flagged[name] {
+					return nil
+				}
+				op := infoMisuse(path)
+				if op == "" {
+					return nil
+				}
+				flagged[name] = true
+				findings = append(findings, Finding{
+					RuleID:      "Q22",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Info metric aggregated instead of joined",
+					Why:         fmt.Sprintf("%s is an info metric (value always 1) used under %s() without a join. Its value carries no measurement, so the result only reflects how many series matched.", name, op),
+					Fix:         fmt.Sprintf("Join it onto a real metric to attach its labels, e.g. <metric> * on(<labels>) group_left(<info labels>) %s, or use count() to count series.", name),
+					Impact:      "Panel shows a meaningful measurement instead of a constant or a series count",
+					Validate:    "Check the panel values change with the underlying workload, not just with the number of series",
+					AutoFixable: false,
+					Confidence:  0.6,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// isInfoMetric reports whether name follows the info-metric convention.
+func isInfoMetric(name string) bool {
+	return strings.HasSuffix(name, "_info") || knownInfoMetrics[name]
+}
+
+// infoMisuse walks path from the selector outwards and returns the name of
+// the first rate-like function or sum/avg aggregation applied to it. It
+// returns "" if a vector-matching binary operation (a join) comes first.
+func infoMisuse(path []parser.Node) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		switch n := path[i].(type) {
+		case *parser.BinaryExpr:
+			if n.VectorMatching != nil {
+				return ""
+			}
+		case *parser.Call:
+			if rateFuncsForInterval[n.Func.Name] {
+				return n.Func.Name
+			}
+		case *parser.AggregateExpr:
+			if infoMisuseAggregations[n.Op.String()] {
+				return n.Op.String()
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// QuantileWindowMismatch detects histogram_quantile() expressions over the
+// same bucket metric that use different rate windows, e.g. a p50 panel over
+// [5m] next to a p99 panel over [1h]. The quantiles then describe different
+// periods and cannot be compared. Targets using Grafana duration variables
+// are skipped because their parsed window is a placeholder.
+type QuantileWindowMismatch struct{}
+
+func (r *QuantileWindowMismatch) ID() string            { return "Q23" }
+func (r *QuantileWindowMismatch) RuleSeverity() Severity { return Medium }
+
+func (r *QuantileWindowMismatch) Describe() Description {
+	return Description{
+		Title:       "Inconsistent rate windows across quantiles",
+		Summary:     "histogram_quantile() over the same bucket metric with different rate windows on one dashboard.",
+		Rationale:   "Quantiles over different windows describe different periods, so p50 and p99 panels side by side cannot be compared.",
+		Bad:         "p50 over rate(..._bucket[5m]) next to p99 over rate(..._bucket[1h])",
+		Good:        `both quantiles over rate(http_request_duration_seconds_bucket{job="api"}[$__rate_interval])`,
+		AutoFixable: false,
+	}
+}
+
+// quantileUse is one histogram_quantile() over a bucket metric.
+type quantileUse struct {
+	panelID    int
+	panelTitle string
+	window     time.Duration
+}
+
+func (r *QuantileWindowMismatch) Check(ctx *AnalysisContext) []Finding {
+	var metrics []string
+	uses := make(map[string][]quantileUse)
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if strings.Contains(target.Expr, "$__") {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || call.Func.Name != "histogram_quantile" || len(call.Args) < 2 {
+					return nil
+				}
+				metric, window := ratedBucketWindow(call.Args[1])
+				if metric == "" {
+					return nil
+				}
+				if _, ok := uses[metric]; !ok {
+					metrics = append(metrics, metric)
+				}
+				uses[metric] = append(uses[metric], quantileUse{panel.ID, panel.Title, window})
+				return nil
+			})
+		}
+	}
+
+	var findings []Finding
+	for _, metric := range metrics {
+		group := uses[metric]
+		windows := make(map[time.Duration]bool)
+		for _, u := range group {
+			windows[u.window] = true
+		}
+		if len(windows) < 2 {
+			continue
+		}
+
+		seen := make(map[int]bool)
+		var ids []int
+		var titles, details []string
+		for _, u := range group {
+			details = append(details, fmt.Sprintf("%q uses [%s]", u.panelTitle, u.window))
+			if !seen[u.panelID] {
+				seen[u.panelID] = true
+				ids = append(ids, u.panelID)
+				titles = append(titles, u.panelTitle)
+			}
+		}
+		findings = append(findings, Finding{
+			RuleID:      "Q23",
+			Severity:    Medium,
+			PanelIDs:    ids,
+			PanelTitles: titles,
+			Title:       "Inconsistent rate windows across quantiles",
+			Why:         fmt.Sprintf("histogram_quantile() over %s uses %d different rate windows: %s. Each quantile describes a different period, so comparing them (e.g. p50 vs p99) is misleading.", metric, len(windows), strings.Join(details, ", ")),
+			Fix:         "Use the same rate window for every quantile of this histogram, ideally $__rate_interval.",
+			Impact:      "Quantile panels become directly comparable",
+			Validate:    "Check all quantile panels of the histogram use the same window and p50 ≤ p90 ≤ p99 holds",
+			AutoFixable: false,
+			Confidence:  0.8,
+		})
+	}
+	return findings
+}
+
+// ratedBucketWindow returns the _bucket metric under expr and the range of the
+// rate()/irate()/increase() applied to it. Returns "" if there is none.
+func ratedBucketWindow(expr parser.Expr) (string, time.Duration) {
+	var metric string
+	var window time.Duration
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		call, ok := node.(*parser.Call)
+		if metric != "" || !ok || !rateFuncsForInterval[call.Func.Name] || len(call.Args) == 0 {
+			return nil
+		}
+		ms, ok := call.Args[0].(*parser.MatrixSelector)
+		if !ok {
+			return nil
+		}
+		if name := extractMetricName(ms); strings.HasSuffix(name, "_bucket") {
+			metric, window = name, ms.Range
+		}
+		return nil
+	})
+	return metric, window
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// counterSuffixes are the metric name suffixes of counter series: plain
+// counters and the cumulative series of classic histograms and summaries.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// ResetsOnGauge detects resets() applied to metrics that are not counters.
+// resets() counts decreases, which for a counter mean a process restart. A
+// gauge goes down all the time, so resets() on a gauge just counts ordinary
+// fluctuations — usually changes() or a rate was intended. Metrics
+// classified in AnalysisContext.MetricTypes are judged by that
+// classification; the counter naming convention only covers the rest.
+type ResetsOnGauge struct{}
+
+func (r *ResetsOnGauge) ID() string            { return "Q24" }
+func (r *ResetsOnGauge) RuleSeverity() Severity { return Medium }
+
+func (r *ResetsOnGauge) Describe() Description {
+	return Description{
+		Title:       "resets() on gauge",
+		Summary:     "resets() applied to metrics that are not counters.",
+		Rationale:   "On a gauge, resets() counts ordinary decreases rather than process restarts.",
+		Bad:         `resets(node_memory_MemAvailable_bytes{job="node"}[1h])`,
+		Good:        `changes(node_memory_MemAvailable_bytes{job="node"}[1h])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *ResetsOnGauge) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || call.Func.Name != "resets" || len(call.Args) == 0 {
+					return nil
+				}
+				metricName := extractMetricName(call.Args[0])
+				if metricName == "" {
+					return nil
+				}
+				gauge, classified := !isCounterName(metricName), false
+				if t, ok := ctx.MetricTypes.Lookup(metricName); ok {
+					gauge, classified = t == MetricTypeGauge, true
+				}
+				if !gauge {
+					return nil
+				}
+				looks, confidence := "does not look like a counter", 0.7
+				if classified {
+					looks, confidence = "is classified as a gauge", 0.9
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q24",
+					Severity:    Medium,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "resets() on gauge",
+					Why:         fmt.Sprintf("resets() is applied to %q, which %s. resets() counts every decrease, so on a gauge it counts ordinary fluctuations rather than process restarts.", metricName, looks),
+					Fix:         fmt.Sprintf("Use changes(%s[...]) to count value changes, or deriv()/delta() for the trend of a gauge.", metricName),
+					Impact:      "Panel shows a meaningful value instead of a count of normal decreases",
+					Validate:    "Confirm the metric type in the exporter's /metrics output (# TYPE line)",
+					AutoFixable: false,
+					Confidence:  confidence,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// isCounterName reports whether name follows the counter naming convention.
+func isCounterName(name string) bool {
+	for _, suffix := range counterSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
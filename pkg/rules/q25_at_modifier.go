@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// AtModifier detects the @ modifier (x @ end(), x @ 1700000000) on selectors
+// and subqueries. Pinning evaluation to a fixed time makes the result
+// depend on the whole query range rather than on each step, so a query
+// frontend cannot split the query by step interval and reuse cached
+// results. The effect depends on the frontend, so confidence is low.
+type AtModifier struct{}
+
+func (r *AtModifier) ID() string            { return "Q25" }
+func (r *AtModifier) RuleSeverity() Severity { return Low }
+
+func (r *AtModifier) Describe() Description {
+	return Description{
+		Title:       "@ modifier may defeat query-frontend caching",
+		Summary:     "The @ modifier on selectors and subqueries.",
+		Rationale:   "Pinning evaluation time ties every step to the whole query range, so a query frontend cannot split the query by interval or reuse cached results.",
+		Bad:         `rate(http_requests_total{job="api"}[5m] @ end())`,
+		Good:        `rate(http_requests_total{job="api"}[5m])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *AtModifier) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				var at string
+				switch n := node.(type) {
+				case *parser.VectorSelector:
+					at = atModifierString(n.Timestamp, n.StartOrEnd)
+				case *parser.SubqueryExpr:
+					at = atModifierString(n.Timestamp, n.StartOrEnd)
+				}
+				if at == "" {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q25",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "@ modifier may defeat query-frontend caching",
+					Why:         fmt.Sprintf("The query uses @ %s, which pins evaluation to a fixed time. The result of each step then depends on the query range, so a query frontend may not split or cache it by step.", at),
+					Fix:         "Remove the @ modifier if the panel does not need a fixed evaluation time, or accept the uncached cost for this panel.",
+					Impact:      "Lets the query frontend cache and split the query like any other range query",
+					Validate:    "Compare query-frontend cache hit rate for this query before/after",
+					AutoFixable: false,
+					Confidence:  0.4,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// atModifierString renders an @ modifier as written ("end()", "start()" or a
+// Unix timestamp), or returns "" if there is none.
+func atModifierString(ts *int64, startOrEnd parser.ItemType) string {
+	switch {
+	case startOrEnd == parser.START:
+		return "start()"
+	case startOrEnd == parser.END:
+		return "end()"
+	case ts != nil:
+		return fmt.Sprintf("%.3f", float64(*ts)/1000)
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// BucketGroupingExplosion detects aggregations that keep le alongside a
+// high-cardinality label, e.g. sum by(le, pod). Keeping le is required for
+// histogram_quantile and heatmaps, but every extra label multiplies the
+// bucket count: 12 buckets × 3000 pods is 36000 output series. This is a
+// narrower, more costly case of Q4.
+type BucketGroupingExplosion struct{}
+
+func (r *BucketGroupingExplosion) ID() string            { return "Q26" }
+func (r *BucketGroupingExplosion) RuleSeverity() Severity { return High }
+
+func (r *BucketGroupingExplosion) Describe() Description {
+	return Description{
+		Title:       "Histogram buckets grouped by high-cardinality label",
+		Summary:     "Aggregations keeping le together with a high-cardinality label.",
+		Rationale:   "Every extra label multiplies the bucket count: 12 buckets × 3000 pods is 36000 output series.",
+		Bad:         `sum by (le, pod) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		Good:        `sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *BucketGroupingExplosion) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				agg, ok := node.(*parser.AggregateExpr)
+				if !ok || agg.Without {
+					return nil
+				}
+				hasLe := false
+				var highCard []string
+				for _, lbl := range agg.Grouping {
+					if lbl == "le" {
+						hasLe = true
+					} else if highCardinalityLabels[lbl] {
+						highCard = append(highCard, lbl)
+					}
+				}
+				if !hasLe || len(highCard) == 0 {
+					return nil
+				}
+
+				confidence := 0.85
+				why := fmt.Sprintf("Aggregation keeps le together with %s, which is typically high-cardinality. Every histogram bucket is returned once per label value, multiplying the output series.", strings.Join(highCard, ", "))
+				if ctx.Cardinality != nil {
+					if valCount := ctx.Cardinality.LabelCardinality(highCard[0], 0); valCount > 0 {
+						confidence = 0.95
+						why = fmt.Sprintf("Aggregation keeps le together with %s. %q has %d distinct values, so every histogram bucket is returned up to %d times.", strings.Join(highCard, ", "), highCard[0], valCount, valCount)
+					}
+				}
+
+				findings = append(findings, Finding{
+					RuleID:      "Q26",
+					Severity:    High,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Histogram buckets grouped by high-cardinality label",
+					Why:         why,
+					Fix:         fmt.Sprintf("Group by le only (or le plus a low-cardinality label such as job or namespace), and drill into %s on a separate panel filtered by a variable.", strings.Join(highCard, ", ")),
+					Impact:      "Cuts the number of bucket series the quantile or heatmap has to process",
+					Validate:    "Query Inspector → Stats tab → check result series count before/after",
+					AutoFixable: false,
+					Confidence:  confidence,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
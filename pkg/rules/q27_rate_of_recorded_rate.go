@@ -0,0 +1,90 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// recordedRateOps are the operation prefixes that mark a recording rule as
+// already holding a per-second rate or an increase (rate5m, irate1m,
+// increase1h).
+var recordedRateOps = []string{"rate", "irate", "increase"}
+
+// RateOfRecordedRate detects rate-like functions applied to recording rule
+// outputs that already contain a rate, e.g. rate(job:http_requests:rate5m[5m]).
+// The recorded series is a gauge-like per-second value, so rating it again
+// yields the rate of change of the rate, which is near zero and meaningless.
+type RateOfRecordedRate struct{}
+
+func (r *RateOfRecordedRate) ID() string            { return "Q27" }
+func (r *RateOfRecordedRate) RuleSeverity() Severity { return Medium }
+
+func (r *RateOfRecordedRate) Describe() Description {
+	return Description{
+		Title:       "Rate applied to a recorded rate",
+		Summary:     "Rate-like functions over recording rule outputs that already contain a rate.",
+		Rationale:   "The recorded series is already per-second; rating it again yields the change of the rate, which is near zero.",
+		Bad:         `rate(job:http_requests:rate5m{job="api"}[5m])`,
+		Good:        `job:http_requests:rate5m{job="api"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RateOfRecordedRate) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || !rateFuncNames[call.Func.Name] || len(call.Args) == 0 {
+					return nil
+				}
+				metricName := extractMetricName(call.Args[0])
+				if !isRecordedRate(metricName) {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q27",
+					Severity:    Medium,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Rate applied to a recorded rate",
+					Why:         fmt.Sprintf("%s() is applied to %q, a recording rule whose name says it already holds a rate or increase. Rating it again computes the change of the rate, not the rate itself.", call.Func.Name, metricName),
+					Fix:         fmt.Sprintf("Query %s directly (aggregate it with sum/avg if needed) instead of wrapping it in %s().", metricName, call.Func.Name),
+					Impact:      "Panel shows the recorded rate instead of a near-zero derivative",
+					Validate:    "Compare the panel with the raw recording rule series in Explore",
+					AutoFixable: false,
+					Confidence:  0.8,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// isRecordedRate reports whether name follows the level:metric:operations
+// recording rule convention with a rate or increase in its operations part.
+// Only the segment after the last colon is checked, so metric names such as
+// job:rate_limited_requests:sum are not mistaken for rates.
+func isRecordedRate(name string) bool {
+	i := strings.LastIndex(name, ":")
+	if i < 0 {
+		return false
+	}
+	for _, op := range strings.Split(name[i+1:], "_") {
+		for _, prefix := range recordedRateOps {
+			if strings.HasPrefix(op, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
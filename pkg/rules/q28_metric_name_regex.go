@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dashboard-advisor/pkg/rewrite"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// MetricNameRegex detects selectors that pick metrics by a broad regex on
+// __name__, such as {__name__=~"node_.*"}. The metric name is the most
+// selective index entry; a prefix or wildcard regex on it has to be checked
+// against every metric name and typically pulls in dozens of unrelated
+// metrics. Alternations of literal names (a|b) are not flagged.
+type MetricNameRegex struct{}
+
+func (r *MetricNameRegex) ID() string            { return "Q28" }
+func (r *MetricNameRegex) RuleSeverity() Severity { return High }
+
+func (r *MetricNameRegex) Describe() Description {
+	return Description{
+		Title:       "Broad metric name regex",
+		Summary:     "Selectors that choose metrics with a broad regex on __name__.",
+		Rationale:   "The metric name is the most selective index entry; a wildcard on it is checked against every metric name and pulls in unrelated metrics.",
+		Bad:         `{__name__=~"node_.*", job="node"}`,
+		Good:        `node_load1{job="node"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *MetricNameRegex) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				vs, ok := node.(*parser.VectorSelector)
+				if !ok {
+					return nil
+				}
+				for _, m := range vs.LabelMatchers {
+					if m.Name != "__name__" || m.Type != labels.MatchRegexp || !isBroadNameRegex(m.Value) {
+						continue
+					}
+
+					confidence := 0.8
+					why := fmt.Sprintf("The selector matches metric names by regex __name__=~%q, which is checked against every metric name and usually selects many unrelated metrics.", m.Value)
+					if ctx.Cardinality != nil {
+						if metrics, series := matchingMetricSeries(ctx.Cardinality.SeriesByMetric, m.Value); metrics > 0 {
+							confidence = 0.95
+							why = fmt.Sprintf("The selector matches metric names by regex __name__=~%q, which selects at least %d metrics with %d active series among those reported by the TSDB status API.", m.Value, metrics, series)
+						}
+					}
+
+					findings = append(findings, Finding{
+						RuleID:      "Q28",
+						Severity:    High,
+						PanelIDs:    []int{panel.ID},
+						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
+						Title:       "Broad metric name regex",
+						Why:         why,
+						Fix:         "Select the specific metrics the panel needs by name (one query per metric, or an alternation of exact names) instead of a name prefix or wildcard.",
+						Impact:      "Limits the query to the intended metrics instead of every metric sharing the prefix",
+						Validate:    "Query Inspector → Stats tab → compare 'Series fetched' before/after",
+						AutoFixable: false,
+						Confidence:  confidence,
+					})
+				}
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// isBroadNameRegex reports whether a __name__ regex is a bare wildcard or a
+// literal prefix followed by .* or .+ (node_.*). Literals (Q3) and
+// alternations of exact names are not broad.
+func isBroadNameRegex(value string) bool {
+	if strings.HasPrefix(value, ".*") || strings.HasPrefix(value, ".+") {
+		return true
+	}
+	if strings.HasSuffix(value, ".*") || strings.HasSuffix(value, ".+") {
+		return !rewrite.ContainsRegexMeta(value[:len(value)-2])
+	}
+	return false
+}
+
+// matchingMetricSeries returns how many metric names in seriesByMetric the
+// fully-anchored regex matches and their summed series count.
+func matchingMetricSeries(seriesByMetric map[string]int, pattern string) (metrics, series int) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return 0, 0
+	}
+	for name, count := range seriesByMetric {
+		if re.MatchString(name) {
+			metrics++
+			series += count
+		}
+	}
+	return metrics, series
+}
@@ -0,0 +1,84 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// VectorZeroFallback detects `... or vector(0)` (including `or on() vector(0)`).
+// The fallback keeps stat panels from showing "No data" when a counter has
+// no increments yet, which is sometimes what you want. But it also turns a
+// missing target, a broken scrape or a typo in the selector into a
+// confident 0, hiding exactly the outages an ops dashboard should surface.
+// The trade-off is legitimate often enough that the rule is Low.
+type VectorZeroFallback struct{}
+
+func (r *VectorZeroFallback) ID() string            { return "Q29" }
+func (r *VectorZeroFallback) RuleSeverity() Severity { return Low }
+
+func (r *VectorZeroFallback) Describe() Description {
+	return Description{
+		Title:       "or vector(0) masks missing data",
+		Summary:     "Targets with an or vector(0) fallback.",
+		Rationale:   "The fallback turns a missing target, broken scrape or selector typo into a confident 0, hiding outages.",
+		Bad:         `sum(rate(http_errors_total{job="api"}[5m])) or vector(0)`,
+		Good:        `sum(rate(http_errors_total{job="api"}[5m])), with "No data" mapped to 0 in the panel if needed`,
+		AutoFixable: false,
+	}
+}
+
+func (r *VectorZeroFallback) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				bin, ok := node.(*parser.BinaryExpr)
+				if !ok || bin.Op != parser.LOR || !isVectorZero(bin.RHS) {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q29",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "or vector(0) masks missing data",
+					Why:         fmt.Sprintf("The query falls back to vector(0) when %s returns nothing. That hides \"No data\" for quiet counters, but also shows 0 when the target is down, the scrape fails or the selector matches nothing.", truncateQuery(bin.LHS.String(), 80)),
+					Fix:         "Drop the fallback and let the panel show \"No data\" (or set a No value text in the panel options), or keep it only on panels where an absent series really means zero.",
+					Impact:      "Missing targets and broken selectors show up instead of reading as a healthy zero",
+					Validate:    "Stop the target (or break the selector) and confirm the panel no longer shows 0",
+					AutoFixable: false,
+					Confidence:  0.5,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// isVectorZero reports whether expr is vector(0), ignoring parentheses.
+func isVectorZero(expr parser.Expr) bool {
+	call, ok := unwrapParens(expr).(*parser.Call)
+	if !ok || call.Func.Name != "vector" || len(call.Args) != 1 {
+		return false
+	}
+	n, ok := unwrapParens(call.Args[0]).(*parser.NumberLiteral)
+	return ok && n.Val == 0
+}
+
+// unwrapParens strips any number of enclosing parentheses from expr.
+func unwrapParens(expr parser.Expr) parser.Expr {
+	for {
+		p, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = p.Expr
+	}
+}
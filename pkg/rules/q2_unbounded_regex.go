@@ -16,6 +16,17 @@ type UnboundedRegex struct{}
 func (r *UnboundedRegex) ID() string            { return "Q2" }
 func (r *UnboundedRegex) RuleSeverity() Severity { return High }
 
+func (r *UnboundedRegex) Describe() Description {
+	return Description{
+		Title:       "Unbounded regex matcher",
+		Summary:     "Label matchers whose regex is unanchored or matches almost anything (.*foo, foo.*bar, .+).",
+		Rationale:   "Prometheus must run the regex against every value of the label in the index, which is slow for high-cardinality labels and rarely narrows the selection.",
+		Bad:         `rate(http_requests_total{path=~".*api.*"}[5m])`,
+		Good:        `rate(http_requests_total{path=~"/api/(users|orders)"}[5m])`,
+		AutoFixable: false,
+	}
+}
+
 func (r *UnboundedRegex) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -45,6 +56,7 @@ func (r *UnboundedRegex) Check(ctx *AnalysisContext) []Finding {
 						Severity:    High,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "Unbounded regex matcher",
 						Why:         fmt.Sprintf("Label %q uses regex =~%q — %s. This can force a full scan of all label values.", m.Name, m.Value, reason),
 						Fix:         fmt.Sprintf("Rewrite the regex for %s to be more specific, e.g. use a prefix match or equality.", m.Name),
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// BoolComparisonOnTimeSeries detects comparisons with the bool modifier
+// (up == bool 1) on time-series panels. The result is a 0/1 line: alerting
+// logic evaluated inline on every refresh, and a graph that is hard to read.
+// A threshold check belongs in an alert rule, and a current up/down state
+// reads better as a stat or state-timeline panel with value mappings.
+type BoolComparisonOnTimeSeries struct{}
+
+func (r *BoolComparisonOnTimeSeries) ID() string            { return "Q30" }
+func (r *BoolComparisonOnTimeSeries) RuleSeverity() Severity { return Low }
+
+func (r *BoolComparisonOnTimeSeries) Describe() Description {
+	return Description{
+		Title:       "Alert-style bool comparison on time-series panel",
+		Summary:     "Comparisons with the bool modifier on time-series panels.",
+		Rationale:   "A 0/1 line is alerting logic re-evaluated on every refresh and reads poorly as a graph.",
+		Bad:         `up{job="api"} == bool 1`,
+		Good:        `up{job="api"} in a stat or state-timeline panel with value mappings, or an alert rule`,
+		AutoFixable: false,
+	}
+}
+
+func (r *BoolComparisonOnTimeSeries) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if !timeSeriesPanelTypes[panel.Type] {
+			continue
+		}
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			var cmp *parser.BinaryExpr
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				if bin, ok := node.(*parser.BinaryExpr); ok && bin.ReturnBool && cmp == nil {
+					cmp = bin
+				}
+				return nil
+			})
+			if cmp == nil {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q30",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Alert-style bool comparison on time-series panel",
+				Why:         fmt.Sprintf("The query compares with %s bool, turning the series into a 0/1 signal. That is alerting logic re-evaluated on every dashboard refresh and drawn as a hard-to-read step line.", cmp.Op),
+				Fix:         "Move the threshold into an alert rule, or show the current state in a stat or state-timeline panel with value mappings and thresholds instead of a bool comparison.",
+				Impact:      "Alert logic is evaluated once by the rule evaluator, and the panel shows the underlying value or a readable state",
+				Validate:    "Check an alert rule covers the condition, then confirm the panel shows the raw value with thresholds",
+				AutoFixable: false,
+				Confidence:  0.6,
+			})
+		}
+	}
+	return findings
+}
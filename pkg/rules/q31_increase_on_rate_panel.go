@@ -0,0 +1,92 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// perSecondLabelRe matches panel titles and legends that promise a
+// per-second value: "req/s", "per second", "RPS", "Request rate".
+var perSecondLabelRe = regexp.MustCompile(`(?i)/\s*s(ec)?\b|per[ -]?sec(ond)?\b|\b[rqo]ps\b|\brate\b`)
+
+// IncreaseOnRatePanel detects increase() on time-series panels whose title
+// or legend reads as a per-second rate. increase(x[5m]) is the count over
+// the whole window, so drawn as "per second" it is off by the window length
+// in seconds (300× for 5m). The title and legend are only a hint about the
+// intended unit, hence the low confidence.
+type IncreaseOnRatePanel struct{}
+
+func (r *IncreaseOnRatePanel) ID() string            { return "Q31" }
+func (r *IncreaseOnRatePanel) RuleSeverity() Severity { return Low }
+
+func (r *IncreaseOnRatePanel) Describe() Description {
+	return Description{
+		Title:       "increase() on a per-second panel",
+		Summary:     "increase() on time-series panels whose title or legend reads as a per-second rate.",
+		Rationale:   "increase(x[5m]) is the count over the window, so read as per-second it is off by the window length in seconds.",
+		Bad:         `Panel "Requests/s": sum(increase(http_requests_total{job="api"}[5m]))`,
+		Good:        `Panel "Requests/s": sum(rate(http_requests_total{job="api"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *IncreaseOnRatePanel) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if !timeSeriesPanelTypes[panel.Type] {
+			continue
+		}
+		titleHint := perSecondLabelRe.MatchString(panel.Title)
+		for _, target := range panel.Targets {
+			if !titleHint && !perSecondLabelRe.MatchString(target.LegendFormat) {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			call := increaseOutsideRatio(expr)
+			if call == nil {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q31",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "increase() on a per-second panel",
+				Why:         fmt.Sprintf("The panel's title or legend suggests a per-second rate, but the query uses %s, which is the total count over the window, not a per-second value. Read as a rate, it is off by the window length in seconds.", call),
+				Fix:         "Use rate() for a per-second value, or keep increase() and retitle the panel as a count per window (e.g. \"Requests per 5m\").",
+				Impact:      "The graph shows the unit its title promises",
+				Validate:    "Compare the panel against rate() of the same selector; the values should agree after the change",
+				AutoFixable: false,
+				Confidence:  0.4,
+			})
+		}
+	}
+	return findings
+}
+
+// increaseOutsideRatio returns the first increase() call that is not under a
+// division. increase(a[5m]) / increase(b[5m]) is a ratio, where the window
+// cancels out, so it is not a unit mistake.
+func increaseOutsideRatio(expr parser.Expr) *parser.Call {
+	var found *parser.Call
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		call, ok := node.(*parser.Call)
+		if !ok || call.Func.Name != "increase" || found != nil {
+			return nil
+		}
+		for _, anc := range path {
+			if bin, ok := anc.(*parser.BinaryExpr); ok && bin.Op == parser.DIV {
+				return nil
+			}
+		}
+		found = call
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,134 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RateMixedWithRawCounter detects arithmetic between a rate-like call and a
+// bare counter, e.g. rate(errors_total[5m]) / requests_total. The rate side
+// is a per-second (or per-window) change while the raw side is a cumulative
+// total since process start, so the result has no meaningful unit and drifts
+// towards zero as the counter grows.
+type RateMixedWithRawCounter struct{}
+
+func (r *RateMixedWithRawCounter) ID() string            { return "Q32" }
+func (r *RateMixedWithRawCounter) RuleSeverity() Severity { return Medium }
+
+func (r *RateMixedWithRawCounter) Describe() Description {
+	return Description{
+		Title:       "rate() combined with a raw counter",
+		Summary:     "Arithmetic between rate()/irate()/increase() and a bare _total counter.",
+		Rationale:   "The raw counter is a total since process start, so the result mixes units and decays as the counter grows.",
+		Bad:         `rate(http_errors_total{job="api"}[5m]) / http_requests_total{job="api"}`,
+		Good:        `rate(http_errors_total{job="api"}[5m]) / rate(http_requests_total{job="api"}[5m])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RateMixedWithRawCounter) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			rateCall, counter := rateAndRawCounter(expr)
+			if rateCall == nil {
+				continue
+			}
+			subject := rateCall.Func.Name + "()"
+			if name := extractMetricName(rateCall); name != "" {
+				subject += fmt.Sprintf(" of %q", name)
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q32",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "rate() combined with a raw counter",
+				Why:         fmt.Sprintf("%s is combined with the raw counter %q. The counter is a cumulative total since the process started, not a change over the window, so the result mixes units and shrinks as the counter grows.", subject, counter),
+				Fix:         fmt.Sprintf("Wrap the counter in the same function and window, e.g. %s(%s[5m]).", rateCall.Func.Name, counter),
+				Impact:      "The expression returns a meaningful ratio or sum instead of a value that decays over time",
+				Validate:    "Compare the panel before/after — the value should stay stable across counter resets and long uptimes",
+				AutoFixable: false,
+				Confidence:  0.7,
+			})
+		}
+	}
+	return findings
+}
+
+// rateAndRawCounter returns the rate-like call and counter name of the first
+// arithmetic BinaryExpr in expr with a rate()/irate()/increase() on one side
+// and a bare _total selector on the other. Comparisons and set operators
+// are skipped: they filter series rather than combine their values.
+func rateAndRawCounter(expr parser.Expr) (*parser.Call, string) {
+	var rateCall *parser.Call
+	var counter string
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		bin, ok := node.(*parser.BinaryExpr)
+		if !ok || rateCall != nil || bin.Op.IsComparisonOperator() || bin.Op.IsSetOperator() {
+			return nil
+		}
+		for _, sides := range [][2]parser.Expr{{bin.LHS, bin.RHS}, {bin.RHS, bin.LHS}} {
+			call := rateLikeCall(sides[0])
+			name := rawCounterName(sides[1])
+			if call != nil && name != "" {
+				rateCall, counter = call, name
+				return nil
+			}
+		}
+		return nil
+	})
+	return rateCall, counter
+}
+
+// rateLikeCall returns the rate(), irate() or increase() call at the top of
+// expr, looking through parentheses and aggregations.
+func rateLikeCall(expr parser.Expr) *parser.Call {
+	call, ok := unwrapAggregation(expr).(*parser.Call)
+	if !ok {
+		return nil
+	}
+	switch call.Func.Name {
+	case "rate", "irate", "increase":
+		return call
+	}
+	return nil
+}
+
+// rawCounterName returns the metric name if expr, looking through
+// parentheses and aggregations, is a plain _total selector with no
+// function applied. The name may come from a __name__ matcher.
+func rawCounterName(expr parser.Expr) string {
+	vs, ok := unwrapAggregation(expr).(*parser.VectorSelector)
+	if !ok {
+		return ""
+	}
+	if name := extractMetricName(vs); strings.HasSuffix(name, "_total") {
+		return name
+	}
+	return ""
+}
+
+// unwrapAggregation strips parentheses, step-invariant wrappers and
+// aggregations (sum by (job) (...)) from expr.
+func unwrapAggregation(expr parser.Expr) parser.Expr {
+	for {
+		switch n := expr.(type) {
+		case *parser.ParenExpr:
+			expr = n.Expr
+		case *parser.StepInvariantExpr:
+			expr = n.Expr
+		case *parser.AggregateExpr:
+			expr = n.Expr
+		default:
+			return expr
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// smoothingOverTimeFuncs are the *_over_time functions that average or sum
+// their window. max/min/quantile_over_time over a rate subquery pick a peak
+// from the rate series, which is a legitimate use and is not reported.
+var smoothingOverTimeFuncs = map[string]bool{
+	"avg_over_time": true,
+	"sum_over_time": true,
+}
+
+// DoubleSmoothingSubquery detects avg_over_time/sum_over_time applied to a
+// subquery whose inner expression is itself a rate-like call, e.g.
+// avg_over_time(rate(x[5m])[1h:]). rate() already averages over its window,
+// so the outer function smooths the result a second time, and the subquery
+// evaluates the inner rate() once per subquery step.
+type DoubleSmoothingSubquery struct{}
+
+func (r *DoubleSmoothingSubquery) ID() string            { return "Q33" }
+func (r *DoubleSmoothingSubquery) RuleSeverity() Severity { return Medium }
+
+func (r *DoubleSmoothingSubquery) Describe() Description {
+	return Description{
+		Title:       "Double-smoothing subquery",
+		Summary:     "avg_over_time() or sum_over_time() over a subquery of rate()/irate()/increase().",
+		Rationale:   "rate() already averages over its window; smoothing it again via a subquery hides spikes and re-evaluates rate() at every subquery step.",
+		Bad:         `avg_over_time(rate(http_requests_total{job="api"}[5m])[1h:])`,
+		Good:        `rate(http_requests_total{job="api"}[1h])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *DoubleSmoothingSubquery) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				outer, inner, sq := doubleSmoothingCall(node)
+				if outer == nil {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q33",
+					Severity:    Medium,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Double-smoothing subquery",
+					Why:         fmt.Sprintf("%s() is applied to a %s subquery of %s(). %s() already averages over its own window, so the result is smoothed twice — hiding short spikes — and the subquery re-evaluates %s() at every step.", outer.Func.Name, model.Duration(sq.Range), inner.Func.Name, inner.Func.Name, inner.Func.Name),
+					Fix:         fmt.Sprintf("Query the counter over the longer window directly, e.g. %s(%s[%s]), or read a recording rule if the inner rate is needed elsewhere.", smoothingReplacement(outer), primaryMetricName(inner), model.Duration(sq.Range)),
+					Impact:      "Removes the subquery's per-step inner evaluations and returns the intended average",
+					Validate:    "Query Inspector → Stats tab → compare query time and samples before/after",
+					AutoFixable: false,
+					Confidence:  0.75,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// doubleSmoothingCall returns the outer smoothing call, the inner rate-like
+// call and the subquery between them if node is avg_over_time or
+// sum_over_time of a subquery whose expression is a rate-like call.
+// Parentheses and aggregations inside the subquery (sum(rate(x[5m]))[1h:])
+// are looked through; any other inner expression is not reported.
+func doubleSmoothingCall(node parser.Node) (*parser.Call, *parser.Call, *parser.SubqueryExpr) {
+	outer, ok := node.(*parser.Call)
+	if !ok || !smoothingOverTimeFuncs[outer.Func.Name] || len(outer.Args) == 0 {
+		return nil, nil, nil
+	}
+	arg := outer.Args[0]
+	for {
+		paren, ok := arg.(*parser.ParenExpr)
+		if !ok {
+			break
+		}
+		arg = paren.Expr
+	}
+	sq, ok := arg.(*parser.SubqueryExpr)
+	if !ok {
+		return nil, nil, nil
+	}
+	inner := rateLikeCall(sq.Expr)
+	if inner == nil {
+		return nil, nil, nil
+	}
+	return outer, inner, sq
+}
+
+// smoothingReplacement returns the single function equivalent to smoothing a
+// rate subquery with outer: an average of rates is a rate over the whole
+// window, a sum of them an increase.
+func smoothingReplacement(outer *parser.Call) string {
+	if outer.Func.Name == "sum_over_time" {
+		return "increase"
+	}
+	return "rate"
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// QuantileAggregation detects the quantile() aggregation (not
+// histogram_quantile) over an unfiltered or high-cardinality selector.
+// quantile() keeps the value of every input series in memory at every step
+// to sort them, and a quantile across series is rarely the latency
+// percentile the panel author wanted: that needs histogram_quantile() over
+// the metric's _bucket series.
+type QuantileAggregation struct {
+	// MaxSeries is the series count above which a filtered selector is
+	// still reported when cardinality data is available. Defaults to 10000
+	// if zero.
+	MaxSeries int
+}
+
+func (r *QuantileAggregation) ID() string            { return "Q34" }
+func (r *QuantileAggregation) RuleSeverity() Severity { return Medium }
+
+func (r *QuantileAggregation) Describe() Description {
+	return Description{
+		Title:       "quantile() over a large series set",
+		Summary:     "quantile() aggregations over an unfiltered selector, or over more than 10000 series with cardinality data.",
+		Rationale:   "quantile() sorts every input series in memory at every step, and a quantile across series is usually not the latency percentile that histogram_quantile() gives.",
+		Bad:         "quantile(0.99, http_request_duration_seconds)",
+		Good:        `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m])))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *QuantileAggregation) maxSeries() int {
+	if r.MaxSeries > 0 {
+		return r.MaxSeries
+	}
+	return 10000
+}
+
+func (r *QuantileAggregation) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				agg, ok := node.(*parser.AggregateExpr)
+				if !ok || agg.Op != parser.QUANTILE {
+					return nil
+				}
+				metricName := extractMetricFromInner(agg.Expr)
+				seriesCount := ctx.Cardinality.EstimatedSeries(metricName, 0)
+				var why string
+				switch {
+				case seriesCount > r.maxSeries():
+					why = fmt.Sprintf("quantile() aggregates %q, which has %d active series. Prometheus holds the value of every series in memory at every step to sort them.", metricName, seriesCount)
+				case hasUnfilteredSelector(agg.Expr):
+					why = fmt.Sprintf("quantile() aggregates %q without any label filters. Prometheus holds the value of every series of the metric in memory at every step to sort them.", metricName)
+				default:
+					return nil
+				}
+				why += " A quantile across series is also not a latency percentile: that needs histogram_quantile() over the metric's buckets."
+
+				findings = append(findings, Finding{
+					RuleID:      "Q34",
+					Severity:    Medium,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "quantile() over a large series set",
+					Why:         why,
+					Fix:         fmt.Sprintf("If %s is a histogram, use histogram_quantile(%s, sum by (le) (rate(%s_bucket{...}[5m]))). Otherwise add label matchers so quantile() sorts only the series the panel needs.", metricName, agg.Param, metricName),
+					Impact:      "Reduces the series held and sorted in memory at every step",
+					Validate:    "Query Inspector → Stats tab → compare 'Series fetched' and query time before/after",
+					AutoFixable: false,
+					Confidence:  0.7,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,55 @@
+package rules
+
+import "regexp"
+
+// intervalMsArithmeticRe matches $__interval_ms (or ${__interval_ms}) as an
+// operand of +, -, * or /.
+var intervalMsArithmeticRe = regexp.MustCompile(`[-+*/]\s*\$\{?__interval_ms\}?|\$\{?__interval_ms\}?\s*[-+*/]`)
+
+// IntervalMsArithmetic detects expressions doing arithmetic with
+// $__interval_ms, typically a hand-rolled rate such as
+// increase(x[$__interval]) / $__interval_ms * 1000. The result is only a
+// per-second rate while the range and the divisor agree, and $__interval can
+// be shorter than the scrape interval, leaving gaps. The check is on the raw
+// expression: the variable is gone once the expression is parsed.
+type IntervalMsArithmetic struct{}
+
+func (r *IntervalMsArithmetic) ID() string            { return "Q35" }
+func (r *IntervalMsArithmetic) RuleSeverity() Severity { return Low }
+
+func (r *IntervalMsArithmetic) Describe() Description {
+	return Description{
+		Title:       "$__interval_ms used in arithmetic",
+		Summary:     "Expressions that add, subtract, multiply or divide by $__interval_ms.",
+		Rationale:   "Normalizing by $__interval_ms by hand breaks when the range and divisor drift apart, and $__interval can be shorter than the scrape interval.",
+		Bad:         `increase(http_requests_total{job="api"}[$__interval]) / $__interval_ms * 1000`,
+		Good:        `rate(http_requests_total{job="api"}[$__rate_interval])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *IntervalMsArithmetic) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if !intervalMsArithmeticRe.MatchString(target.Expr) {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q35",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "$__interval_ms used in arithmetic",
+				Why:         "The expression normalizes by $__interval_ms by hand. The result is only correct while the range selector and the divisor use the same interval, and $__interval can be shorter than the scrape interval, so some steps have no samples.",
+				Fix:         "Let PromQL compute the per-second rate: replace increase(x[$__interval]) / $__interval_ms * 1000 with rate(x[$__rate_interval]).",
+				Impact:      "Correct per-second values at every zoom level, without gaps at short intervals",
+				Validate:    "Compare the panel before/after at a zoomed-in and a zoomed-out time range",
+				AutoFixable: false,
+				Confidence:  0.7,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RepeatedJoin detects the same vector-matching join — a binary expression
+// with on()/ignoring() or group_left/group_right, e.g.
+// a * on(instance) group_left(nodename) b — in several panels. Each copy
+// matches both sides series by series on every refresh, and keeping the
+// label lists in sync by hand is error-prone; a recording rule evaluates the
+// join once. Panels are grouped the way Q9 groups whole expressions, but by
+// the outermost join inside each target rather than the full expression.
+type RepeatedJoin struct {
+	// MinPanels is the number of distinct panels that must share the join.
+	// Defaults to 3 if zero.
+	MinPanels int
+}
+
+func (r *RepeatedJoin) ID() string            { return "Q36" }
+func (r *RepeatedJoin) RuleSeverity() Severity { return Medium }
+
+func (r *RepeatedJoin) Describe() Description {
+	return Description{
+		Title:       "Join repeated across panels",
+		Summary:     "The same on()/ignoring()/group_left join in three or more panels.",
+		Rationale:   "Each copy re-matches both sides on every refresh, and hand-copied label lists drift apart; a recording rule computes the join once.",
+		Bad:         `node_load1 * on(instance) group_left(nodename) node_uname_info in several panels`,
+		Good:        `record instance:node_load1:with_nodename once, and query it in each panel`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RepeatedJoin) minPanels() int {
+	if r.MinPanels > 0 {
+		return r.MinPanels
+	}
+	return 3
+}
+
+func (r *RepeatedJoin) Check(ctx *AnalysisContext) []Finding {
+	groups := newExprGroups()
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+				if !isJoin(node) {
+					return nil
+				}
+				for _, ancestor := range path {
+					if isJoin(ancestor) {
+						return nil
+					}
+				}
+				groups.add(node.String(), panel)
+				return nil
+			})
+		}
+	}
+
+	var findings []Finding
+	for _, g := range groups.shared(r.minPanels()) {
+		findings = append(findings, Finding{
+			RuleID:      "Q36",
+			Severity:    Medium,
+			PanelIDs:    g.ids,
+			PanelTitles: g.titles,
+			Title:       "Join repeated across panels",
+			Why:         fmt.Sprintf("The join %s appears in %d panels (%s). Each copy matches both sides series by series on every refresh, and the copied label lists must be kept in sync by hand.", truncateQuery(g.expr, 80), len(g.ids), strings.Join(g.titles, ", ")),
+			Fix:         "Precompute the join with a Prometheus recording rule and query the recorded series in these panels.",
+			Impact:      fmt.Sprintf("Replaces %d join evaluations per refresh with reads of one precomputed series", len(g.ids)),
+			Validate:    "Compare the recorded series against the original join over the same range",
+			AutoFixable: false,
+			Confidence:  0.7,
+		})
+	}
+	return findings
+}
+
+// isJoin reports whether node is a binary expression between two vectors
+// with explicit vector matching: on(), ignoring() with labels, or a
+// group_left/group_right modifier. Plain a / b with default one-to-one
+// matching is not a join for this purpose.
+func isJoin(node parser.Node) bool {
+	bin, ok := node.(*parser.BinaryExpr)
+	if !ok || bin.VectorMatching == nil {
+		return false
+	}
+	m := bin.VectorMatching
+	return m.On || len(m.MatchingLabels) > 0 || m.Card == parser.CardManyToOne || m.Card == parser.CardOneToMany
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// SumWithoutCount detects rate() or increase() over the _sum series of a
+// summary or histogram that is not divided by the matching _count series in
+// the same expression. rate(x_sum[5m]) alone is the total observed value per
+// second (e.g. seconds of latency per second), which depends on traffic and
+// is rarely what the panel means; the average observation is
+// rate(x_sum[5m]) / rate(x_count[5m]).
+type SumWithoutCount struct{}
+
+func (r *SumWithoutCount) ID() string            { return "Q37" }
+func (r *SumWithoutCount) RuleSeverity() Severity { return Low }
+
+func (r *SumWithoutCount) Describe() Description {
+	return Description{
+		Title:       "_sum rate without the matching _count",
+		Summary:     "rate() or increase() over a _sum series that is not divided by the matching _count.",
+		Rationale:   "The rate of a _sum grows with traffic; the average observation needs the division by the _count rate.",
+		Bad:         `rate(http_request_duration_seconds_sum{job="api"}[5m])`,
+		Good:        `rate(http_request_duration_seconds_sum{job="api"}[5m]) / rate(http_request_duration_seconds_count{job="api"}[5m])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *SumWithoutCount) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || (call.Func.Name != "rate" && call.Func.Name != "increase") {
+					return nil
+				}
+				name := primaryMetricName(call)
+				if !strings.HasSuffix(name, "_sum") {
+					return nil
+				}
+				countName := strings.TrimSuffix(name, "_sum") + "_count"
+				if dividedByMetric(path, countName) {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q37",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "_sum rate without the matching _count",
+					Why:         fmt.Sprintf("%s() of %q is not divided by %q. On its own it is the total observed value per second, which rises and falls with traffic rather than showing the typical observation.", call.Func.Name, name, countName),
+					Fix:         fmt.Sprintf("Divide by the matching count for the average: %s(%s[5m]) / %s(%s[5m]).", call.Func.Name, name, call.Func.Name, countName),
+					Impact:      "The panel shows the average observation instead of a traffic-dependent total",
+					Validate:    "Compare the panel against the average from the same histogram's buckets",
+					AutoFixable: false,
+					Confidence:  0.6,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// dividedByMetric reports whether any division among the ancestors in path
+// has a selector for metric anywhere in it, i.e. whether the node below
+// path takes part in an x_sum / x_count style ratio.
+func dividedByMetric(path []parser.Node, metric string) bool {
+	for _, ancestor := range path {
+		bin, ok := ancestor.(*parser.BinaryExpr)
+		if !ok || bin.Op != parser.DIV {
+			continue
+		}
+		found := false
+		parser.Inspect(bin, func(node parser.Node, _ []parser.Node) error {
+			if vs, ok := node.(*parser.VectorSelector); ok && vs.Name == metric {
+				found = true
+			}
+			return nil
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
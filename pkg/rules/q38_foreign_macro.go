@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultForeignMacros are Grafana macros of SQL, InfluxDB and Graphite
+// datasources that the Prometheus datasource does not expand.
+var defaultForeignMacros = []string{
+	"$timeFilter",
+	"$__timeFilter",
+	"$__timeGroup",
+	"$__timeGroupAlias",
+	"$__timeFrom",
+	"$__timeTo",
+	"$__unixEpochFilter",
+	"$__unixEpochGroup",
+	"$__unixEpochFrom",
+	"$__unixEpochTo",
+}
+
+// ForeignMacro detects Prometheus targets that contain macros of other
+// datasources, usually left behind when a panel was copied from an InfluxDB
+// or SQL dashboard. The Prometheus datasource does not expand them, so the
+// query is sent with the literal macro and fails or returns nothing. The
+// check is on the raw expression, against a configurable macro list.
+// Targets of other datasource types, where the macros are valid, are
+// skipped.
+type ForeignMacro struct {
+	// Macros is the list of macros to report, each with its leading $.
+	// Defaults to defaultForeignMacros if empty.
+	Macros []string
+}
+
+func (r *ForeignMacro) ID() string            { return "Q38" }
+func (r *ForeignMacro) RuleSeverity() Severity { return High }
+
+func (r *ForeignMacro) Describe() Description {
+	return Description{
+		Title:       "Non-Prometheus macro in PromQL",
+		Summary:     "Prometheus targets containing SQL/InfluxDB macros such as $timeFilter or $__timeGroup.",
+		Rationale:   "The Prometheus datasource does not expand them, so the query is likely broken: it fails to parse or matches nothing.",
+		Bad:         `rate(http_requests_total{job="api"}[5m]) and $timeFilter`,
+		Good:        `rate(http_requests_total{job="api"}[$__rate_interval])`,
+		AutoFixable: false,
+	}
+}
+
+func (r *ForeignMacro) macros() []string {
+	if len(r.Macros) > 0 {
+		return r.Macros
+	}
+	return defaultForeignMacros
+}
+
+func (r *ForeignMacro) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			if target.Expr == "" {
+				continue
+			}
+			if dsType := targetDatasourceType(panel, target); dsType != "" && dsType != "prometheus" {
+				continue
+			}
+			var found []string
+			for _, macro := range r.macros() {
+				if containsMacro(target.Expr, macro) {
+					found = append(found, macro)
+				}
+			}
+			if len(found) == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q38",
+				Severity:    High,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Non-Prometheus macro in PromQL",
+				Why:         fmt.Sprintf("The expression contains %s, a macro of SQL/InfluxDB datasources that the Prometheus datasource does not expand. The query is sent with the literal macro and most likely fails or returns no data.", strings.Join(found, ", ")),
+				Fix:         "Remove the macro. Prometheus queries are already limited to the dashboard time range; use $__rate_interval or $__interval for range selectors and $__range for whole-range aggregations.",
+				Impact:      "Turns a broken panel back into a working query",
+				Validate:    "Edit the panel → check the query returns data without errors",
+				AutoFixable: false,
+				Confidence:  0.9,
+			})
+		}
+	}
+	return findings
+}
+
+// containsMacro reports whether expr contains macro as a whole name, i.e.
+// not followed by another identifier character, so $__timeGroup does not
+// match inside $__timeGroupAlias.
+func containsMacro(expr, macro string) bool {
+	for i := 0; ; {
+		j := strings.Index(expr[i:], macro)
+		if j < 0 {
+			return false
+		}
+		end := i + j + len(macro)
+		if end == len(expr) || !isIdentChar(expr[end]) {
+			return true
+		}
+		i = end
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
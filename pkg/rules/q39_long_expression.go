@@ -0,0 +1,65 @@
+package rules
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// LongExpression detects targets whose raw expression is longer than a
+// threshold. Such queries are hard to review and maintain, and are usually
+// complex logic pasted between panels that belongs in recording rules. The
+// check is on the raw text, so it also covers expressions that fail to
+// parse.
+type LongExpression struct {
+	// MaxLength is the expression length, in characters, above which a
+	// target is reported. Defaults to 500 if zero.
+	MaxLength int
+}
+
+func (r *LongExpression) ID() string            { return "Q39" }
+func (r *LongExpression) RuleSeverity() Severity { return Low }
+
+func (r *LongExpression) Describe() Description {
+	return Description{
+		Title:       "Very long query expression",
+		Summary:     "Targets whose raw expression is longer than 500 characters.",
+		Rationale:   "Long expressions are hard to maintain and usually hold logic that belongs in recording rules.",
+		Bad:         "a 900-character expression joining and re-aggregating several metrics inline",
+		Good:        "recording rules for the intermediate results; the panel queries the recorded series",
+		AutoFixable: false,
+	}
+}
+
+func (r *LongExpression) maxLength() int {
+	if r.MaxLength > 0 {
+		return r.MaxLength
+	}
+	return 500
+}
+
+func (r *LongExpression) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			length := utf8.RuneCountInString(target.Expr)
+			if length <= r.maxLength() {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q39",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Very long query expression",
+				Why:         fmt.Sprintf("The expression is %d characters long (threshold: %d). Queries this size are hard to review and change safely, and usually hold logic copied between panels.", length, r.maxLength()),
+				Fix:         "Move intermediate results into recording rules and query the recorded series, or split the query into several targets.",
+				Impact:      "Shorter, reviewable queries; recording rules also precompute the expensive parts",
+				Validate:    "Compare the panel before/after — the series shown should be unchanged",
+				AutoFixable: false,
+				Confidence:  0.5,
+			})
+		}
+	}
+	return findings
+}
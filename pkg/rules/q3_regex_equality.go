@@ -3,6 +3,7 @@ package rules
 import (
 	"fmt"
 
+	"github.com/dashboard-advisor/pkg/rewrite"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 )
@@ -15,6 +16,17 @@ type RegexEquality struct{}
 func (r *RegexEquality) ID() string            { return "Q3" }
 func (r *RegexEquality) RuleSeverity() Severity { return Medium }
 
+func (r *RegexEquality) Describe() Description {
+	return Description{
+		Title:       "Regex matcher where equality suffices",
+		Summary:     "=~ matchers whose value contains no regex metacharacters.",
+		Rationale:   "A literal regex still goes through the regex engine for every label value; = is a direct index lookup.",
+		Bad:         `up{job=~"api"}`,
+		Good:        `up{job="api"}`,
+		AutoFixable: true,
+	}
+}
+
 func (r *RegexEquality) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -29,12 +41,13 @@ func (r *RegexEquality) Check(ctx *AnalysisContext) []Finding {
 					return nil
 				}
 				for _, m := range vs.LabelMatchers {
-					if m.Type == labels.MatchRegexp && !containsRegexMeta(m.Value) {
+					if m.Type == labels.MatchRegexp && !rewrite.ContainsRegexMeta(m.Value) {
 						findings = append(findings, Finding{
 							RuleID:      "Q3",
 							Severity:    Medium,
 							PanelIDs:    []int{panel.ID},
 							PanelTitles: []string{panel.Title},
+							TargetExpr:  target.Expr,
 							Title:       "Regex matcher where equality suffices",
 							Why:         fmt.Sprintf("Label %q uses regex match =~%q but the value contains no regex metacharacters. Regex matching is slower than equality.", m.Name, m.Value),
 							Fix:         fmt.Sprintf("Change %s=~\"%s\" to %s=\"%s\"", m.Name, m.Value, m.Name, m.Value),
@@ -42,6 +55,7 @@ func (r *RegexEquality) Check(ctx *AnalysisContext) []Finding {
 							Validate:    "Query Inspector → Stats tab → compare query time before/after",
 							AutoFixable: true,
 							Confidence:  1.0,
+							Suggestion:  rewrite.RegexEquality(target.Expr),
 						})
 					}
 				}
@@ -51,14 +65,3 @@ func (r *RegexEquality) Check(ctx *AnalysisContext) []Finding {
 	}
 	return findings
 }
-
-// containsRegexMeta returns true if s contains regex metacharacters.
-func containsRegexMeta(s string) bool {
-	for _, c := range s {
-		switch c {
-		case '.', '*', '+', '?', '(', ')', '[', ']', '{', '}', '|', '^', '$', '\\':
-			return true
-		}
-	}
-	return false
-}
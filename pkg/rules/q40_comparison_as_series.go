@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// ComparisonAsSeries detects timeseries panels that display the result of a
+// filtering comparison against a fixed value, e.g. latency > 0.5: the series
+// vanishes whenever it is below the value, leaving gaps instead of a line
+// with a threshold. Filtering against 0 (x > 0, hiding empty series) is a
+// common idiom and is not reported, nor are comparisons between two vectors,
+// which filter by another query. Comparisons with bool are left to Q30.
+type ComparisonAsSeries struct{}
+
+func (r *ComparisonAsSeries) ID() string            { return "Q40" }
+func (r *ComparisonAsSeries) RuleSeverity() Severity { return Low }
+
+func (r *ComparisonAsSeries) Describe() Description {
+	return Description{
+		Title:       "Comparison displayed instead of a threshold",
+		Summary:     "Timeseries panels whose displayed query filters against a fixed non-zero value.",
+		Rationale:   "A panel threshold shows the same information on the real line; the comparison hides the values on the other side.",
+		Bad:         `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))) > 0.5`,
+		Good:        "the p99 query alone, with a panel threshold at 0.5",
+		AutoFixable: false,
+	}
+}
+
+func (r *ComparisonAsSeries) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		if panel.Type != "timeseries" && panel.Type != "graph" {
+			continue
+		}
+		for _, target := range panel.Targets {
+			if target.Hide {
+				continue
+			}
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			bin, ok := unwrapParens(expr).(*parser.BinaryExpr)
+			// bool comparisons are 0/1 series, reported by Q30.
+			if !ok || !bin.Op.IsComparisonOperator() || bin.ReturnBool {
+				continue
+			}
+			value, scalarSide := comparisonValue(bin)
+			if !scalarSide || value == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q40",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Comparison displayed instead of a threshold",
+				Why:         fmt.Sprintf("The panel shows a \"%s\" comparison with %s, which drops every sample on the other side: the line has gaps instead of showing how far it is from the value.", bin.Op, strconv.FormatFloat(value, 'g', -1, 64)),
+				Fix:         "Graph the compared value itself and add a panel threshold (Thresholds → Show thresholds as lines or regions) at the value. Keep comparisons for alert rules.",
+				Impact:      "Readers see the actual values and how close they are to the limit",
+				Validate:    "Edit the panel → verify the threshold line is drawn at the value and the series is continuous",
+				AutoFixable: false,
+				Confidence:  0.6,
+			})
+		}
+	}
+	return findings
+}
+
+// comparisonValue returns the number a comparison is made against when one
+// side is a literal and the other is a vector.
+func comparisonValue(bin *parser.BinaryExpr) (float64, bool) {
+	lhs, lok := unwrapParens(bin.LHS).(*parser.NumberLiteral)
+	rhs, rok := unwrapParens(bin.RHS).(*parser.NumberLiteral)
+	switch {
+	case rok && !lok:
+		return rhs.Val, true
+	case lok && !rok:
+		return lhs.Val, true
+	}
+	return 0, false
+}
@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// defaultTopologyAlternatives maps per-instance labels to the coarser
+// topology labels that often answer the same question with fewer series.
+var defaultTopologyAlternatives = map[string][]string{
+	"pod":      {"deployment", "node"},
+	"instance": {"node"},
+}
+
+// TopologyGrouping is an advisory companion to Q4: when an aggregation
+// groups by pod or instance, it checks the TSDB label cardinality for a
+// topology label (node, deployment) with fewer distinct values and suggests
+// grouping by it if that answers the panel's question. It only runs with
+// cardinality data, since the comparison is the whole point; the label
+// counts are global, so the alternative is not guaranteed to exist on the
+// queried metric.
+type TopologyGrouping struct {
+	// Alternatives maps a grouped label to the labels suggested instead.
+	// Defaults to pod → deployment, node and instance → node if empty.
+	Alternatives map[string][]string
+}
+
+func (r *TopologyGrouping) ID() string            { return "Q41" }
+func (r *TopologyGrouping) RuleSeverity() Severity { return Low }
+
+func (r *TopologyGrouping) Describe() Description {
+	return Description{
+		Title:       "Grouping by pod/instance where a topology label may do",
+		Summary:     "Aggregations by pod or instance when node or deployment has fewer distinct values (needs --prometheus-url).",
+		Rationale:   "Per-pod results are often read per node or per deployment anyway; the coarser label returns far fewer series.",
+		Bad:         `sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="prod"}[5m]))`,
+		Good:        `sum by (node) (rate(container_cpu_usage_seconds_total{namespace="prod"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *TopologyGrouping) alternatives() map[string][]string {
+	if len(r.Alternatives) > 0 {
+		return r.Alternatives
+	}
+	return defaultTopologyAlternatives
+}
+
+func (r *TopologyGrouping) Check(ctx *AnalysisContext) []Finding {
+	if ctx.Cardinality == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				agg, ok := node.(*parser.AggregateExpr)
+				if !ok || agg.Without {
+					return nil
+				}
+				grouped := make(map[string]bool, len(agg.Grouping))
+				for _, g := range agg.Grouping {
+					grouped[g] = true
+				}
+				for _, label := range agg.Grouping {
+					count := ctx.Cardinality.LabelCardinality(label, 0)
+					if count == 0 {
+						continue
+					}
+					var better []labelCount
+					for _, alt := range r.alternatives()[label] {
+						altCount := ctx.Cardinality.LabelCardinality(alt, 0)
+						if grouped[alt] || altCount == 0 || altCount >= count {
+							continue
+						}
+						better = append(better, labelCount{alt, altCount})
+					}
+					if len(better) == 0 {
+						continue
+					}
+					sort.Slice(better, func(i, j int) bool { return better[i].count < better[j].count })
+					described := make([]string, len(better))
+					for i, b := range better {
+						described[i] = fmt.Sprintf("%s (%d values)", b.label, b.count)
+					}
+					best := better[0]
+					findings = append(findings, Finding{
+						RuleID:      "Q41",
+						Severity:    Low,
+						PanelIDs:    []int{panel.ID},
+						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
+						Title:       "Grouping by pod/instance where a topology label may do",
+						Why:         fmt.Sprintf("The %s aggregation groups by %s, which has %d distinct values in the TSDB, while %s has fewer. If the panel is read per %s anyway, the per-%s series are wasted work.", agg.Op, label, count, strings.Join(described, " and "), best.label, label),
+						Fix:         fmt.Sprintf("Check whether grouping by %s answers the panel's question and that the metric carries that label; if so, use it instead of %s in the by() clause.", best.label, label),
+						Impact:      fmt.Sprintf("Up to %dx fewer result series", count/best.count),
+						Validate:    "Query Inspector → Data tab → compare the number of series before/after",
+						AutoFixable: false,
+						Confidence:  0.4,
+					})
+				}
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// labelCount is a label name with its distinct value count.
+type labelCount struct {
+	label string
+	count int
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// RedundantClampMin detects clamp_min(..., 0) around rate(), irate() or
+// increase(), possibly aggregated. These functions never return negative
+// values — counter resets are already compensated — so the clamp does
+// nothing. It usually comes from a belief that resets produce negative
+// spikes; when the metric is really a gauge that goes down, the clamp hides
+// the mistake that deriv() or delta() would fix (see Q11).
+type RedundantClampMin struct{}
+
+func (r *RedundantClampMin) ID() string            { return "Q42" }
+func (r *RedundantClampMin) RuleSeverity() Severity { return Low }
+
+func (r *RedundantClampMin) Describe() Description {
+	return Description{
+		Title:       "Redundant clamp_min around rate()",
+		Summary:     "clamp_min(rate(...), 0) and the same around irate(), increase() or aggregations of them.",
+		Rationale:   "rate() already compensates counter resets and is never negative; the clamp does nothing, or hides a gauge that needs deriv().",
+		Bad:         `clamp_min(sum(rate(http_requests_total{job="api"}[5m])), 0)`,
+		Good:        `sum(rate(http_requests_total{job="api"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *RedundantClampMin) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				call, ok := node.(*parser.Call)
+				if !ok || call.Func.Name != "clamp_min" || len(call.Args) != 2 {
+					return nil
+				}
+				bound, ok := unwrapParens(call.Args[1]).(*parser.NumberLiteral)
+				if !ok || bound.Val > 0 {
+					return nil
+				}
+				inner := rateLikeCall(call.Args[0])
+				if inner == nil {
+					return nil
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q42",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "Redundant clamp_min around rate()",
+					Why:         fmt.Sprintf("clamp_min(..., %s) wraps %s(), which is never negative: counter resets are already compensated. The clamp does nothing, and if %s is a gauge that goes down, it hides that %s() is the wrong function.", bound.String(), inner.Func.Name, primaryMetricName(inner), inner.Func.Name),
+					Fix:         fmt.Sprintf("Remove the clamp_min and query %s directly. If the metric is a gauge, use deriv() or delta() instead of %s().", call.Args[0].String(), inner.Func.Name),
+					Impact:      "Simpler query with the same result",
+					Validate:    "Compare the panel before/after — the series should be identical",
+					AutoFixable: false,
+					Confidence:  0.8,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
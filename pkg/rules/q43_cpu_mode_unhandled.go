@@ -0,0 +1,141 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// defaultModeCounters are CPU time counters split by a mode label (idle,
+// user, system, ...) whose modes add up to wall-clock time.
+var defaultModeCounters = []string{
+	"node_cpu_seconds_total",
+	"windows_cpu_time_total",
+}
+
+// CPUModeUnhandled detects rate()/irate()/increase() over a CPU time
+// counter split by mode, e.g. rate(node_cpu_seconds_total[5m]), where the
+// query neither filters on mode nor keeps it as a dimension. Unaggregated,
+// that is one series per CPU per mode; summed or averaged across modes it
+// includes idle, so every busy and idle CPU reads the same ~1 second per
+// second. Either way the panel means little; CPU utilization is usually
+// 1 - the idle rate. The check is a heuristic on metric names.
+type CPUModeUnhandled struct {
+	// Counters are the metric names checked. Defaults to
+	// defaultModeCounters if nil.
+	Counters []string
+}
+
+func (r *CPUModeUnhandled) ID() string            { return "Q43" }
+func (r *CPUModeUnhandled) RuleSeverity() Severity { return Low }
+
+func (r *CPUModeUnhandled) Describe() Description {
+	return Description{
+		Title:       "CPU counter rated without mode handling",
+		Summary:     "rate(node_cpu_seconds_total) without a mode filter or a by (mode) breakdown.",
+		Rationale:   "Summed across modes, idle included, every CPU reads ~1 second per second; unaggregated it is one series per CPU per mode.",
+		Bad:         `sum by (instance) (rate(node_cpu_seconds_total{job="node"}[5m]))`,
+		Good:        `1 - avg by (instance) (rate(node_cpu_seconds_total{job="node", mode="idle"}[$__rate_interval]))`,
+		AutoFixable: false,
+	}
+}
+
+func (r *CPUModeUnhandled) counters() map[string]bool {
+	names := r.Counters
+	if names == nil {
+		names = defaultModeCounters
+	}
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
+
+func (r *CPUModeUnhandled) Check(ctx *AnalysisContext) []Finding {
+	counters := r.counters()
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			var call *parser.Call
+			var metric string
+			parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+				c, ok := node.(*parser.Call)
+				if call != nil || !ok || !rateFuncsForInterval[c.Func.Name] || len(c.Args) == 0 {
+					return nil
+				}
+				ms, ok := c.Args[0].(*parser.MatrixSelector)
+				if !ok {
+					return nil
+				}
+				name := extractMetricName(ms)
+				if !counters[name] || modeHandled(ms, path) {
+					return nil
+				}
+				call, metric = c, name
+				return nil
+			})
+			if call == nil {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q43",
+				Severity:    Low,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "CPU counter rated without mode handling",
+				Why:         fmt.Sprintf("%s() over %s neither filters on mode nor keeps it as a dimension. The counter has one series per CPU per mode, and its modes, idle included, add up to one second per second: summed or averaged across modes every CPU looks the same whether busy or idle, and unaggregated the panel shows one line per CPU per mode.", call.Func.Name, metric),
+				Fix:         fmt.Sprintf("For CPU utilization, use the idle rate: 1 - avg by (instance) (rate(%s{mode=\"idle\", ...}[$__rate_interval])). For a breakdown, aggregate by (mode) or filter the modes of interest (mode!=\"idle\").", metric),
+				Impact:      "A CPU panel that reflects actual utilization, with far fewer series",
+				Validate:    "Compare the panel with top/htop on one host — utilization should match",
+				AutoFixable: false,
+				Confidence:  0.6,
+			})
+		}
+	}
+	return findings
+}
+
+// modeHandled reports whether the mode label of a CPU counter selector is
+// dealt with: matched by the selector itself, kept by every aggregation
+// above it (there must be at least one), or named in a vector matching
+// clause, as in x / ignoring(mode) group_left sum without (mode) (x).
+func modeHandled(ms *parser.MatrixSelector, path []parser.Node) bool {
+	if vs, ok := ms.VectorSelector.(*parser.VectorSelector); ok {
+		for _, m := range vs.LabelMatchers {
+			if m.Name == "mode" {
+				return true
+			}
+		}
+	}
+	aggregated, kept := false, true
+	for _, n := range path {
+		switch n := n.(type) {
+		case *parser.AggregateExpr:
+			aggregated = true
+			if hasLabel(n.Grouping, "mode") == n.Without {
+				kept = false
+			}
+		case *parser.BinaryExpr:
+			if n.VectorMatching != nil && hasLabel(n.VectorMatching.MatchingLabels, "mode") {
+				return true
+			}
+		}
+	}
+	return aggregated && kept
+}
+
+// hasLabel reports whether labels contains name.
+func hasLabel(labels []string, name string) bool {
+	for _, l := range labels {
+		if l == name {
+			return true
+		}
+	}
+	return false
+}
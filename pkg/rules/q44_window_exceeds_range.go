@@ -0,0 +1,118 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// literalWindowRe matches a hardcoded range or subquery window such as
+// [1h] or [30m:1m]. The duration is captured.
+var literalWindowRe = regexp.MustCompile(`\[(\d+(?:ms|[smhdwy]))(?::[^\]]*)?\]`)
+
+// windowedFuncs are the functions whose result summarizes their whole
+// window, so a window longer than the displayed range dominates the graph.
+var windowedFuncs = map[string]bool{
+	"rate":     true,
+	"increase": true,
+	"delta":    true,
+}
+
+// WindowExceedsRange detects rate(), increase() and delta() calls whose
+// hardcoded window is longer than the time range the panel displays, e.g.
+// increase(x[1h]) on a dashboard showing the last 15 minutes. Every point
+// then summarizes mostly data from before the visible range: the graph is
+// a slowly moving average that hides what happened in view, and series
+// younger than the window are extrapolated. Windows from template variables
+// are skipped; they follow the range.
+type WindowExceedsRange struct{}
+
+func (r *WindowExceedsRange) ID() string            { return "Q44" }
+func (r *WindowExceedsRange) RuleSeverity() Severity { return Medium }
+
+func (r *WindowExceedsRange) Describe() Description {
+	return Description{
+		Title:       "Rate window longer than the displayed range",
+		Summary:     "rate()/increase()/delta() with a hardcoded window longer than the dashboard's (or panel's) time range.",
+		Rationale:   "Every point summarizes mostly data from before the visible range, hiding what happened in view.",
+		Bad:         `increase(http_requests_total{job="api"}[1h]) on "time": {"from": "now-15m"}`,
+		Good:        `increase(http_requests_total{job="api"}[$__rate_interval]) on "time": {"from": "now-15m"}`,
+		AutoFixable: false,
+	}
+}
+
+func (r *WindowExceedsRange) Check(ctx *AnalysisContext) []Finding {
+	// An absolute or unparseable range leaves dashRange at zero; only
+	// panels with a time override are checked then.
+	dashRange, _ := parseRelativeRange(ctx.Dashboard.Time.From)
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		shown, source := dashRange, fmt.Sprintf("dashboard range %q", ctx.Dashboard.Time.From)
+		if panel.TimeFrom != "" {
+			if d, err := parseGrafanaDuration(panel.TimeFrom); err == nil {
+				shown, source = d, fmt.Sprintf("panel time override %q", panel.TimeFrom)
+			}
+		}
+		if shown <= 0 {
+			continue
+		}
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			literals := literalWindows(target.Expr)
+			if len(literals) == 0 {
+				continue
+			}
+			var call *parser.Call
+			var window time.Duration
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				c, ok := node.(*parser.Call)
+				if call != nil || !ok || !windowedFuncs[c.Func.Name] || len(c.Args) == 0 {
+					return nil
+				}
+				ms, ok := c.Args[0].(*parser.MatrixSelector)
+				if !ok || !literals[ms.Range] || ms.Range <= shown {
+					return nil
+				}
+				call, window = c, ms.Range
+				return nil
+			})
+			if call == nil {
+				continue
+			}
+			findings = append(findings, Finding{
+				RuleID:      "Q44",
+				Severity:    Medium,
+				PanelIDs:    []int{panel.ID},
+				PanelTitles: []string{panel.Title},
+				TargetExpr:  target.Expr,
+				Title:       "Rate window longer than the displayed range",
+				Why:         fmt.Sprintf("%s() uses a [%s] window, %.3gx the %s (%s). Every point summarizes mostly data from before the visible range, so the graph barely moves and hides what happened in view; series younger than the window are extrapolated at the left edge.", call.Func.Name, model.Duration(window), float64(window)/float64(shown), model.Duration(shown), source),
+				Fix:         fmt.Sprintf("Use [$__rate_interval] so the window follows the range, or widen the range to at least %s. For a single total over the displayed range, use a stat panel with %s(...[$__range]).", model.Duration(window), call.Func.Name),
+				Impact:      "A graph that reflects the displayed time range",
+				Validate:    "Compare the panel with a [$__rate_interval] copy over the same range",
+				AutoFixable: false,
+				Confidence:  0.7,
+			})
+		}
+	}
+	return findings
+}
+
+// literalWindows returns the durations written literally as range or
+// subquery windows in the raw expression. Windows from template variables
+// are substituted before parsing, so only these are known to be hardcoded.
+func literalWindows(raw string) map[time.Duration]bool {
+	windows := make(map[time.Duration]bool)
+	for _, m := range literalWindowRe.FindAllStringSubmatch(raw, -1) {
+		if d, err := model.ParseDuration(m[1]); err == nil {
+			windows[time.Duration(d)] = true
+		}
+	}
+	return windows
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// LongIgnoringList detects binary operations matching with ignoring() over
+// a long label list, e.g. a / ignoring(pod, instance, container, node,
+// endpoint) b. Listing every label that differs between the sides is
+// fragile — one new label on either side breaks the match and the panel
+// goes empty — and usually means the sides do not share the dimensions the
+// author thought. on() with the few labels they do share states the intent
+// and survives relabeling.
+type LongIgnoringList struct {
+	// MaxLabels is the number of ignored labels tolerated. Defaults to 4
+	// if zero.
+	MaxLabels int
+}
+
+func (r *LongIgnoringList) ID() string            { return "Q45" }
+func (r *LongIgnoringList) RuleSeverity() Severity { return Low }
+
+func (r *LongIgnoringList) Describe() Description {
+	return Description{
+		Title:       "ignoring() with a long label list",
+		Summary:     "Binary operations using ignoring() with more than 4 labels.",
+		Rationale:   "Any new label on either side breaks the match; on() with the shared labels states the intent and survives relabeling.",
+		Bad:         `a / ignoring(pod, instance, container, node, endpoint) b`,
+		Good:        `a / on(namespace, service) b`,
+		AutoFixable: false,
+	}
+}
+
+func (r *LongIgnoringList) maxLabels() int {
+	if r.MaxLabels > 0 {
+		return r.MaxLabels
+	}
+	return 4
+}
+
+func (r *LongIgnoringList) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				be, ok := node.(*parser.BinaryExpr)
+				if !ok || be.VectorMatching == nil || be.VectorMatching.On {
+					return nil
+				}
+				ignored := be.VectorMatching.MatchingLabels
+				if len(ignored) <= r.maxLabels() {
+					return nil
+				}
+				fix := "Match with on() and the few labels both sides share instead, e.g. on(namespace, service)."
+				if shared := knownSharedLabels(be); len(shared) > 0 {
+					fix = fmt.Sprintf("Match with on(%s) instead: those are the labels the aggregated side keeps.", strings.Join(shared, ", "))
+				}
+				findings = append(findings, Finding{
+					RuleID:      "Q45",
+					Severity:    Low,
+					PanelIDs:    []int{panel.ID},
+					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
+					Title:       "ignoring() with a long label list",
+					Why:         fmt.Sprintf("The %s operation matches with ignoring(%s): %d labels (threshold: %d). Listing every label that differs is fragile — a new label on either side breaks the match and the panel goes empty — and often masks sides that do not share the dimensions they seem to.", be.Op, strings.Join(ignored, ", "), len(ignored), r.maxLabels()),
+					Fix:         fix,
+					Impact:      "A match that states its intent and survives new or relabeled labels",
+					Validate:    "Compare the panel before/after — the series should be identical",
+					AutoFixable: false,
+					Confidence:  0.6,
+				})
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// knownSharedLabels returns, sorted, the labels kept by the first side of
+// be whose result labels are a known, finite set (e.g. sum by (a, b)),
+// less the ignored ones. Those are the only labels that side can match
+// on. Returns nil when neither side is aggregated that way.
+func knownSharedLabels(be *parser.BinaryExpr) []string {
+	for _, side := range []parser.Expr{be.LHS, be.RHS} {
+		out, known := resultLabels(side)
+		if !known || out.all {
+			continue
+		}
+		out = out.without(be.VectorMatching.MatchingLabels)
+		if len(out.kept) == 0 {
+			continue
+		}
+		labels := make([]string, 0, len(out.kept))
+		for l := range out.kept {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		return labels
+	}
+	return nil
+}
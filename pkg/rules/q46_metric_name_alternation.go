@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// MetricNameAlternation detects selectors that union many metrics through a
+// __name__ regex alternation, e.g. {__name__=~"m1|m2|m3|m4|m5|m6"}. Each
+// alternative is a separate index lookup, the result mixes unrelated
+// metrics in one query, and the list grows with every metric added. Separate
+// queries, or a recording rule that relabels the metrics into one, are
+// faster and clearer. Broad name regexes (node_.*) are Q28's.
+type MetricNameAlternation struct {
+	// MaxAlternatives is the number of alternatives tolerated. Defaults to
+	// 5 if zero.
+	MaxAlternatives int
+}
+
+func (r *MetricNameAlternation) ID() string            { return "Q46" }
+func (r *MetricNameAlternation) RuleSeverity() Severity { return Medium }
+
+func (r *MetricNameAlternation) Describe() Description {
+	return Description{
+		Title:       "Long metric name alternation",
+		Summary:     "__name__ regex matchers with more than 5 top-level alternatives.",
+		Rationale:   "Each alternative is a separate index lookup and the query mixes unrelated metrics; separate queries or a recorded metric are faster and clearer.",
+		Bad:         `{__name__=~"m1|m2|m3|m4|m5|m6", job="api"}`,
+		Good:        `m1{job="api"}, m2{job="api"}, ... as separate targets`,
+		AutoFixable: false,
+	}
+}
+
+func (r *MetricNameAlternation) maxAlternatives() int {
+	if r.MaxAlternatives > 0 {
+		return r.MaxAlternatives
+	}
+	return 5
+}
+
+func (r *MetricNameAlternation) Check(ctx *AnalysisContext) []Finding {
+	var findings []Finding
+	for _, panel := range ctx.Panels {
+		for _, target := range panel.Targets {
+			expr, ok := ctx.ParsedExprs[target.Expr]
+			if !ok {
+				continue
+			}
+			parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+				vs, ok := node.(*parser.VectorSelector)
+				if !ok {
+					return nil
+				}
+				for _, m := range vs.LabelMatchers {
+					if m.Name != "__name__" || m.Type != labels.MatchRegexp || isBroadNameRegex(m.Value) {
+						continue
+					}
+					alts := topLevelAlternatives(m.Value)
+					if len(alts) <= r.maxAlternatives() {
+						continue
+					}
+					findings = append(findings, Finding{
+						RuleID:      "Q46",
+						Severity:    Medium,
+						PanelIDs:    []int{panel.ID},
+						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
+						Title:       "Long metric name alternation",
+						Why:         fmt.Sprintf("The selector unions %d metric names through __name__=~%q (threshold: %d). Each alternative is a separate index lookup, the result mixes unrelated metrics under one query, and the list has to be edited whenever a metric is added.", len(alts), truncateQuery(m.Value, 80), r.maxAlternatives()),
+						Fix:         fmt.Sprintf("Split the target into one query per metric (e.g. %s{...}), or add a recording rule that relabels these metrics into a single metric with a distinguishing label and query that.", alts[0]),
+						Impact:      "Cheaper, independently cacheable queries instead of one wide name union",
+						Validate:    "Query Inspector → Stats tab → compare query time before/after",
+						AutoFixable: false,
+						Confidence:  0.7,
+					})
+				}
+				return nil
+			})
+		}
+	}
+	return findings
+}
+
+// topLevelAlternatives splits a regex at the | characters outside groups,
+// character classes and escapes, so (a|b)c is a single alternative.
+func topLevelAlternatives(re string) []string {
+	var alts []string
+	depth, inClass, start := 0, false, 0
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '|' && depth == 0:
+			alts = append(alts, strings.TrimSpace(re[start:i]))
+			start = i + 1
+		}
+	}
+	return append(alts, strings.TrimSpace(re[start:]))
+}
@@ -27,6 +27,17 @@ type HighCardinalityGrouping struct{}
 func (r *HighCardinalityGrouping) ID() string            { return "Q4" }
 func (r *HighCardinalityGrouping) RuleSeverity() Severity { return High }
 
+func (r *HighCardinalityGrouping) Describe() Description {
+	return Description{
+		Title:       "High-cardinality grouping",
+		Summary:     "Aggregations grouping by many labels or by labels known to have very high cardinality (pod, instance, request IDs).",
+		Rationale:   "The result has one series per label combination, so the query returns thousands of series that Prometheus must compute and the browser must draw.",
+		Bad:         `sum by (pod, container, path) (rate(http_requests_total{job="api"}[5m]))`,
+		Good:        `sum by (path) (rate(http_requests_total{job="api"}[5m]))`,
+		AutoFixable: false,
+	}
+}
+
 func (r *HighCardinalityGrouping) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -47,6 +58,7 @@ func (r *HighCardinalityGrouping) Check(ctx *AnalysisContext) []Finding {
 						Severity:    High,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "High-cardinality grouping",
 						Why:         fmt.Sprintf("Aggregation groups by %d labels (%s). More than 3 grouping labels often produces an explosion of output series.", len(agg.Grouping), strings.Join(agg.Grouping, ", ")),
 						Fix:         "Reduce the number of grouping labels to only those needed for the visualization.",
@@ -74,6 +86,7 @@ func (r *HighCardinalityGrouping) Check(ctx *AnalysisContext) []Finding {
 							Severity:    High,
 							PanelIDs:    []int{panel.ID},
 							PanelTitles: []string{panel.Title},
+							TargetExpr:  target.Expr,
 							Title:       "High-cardinality grouping label",
 							Why:         why,
 							Fix:         fmt.Sprintf("Remove %q from the group-by clause or replace it with a lower-cardinality label (e.g. namespace, job).", lbl),
@@ -15,6 +15,17 @@ type LateAggregation struct{}
 func (r *LateAggregation) ID() string            { return "Q5" }
 func (r *LateAggregation) RuleSeverity() Severity { return Medium }
 
+func (r *LateAggregation) Describe() Description {
+	return Description{
+		Title:       "Late aggregation over unfiltered selector",
+		Summary:     "Aggregations that wrap a selector with no label matchers.",
+		Rationale:   "Prometheus fetches every series of the metric before aggregating; filtering inside the aggregation keeps the fetched set small.",
+		Bad:         "sum(node_cpu_seconds_total)",
+		Good:        `sum(node_cpu_seconds_total{job="node", mode!="idle"})`,
+		AutoFixable: false,
+	}
+}
+
 func (r *LateAggregation) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -48,6 +59,7 @@ func (r *LateAggregation) Check(ctx *AnalysisContext) []Finding {
 						Severity:    Medium,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "Late aggregation over unfiltered selector",
 						Why:         why,
 						Fix:         fmt.Sprintf("Add label matchers to %s before aggregating, e.g. %s{namespace=\"...\"}.", metricName, metricName),
@@ -27,6 +27,17 @@ type LongRateRange struct{}
 func (r *LongRateRange) ID() string            { return "Q6" }
 func (r *LongRateRange) RuleSeverity() Severity { return Medium }
 
+func (r *LongRateRange) Describe() Description {
+	return Description{
+		Title:       "Long rate range",
+		Summary:     "rate()/irate()/increase()/delta()/idelta() windows longer than 10 minutes.",
+		Rationale:   "Every evaluation step reads all samples in the window for every series, so long windows multiply the samples read per point.",
+		Bad:         `rate(http_requests_total{job="api"}[1h])`,
+		Good:        `rate(http_requests_total{job="api"}[$__rate_interval])`,
+		AutoFixable: false,
+	}
+}
+
 func (r *LongRateRange) Check(ctx *AnalysisContext) []Finding {
 	const threshold = 10 * time.Minute
 	var findings []Finding
@@ -57,6 +68,7 @@ func (r *LongRateRange) Check(ctx *AnalysisContext) []Finding {
 						Severity:    Medium,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "Long rate range",
 						Why:         fmt.Sprintf("%s() uses a %s range window. Windows longer than 10m force Prometheus to scan many more samples per series.", call.Func.Name, ms.Range),
 						Fix:         fmt.Sprintf("Reduce the range to match the scrape interval or use $__rate_interval. E.g. %s(metric[5m]).", call.Func.Name),
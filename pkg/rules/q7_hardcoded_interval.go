@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/dashboard-advisor/pkg/rewrite"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
 // hardcodedRangeRe matches rate/irate/increase calls with hardcoded time
 // durations like [5m], [1h], [30s] instead of [$__rate_interval] or [$__interval].
-var hardcodedRangeRe = regexp.MustCompile(`(?:rate|irate|increase)\s*\([^)]*\[\d+[smh]\]`)
+// The duration is captured.
+var hardcodedRangeRe = regexp.MustCompile(`(?:rate|irate|increase)\s*\([^)]*\[(\d+[smh])\]`)
 
 // rateFuncsForInterval is the set of functions that should use $__rate_interval
 // or $__interval instead of hardcoded durations.
@@ -24,11 +28,25 @@ var rateFuncsForInterval = map[string]bool{
 // time durations instead of Grafana's $__rate_interval or $__interval
 // template variables. Hardcoded intervals break when the dashboard time
 // range or scrape interval changes, often producing wrong or missing data.
+// When the scrape interval is known (AnalysisContext.ScrapeInterval), the
+// finding quantifies the window against it, and a window shorter than four
+// scrape intervals — the minimum $__rate_interval guarantees — is High.
 type HardcodedInterval struct{}
 
 func (r *HardcodedInterval) ID() string            { return "Q7" }
 func (r *HardcodedInterval) RuleSeverity() Severity { return Medium }
 
+func (r *HardcodedInterval) Describe() Description {
+	return Description{
+		Title:       "Hardcoded interval in rate function",
+		Summary:     "rate()/irate()/increase() with a fixed window instead of $__rate_interval.",
+		Rationale:   "A fixed window is too short for some scrape intervals (gaps in the graph) and needlessly long for zoomed-in ranges; $__rate_interval adapts to both.",
+		Bad:         `rate(http_requests_total{job="api"}[5m])`,
+		Good:        `rate(http_requests_total{job="api"}[$__rate_interval])`,
+		AutoFixable: true,
+	}
+}
+
 func (r *HardcodedInterval) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -58,20 +76,20 @@ func (r *HardcodedInterval) Check(ctx *AnalysisContext) []Finding {
 				continue
 			}
 			// Check for hardcoded intervals in the raw expression
-			if hardcodedRangeRe.MatchString(rawExpr) {
-				matches := hardcodedRangeRe.FindString(rawExpr)
+			if m := hardcodedRangeRe.FindStringSubmatch(rawExpr); m != nil {
 				funcName := "rate"
 				for _, fn := range []string{"rate", "irate", "increase"} {
-					if strings.HasPrefix(matches, fn) {
+					if strings.HasPrefix(m[0], fn) {
 						funcName = fn
 						break
 					}
 				}
-				findings = append(findings, Finding{
+				f := Finding{
 					RuleID:      "Q7",
 					Severity:    Medium,
 					PanelIDs:    []int{panel.ID},
 					PanelTitles: []string{panel.Title},
+					TargetExpr:  target.Expr,
 					Title:       "Hardcoded interval in rate function",
 					Why:         fmt.Sprintf("%s() uses a hardcoded duration instead of $__rate_interval or $__interval. This breaks when the dashboard time range or scrape interval changes.", funcName),
 					Fix:         fmt.Sprintf("Replace the hardcoded duration with $__rate_interval, e.g. %s(metric[$__rate_interval]).", funcName),
@@ -79,9 +97,31 @@ func (r *HardcodedInterval) Check(ctx *AnalysisContext) []Finding {
 					Validate:    "Change the dashboard time range and verify the panel still renders correctly",
 					AutoFixable: true,
 					Confidence:  0.9,
-				})
+					Suggestion:  rewrite.RateInterval(rawExpr),
+				}
+				if window, err := parseGrafanaDuration(m[1]); err == nil && ctx.ScrapeInterval > 0 {
+					quantifyWindow(&f, funcName, m[1], window, ctx.ScrapeInterval)
+				}
+				findings = append(findings, f)
 			}
 		}
 	}
 	return findings
 }
+
+// quantifyWindow rewrites a Q7 finding's Why and Impact with the window
+// measured against the known scrape interval. A window under four scrape
+// intervals may hold fewer than two samples after a missed scrape, so
+// rate() returns nothing for that step; the finding is then High.
+func quantifyWindow(f *Finding, funcName, raw string, window, scrape time.Duration) {
+	minWindow := 4 * scrape
+	if window < minWindow {
+		f.Severity = High
+		f.Confidence = 0.95
+		f.Why = fmt.Sprintf("%s() uses a hardcoded [%s] window, below the recommended minimum of 4× the %s scrape interval (%s). The window spans only %.3g scrape intervals, so a single missed scrape leaves steps with too few samples to compute a rate: the graph has gaps.", funcName, raw, model.Duration(scrape), model.Duration(minWindow), float64(window)/float64(scrape))
+		f.Impact = fmt.Sprintf("Fills the gaps: $__rate_interval is never shorter than %s at this scrape interval", model.Duration(minWindow))
+		return
+	}
+	f.Why = fmt.Sprintf("%s() uses a hardcoded [%s] window, %.3g× the %s scrape interval. It covers enough samples today, but does not grow with the dashboard time range, so zoomed-out panels skip the samples between steps, and it breaks if the scrape interval changes.", funcName, raw, float64(window)/float64(scrape), model.Duration(scrape))
+	f.Impact = fmt.Sprintf("Keeps at least 4 scrapes (%s) per window and uses every sample at wide time ranges", model.Duration(minWindow))
+}
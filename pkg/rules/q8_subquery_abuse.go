@@ -16,6 +16,17 @@ type SubqueryAbuse struct{}
 func (r *SubqueryAbuse) ID() string            { return "Q8" }
 func (r *SubqueryAbuse) RuleSeverity() Severity { return High }
 
+func (r *SubqueryAbuse) Describe() Description {
+	return Description{
+		Title:       "Subquery abuse",
+		Summary:     "Nested subqueries, subqueries with a fine step over a long range, and subqueries with a large range/step ratio.",
+		Rationale:   "A subquery evaluates its inner query once per step; nesting or fine steps multiply that work and can exhaust query limits.",
+		Bad:         `max_over_time(rate(http_requests_total{job="api"}[5m])[7d:10s])`,
+		Good:        `max_over_time(job:http_requests:rate5m{job="api"}[7d])`,
+		AutoFixable: false,
+	}
+}
+
 func (r *SubqueryAbuse) Check(ctx *AnalysisContext) []Finding {
 	var findings []Finding
 	for _, panel := range ctx.Panels {
@@ -37,6 +48,7 @@ func (r *SubqueryAbuse) Check(ctx *AnalysisContext) []Finding {
 						Severity:    High,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "Nested subquery",
 						Why:         "A subquery is nested inside another subquery. Nested subqueries cause exponential evaluation cost and can overwhelm Prometheus.",
 						Fix:         "Flatten the subquery or use recording rules to pre-compute intermediate results.",
@@ -54,6 +66,7 @@ func (r *SubqueryAbuse) Check(ctx *AnalysisContext) []Finding {
 						Severity:    High,
 						PanelIDs:    []int{panel.ID},
 						PanelTitles: []string{panel.Title},
+						TargetExpr:  target.Expr,
 						Title:       "Subquery with fine step over long range",
 						Why:         fmt.Sprintf("Subquery has a %s step over a %s range. This produces %d evaluation points, creating excessive load.", sq.Step, sq.Range, int(sq.Range/sq.Step)),
 						Fix:         "Increase the step or reduce the range. Consider using a recording rule for long-range aggregations.",
@@ -73,6 +86,7 @@ func (r *SubqueryAbuse) Check(ctx *AnalysisContext) []Finding {
 							Severity:    High,
 							PanelIDs:    []int{panel.ID},
 							PanelTitles: []string{panel.Title},
+							TargetExpr:  target.Expr,
 							Title:       "Subquery with excessive range/step ratio",
 							Why:         fmt.Sprintf("Subquery range/step ratio is %d (range=%s, step=%s). Ratios above 360 cause excessive evaluation points.", ratio, sq.Range, sq.Step),
 							Fix:         "Increase the step or reduce the range to bring the ratio under 360.",
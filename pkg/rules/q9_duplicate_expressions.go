@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"strings"
+
+	"github.com/dashboard-advisor/pkg/extractor"
 )
 
 // DuplicateExpressions detects identical PromQL expressions used across
@@ -15,56 +17,36 @@ type DuplicateExpressions struct{}
 func (r *DuplicateExpressions) ID() string            { return "Q9" }
 func (r *DuplicateExpressions) RuleSeverity() Severity { return High }
 
-func (r *DuplicateExpressions) Check(ctx *AnalysisContext) []Finding {
-	// Map normalized expression → list of panels that use it.
-	type panelRef struct {
-		ID    int
-		Title string
+func (r *DuplicateExpressions) Describe() Description {
+	return Description{
+		Title:       "Duplicate expression across panels",
+		Summary:     "The same PromQL expression used in three or more panels.",
+		Rationale:   "Each copy is evaluated independently on every refresh, multiplying load for identical results.",
+		Bad:         `sum(rate(http_requests_total{job="api"}[5m])) in panels 1, 4 and 7`,
+		Good:        "one panel runs the query; the others use the -- Dashboard -- datasource to reuse its result",
+		AutoFixable: false,
 	}
-	exprPanels := make(map[string][]panelRef)
+}
 
+func (r *DuplicateExpressions) Check(ctx *AnalysisContext) []Finding {
+	groups := newExprGroups()
 	for _, panel := range ctx.Panels {
 		for _, target := range panel.Targets {
-			normalized := normalizeExpr(target.Expr)
-			if normalized == "" {
-				continue
-			}
-			key := hashExpr(normalized)
-			exprPanels[key] = append(exprPanels[key], panelRef{
-				ID:    panel.ID,
-				Title: panel.Title,
-			})
+			groups.add(target.Expr, panel)
 		}
 	}
 
 	var findings []Finding
-	for _, panels := range exprPanels {
-		if len(panels) <= 2 {
-			continue
-		}
-		// Deduplicate panel IDs (a panel might have the expr in multiple targets)
-		seen := make(map[int]bool)
-		var ids []int
-		var titles []string
-		for _, p := range panels {
-			if !seen[p.ID] {
-				seen[p.ID] = true
-				ids = append(ids, p.ID)
-				titles = append(titles, p.Title)
-			}
-		}
-		if len(ids) <= 2 {
-			continue
-		}
+	for _, g := range groups.shared(3) {
 		findings = append(findings, Finding{
 			RuleID:      "Q9",
 			Severity:    High,
-			PanelIDs:    ids,
-			PanelTitles: titles,
+			PanelIDs:    g.ids,
+			PanelTitles: g.titles,
 			Title:       "Duplicate expression across panels",
-			Why:         fmt.Sprintf("The same PromQL expression is used in %d panels (%s). Each copy is evaluated independently, multiplying Prometheus load.", len(ids), strings.Join(titles, ", ")),
+			Why:         fmt.Sprintf("The same PromQL expression is used in %d panels (%s). Each copy is evaluated independently, multiplying Prometheus load.", len(g.ids), strings.Join(g.titles, ", ")),
 			Fix:         "Use a shared query (panel data source), a library panel, or a recording rule to evaluate the expression once.",
-			Impact:      fmt.Sprintf("Eliminates %d redundant query evaluations per refresh", len(ids)-1),
+			Impact:      fmt.Sprintf("Eliminates %d redundant query evaluations per refresh", len(g.ids)-1),
 			Validate:    "Verify each panel still renders after consolidation",
 			AutoFixable: false,
 			Confidence:  0.95,
@@ -73,6 +55,59 @@ func (r *DuplicateExpressions) Check(ctx *AnalysisContext) []Finding {
 	return findings
 }
 
+// exprGroup is a normalized expression and the distinct panels using it,
+// in first-seen order.
+type exprGroup struct {
+	expr   string // first raw form seen
+	ids    []int
+	titles []string
+}
+
+// exprGroups groups panels by normalized expression text. Shared by Q9 and
+// Q21 so both agree on what counts as "the same expression".
+type exprGroups struct {
+	order  []string
+	groups map[string]*exprGroup
+}
+
+func newExprGroups() *exprGroups {
+	return &exprGroups{groups: make(map[string]*exprGroup)}
+}
+
+// add records that panel uses expr. A panel using the same expression in
+// several targets is counted once.
+func (g *exprGroups) add(expr string, panel extractor.PanelModel) {
+	normalized := normalizeExpr(expr)
+	if normalized == "" {
+		return
+	}
+	key := hashExpr(normalized)
+	grp, ok := g.groups[key]
+	if !ok {
+		grp = &exprGroup{expr: expr}
+		g.groups[key] = grp
+		g.order = append(g.order, key)
+	}
+	for _, id := range grp.ids {
+		if id == panel.ID {
+			return
+		}
+	}
+	grp.ids = append(grp.ids, panel.ID)
+	grp.titles = append(grp.titles, panel.Title)
+}
+
+// shared returns the groups used by at least minPanels distinct panels.
+func (g *exprGroups) shared(minPanels int) []*exprGroup {
+	var out []*exprGroup
+	for _, key := range g.order {
+		if grp := g.groups[key]; len(grp.ids) >= minPanels {
+			out = append(out, grp)
+		}
+	}
+	return out
+}
+
 // normalizeExpr strips whitespace to normalize expressions for comparison.
 func normalizeExpr(expr string) string {
 	// Remove all whitespace for normalization
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"fmt"
+	"sync"
+)
+
+// registry holds rules contributed from outside this package, typically by
+// an org-specific package's init function. Built-in rules are not listed
+// here; analyzer.DefaultEngine registers them directly.
+var registry struct {
+	mu        sync.Mutex
+	names     []string // registration order
+	factories map[string]func() Rule
+}
+
+// Register makes a third-party rule available to
+// analyzer.NewEngineWithRegistered. name identifies the registration (it
+// need not equal the rule's ID). factory is called once per engine so rules
+// with state or thresholds are not shared between engines.
+//
+// Register is meant to be called from init and panics if name is empty,
+// already registered, or factory is nil, like database/sql.Register.
+func Register(name string, factory func() Rule) {
+	if name == "" {
+		panic("rules: Register with empty name")
+	}
+	if factory == nil {
+		panic("rules: Register factory is nil for " + name)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.factories[name]; dup {
+		panic(fmt.Sprintf("rules: Register called twice for %q", name))
+	}
+	if registry.factories == nil {
+		registry.factories = make(map[string]func() Rule)
+	}
+	registry.factories[name] = factory
+	registry.names = append(registry.names, name)
+}
+
+// Registered returns a new instance of every registered rule, in
+// registration order.
+func Registered() []Rule {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	out := make([]Rule, 0, len(registry.names))
+	for _, name := range registry.names {
+		out = append(out, registry.factories[name]())
+	}
+	return out
+}
@@ -3,6 +3,8 @@ package rules
 import (
 	"fmt"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/dashboard-advisor/pkg/cardinality"
 	"github.com/dashboard-advisor/pkg/extractor"
@@ -52,40 +54,61 @@ func (s Severity) String() string {
 
 // Finding represents a single detected issue in a dashboard.
 type Finding struct {
-	RuleID      string   // "Q1", "D2", "B1", etc. — stable, never renumbered
-	Severity    Severity // Critical, High, Medium, Low
-	PanelIDs    []int    // affected panel IDs (empty for dashboard-level findings)
-	PanelTitles []string // human-readable panel names
-	Title       string   // short: "Missing label filters"
-	Why         string   // explanation of why this is a problem
-	Fix         string   // what to change
-	Impact      string   // expected improvement
-	Validate    string   // how to verify the fix worked
-	AutoFixable bool     // true if --fix can patch this automatically
-	Confidence  float64  // 0.0-1.0; lower for static-only, higher with cardinality data
+	RuleID         string   // "Q1", "D2", "B1", etc. — stable, never renumbered
+	Severity       Severity // Critical, High, Medium, Low
+	PanelIDs       []int    // affected panel IDs (empty for dashboard-level findings)
+	PanelTitles    []string // human-readable panel names
+	TargetExpr     string   // raw expr of the target the finding is about; empty for panel- and dashboard-level findings
+	Title          string   // short: "Missing label filters"
+	Why            string   // explanation of why this is a problem
+	Fix            string   // what to change
+	Impact         string   // expected improvement
+	Validate       string   // how to verify the fix worked
+	AutoFixable    bool     // true if --fix can patch this automatically
+	Confidence     float64  // 0.0-1.0; lower for static-only, higher with cardinality data
+	RelatedRuleIDs []string // other rules flagging any of the same panels; set by the engine
+	Suggestion     string   // rewritten target expression for auto-fixable PromQL findings, as --fix writes it
+	Line           int      `json:",omitempty"` // 1-based source line of TargetExpr, or of the first panel; 0 when unknown
+	Col            int      `json:",omitempty"` // 1-based source column (in characters) matching Line
 }
 
 // Report is the output of analyzing one dashboard.
 type Report struct {
-	DashboardUID   string
-	DashboardTitle string
-	Score          int            // 0-100 composite health score
-	Findings       []Finding
-	PanelScores    map[int]int    // panel ID → per-panel score
-	Metadata       ReportMetadata
+	DashboardUID    string
+	DashboardTitle  string
+	Score           int // 0-100 composite health score
+	Findings        []Finding
+	PanelScores     map[int]int     // panel ID → per-panel score
+	PanelTitlesByID map[int]string  // panel ID → title, for every panel including rows
+	PanelCosts      map[int]float64 // panel ID → summed estimated cost of its targets
+	Metadata        ReportMetadata
 }
 
 // ReportMetadata holds supplementary info about the analysis run.
 type ReportMetadata struct {
-	TotalPanels          int
-	TotalTargets         int
-	ParseErrors          int
-	AnalyzerVersion      string
-	CardinalityAvailable bool               `json:"cardinalityAvailable"` // true if TSDB status was fetched
-	QueryCosts           map[string]float64  `json:"queryCosts,omitempty"` // expr → estimated cost
+	TotalPanels                int
+	TotalTargets               int
+	ParseErrors                int
+	AnalyzerVersion            string
+	CardinalityAvailable       bool               `json:"cardinalityAvailable"`       // true if TSDB status was fetched
+	QueryCosts                 map[string]float64 `json:"queryCosts,omitempty"`       // expr → estimated cost
+	EstimatedQueriesPerRefresh int                `json:"estimatedQueriesPerRefresh"` // visible targets × worst-case variable fan-out
+	AutoFixableCount           int                `json:"autoFixableCount"`           // findings with AutoFixable set
+	AutoFixablePct             float64            `json:"autoFixablePct"`             // AutoFixableCount as a percentage of all findings; 0 with no findings
+	Tags                       []string           `json:"tags,omitempty"`             // the dashboard's tags, for routing findings to owners
+	NormalizedExprs            map[string]string  `json:"normalizedExprs,omitempty"`  // raw expr → text parsed after template substitution; JSON output only with --debug-exprs
 }
 
-// Rule is the interface every detection rule implements.
+// Rule is the interface every detection rule implements, built-in or
+// registered from another package (see Register). It is the stable contract
+// for third-party rules:
+//
+//   - ID returns a short unique identifier ("Q1", "ORG3"). It appears in
+//     reports and is what Engine.ReplaceRule matches on.
+//   - RuleSeverity returns the rule's default severity, used in the rule
+//     catalog; individual findings may set their own.
+//   - Check inspects ctx and returns zero or more findings. It must not
+//     modify ctx, which is shared by every rule in the analysis.
 type Rule interface {
 	ID() string
 	RuleSeverity() Severity
@@ -94,12 +117,51 @@ type Rule interface {
 
 // AnalysisContext carries all data a rule might need.
 type AnalysisContext struct {
-	Dashboard   *extractor.DashboardModel
-	Panels      []extractor.PanelModel            // all panels (including nested)
-	Variables   []extractor.VariableModel          // template variables
-	ParsedExprs map[string]parser.Expr             // raw expr → parsed AST
-	Cardinality *cardinality.CardinalityData       // nil when no Prometheus URL provided (Phase 2)
-	PrometheusURL string                           // empty when not configured; used by B-series rules
+	Dashboard      *extractor.DashboardModel
+	Panels         []extractor.PanelModel       // all panels (including nested)
+	Variables      []extractor.VariableModel    // template variables
+	ParsedExprs    map[string]parser.Expr       // raw expr → parsed AST
+	Cardinality    *cardinality.CardinalityData // nil when no Prometheus URL provided (Phase 2)
+	PrometheusURL  string                       // empty when not configured; used by B-series rules
+	QueryCosts     map[string]float64           // raw expr → estimated cost (same values as ReportMetadata.QueryCosts)
+	MetricTypes    MetricTypes                  // user-supplied metric classification; nil when none given
+	ScrapeInterval time.Duration                // scrape interval of the queried targets; 0 when unknown
+}
+
+// ForPanels returns a copy of ctx narrowed to the panels with the given IDs.
+// Everything else (variables, parsed expressions, costs) is shared with ctx,
+// so per-panel rules can re-check a few edited panels without re-parsing the
+// whole dashboard. Dashboard-wide rules should not be run on the result:
+// they would judge the dashboard by the subset alone.
+func (ctx *AnalysisContext) ForPanels(ids ...int) *AnalysisContext {
+	want := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	narrowed := *ctx
+	narrowed.Panels = nil
+	for _, p := range ctx.Panels {
+		if want[p.ID] {
+			narrowed.Panels = append(narrowed.Panels, p)
+		}
+	}
+	return &narrowed
+}
+
+// crossPanelRules are Q-series rules that compare expressions between
+// panels, so a narrowed context would hide what they look for.
+var crossPanelRules = map[string]bool{
+	"Q9":  true, // duplicate expressions
+	"Q21": true, // recording rule candidates
+	"Q23": true, // quantile windows compared across panels
+	"Q36": true, // repeated joins
+}
+
+// IsPanelRule reports whether r judges each panel on its own queries, so
+// its findings for a panel do not depend on the rest of the dashboard. Only
+// these rules are meaningful on a context narrowed with ForPanels.
+func IsPanelRule(r Rule) bool {
+	return strings.HasPrefix(r.ID(), "Q") && !crossPanelRules[r.ID()]
 }
 
 // ComputeScore calculates the composite health score from findings using
@@ -125,3 +187,32 @@ func ComputeScore(findings []Finding) int {
 	score := int(math.Round(100.0 * k / (float64(penalty) + k)))
 	return score
 }
+
+// ComputeDedupedScore is ComputeScore counting only the highest-severity
+// finding on each panel, so one problematic panel flagged by several rules
+// for overlapping reasons is penalized once. Dashboard-level findings (no
+// PanelIDs) always count. A finding that is the highest on several panels
+// still counts only once.
+func ComputeDedupedScore(findings []Finding) int {
+	worst := make(map[int]int) // panel ID → index of highest-severity finding
+	var kept []Finding
+	for i, f := range findings {
+		if len(f.PanelIDs) == 0 {
+			kept = append(kept, f)
+			continue
+		}
+		for _, pid := range f.PanelIDs {
+			if j, ok := worst[pid]; !ok || f.Severity > findings[j].Severity {
+				worst[pid] = i
+			}
+		}
+	}
+	seen := make(map[int]bool, len(worst))
+	for _, i := range worst {
+		if !seen[i] {
+			seen[i] = true
+			kept = append(kept, findings[i])
+		}
+	}
+	return ComputeScore(kept)
+}
@@ -1,6 +1,9 @@
 package rules
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestSeverityWeight(t *testing.T) {
 	tests := []struct {
@@ -100,3 +103,81 @@ func TestComputeScore(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeDedupedScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []Finding
+		want     int
+	}{
+		{
+			name:     "no findings = perfect score",
+			findings: nil,
+			want:     100,
+		},
+		{
+			name: "overlapping findings on one panel count the worst only",
+			findings: []Finding{
+				{Severity: Critical, PanelIDs: []int{1}},
+				{Severity: Medium, PanelIDs: []int{1}},
+				{Severity: Low, PanelIDs: []int{1}},
+			}, // penalty=15 → 87
+			want: 87,
+		},
+		{
+			name: "multi-panel finding counted once",
+			findings: []Finding{
+				{Severity: High, PanelIDs: []int{1, 2}},
+				{Severity: Low, PanelIDs: []int{2}},
+			}, // penalty=10 → 91
+			want: 91,
+		},
+		{
+			name: "dashboard-level findings always count",
+			findings: []Finding{
+				{Severity: High, PanelIDs: []int{1}},
+				{Severity: Medium},
+				{Severity: Low},
+			}, // penalty=10+5+2=17 → 85
+			want: 85,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeDedupedScore(tt.findings)
+			if got != tt.want {
+				t.Errorf("ComputeDedupedScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffReports(t *testing.T) {
+	before := &Report{Score: 40, Findings: []Finding{
+		{RuleID: "Q1", PanelIDs: []int{1}, TargetExpr: "up"},
+		{RuleID: "Q1", PanelIDs: []int{1}, TargetExpr: "down"},
+		{RuleID: "Q7", PanelIDs: []int{2}, TargetExpr: "rate(x[1m])"},
+		{RuleID: "D5"},
+	}}
+	after := &Report{Score: 70, Findings: []Finding{
+		{RuleID: "Q1", PanelIDs: []int{1}, TargetExpr: "down"},
+		// Same rule and panel with an edited query: unchanged, not resolved.
+		{RuleID: "Q7", PanelIDs: []int{2}, TargetExpr: `rate(x{job="api"}[1m])`},
+		{RuleID: "Q2", PanelIDs: []int{3}},
+	}}
+
+	diff := DiffReports(before, after)
+	if diff.ScoreBefore != 40 || diff.ScoreAfter != 70 {
+		t.Errorf("scores = %d → %d, want 40 → 70", diff.ScoreBefore, diff.ScoreAfter)
+	}
+	if len(diff.Resolved) != 2 || diff.Resolved[0].TargetExpr != "up" || diff.Resolved[1].RuleID != "D5" {
+		t.Errorf("resolved = %+v, want Q1 on up and D5", diff.Resolved)
+	}
+	if len(diff.Introduced) != 1 || diff.Introduced[0].RuleID != "Q2" {
+		t.Errorf("introduced = %+v, want Q2", diff.Introduced)
+	}
+	want := []RuleCountChange{{"D5", 1, 0}, {"Q1", 2, 1}, {"Q2", 0, 1}}
+	if fmt.Sprint(diff.RuleChanges) != fmt.Sprint(want) {
+		t.Errorf("rule changes = %v, want %v", diff.RuleChanges, want)
+	}
+}
@@ -1,14 +1,18 @@
 package rules_test
 
 import (
+	"fmt"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dashboard-advisor/pkg/analyzer"
 	"github.com/dashboard-advisor/pkg/cardinality"
 	"github.com/dashboard-advisor/pkg/extractor"
 	"github.com/dashboard-advisor/pkg/rules"
+	"github.com/prometheus/prometheus/promql/parser"
 )
 
 func testdataPath(name string) string {
@@ -23,16 +27,71 @@ func buildContext(t *testing.T, name string) *rules.AnalysisContext {
 	if err != nil {
 		t.Fatalf("failed to load %s: %v", name, err)
 	}
-	exprs := extractor.AllTargetExprs(dash)
+	exprs := append(extractor.AllTargetExprs(dash), extractor.AnnotationExprs(dash)...)
 	parsed, _ := analyzer.ParseAllExprs(exprs)
 	return &rules.AnalysisContext{
 		Dashboard:   dash,
 		Panels:      extractor.PanelsWithTargets(dash),
 		Variables:   dash.Templating.List,
 		ParsedExprs: parsed,
+		QueryCosts:  estimateCosts(parsed),
 	}
 }
 
+// buildExprContext builds an AnalysisContext for a synthetic dashboard with
+// one timeseries panel per expression (panel IDs 1..n). Use it for
+// flag/don't-flag cases that are too narrow to add to the demo dashboards.
+func buildExprContext(t *testing.T, exprs ...string) *rules.AnalysisContext {
+	t.Helper()
+	dash := &extractor.DashboardModel{UID: "synthetic", Title: "Synthetic"}
+	for i, e := range exprs {
+		dash.Panels = append(dash.Panels, extractor.PanelModel{
+			ID:      i + 1,
+			Title:   fmt.Sprintf("Panel %d", i+1),
+			Type:    "timeseries",
+			Targets: []extractor.TargetModel{{Expr: e, RefID: "A"}},
+		})
+	}
+	parsed, _ := analyzer.ParseAllExprs(extractor.AllTargetExprs(dash))
+	return &rules.AnalysisContext{
+		Dashboard:   dash,
+		Panels:      extractor.PanelsWithTargets(dash),
+		Variables:   dash.Templating.List,
+		ParsedExprs: parsed,
+		QueryCosts:  estimateCosts(parsed),
+	}
+}
+
+// buildJSONContext builds an AnalysisContext from an inline dashboard JSON
+// fixture, for scenarios the demo dashboards can't carry (e.g. datasources
+// the demo stack doesn't provision).
+func buildJSONContext(t *testing.T, dashboardJSON string) *rules.AnalysisContext {
+	t.Helper()
+	dash, err := extractor.ParseDashboard([]byte(dashboardJSON))
+	if err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	exprs := append(extractor.AllTargetExprs(dash), extractor.AnnotationExprs(dash)...)
+	parsed, _ := analyzer.ParseAllExprs(exprs)
+	return &rules.AnalysisContext{
+		Dashboard:   dash,
+		Panels:      extractor.PanelsWithTargets(dash),
+		Variables:   dash.Templating.List,
+		ParsedExprs: parsed,
+		QueryCosts:  estimateCosts(parsed),
+	}
+}
+
+// estimateCosts mirrors the engine's per-expression cost estimate (static,
+// 15s step) so cost-aware rules see the same values as in a real run.
+func estimateCosts(parsed map[string]parser.Expr) map[string]float64 {
+	costs := make(map[string]float64, len(parsed))
+	for raw, expr := range parsed {
+		costs[raw] = analyzer.EstimateQueryCost(expr, nil, 15.0)
+	}
+	return costs
+}
+
 // --- Q1: Missing label filters ---
 
 func TestQ1_SlowDashboard(t *testing.T) {
@@ -71,6 +130,79 @@ func TestQ1_FixedDashboard(t *testing.T) {
 	}
 }
 
+func TestQ1_FixUsesDashboardVariables(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "q1-vars",
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Restarts",
+			 "targets": [{"expr": "kube_pod_container_status_restarts_total", "refId": "A"}]}
+		],
+		"templating": {"list": [
+			{"name": "interval", "type": "interval", "query": "1m,5m"},
+			{"name": "namespace", "type": "query", "multi": true,
+			 "query": "label_values(kube_pod_info, namespace)"},
+			{"name": "svc", "type": "query",
+			 "query": {"query": "label_values(service)", "refId": "V"}}
+		]}
+	}`)
+
+	findings := (&rules.MissingFilters{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 Q1 finding, got %d", len(findings))
+	}
+	fix := findings[0].Fix
+	for _, want := range []string{`namespace=~"$namespace"`, `service="$svc"`} {
+		if !strings.Contains(fix, want) {
+			t.Errorf("fix %q should contain %s", fix, want)
+		}
+	}
+	if strings.Contains(fix, "$interval") {
+		t.Errorf("fix %q should not reference the interval variable", fix)
+	}
+}
+
+func TestQ1_FixSkipsNonLabelVariables(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "q1-non-label-vars",
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Restarts",
+			 "targets": [{"expr": "kube_pod_container_status_restarts_total", "refId": "A"}]}
+		],
+		"templating": {"list": [
+			{"name": "percentile", "type": "custom", "query": "0.5,0.9,0.99"},
+			{"name": "filter", "type": "textbox", "query": ""},
+			{"name": "metric", "type": "query", "query": "metrics(node_.*)"},
+			{"name": "instance", "type": "query", "query": "query_result(count by (instance) (up))"},
+			{"name": "namespace", "type": "query", "query": "label_values(kube_pod_info, namespace)"}
+		]}
+	}`)
+
+	findings := (&rules.MissingFilters{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 Q1 finding, got %d", len(findings))
+	}
+	fix := findings[0].Fix
+	if !strings.Contains(fix, `namespace="$namespace"`) {
+		t.Errorf("fix %q should contain the label_values() variable", fix)
+	}
+	for _, bad := range []string{"$percentile", "$filter", "$metric", "$instance"} {
+		if strings.Contains(fix, bad) {
+			t.Errorf("fix %q should not turn %s into a label matcher", fix, bad)
+		}
+	}
+}
+
+func TestQ1_FixGenericWithoutVariables(t *testing.T) {
+	ctx := buildExprContext(t, "up")
+	findings := (&rules.MissingFilters{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 Q1 finding, got %d", len(findings))
+	}
+	if !strings.Contains(findings[0].Fix, `job="..."`) {
+		t.Errorf("fix %q should fall back to the generic suggestion", findings[0].Fix)
+	}
+}
+
 // --- Q3: Regex as equality ---
 
 func TestQ3_SlowDashboard(t *testing.T) {
@@ -135,6 +267,32 @@ func TestD1_FixedDashboard(t *testing.T) {
 	}
 }
 
+func TestD1_MaxPanels(t *testing.T) {
+	exprs := make([]string, 30)
+	for i := range exprs {
+		exprs[i] = fmt.Sprintf(`up{job="api", instance="host-%d"}`, i)
+	}
+	ctx := buildExprContext(t, exprs...)
+
+	findings := (&rules.TooManyPanels{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("default D1 should fire on 30 panels, got %d findings", len(findings))
+	}
+	if !strings.Contains(findings[0].Why, "30 visible panels (threshold: 25)") {
+		t.Errorf("Why should include the count and threshold: %s", findings[0].Why)
+	}
+
+	if findings := (&rules.TooManyPanels{MaxPanels: 50}).Check(ctx); len(findings) > 0 {
+		t.Errorf("D1 with MaxPanels 50 should not fire on 30 panels, got %d findings", len(findings))
+	}
+	if findings := (&rules.TooManyPanels{Threshold: 50}).Check(ctx); len(findings) > 0 {
+		t.Errorf("D1 with the deprecated Threshold 50 should not fire on 30 panels, got %d findings", len(findings))
+	}
+	if findings := (&rules.TooManyPanels{MaxPanels: 25, Threshold: 50}).Check(ctx); len(findings) != 1 {
+		t.Errorf("MaxPanels should take precedence over Threshold, got %d findings", len(findings))
+	}
+}
+
 // --- Combined: score check ---
 
 func TestCombinedScore_SlowDashboard(t *testing.T) {
@@ -360,6 +518,40 @@ func TestQ7_FixedDashboard(t *testing.T) {
 	}
 }
 
+func TestQ7_ScrapeInterval(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum(rate(http_requests_total{job="api"}[1m]))`,
+		`sum(rate(http_requests_total{job="api"}[5m]))`,
+	)
+	rule := &rules.HardcodedInterval{}
+
+	// Without a scrape interval both windows are Medium with the generic text.
+	for _, f := range rule.Check(ctx) {
+		if f.Severity != rules.Medium || strings.Contains(f.Why, "scrape interval (") {
+			t.Errorf("panel %v without scrape interval: %s %q", f.PanelIDs, f.Severity, f.Why)
+		}
+	}
+
+	ctx.ScrapeInterval = 30 * time.Second
+	findings := rule.Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("Q7 with scrape interval: got %d findings, want 2", len(findings))
+	}
+	short, long := findings[0], findings[1]
+	if short.Severity != rules.High {
+		t.Errorf("[1m] at a 30s scrape interval: severity %s, want High", short.Severity)
+	}
+	if want := "[1m] window, below the recommended minimum of 4× the 30s scrape interval (2m)"; !strings.Contains(short.Why, want) {
+		t.Errorf("Why %q should contain %q", short.Why, want)
+	}
+	if long.Severity != rules.Medium || !strings.Contains(long.Why, "[5m] window, 10× the 30s scrape interval") {
+		t.Errorf("[5m] at a 30s scrape interval: %s %q", long.Severity, long.Why)
+	}
+	if !strings.Contains(long.Impact, "(2m)") {
+		t.Errorf("Impact should quantify the minimum window, got %q", long.Impact)
+	}
+}
+
 // --- Q8: Subquery abuse ---
 
 func TestQ8_SlowDashboard(t *testing.T) {
@@ -871,6 +1063,93 @@ func TestB6_WithoutCardinality(t *testing.T) {
 	}
 }
 
+// --- B8: Query exceeds max-samples ---
+
+func TestB8_CardinalityPushesQueryOverLimit(t *testing.T) {
+	// 30m at a 15s scrape interval is 120 samples per series.
+	ctx := buildExprContext(t, `sum(rate(http_requests_total{job="api"}[30m]))`)
+	rule := &rules.MaxSamplesExceeded{}
+
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Fatalf("B8 should not fire without cardinality data, got %d findings", len(findings))
+	}
+
+	ctx.Cardinality = &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{"http_requests_total": 100_000},
+	}
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Fatalf("B8 should not fire at 12M samples, got %d findings", len(findings))
+	}
+
+	ctx.Cardinality.SeriesByMetric["http_requests_total"] = 1_000_000
+	findings := rule.Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("B8 should fire at 120M samples, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Critical {
+		t.Errorf("severity = %s, want Critical", f.Severity)
+	}
+	if f.TargetExpr == "" || len(f.PanelIDs) != 1 || f.PanelIDs[0] != 1 {
+		t.Errorf("finding should point at panel 1's target, got panels %v expr %q", f.PanelIDs, f.TargetExpr)
+	}
+}
+
+func TestB8_ConfigurableLimit(t *testing.T) {
+	ctx := buildExprContext(t,
+		`max_over_time(node_load1{instance="a"}[1h:1m])`, // 60 evaluations × 1000 series
+		`node_load1{instance="a"}`,                       // 1000 samples
+	)
+	ctx.Cardinality = &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{"node_load1": 1000},
+	}
+
+	if findings := (&rules.MaxSamplesExceeded{}).Check(ctx); len(findings) != 0 {
+		t.Errorf("B8 should not fire under the default 50M limit, got %d findings", len(findings))
+	}
+	findings := (&rules.MaxSamplesExceeded{MaxSamples: 50_000}).Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 1 {
+		t.Fatalf("B8 with a 50K limit should flag only the subquery panel, got %+v", findings)
+	}
+}
+
+func TestB8_ContextScrapeInterval(t *testing.T) {
+	// 1M series × 30m: 120M samples at the 15s default, 30M at 60s.
+	ctx := buildExprContext(t, `sum(rate(http_requests_total{job="api"}[30m]))`)
+	ctx.Cardinality = &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{"http_requests_total": 1_000_000},
+	}
+	if findings := (&rules.MaxSamplesExceeded{}).Check(ctx); len(findings) != 1 {
+		t.Fatalf("B8 should fire at the 15s default, got %d findings", len(findings))
+	}
+	ctx.ScrapeInterval = time.Minute
+	if findings := (&rules.MaxSamplesExceeded{}).Check(ctx); len(findings) != 0 {
+		t.Errorf("B8 should estimate at the context's 60s scrape interval, got %d findings", len(findings))
+	}
+	if findings := (&rules.MaxSamplesExceeded{ScrapeInterval: 15 * time.Second}).Check(ctx); len(findings) != 1 {
+		t.Errorf("the rule's own ScrapeInterval should take precedence, got %d findings", len(findings))
+	}
+}
+
+func TestB8_DemoDashboards(t *testing.T) {
+	// Series counts as the demo exporter emits them.
+	card := &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{"http_requests_total": 720, "prometheus_tsdb_compactions_total": 1},
+	}
+	rule := &rules.MaxSamplesExceeded{}
+	ctx := buildContext(t, "slow-by-design.json")
+	ctx.Cardinality = card
+	findings := rule.Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 56 {
+		t.Fatalf("B8 should flag panel 56 (two increase() over 7d) on the slow dashboard, got %v", findings)
+	}
+	ctx = buildContext(t, "fixed-by-advisor.json")
+	ctx.Cardinality = card
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("B8 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
 // --- Q11: rate() on gauge metric ---
 
 func TestQ11_SlowDashboard(t *testing.T) {
@@ -913,6 +1192,62 @@ func TestQ11_FixedDashboard(t *testing.T) {
 	}
 }
 
+func TestQ11_MetricTypeOverrides(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(my_custom_temp{job="sensor"}[5m])`,
+		`rate(acme_queue_depth{job="worker"}[5m])`,
+		`rate(go_goroutines{job="api"}[5m])`,
+	)
+	rule := &rules.RateOnGauge{}
+	if findings := rule.Check(ctx); len(findings) != 1 || findings[0].PanelIDs[0] != 3 {
+		t.Fatalf("Q11 without overrides should flag only the built-in gauge, got %v", findings)
+	}
+
+	ctx.MetricTypes = rules.MetricTypes{
+		"my_custom_temp": rules.MetricTypeGauge,
+		"acme_queue_*":   rules.MetricTypeGauge,
+		"go_goroutines":  rules.MetricTypeCounter, // overrides the built-in list
+	}
+	findings := rule.Check(ctx)
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Confidence != 0.9 {
+			t.Errorf("panel %v: confidence %v, want 0.9 for a classified metric", f.PanelIDs, f.Confidence)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q11 with overrides flagged panels %v, want [1 2]", got)
+	}
+}
+
+func TestMetricTypes_Lookup(t *testing.T) {
+	types := rules.MetricTypes{
+		"acme_*":        rules.MetricTypeCounter,
+		"acme_queue_*":  rules.MetricTypeGauge,
+		"acme_queue_in": rules.MetricTypeHistogram,
+	}
+	tests := []struct {
+		name string
+		want rules.MetricType
+		ok   bool
+	}{
+		{"acme_queue_in", rules.MetricTypeHistogram, true}, // exact beats prefix
+		{"acme_queue_depth", rules.MetricTypeGauge, true},  // longest prefix wins
+		{"acme_jobs", rules.MetricTypeCounter, true},
+		{"other", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := types.Lookup(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := rules.MetricTypes(nil).Lookup("acme_jobs"); ok {
+		t.Error("nil MetricTypes should classify nothing")
+	}
+}
+
 // --- Q12: Impossible vector matching ---
 
 func TestQ12_SlowDashboard(t *testing.T) {
@@ -941,3 +1276,3308 @@ func TestQ12_FixedDashboard(t *testing.T) {
 		}
 	}
 }
+
+// --- Q15: histogram_quantile() on raw buckets ---
+
+func TestQ15_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.HistogramQuantileWithoutRate{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q15 should flag exactly panel 31 (raw bucket quantile), got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.RuleID != "Q15" || f.Severity != rules.High {
+		t.Errorf("finding = %s/%s, want Q15/High", f.RuleID, f.Severity)
+	}
+	if len(f.PanelIDs) != 1 || f.PanelIDs[0] != 31 {
+		t.Errorf("Q15 flagged panels %v, want [31]", f.PanelIDs)
+	}
+}
+
+func TestQ15_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.HistogramQuantileWithoutRate{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("Q15 should find no issues in fixed dashboard, got %d:", len(findings))
+		for _, f := range findings {
+			t.Logf("  %s", f.Why)
+		}
+	}
+}
+
+func TestQ15_RawVsRatedBuckets(t *testing.T) {
+	tests := []struct {
+		expr string
+		want int
+	}{
+		{`histogram_quantile(0.9, http_request_duration_seconds_bucket{job="api"})`, 1},
+		{`histogram_quantile(0.9, sum by(le) (http_request_duration_seconds_bucket{job="api"}))`, 1},
+		{`histogram_quantile(0.9, sum by(le) (rate(http_request_duration_seconds_bucket{job="api"}[5m])))`, 0},
+		{`histogram_quantile(0.9, sum by(le) (increase(http_request_duration_seconds_bucket{job="api"}[1h])))`, 0},
+		{`histogram_quantile(0.9, rate(http_request_duration_seconds_bucket{job="api"}[$__rate_interval]))`, 0},
+	}
+	rule := &rules.HistogramQuantileWithoutRate{}
+	for _, tt := range tests {
+		ctx := buildExprContext(t, tt.expr)
+		if got := len(rule.Check(ctx)); got != tt.want {
+			t.Errorf("Q15 on %s: got %d findings, want %d", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// --- D11: Prometheus mixed with slow datasource types ---
+
+func TestD11_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.SlowDatasourceMixing{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("D11 should flag the Elasticsearch target of panel 36, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.RuleID != "D11" || f.Severity != rules.Low {
+		t.Errorf("finding = %s/%s, want D11/Low", f.RuleID, f.Severity)
+	}
+	if !strings.Contains(f.Why, "[elasticsearch]") {
+		t.Errorf("Why should name elasticsearch only: %s", f.Why)
+	}
+
+	// Elasticsearch is only slow because the default list says so.
+	if findings := (&rules.SlowDatasourceMixing{SlowTypes: []string{"mysql"}}).Check(ctx); len(findings) != 0 {
+		t.Errorf("D11 with SlowTypes [mysql]: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestD11_LokiOnlySlowOverWideRange(t *testing.T) {
+	ctx := buildJSONContext(t, lokiFixture)
+	rule := &rules.SlowDatasourceMixing{}
+
+	ctx.Dashboard.Time.From = "now-1h"
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("D11 should not flag Prometheus + Loki over 1h, got %d findings", len(findings))
+	}
+	ctx.Dashboard.Time.From = "now-24h"
+	findings := rule.Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("D11 should flag Prometheus + Loki over 24h, got %d findings", len(findings))
+	}
+	if !strings.Contains(findings[0].Why, "[loki]") {
+		t.Errorf("Why should name loki: %s", findings[0].Why)
+	}
+}
+
+func TestD11_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.SlowDatasourceMixing{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("D11 should find no issues in Prometheus-only fixed dashboard, got %d", len(findings))
+	}
+}
+
+// --- Q16: Rate window not aligned to scrape interval ---
+
+func TestQ16_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.RateWindowAlignment{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q16 should flag exactly panel 32 ([95s] at 30s scrape), got %d findings", len(findings))
+	}
+	if findings[0].PanelIDs[0] != 32 || findings[0].Severity != rules.Low {
+		t.Errorf("Q16 finding = panel %v/%s, want panel 32/Low", findings[0].PanelIDs, findings[0].Severity)
+	}
+}
+
+func TestQ16_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.RateWindowAlignment{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("Q16 should find no issues in fixed dashboard, got %d", len(findings))
+	}
+}
+
+func TestQ16_WindowVsScrapeInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		scrape time.Duration
+		want   int
+	}{
+		{"5m at default 30s", `rate(http_requests_total{job="api"}[5m])`, 0, 0},
+		{"7m at default 30s is 14 scrapes", `rate(http_requests_total{job="api"}[7m])`, 0, 0},
+		{"7m at 45s", `rate(http_requests_total{job="api"}[7m])`, 45 * time.Second, 1},
+		{"95s at default 30s", `increase(http_requests_total{job="api"}[95s])`, 0, 1},
+		{"rate interval variable", `rate(http_requests_total{job="api"}[$__rate_interval])`, 45 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := buildExprContext(t, tt.expr)
+			rule := &rules.RateWindowAlignment{ScrapeInterval: tt.scrape}
+			if got := len(rule.Check(ctx)); got != tt.want {
+				t.Errorf("got %d findings, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQ16_ContextScrapeInterval(t *testing.T) {
+	ctx := buildExprContext(t, `rate(http_requests_total{job="api"}[7m])`)
+	ctx.ScrapeInterval = 45 * time.Second
+	if got := len((&rules.RateWindowAlignment{}).Check(ctx)); got != 1 {
+		t.Errorf("7m at a 45s context scrape interval: got %d findings, want 1", got)
+	}
+	if got := len((&rules.RateWindowAlignment{ScrapeInterval: 30 * time.Second}).Check(ctx)); got != 0 {
+		t.Errorf("the rule's own ScrapeInterval should take precedence: got %d findings, want 0", got)
+	}
+}
+
+// --- Q17: sort() on a time-series panel ---
+
+func TestQ17_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.SortOnTimeSeries{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q17 should flag exactly panel 33 (sort_desc on timeseries), got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.PanelIDs[0] != 33 || f.Severity != rules.Low || !f.AutoFixable {
+		t.Errorf("Q17 finding = panel %v/%s/autofix=%v, want panel 33/Low/true", f.PanelIDs, f.Severity, f.AutoFixable)
+	}
+}
+
+func TestQ17_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.SortOnTimeSeries{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("Q17 should find no issues in fixed dashboard, got %d", len(findings))
+	}
+}
+
+func TestQ17_OnlyOutermostOnTimeSeries(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sort(sum by(job) (up{job="api"}))`,
+		`sum(sort(up{job="api"}))`,
+		`topk(5, sort_desc(up{job="api"}))`,
+	)
+	// A table panel legitimately sorts its rows.
+	ctx.Panels = append(ctx.Panels, ctx.Panels[0])
+	ctx.Panels[3].ID = 4
+	ctx.Panels[3].Type = "table"
+
+	rule := &rules.SortOnTimeSeries{}
+	findings := rule.Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 1 {
+		t.Errorf("Q17 should flag only panel 1 (outermost sort on timeseries), got %d findings", len(findings))
+		for _, f := range findings {
+			t.Logf("  panel %v: %s", f.PanelIDs, f.Why)
+		}
+	}
+}
+
+// --- Q18: delta() on counter ---
+
+func TestQ18_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.DeltaOnCounter{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q18 should flag exactly panel 34 (delta on http_requests_total), got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.PanelIDs[0] != 34 || f.Severity != rules.Medium || !f.AutoFixable {
+		t.Errorf("Q18 finding = panel %v/%s/autofix=%v, want panel 34/Medium/true", f.PanelIDs, f.Severity, f.AutoFixable)
+	}
+}
+
+func TestQ18_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.DeltaOnCounter{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("Q18 should find no issues in fixed dashboard, got %d", len(findings))
+	}
+}
+
+func TestQ18_CountersVsGauges(t *testing.T) {
+	ctx := buildExprContext(t,
+		`delta(http_requests_total{job="api"}[5m])`,
+		`idelta(http_requests_total{job="api"}[5m])`,
+		`delta(node_memory_MemAvailable_bytes{job="node"}[5m])`,
+		`increase(http_requests_total{job="api"}[5m])`,
+	)
+	findings := (&rules.DeltaOnCounter{}).Check(ctx)
+
+	if len(findings) != 2 {
+		t.Fatalf("Q18 should flag delta and idelta on the counter only, got %d findings", len(findings))
+	}
+	if findings[0].PanelIDs[0] != 1 || !findings[0].AutoFixable {
+		t.Errorf("delta() finding = panel %v/autofix=%v, want panel 1/true", findings[0].PanelIDs, findings[0].AutoFixable)
+	}
+	if findings[1].PanelIDs[0] != 2 || findings[1].AutoFixable {
+		t.Errorf("idelta() finding = panel %v/autofix=%v, want panel 2/false", findings[1].PanelIDs, findings[1].AutoFixable)
+	}
+}
+
+// --- D12: No default time range ---
+
+func TestD12_NoTimeRange(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "no-time-range",
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Up",
+			 "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]}
+		]
+	}`)
+
+	findings := (&rules.MissingTimeRange{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("D12 should flag a dashboard without time.from, got %d findings", len(findings))
+	}
+	if findings[0].RuleID != "D12" || findings[0].Severity != rules.Low {
+		t.Errorf("finding = %s/%s, want D12/Low", findings[0].RuleID, findings[0].Severity)
+	}
+
+	// D6 bails out on an empty range, so the two rules never double-report.
+	if d6 := (&rules.RangeTooWide{}).Check(ctx); len(d6) != 0 {
+		t.Errorf("D6 should not fire on an empty time range, got %d findings", len(d6))
+	}
+}
+
+// D12 and D6 cannot both fire on one dashboard: the slow dashboard keeps its
+// 7d range for D6 and D33, so D12 is checked on it with the range removed.
+func TestD12_DemoDashboards(t *testing.T) {
+	rule := &rules.MissingTimeRange{}
+	ctx := buildContext(t, "slow-by-design.json")
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("D12 should leave the slow dashboard's 7d range to D6, got %d findings", len(findings))
+	}
+	ctx.Dashboard.Time.From = ""
+	if findings := rule.Check(ctx); len(findings) != 1 {
+		t.Errorf("D12 should fire on the slow dashboard without a time range, got %d findings", len(findings))
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D12 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D13: Repeated row containing repeated panels ---
+
+// nestedRepeatFixture has a collapsed row repeating by $cluster that holds a
+// panel repeating by $pod, an expanded row repeating by $namespace followed by
+// a panel repeating by $pod, and a plain expanded row whose panel repeats
+// (panel-level repeat only, which D13 must ignore).
+const nestedRepeatFixture = `{
+	"uid": "nested-repeat",
+	"panels": [
+		{"id": 1, "type": "row", "title": "Cluster $cluster", "repeat": "cluster", "collapsed": true,
+		 "panels": [
+			{"id": 2, "type": "timeseries", "title": "Pod CPU", "repeat": "pod",
+			 "targets": [{"expr": "sum(rate(container_cpu_usage_seconds_total{pod=\"$pod\"}[5m]))", "refId": "A"}]},
+			{"id": 3, "type": "stat", "title": "Pods",
+			 "targets": [{"expr": "count(kube_pod_info{cluster=\"$cluster\"})", "refId": "A"}]}
+		 ]},
+		{"id": 10, "type": "row", "title": "Namespace $namespace", "repeat": "namespace", "collapsed": false},
+		{"id": 11, "type": "timeseries", "title": "Pod Memory", "repeat": "pod",
+		 "targets": [{"expr": "sum(container_memory_working_set_bytes{pod=\"$pod\"})", "refId": "A"}]},
+		{"id": 20, "type": "row", "title": "Overview", "collapsed": false},
+		{"id": 21, "type": "timeseries", "title": "Per-Pod Restarts", "repeat": "pod",
+		 "targets": [{"expr": "sum(kube_pod_container_status_restarts_total{pod=\"$pod\"})", "refId": "A"}]}
+	]
+}`
+
+func TestD13_NestedRepeats(t *testing.T) {
+	ctx := buildJSONContext(t, nestedRepeatFixture)
+	findings := (&rules.NestedRepeat{}).Check(ctx)
+
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 D13 findings (collapsed + expanded row), got %d", len(findings))
+	}
+	want := [][]int{{1, 2}, {10, 11}}
+	for i, f := range findings {
+		if f.RuleID != "D13" || f.Severity != rules.High {
+			t.Errorf("finding %d = %s/%s, want D13/High", i, f.RuleID, f.Severity)
+		}
+		if fmt.Sprint(f.PanelIDs) != fmt.Sprint(want[i]) {
+			t.Errorf("finding %d PanelIDs = %v, want %v", i, f.PanelIDs, want[i])
+		}
+	}
+}
+
+func TestD13_DemoDashboards(t *testing.T) {
+	rule := &rules.NestedRepeat{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 {
+		t.Fatalf("D13 should flag the repeated row on the slow dashboard, got %d findings", len(findings))
+	}
+	if got := fmt.Sprint(findings[0].PanelIDs); got != "[102 37]" {
+		t.Errorf("D13 panel IDs = %s, want [102 37] (row, then repeated panel)", got)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D13 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D14: Excessive annotation queries ---
+
+// annotationsFixture has Grafana's built-in annotation, four enabled
+// Prometheus annotations (one with a bare selector) and one disabled
+// annotation with a subquery.
+const annotationsFixture = `{
+	"uid": "annotations",
+	"time": {"from": "now-1h", "to": "now"},
+	"annotations": {"list": [
+		{"builtIn": 1, "enable": true, "hide": true, "name": "Annotations & Alerts",
+		 "datasource": {"type": "grafana", "uid": "-- Grafana --"}},
+		{"enable": true, "name": "Deploys", "datasource": {"type": "prometheus", "uid": "prom"},
+		 "expr": "changes(kube_deployment_status_observed_generation{namespace=\"$namespace\"}[5m]) > 0"},
+		{"enable": true, "name": "Restarts", "datasource": {"type": "prometheus", "uid": "prom"},
+		 "expr": "changes(kube_pod_container_status_restarts_total[$__rate_interval]) > 0"},
+		{"enable": true, "name": "Alerts", "datasource": {"type": "prometheus", "uid": "prom"},
+		 "expr": "ALERTS{alertstate=\"firing\", namespace=\"$namespace\"}"},
+		{"enable": true, "name": "Config reloads", "datasource": {"type": "prometheus", "uid": "prom"},
+		 "expr": "changes(prometheus_config_last_reload_success_timestamp_seconds{job=\"prometheus\"}[5m]) > 0"},
+		{"enable": false, "name": "Slow SLO burn", "datasource": {"type": "prometheus", "uid": "prom"},
+		 "expr": "max_over_time(rate(http_requests_total{job=\"api\"}[5m])[1d:1m])"}
+	]},
+	"panels": []
+}`
+
+func TestD14_Annotations(t *testing.T) {
+	ctx := buildJSONContext(t, annotationsFixture)
+	findings := (&rules.ExcessiveAnnotations{}).Check(ctx)
+
+	if len(findings) != 2 {
+		for _, f := range findings {
+			t.Logf("  [%s] %s — %s", f.Severity, f.Title, f.Why)
+		}
+		t.Fatalf("expected 2 D14 findings (expensive Restarts + too many enabled), got %d", len(findings))
+	}
+	if f := findings[0]; f.Severity != rules.Medium || !strings.Contains(f.Why, `"Restarts"`) {
+		t.Errorf("first finding = %s %q, want Medium on the Restarts annotation", f.Severity, f.Why)
+	}
+	if f := findings[1]; f.Severity != rules.Low || !strings.Contains(f.Why, "4 enabled") {
+		t.Errorf("second finding = %s %q, want Low counting 4 enabled annotations", f.Severity, f.Why)
+	}
+
+	// A higher threshold leaves only the expensive-query finding.
+	findings = (&rules.ExcessiveAnnotations{MaxEnabled: 4}).Check(ctx)
+	if len(findings) != 1 || findings[0].Severity != rules.Medium {
+		t.Errorf("MaxEnabled=4 should leave only the Medium finding, got %d findings", len(findings))
+	}
+}
+
+func TestD14_DemoDashboards(t *testing.T) {
+	rule := &rules.ExcessiveAnnotations{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 {
+		t.Fatalf("D14 should flag the unfiltered compactions annotation on the slow dashboard, got %d findings", len(findings))
+	}
+	if f := findings[0]; f.Severity != rules.Medium || !strings.Contains(f.Why, `"TSDB Compactions" selects every series of "prometheus_tsdb_compactions_total"`) {
+		t.Errorf("unexpected D14 finding: %s %q", f.Severity, f.Why)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D14 should not fire on the fixed dashboard (built-in annotation only), got %d findings", len(findings))
+	}
+}
+
+// --- D15: Alert condition references a missing query ---
+
+// alertDriftFixture has one panel whose alert queries B while its only target
+// is A (drifted), one whose alert matches its target, and a nested panel in a
+// collapsed row whose alert also drifted.
+const alertDriftFixture = `{
+	"uid": "alert-drift",
+	"panels": [
+		{"id": 1, "type": "graph", "title": "Error Rate",
+		 "targets": [{"expr": "sum(rate(http_requests_total{code=~\"5..\"}[5m]))", "refId": "A"}],
+		 "alert": {"name": "High error rate", "conditions": [
+			{"type": "query", "query": {"params": ["B", "5m", "now"]},
+			 "evaluator": {"type": "gt", "params": [5]}}
+		 ]}},
+		{"id": 2, "type": "graph", "title": "Latency",
+		 "targets": [{"expr": "histogram_quantile(0.99, sum by(le) (rate(http_request_duration_seconds_bucket[5m])))", "refId": "A"}],
+		 "alert": {"name": "Slow requests", "conditions": [
+			{"type": "query", "query": {"params": ["A", "5m", "now"]},
+			 "evaluator": {"type": "gt", "params": [0.5]}}
+		 ]}},
+		{"id": 3, "type": "row", "title": "Saturation", "collapsed": true, "panels": [
+			{"id": 4, "type": "graph", "title": "CPU",
+			 "targets": [{"expr": "sum(rate(container_cpu_usage_seconds_total[5m]))", "refId": "A"}],
+			 "alert": {"name": "CPU high", "conditions": [
+				{"type": "query", "query": {"params": ["C", "10m", "now"]}}
+			 ]}}
+		]}
+	]
+}`
+
+func TestD15_AlertDrift(t *testing.T) {
+	ctx := buildJSONContext(t, alertDriftFixture)
+	findings := (&rules.AlertRefMismatch{}).Check(ctx)
+
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 D15 findings (panels 1 and 4), got %d", len(findings))
+	}
+	for i, want := range []int{1, 4} {
+		f := findings[i]
+		if f.PanelIDs[0] != want || f.Severity != rules.Low {
+			t.Errorf("finding %d = panel %v/%s, want panel %d/Low", i, f.PanelIDs, f.Severity, want)
+		}
+	}
+	if !strings.Contains(findings[0].Why, "query B") {
+		t.Errorf("Why should name the missing RefID: %s", findings[0].Why)
+	}
+}
+
+func TestD15_DemoDashboards(t *testing.T) {
+	rule := &rules.AlertRefMismatch{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 38 {
+		t.Fatalf("D15 should flag panel 38's alert on query B, got %v", findings)
+	}
+	if !strings.Contains(findings[0].Why, "evaluates query B, but the panel's targets are [A]") {
+		t.Errorf("unexpected D15 Why: %q", findings[0].Why)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D15 should not fire on the fixed dashboard (no panel alerts), got %d findings", len(findings))
+	}
+}
+
+// --- Q19: $__range as rate window ---
+
+func TestQ19_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	rule := &rules.RangeAsRateWindow{}
+	findings := rule.Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q19 should flag exactly panel 35 (rate over $__range), got %d findings", len(findings))
+	}
+	if f := findings[0]; f.PanelIDs[0] != 35 || f.Severity != rules.Medium {
+		t.Errorf("Q19 finding = panel %v/%s, want panel 35/Medium", f.PanelIDs, f.Severity)
+	}
+}
+
+func TestQ19_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	rule := &rules.RangeAsRateWindow{}
+	findings := rule.Check(ctx)
+
+	if len(findings) > 0 {
+		t.Errorf("Q19 should find no issues in fixed dashboard, got %d", len(findings))
+	}
+}
+
+func TestQ19_RawExpression(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum(rate(http_requests_total{job="api"}[$__range]))`,
+		`increase(http_requests_total{job="api"}[${__range}])`,
+		`sum(rate(http_requests_total{job="api"}[$__rate_interval]))`,
+		`avg_over_time(up{job="api"}[$__range])`,
+		`sum(increase(http_requests_total{job="api"}[$__range]))`,
+	)
+	// A stat panel showing the total over the range is the idiomatic use.
+	ctx.Panels[4].Type = "stat"
+
+	// Both $__range spellings parse to the same AST as $__rate_interval;
+	// only the raw string tells them apart.
+	if fmt.Sprint(ctx.ParsedExprs[ctx.Panels[0].Targets[0].Expr]) != fmt.Sprint(ctx.ParsedExprs[ctx.Panels[2].Targets[0].Expr]) {
+		t.Fatal("expected $__range and $__rate_interval to parse identically")
+	}
+
+	findings := (&rules.RangeAsRateWindow{}).Check(ctx)
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q19 flagged panels %v, want [1 2]", got)
+	}
+}
+
+// --- Q20: Negative offset ---
+
+func TestQ20_NegativeOffset(t *testing.T) {
+	ctx := buildExprContext(t,
+		`up offset -1h`,
+		`sum(rate(http_requests_total{job="api"}[5m] offset -30m))`,
+		`max_over_time(up{job="api"}[1h:5m] offset -1d)`,
+		`up{job="api"} offset 1h`,
+		`up{job="api"}`,
+	)
+	findings := (&rules.NegativeOffset{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.High || f.Confidence != 0.7 {
+			t.Errorf("finding on panel %v = %s/%.1f, want High/0.7", f.PanelIDs, f.Severity, f.Confidence)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Q20 flagged panels %v, want [1 2 3]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Fix, "offset 1h") {
+		t.Errorf("fix should suggest the positive offset: %s", findings[0].Fix)
+	}
+}
+
+func TestQ20_DemoDashboards(t *testing.T) {
+	rule := &rules.NegativeOffset{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 39 {
+		t.Fatalf("Q20 should flag panel 39 (offset -1h) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q20 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q21: Recording rule candidate ---
+
+// recordingRuleFixture repeats a 1d max_over_time() across three panels
+// inside different outer expressions, so Q9 (whole-expression duplicates)
+// stays silent while Q21 fires. The 30m and $__range windows never count.
+const recordingRuleFixture = `{
+	"uid": "recording-rule-candidate",
+	"panels": [
+		{"id": 1, "type": "stat", "title": "Peak Memory",
+		 "targets": [{"expr": "max(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[1d]))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Memory vs Peak",
+		 "targets": [
+			{"expr": "sum(container_memory_working_set_bytes{namespace=\"shop\"})", "refId": "A"},
+			{"expr": "sum(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[1d]))", "refId": "B"}
+		 ]},
+		{"id": 3, "type": "table", "title": "Peak by Pod",
+		 "targets": [{"expr": "topk(10, max_over_time(container_memory_working_set_bytes{ namespace=\"shop\" }[1d]))", "refId": "A"}]},
+		{"id": 4, "type": "stat", "title": "Recent Peak",
+		 "targets": [{"expr": "max(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[30m]))", "refId": "A"}]},
+		{"id": 5, "type": "stat", "title": "Recent Peak 2",
+		 "targets": [{"expr": "sum(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[30m]))", "refId": "A"}]},
+		{"id": 6, "type": "stat", "title": "Recent Peak 3",
+		 "targets": [{"expr": "min(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[30m]))", "refId": "A"}]},
+		{"id": 7, "type": "stat", "title": "Range Peak",
+		 "targets": [{"expr": "max(max_over_time(container_memory_working_set_bytes{namespace=\"shop\"}[$__range]))", "refId": "A"}]}
+	]
+}`
+
+func TestQ21_RecordingRuleCandidate(t *testing.T) {
+	ctx := buildJSONContext(t, recordingRuleFixture)
+	findings := (&rules.RecordingRuleCandidate{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q21 should flag exactly the shared 1d window, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium {
+		t.Errorf("severity = %s, want Medium", f.Severity)
+	}
+	if fmt.Sprint(f.PanelIDs) != "[1 2 3]" {
+		t.Errorf("PanelIDs = %v, want [1 2 3]", f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, "max_over_time") || !strings.Contains(f.Fix, "recording rule") {
+		t.Errorf("finding should name the call and suggest a recording rule: %s / %s", f.Why, f.Fix)
+	}
+
+	if q9 := (&rules.DuplicateExpressions{}).Check(ctx); len(q9) > 0 {
+		t.Errorf("fixture should not trigger Q9, got %d findings", len(q9))
+	}
+	if findings := (&rules.RecordingRuleCandidate{MinPanels: 4}).Check(ctx); len(findings) > 0 {
+		t.Errorf("Q21 with MinPanels 4 should not fire, got %d findings", len(findings))
+	}
+}
+
+func TestQ21_DemoDashboards(t *testing.T) {
+	rule := &rules.RecordingRuleCandidate{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 {
+		t.Fatalf("Q21 should flag the shared 6h max_over_time() on the slow dashboard, got %d findings", len(findings))
+	}
+	if got := fmt.Sprint(findings[0].PanelIDs); got != "[40 41 42]" {
+		t.Errorf("Q21 panel IDs = %s, want [40 41 42]", got)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q21 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q22: Info metric aggregation ---
+
+func TestQ22_InfoMetricAggregation(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum(kube_pod_info)`,
+		`kube_pod_info * on(pod) group_left(node) up`,
+		`avg(kube_pod_labels{namespace="shop"} * 2)`,
+		`rate(node_uname_info[5m])`,
+		`count(kube_pod_info)`,
+		`sum(up * on(instance) group_left(version) build_info)`,
+		`sum(kube_pod_info) + sum(kube_pod_info)`,
+	)
+	findings := (&rules.InfoMetricAggregation{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v severity = %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 3 4 7]" {
+		t.Errorf("Q22 flagged panels %v, want [1 3 4 7]", got)
+	}
+}
+
+func TestQ22_DemoDashboards(t *testing.T) {
+	rule := &rules.InfoMetricAggregation{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 43 {
+		t.Fatalf("Q22 should flag panel 43 (sum of kube_pod_info) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q22 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D16: Unused repeat variable ---
+
+const unusedRepeatFixture = `{
+	"uid": "unused-repeat",
+	"templating": {"list": [
+		{"name": "instance", "type": "query", "multi": true},
+		{"name": "ds", "type": "datasource"}
+	]},
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "CPU", "repeat": "instance",
+		 "targets": [{"expr": "sum(rate(node_cpu_seconds_total{mode!=\"idle\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "CPU by Instance", "repeat": "instance",
+		 "targets": [{"expr": "sum(rate(node_cpu_seconds_total{instance=~\"$instance\"}[5m]))", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Memory by Instance", "repeat": "instance",
+		 "targets": [{"expr": "node_memory_MemAvailable_bytes{instance=\"${instance:regex}\"}", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Load", "repeat": "instance",
+		 "targets": [{"expr": "node_load1{instance=~\"$instance_name\"}", "refId": "A"}]},
+		{"id": 5, "type": "timeseries", "title": "Up per Datasource", "repeat": "ds",
+		 "datasource": {"type": "prometheus", "uid": "${ds}"},
+		 "targets": [{"expr": "sum(up)", "refId": "A"}]},
+		{"id": 6, "type": "text", "title": "Notes", "repeat": "instance"},
+		{"id": 10, "type": "row", "title": "Details", "collapsed": true,
+		 "panels": [
+			{"id": 11, "type": "stat", "title": "Disk", "repeat": "instance",
+			 "targets": [{"expr": "sum(node_filesystem_avail_bytes)", "refId": "A"}]}
+		 ]}
+	]
+}`
+
+func TestD16_UnusedRepeatVariable(t *testing.T) {
+	ctx := buildJSONContext(t, unusedRepeatFixture)
+	findings := (&rules.UnusedRepeatVariable{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("panel %v severity = %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	// 4 references $instance_name, a different variable; 11 sits in a collapsed row.
+	if fmt.Sprint(got) != "[1 4 11]" {
+		t.Errorf("D16 flagged panels %v, want [1 4 11]", got)
+	}
+}
+
+func TestD16_DemoDashboards(t *testing.T) {
+	rule := &rules.UnusedRepeatVariable{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 44 {
+		t.Fatalf("D16 should flag panel 44 (repeats by $pod without using it) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D16 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D17: Variable chains ---
+
+// variableChainFixture chains $pod → $namespace → $cluster. $env is a custom
+// variable and does not count as a waterfall step; $a and $b form a cycle.
+const variableChainFixture = `{
+	"uid": "variable-chain",
+	"templating": {"list": [
+		{"name": "env", "type": "custom", "query": "prod,staging"},
+		{"name": "cluster", "type": "query", "query": "label_values(up{env=\"$env\"}, cluster)"},
+		{"name": "namespace", "type": "query", "query": {"query": "label_values(kube_pod_info{cluster=\"$cluster\"}, namespace)", "refId": "A"}},
+		{"name": "pod", "type": "query", "query": "label_values(kube_pod_info{cluster=\"$cluster\", namespace=~\"${namespace:regex}\"}, pod)"},
+		{"name": "a", "type": "query", "query": "label_values(up{job=\"$b\"}, instance)"},
+		{"name": "b", "type": "query", "query": "label_values(up{instance=\"$a\"}, job)"}
+	]},
+	"panels": []
+}`
+
+func TestD17_VariableChain(t *testing.T) {
+	ctx := buildJSONContext(t, variableChainFixture)
+	findings := (&rules.VariableChain{}).Check(ctx)
+
+	if len(findings) != 2 {
+		t.Fatalf("D17 should report one cycle and one chain, got %d findings", len(findings))
+	}
+	cycle, chain := findings[0], findings[1]
+	if cycle.Severity != rules.High || !strings.Contains(cycle.Why, "$a → $b → $a") {
+		t.Errorf("cycle finding = %s: %s", cycle.Severity, cycle.Why)
+	}
+	if chain.Severity != rules.Medium || !strings.Contains(chain.Why, "$cluster → $namespace → $pod (3 levels, threshold: 2)") {
+		t.Errorf("chain finding = %s: %s", chain.Severity, chain.Why)
+	}
+
+	for _, f := range (&rules.VariableChain{MaxDepth: 3}).Check(ctx) {
+		if f.Severity == rules.Medium {
+			t.Errorf("D17 with MaxDepth 3 should not flag the 3-level chain: %s", f.Why)
+		}
+	}
+}
+
+func TestD17_DemoDashboards(t *testing.T) {
+	rule := &rules.VariableChain{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, "$namespace → $job → $target (3 levels") {
+		t.Fatalf("D17 should flag the namespace → job → target chain on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D17 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D18: Old schema version ---
+
+const oldSchemaFixture = `{
+	"uid": "old-schema",
+	"schemaVersion": %d,
+	"panels": [
+		{"id": 1, "type": "graph", "title": "Requests",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]}
+	]
+}`
+
+func TestD18_OldSchemaVersion(t *testing.T) {
+	cases := []struct {
+		version int
+		rule    *rules.OldSchemaVersion
+		want    int
+	}{
+		{version: 16, rule: &rules.OldSchemaVersion{}, want: 1},
+		{version: 30, rule: &rules.OldSchemaVersion{}, want: 0},
+		{version: 0, rule: &rules.OldSchemaVersion{}, want: 0},
+		{version: 30, rule: &rules.OldSchemaVersion{MinVersion: 36}, want: 1},
+	}
+	for _, tc := range cases {
+		ctx := buildJSONContext(t, fmt.Sprintf(oldSchemaFixture, tc.version))
+		findings := tc.rule.Check(ctx)
+		if len(findings) != tc.want {
+			t.Errorf("schemaVersion %d, MinVersion %d: got %d findings, want %d", tc.version, tc.rule.MinVersion, len(findings), tc.want)
+			continue
+		}
+		if tc.want > 0 && !strings.Contains(findings[0].Why, fmt.Sprintf("schemaVersion %d", tc.version)) {
+			t.Errorf("Why should name the schema version: %s", findings[0].Why)
+		}
+	}
+}
+
+func TestD18_DemoDashboards(t *testing.T) {
+	rule := &rules.OldSchemaVersion{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, "schemaVersion 27") {
+		t.Fatalf("D18 should flag the slow dashboard's schemaVersion 27, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D18 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q23: Quantile window mismatch ---
+
+const quantileWindowFixture = `{
+	"uid": "quantile-windows",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "p50 Latency",
+		 "targets": [{"expr": "histogram_quantile(0.5, sum by(le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[5m])))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "p99 Latency",
+		 "targets": [{"expr": "histogram_quantile(0.99, sum by(le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[1h])))", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "p90 Latency",
+		 "targets": [{"expr": "histogram_quantile(0.9, sum by(le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[$__rate_interval])))", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "DB p50",
+		 "targets": [{"expr": "histogram_quantile(0.5, sum by(le) (rate(db_query_duration_seconds_bucket[5m])))", "refId": "A"}]},
+		{"id": 5, "type": "timeseries", "title": "DB p99",
+		 "targets": [{"expr": "histogram_quantile(0.99, sum by(le) (rate(db_query_duration_seconds_bucket[5m])))", "refId": "A"}]}
+	]
+}`
+
+func TestQ23_QuantileWindowMismatch(t *testing.T) {
+	ctx := buildJSONContext(t, quantileWindowFixture)
+	findings := (&rules.QuantileWindowMismatch{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q23 should flag only the HTTP histogram, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium || fmt.Sprint(f.PanelIDs) != "[1 2]" {
+		t.Errorf("finding = %s on %v, want Medium on [1 2]", f.Severity, f.PanelIDs)
+	}
+	for _, want := range []string{"http_request_duration_seconds_bucket", "[5m0s]", "[1h0m0s]"} {
+		if !strings.Contains(f.Why, want) {
+			t.Errorf("Why missing %q: %s", want, f.Why)
+		}
+	}
+}
+
+func TestQ23_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	findings := (&rules.QuantileWindowMismatch{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q23 should flag the mixed 5m/1h latency quantiles, got %d findings", len(findings))
+	}
+	if !strings.Contains(findings[0].Why, "P99 over 1h") {
+		t.Errorf("Why should name the 1h panel: %s", findings[0].Why)
+	}
+}
+
+func TestQ23_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	if findings := (&rules.QuantileWindowMismatch{}).Check(ctx); len(findings) > 0 {
+		t.Errorf("Q23 should not fire on fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q24: resets() on gauge ---
+
+func TestQ24_ResetsOnGauge(t *testing.T) {
+	ctx := buildExprContext(t,
+		`resets(node_load1[5m])`,
+		`resets(http_requests_total[5m])`,
+		`sum(resets(process_resident_memory_bytes{job="api"}[1h]))`,
+		`resets(http_request_duration_seconds_count[5m])`,
+		`changes(node_load1[5m])`,
+	)
+	findings := (&rules.ResetsOnGauge{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("panel %v severity = %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 3]" {
+		t.Errorf("Q24 flagged panels %v, want [1 3]", got)
+	}
+}
+
+func TestQ24_MetricTypeOverrides(t *testing.T) {
+	ctx := buildExprContext(t,
+		`resets(acme_jobs_processed{job="worker"}[1h])`,
+		`resets(acme_queue_total{job="worker"}[1h])`,
+	)
+	rule := &rules.ResetsOnGauge{}
+	if findings := rule.Check(ctx); len(findings) != 1 || findings[0].PanelIDs[0] != 1 {
+		t.Fatalf("Q24 without overrides should flag only the non-_total name, got %v", findings)
+	}
+
+	ctx.MetricTypes = rules.MetricTypes{
+		"acme_jobs_processed": rules.MetricTypeCounter,
+		"acme_queue_*":        rules.MetricTypeGauge,
+	}
+	findings := rule.Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 2 {
+		t.Fatalf("Q24 with overrides should flag only the classified gauge, got %v", findings)
+	}
+	if findings[0].Confidence != 0.9 || !strings.Contains(findings[0].Why, "classified as a gauge") {
+		t.Errorf("classified finding: confidence %v, why %q", findings[0].Confidence, findings[0].Why)
+	}
+}
+
+func TestQ24_DemoDashboards(t *testing.T) {
+	rule := &rules.ResetsOnGauge{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 45 {
+		t.Fatalf("Q24 should flag panel 45 (resets of a memory gauge) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q24 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D19: liveNow with many panels ---
+
+// liveNowFixture builds a dashboard with liveNow set and n querying panels
+// plus a text panel, which does not count.
+func liveNowFixture(liveNow bool, n int) string {
+	panels := []string{`{"id": 100, "type": "text", "title": "Notes"}`}
+	for i := 1; i <= n; i++ {
+		panels = append(panels, fmt.Sprintf(
+			`{"id": %d, "type": "timeseries", "title": "Panel %d", "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]}`, i, i))
+	}
+	return fmt.Sprintf(`{"uid": "live-now", "liveNow": %t, "panels": [%s]}`, liveNow, strings.Join(panels, ","))
+}
+
+func TestD19_LiveNowManyPanels(t *testing.T) {
+	cases := []struct {
+		liveNow bool
+		panels  int
+		rule    *rules.LiveNowManyPanels
+		want    int
+	}{
+		{liveNow: true, panels: 12, rule: &rules.LiveNowManyPanels{}, want: 1},
+		{liveNow: true, panels: 10, rule: &rules.LiveNowManyPanels{}, want: 0},
+		{liveNow: false, panels: 30, rule: &rules.LiveNowManyPanels{}, want: 0},
+		{liveNow: true, panels: 12, rule: &rules.LiveNowManyPanels{MaxPanels: 20}, want: 0},
+	}
+	for _, tc := range cases {
+		ctx := buildJSONContext(t, liveNowFixture(tc.liveNow, tc.panels))
+		findings := tc.rule.Check(ctx)
+		if len(findings) != tc.want {
+			t.Errorf("liveNow=%t panels=%d MaxPanels=%d: got %d findings, want %d", tc.liveNow, tc.panels, tc.rule.MaxPanels, len(findings), tc.want)
+			continue
+		}
+		if tc.want > 0 && (findings[0].Severity != rules.High || !strings.Contains(findings[0].Why, "12 visible querying panels")) {
+			t.Errorf("finding = %s: %s", findings[0].Severity, findings[0].Why)
+		}
+	}
+}
+
+func TestD19_DemoDashboards(t *testing.T) {
+	rule := &rules.LiveNowManyPanels{}
+	if findings := rule.Check(buildContext(t, "slow-by-design.json")); len(findings) != 1 {
+		t.Errorf("D19 should fire on the slow dashboard (liveNow with every panel visible), got %d findings", len(findings))
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D19 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q25: @ modifier ---
+
+func TestQ25_AtModifier(t *testing.T) {
+	ctx := buildExprContext(t,
+		`up @ end()`,
+		`sum(rate(http_requests_total{job="api"}[5m] @ start()))`,
+		`max_over_time(up{job="api"}[1h:5m] @ 1700000000)`,
+		`up{job="api"}`,
+	)
+	findings := (&rules.AtModifier{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low || f.Confidence != 0.4 {
+			t.Errorf("finding on panel %v = %s/%.1f, want Low/0.4", f.PanelIDs, f.Severity, f.Confidence)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Q25 flagged panels %v, want [1 2 3]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, "@ end()") {
+		t.Errorf("Why should quote the modifier: %s", findings[0].Why)
+	}
+}
+
+func TestQ25_DemoDashboards(t *testing.T) {
+	rule := &rules.AtModifier{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 46 {
+		t.Fatalf("Q25 should flag panel 46 (@ end()) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q25 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D20: Hidden expensive target ---
+
+const hiddenTargetFixture = `{
+	"uid": "hidden-targets",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests",
+		 "targets": [
+			{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"},
+			{"expr": "histogram_quantile(0.99, sum by(le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[1h])))", "refId": "B", "hide": true}
+		 ]},
+		{"id": 2, "type": "stat", "title": "Up",
+		 "targets": [
+			{"expr": "sum(up{job=\"api\"})", "refId": "A"},
+			{"expr": "up{job=\"api\"}", "refId": "B", "hide": true}
+		 ]},
+		{"id": 3, "type": "stat", "title": "Error %",
+		 "targets": [
+			{"expr": "sum(rate(http_requests_total{job=\"api\", code=~\"5..\"}[5m]))", "refId": "A", "hide": true},
+			{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "B", "hide": true},
+			{"refId": "C", "datasource": {"type": "__expr__", "uid": "__expr__"}, "expression": "$A / ${B} * 100"}
+		 ]}
+	]
+}`
+
+func TestD20_HiddenExpensiveTarget(t *testing.T) {
+	ctx := buildJSONContext(t, hiddenTargetFixture)
+	findings := (&rules.HiddenExpensiveTarget{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("D20 should flag only panel 1, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Low || fmt.Sprint(f.PanelIDs) != "[1]" {
+		t.Errorf("finding = %s on %v, want Low on [1]", f.Severity, f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, "hidden target(s) B") {
+		t.Errorf("Why should name the hidden RefID: %s", f.Why)
+	}
+
+	if findings := (&rules.HiddenExpensiveTarget{MinCost: 1e9}).Check(ctx); len(findings) > 0 {
+		t.Errorf("D20 with MinCost 1e9 should not fire, got %d findings", len(findings))
+	}
+}
+
+func TestD20_DemoDashboards(t *testing.T) {
+	rule := &rules.HiddenExpensiveTarget{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 47 {
+		t.Fatalf("D20 should flag panel 47's hidden target B on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D20 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D21: label_values() without a metric ---
+
+const labelValuesFixture = `{
+	"uid": "label-values",
+	"templating": {"list": [
+		{"name": "instance", "type": "query", "query": "label_values(instance)"},
+		{"name": "job", "type": "query", "query": {"query": " label_values( job ) ", "refId": "A"}},
+		{"name": "pod", "type": "query", "query": "label_values(kube_pod_info{namespace=\"shop\"}, pod)"},
+		{"name": "node", "type": "query", "query": "label_values(up, node)"},
+		{"name": "env", "type": "custom", "query": "label_values(env)"}
+	]},
+	"panels": []
+}`
+
+func TestD21_UnscopedLabelValues(t *testing.T) {
+	ctx := buildJSONContext(t, labelValuesFixture)
+	findings := (&rules.UnscopedLabelValues{}).Check(ctx)
+
+	if len(findings) != 2 {
+		t.Fatalf("D21 should flag the two one-argument queries, got %d findings", len(findings))
+	}
+	for i, label := range []string{"instance", "job"} {
+		f := findings[i]
+		if f.Severity != rules.Medium || !strings.Contains(f.Why, "label_values("+label+")") {
+			t.Errorf("finding %d = %s: %s", i, f.Severity, f.Why)
+		}
+		if !strings.Contains(f.Fix, "label_values(up, "+label+")") {
+			t.Errorf("fix should suggest the two-argument form: %s", f.Fix)
+		}
+	}
+}
+
+func TestD21_DemoDashboards(t *testing.T) {
+	rule := &rules.UnscopedLabelValues{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, "$device") {
+		t.Fatalf("D21 should flag $device (label_values(device)) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D21 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q26: Bucket grouping explosion ---
+
+func TestQ26_BucketGroupingExplosion(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum by(le, pod)(rate(x_bucket[5m]))`,
+		`histogram_quantile(0.99, sum by(le) (rate(x_bucket[5m])))`,
+		`sum by(pod)(rate(x_bucket[5m]))`,
+		`sum without(le, pod)(rate(x_bucket[5m]))`,
+		`histogram_quantile(0.9, sum by(namespace, le, instance, container)(rate(x_bucket[5m])))`,
+	)
+	findings := (&rules.BucketGroupingExplosion{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.High {
+			t.Errorf("finding on panel %v has severity %s, want High", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 5]" {
+		t.Errorf("Q26 flagged panels %v, want [1 5]", got)
+	}
+	if len(findings) == 2 && !strings.Contains(findings[1].Why, "instance, container") {
+		t.Errorf("Why should list every high-cardinality label: %s", findings[1].Why)
+	}
+}
+
+func TestQ26_SlowDashboard(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	findings := (&rules.BucketGroupingExplosion{}).Check(ctx)
+	if len(findings) != 1 || findings[0].PanelTitles[0] != "Latency by Pod" {
+		t.Fatalf("expected one Q26 finding on \"Latency by Pod\", got %+v", findings)
+	}
+}
+
+func TestQ26_FixedDashboard(t *testing.T) {
+	ctx := buildContext(t, "fixed-by-advisor.json")
+	if findings := (&rules.BucketGroupingExplosion{}).Check(ctx); len(findings) > 0 {
+		t.Errorf("Q26 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D22: Too many query variables ---
+
+// manyVariablesFixture builds a dashboard with n query variables plus a
+// custom and a constant variable, which do not count.
+func manyVariablesFixture(n int) string {
+	vars := []string{
+		`{"name": "env", "type": "custom", "query": "prod,staging"}`,
+		`{"name": "cluster", "type": "constant", "query": "eu-1"}`,
+	}
+	for i := 1; i <= n; i++ {
+		vars = append(vars, fmt.Sprintf(
+			`{"name": "v%d", "type": "query", "query": "label_values(up{job=\"api\"}, label%d)"}`, i, i))
+	}
+	return fmt.Sprintf(`{"uid": "many-vars", "templating": {"list": [%s]},
+		"panels": [{"id": 1, "type": "timeseries", "title": "Up", "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]}]}`,
+		strings.Join(vars, ","))
+}
+
+func TestD22_TooManyQueryVariables(t *testing.T) {
+	cases := []struct {
+		vars int
+		rule *rules.TooManyQueryVariables
+		want int
+	}{
+		{vars: 15, rule: &rules.TooManyQueryVariables{}, want: 1},
+		{vars: 10, rule: &rules.TooManyQueryVariables{}, want: 0},
+		{vars: 15, rule: &rules.TooManyQueryVariables{MaxVariables: 20}, want: 0},
+		{vars: 6, rule: &rules.TooManyQueryVariables{MaxVariables: 5}, want: 1},
+	}
+	for _, tc := range cases {
+		ctx := buildJSONContext(t, manyVariablesFixture(tc.vars))
+		findings := tc.rule.Check(ctx)
+		if len(findings) != tc.want {
+			t.Errorf("vars=%d MaxVariables=%d: got %d findings, want %d", tc.vars, tc.rule.MaxVariables, len(findings), tc.want)
+			continue
+		}
+		if tc.want > 0 && (findings[0].Severity != rules.Medium || !strings.Contains(findings[0].Why, fmt.Sprintf("%d query variables", tc.vars))) {
+			t.Errorf("finding = %s: %s", findings[0].Severity, findings[0].Why)
+		}
+	}
+}
+
+func TestD22_DemoDashboards(t *testing.T) {
+	rule := &rules.TooManyQueryVariables{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, "11 query variables") {
+		t.Fatalf("D22 should flag the slow dashboard's 11 query variables, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D22 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q27: Rate of recorded rate ---
+
+func TestQ27_RateOfRecordedRate(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(job:http_requests:rate5m[5m])`,
+		`sum(increase(instance_path:requests:increase1h[1h]))`,
+		`irate({__name__="job:errors:sum_irate1m", job="api"}[5m])`,
+		`sum(job:http_requests:rate5m)`,
+		`rate(job:rate_limited_requests:sum[5m])`,
+		`rate(http_requests_total{job="api"}[5m])`,
+	)
+	findings := (&rules.RateOfRecordedRate{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v has severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Q27 flagged panels %v, want [1 2 3]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, "job:http_requests:rate5m") {
+		t.Errorf("Why should name the recording rule: %s", findings[0].Why)
+	}
+}
+
+func TestQ27_DemoDashboards(t *testing.T) {
+	rule := &rules.RateOfRecordedRate{}
+	ctx := buildContext(t, "slow-by-design.json")
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("Q27 should not fire on the slow dashboard, which reads no recording rules (B10), got %d findings", len(findings))
+	}
+	// A recorded series would silence B10, so the slow panel is added here.
+	expr := `rate(job:http_requests:rate5m{job="api-server"}[5m])`
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.ParsedExprs[expr] = parsed
+	ctx.Panels = append(ctx.Panels, extractor.PanelModel{
+		ID:      999,
+		Title:   "Request Rate (recorded)",
+		Type:    "timeseries",
+		Targets: []extractor.TargetModel{{Expr: expr, RefID: "A"}},
+	})
+	if findings := rule.Check(ctx); len(findings) != 1 || findings[0].PanelIDs[0] != 999 {
+		t.Errorf("Q27 should flag a rate of job:http_requests:rate5m on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q27 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D23: Panel time override ---
+
+const timeOverrideFixture = `{
+	"uid": "time-override",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests", "timeShift": "1d",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "stat", "title": "Errors", "timeFrom": "24h",
+		 "targets": [{"expr": "sum(increase(http_requests_total{job=\"api\", code=~\"5..\"}[24h]))", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Requests vs last week", "timeShift": "7d",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 4, "type": "text", "title": "Notes", "timeFrom": "1h"},
+		{"id": 5, "type": "timeseries", "title": "Latency",
+		 "targets": [{"expr": "histogram_quantile(0.99, sum by(le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[5m])))", "refId": "A"}]}
+	]
+}`
+
+func TestD23_PanelTimeOverride(t *testing.T) {
+	ctx := buildJSONContext(t, timeOverrideFixture)
+	findings := (&rules.PanelTimeOverride{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v has severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("D23 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, `timeShift "1d"`) {
+		t.Errorf("Why should quote the override: %s", findings[0].Why)
+	}
+}
+
+func TestD23_DemoDashboards(t *testing.T) {
+	rule := &rules.PanelTimeOverride{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 48 {
+		t.Fatalf("D23 should flag panel 48 (timeFrom 24h) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D23 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q28: Broad metric name regex ---
+
+func TestQ28_MetricNameRegex(t *testing.T) {
+	ctx := buildExprContext(t,
+		`{__name__=~"node_.*"}`,
+		`sum(rate({__name__=~".*_total", job="api"}[5m]))`,
+		`{__name__=~"node_cpu_seconds_total|node_load1", instance="a"}`,
+		`{__name__=~"up"}`,
+		`count({__name__=~"node_(cpu|memory)_.*"})`,
+		`node_load1{instance="a"}`,
+	)
+	findings := (&rules.MetricNameRegex{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.High || f.Confidence != 0.8 {
+			t.Errorf("finding on panel %v = %s/%.2f, want High/0.8", f.PanelIDs, f.Severity, f.Confidence)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q28 flagged panels %v, want [1 2]", got)
+	}
+}
+
+func TestQ28_WithCardinality(t *testing.T) {
+	ctx := buildExprContext(t, `{__name__=~"node_.*"}`)
+	ctx.Cardinality = &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{
+			"node_cpu_seconds_total": 800,
+			"node_load1":             10,
+			"http_requests_total":    5000,
+		},
+	}
+	findings := (&rules.MetricNameRegex{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Confidence != 0.95 || !strings.Contains(findings[0].Why, "2 metrics with 810 active series") {
+		t.Errorf("finding should be quantified from SeriesByMetric: %.2f %s", findings[0].Confidence, findings[0].Why)
+	}
+}
+
+func TestQ28_DemoDashboards(t *testing.T) {
+	rule := &rules.MetricNameRegex{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 49 {
+		t.Fatalf("Q28 should flag panel 49 (__name__=~\"node_network_.*\") on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q28 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q29: or vector(0) fallback ---
+
+func TestQ29_VectorZeroFallback(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum(rate(http_requests_total{job="api", code=~"5.."}[5m])) or vector(0)`,
+		`sum(up{job="api"}) or on() vector(0)`,
+		`sum(rate(http_requests_total{job="api"}[5m])) or (vector((0)))`,
+		`sum(up{job="api"}) or vector(1)`,
+		`sum(up{job="api"}) + vector(0)`,
+		`sum(up{job="api"})`,
+	)
+	findings := (&rules.VectorZeroFallback{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v has severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Q29 flagged panels %v, want [1 2 3]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, "target is down") {
+		t.Errorf("Why should explain the masking trade-off: %s", findings[0].Why)
+	}
+}
+
+func TestQ29_DemoDashboards(t *testing.T) {
+	rule := &rules.VectorZeroFallback{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 50 {
+		t.Fatalf("Q29 should flag panel 50 (or vector(0)) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q29 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D24: Manual label repeat ---
+
+const manualRepeatFixture = `{
+	"uid": "manual-repeat",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "CPU per instance",
+		 "targets": [
+			{"expr": "rate(node_cpu_seconds_total{job=\"node\", instance=\"instance-0\", mode=\"user\"}[5m])", "refId": "A"},
+			{"expr": "rate(node_cpu_seconds_total{job=\"node\", instance=\"instance-1\", mode=\"user\"}[5m])", "refId": "B"},
+			{"expr": "rate(node_cpu_seconds_total{job=\"node\", instance=\"instance-2\", mode=\"user\"}[5m])", "refId": "C"}
+		 ]},
+		{"id": 2, "type": "timeseries", "title": "Error ratio per pod",
+		 "targets": [
+			{"expr": "sum(rate(http_requests_total{job=\"api\", pod=\"a\", code=\"500\"}[5m])) / sum(rate(http_requests_total{job=\"api\", pod=\"a\"}[5m]))", "refId": "A"},
+			{"expr": "sum(rate(http_requests_total{job=\"api\", pod=\"b\", code=\"500\"}[5m])) / sum(rate(http_requests_total{job=\"api\", pod=\"b\"}[5m]))", "refId": "B"},
+			{"expr": "sum(rate(http_requests_total{job=\"api\", pod=\"c\", code=\"500\"}[5m])) / sum(rate(http_requests_total{job=\"api\", pod=\"c\"}[5m]))", "refId": "C"}
+		 ]},
+		{"id": 3, "type": "timeseries", "title": "Two instances",
+		 "targets": [
+			{"expr": "up{job=\"node\", instance=\"instance-0\"}", "refId": "A"},
+			{"expr": "up{job=\"node\", instance=\"instance-1\"}", "refId": "B"}
+		 ]},
+		{"id": 4, "type": "timeseries", "title": "Mixed labels",
+		 "targets": [
+			{"expr": "up{job=\"node\", instance=\"instance-0\"}", "refId": "A"},
+			{"expr": "up{job=\"api\", instance=\"instance-1\"}", "refId": "B"},
+			{"expr": "up{job=\"db\", instance=\"instance-2\"}", "refId": "C"}
+		 ]},
+		{"id": 5, "type": "timeseries", "title": "Different queries",
+		 "targets": [
+			{"expr": "up{job=\"node\"}", "refId": "A"},
+			{"expr": "sum(rate(http_requests_total{job=\"node\"}[5m]))", "refId": "B"},
+			{"expr": "node_load1{job=\"node\"}", "refId": "C"}
+		 ]}
+	]
+}`
+
+func TestD24_ManualLabelRepeat(t *testing.T) {
+	ctx := buildJSONContext(t, manualRepeatFixture)
+	findings := (&rules.ManualLabelRepeat{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v has severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("D24 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, `instance ("instance-0", "instance-1", "instance-2")`) {
+		t.Errorf("Why should name the label and its values: %s", findings[0].Why)
+	}
+}
+
+func TestD24_DemoDashboards(t *testing.T) {
+	rule := &rules.ManualLabelRepeat{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 51 {
+		t.Fatalf("D24 should flag panel 51 (one target per namespace) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D24 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q30: bool comparison on time-series panel ---
+
+const boolComparisonFixture = `{
+	"uid": "bool-comparison",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "API up",
+		 "targets": [{"expr": "up{job=\"api\"} == bool 1", "refId": "A"}]},
+		{"id": 2, "type": "graph", "title": "Latency over SLO",
+		 "targets": [{"expr": "sum(rate(http_request_duration_seconds_sum{job=\"api\"}[5m])) / sum(rate(http_request_duration_seconds_count{job=\"api\"}[5m])) > bool 0.5", "refId": "A"}]},
+		{"id": 3, "type": "stat", "title": "API up (stat)",
+		 "targets": [{"expr": "up{job=\"api\"} == bool 1", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Filtered",
+		 "targets": [{"expr": "up{job=\"api\"} == 1", "refId": "A"}]}
+	]
+}`
+
+func TestQ30_BoolComparisonOnTimeSeries(t *testing.T) {
+	ctx := buildJSONContext(t, boolComparisonFixture)
+	findings := (&rules.BoolComparisonOnTimeSeries{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v has severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q30 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, "== bool") {
+		t.Errorf("Why should quote the operator: %s", findings[0].Why)
+	}
+}
+
+func TestQ30_DemoDashboards(t *testing.T) {
+	rule := &rules.BoolComparisonOnTimeSeries{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 52 {
+		t.Fatalf("Q30 should flag panel 52 (> bool 4) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q30 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D25: Explicit $__all ---
+
+const explicitAllFixture = `{
+	"uid": "explicit-all",
+	"templating": {"list": [
+		{"name": "namespace", "type": "query", "includeAll": true, "multi": true,
+		 "query": "label_values(up{job=\"api\"}, namespace)"},
+		{"name": "pod", "type": "query", "includeAll": true, "allValue": ".*",
+		 "query": "label_values(up{job=\"api\"}, pod)"}
+	]},
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "By namespace",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\", namespace=~\"$__all\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Braced",
+		 "targets": [{"expr": "sum(up{job=\"api\", namespace=~\"${__all}\"})", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Proper",
+		 "targets": [{"expr": "sum(up{job=\"api\", namespace=~\"$namespace\", pod=~\"$pod\"})", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Lookalike",
+		 "targets": [{"expr": "sum(up{job=\"api\", namespace=~\"$__allowed\"})", "refId": "A"}]}
+	]
+}`
+
+func TestD25_ExplicitAllValue(t *testing.T) {
+	ctx := buildJSONContext(t, explicitAllFixture)
+	findings := (&rules.ExplicitAllValue{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v has severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("D25 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Why, "without an allValue: namespace.") {
+		t.Errorf("Why should name variables missing an allValue: %s", findings[0].Why)
+	}
+}
+
+func TestD25_DemoDashboards(t *testing.T) {
+	rule := &rules.ExplicitAllValue{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 53 {
+		t.Fatalf("D25 should flag panel 53 (pod=\"$__all\") on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D25 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D26: All value .* in a regex matcher ---
+
+const broadAllValueFixture = `{
+	"uid": "broad-all",
+	"templating": {"list": [
+		{"name": "namespace", "type": "query", "includeAll": true, "multi": true, "allValue": ".*",
+		 "query": "label_values(up{job=\"api\"}, namespace)"},
+		{"name": "pod", "type": "query", "includeAll": true, "multi": true,
+		 "query": "label_values(up{job=\"api\"}, pod)"},
+		{"name": "cluster", "type": "custom", "includeAll": true, "allValue": "prod-.*",
+		 "query": "prod-eu,prod-us"}
+	]},
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests",
+		 "targets": [{"expr": "sum(rate(http_requests_total{namespace=~\"$namespace\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Braced",
+		 "targets": [{"expr": "sum(up{job=\"api\", namespace=~\"${namespace:regex}\"})", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Equality",
+		 "targets": [{"expr": "sum(up{namespace=\"$namespace\"})", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Other variables",
+		 "targets": [{"expr": "sum(up{pod=~\"$pod\", cluster=~\"$cluster\"})", "refId": "A"}]}
+	]
+}`
+
+func TestD26_BroadAllValue(t *testing.T) {
+	ctx := buildJSONContext(t, broadAllValueFixture)
+	findings := (&rules.BroadAllValue{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v has severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+		if !strings.Contains(f.Why, "$namespace") {
+			t.Errorf("Why should name $namespace: %s", f.Why)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("D26 flagged panels %v, want [1 2]", got)
+	}
+}
+
+func TestD26_DemoDashboards(t *testing.T) {
+	rule := &rules.BroadAllValue{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 54 {
+		t.Fatalf("D26 should flag panel 54 (status=~\"$status\" with allValue .*) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D26 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q31: increase() on a per-second panel ---
+
+const increaseOnRateFixture = `{
+	"uid": "increase-rate",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests per second",
+		 "targets": [{"expr": "sum(increase(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 2, "type": "graph", "title": "Throughput",
+		 "targets": [{"expr": "sum by (route) (increase(http_requests_total{job=\"api\"}[1m]))", "legendFormat": "{{route}} req/s", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Requests per second",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Error rate",
+		 "targets": [{"expr": "sum(increase(http_errors_total{job=\"api\"}[5m])) / sum(increase(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 5, "type": "timeseries", "title": "Requests per 5m",
+		 "targets": [{"expr": "sum(increase(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 6, "type": "stat", "title": "Requests/s",
+		 "targets": [{"expr": "sum(increase(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]},
+		{"id": 7, "type": "timeseries", "title": "Generated",
+		 "targets": [{"expr": "sum(increase(jobs_total{job=\"api\"}[1h]))", "refId": "A"}]}
+	]
+}`
+
+func TestQ31_IncreaseOnRatePanel(t *testing.T) {
+	ctx := buildJSONContext(t, increaseOnRateFixture)
+	findings := (&rules.IncreaseOnRatePanel{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low || f.Confidence != 0.4 {
+			t.Errorf("finding on panel %v: severity %s confidence %v, want Low 0.4", f.PanelIDs, f.Severity, f.Confidence)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q31 flagged panels %v, want [1 2]", got)
+	}
+}
+
+func TestQ31_DemoDashboards(t *testing.T) {
+	rule := &rules.IncreaseOnRatePanel{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 55 {
+		t.Fatalf("Q31 should flag panel 55 (increase() titled per second) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q31 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q32: rate() combined with a raw counter ---
+
+func TestQ32_RateMixedWithRawCounter(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(http_errors_total{job="api"}[5m]) / http_requests_total{job="api"}`,                 // 1: flagged
+		`sum(http_requests_total{job="api"}) - sum(increase(http_requests_total{job="api"}[1h]))`, // 2: flagged, raw side first
+		`rate(http_errors_total{job="api"}[5m]) / rate(http_requests_total{job="api"}[5m])`,       // 3: both rated
+		`rate(http_requests_total{job="api"}[5m]) / node_memory_bytes{job="api"}`,                 // 4: raw side is not a counter
+		`rate(http_requests_total{job="api"}[5m]) > on() group_left up_total{job="api"}`,          // 5: comparison filters
+		`rate(http_requests_total{job="api"}[5m]) * 60`,                                           // 6: scalar
+	)
+	findings := (&rules.RateMixedWithRawCounter{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v: severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q32 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Fix, "rate(http_requests_total[5m])") {
+		t.Errorf("Q32 fix should wrap the counter in rate(), got %q", findings[0].Fix)
+	}
+}
+
+// Metric names given as __name__ matchers are reported like bare names; a
+// rate side with no extractable name is described without one.
+func TestQ32_NameMatchers(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(http_errors_total{job="api"}[5m]) / {__name__="http_requests_total", job="api"}`,
+		`sum(rate({job="api"}[5m])) / node_x_total`,
+	)
+	findings := (&rules.RateMixedWithRawCounter{}).Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("Q32: got %d findings, want 2", len(findings))
+	}
+	if why := findings[0].Why; !strings.Contains(why, `rate() of "http_errors_total" is combined with the raw counter "http_requests_total"`) {
+		t.Errorf("unexpected Why for __name__ counter: %q", why)
+	}
+	if why := findings[1].Why; !strings.HasPrefix(why, `rate() is combined with the raw counter "node_x_total"`) || strings.Contains(why, `""`) {
+		t.Errorf("unexpected Why for unnamed rate: %q", why)
+	}
+}
+
+func TestQ32_DemoDashboards(t *testing.T) {
+	rule := &rules.RateMixedWithRawCounter{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 57 {
+		t.Fatalf("Q32 should flag panel 57 (rate() over a raw counter) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q32 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- B9: Unbounded Loki query ---
+
+// lokiFixture mixes Loki panels with bounded and unbounded stream selectors,
+// a mixed-datasource panel, and a Prometheus panel with a bare selector.
+const lokiFixture = `{
+	"uid": "loki-logs",
+	"panels": [
+		{"id": 1, "type": "logs", "title": "All errors",
+		 "datasource": {"type": "loki", "uid": "logs"},
+		 "targets": [{"expr": "{} |= \"\"", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Error rate",
+		 "datasource": {"type": "loki", "uid": "logs"},
+		 "targets": [{"expr": "sum(count_over_time({app=~\".*\"} |= \"error\" [5m]))", "refId": "A"}]},
+		{"id": 3, "type": "logs", "title": "API errors",
+		 "datasource": {"type": "loki", "uid": "logs"},
+		 "targets": [{"expr": "{app=\"api\", namespace=~\".*\"} |= \"error\" | line_format \"{{.msg}}\"", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Mixed",
+		 "datasource": {"type": "datasource", "uid": "-- Mixed --"},
+		 "targets": [
+			{"expr": "up{job=\"api\"}", "refId": "A", "datasource": {"type": "prometheus", "uid": "prom"}},
+			{"expr": "{ } |~ \"panic\"", "refId": "B", "datasource": {"type": "loki", "uid": "logs"}}
+		 ]},
+		{"id": 5, "type": "logs", "title": "Saved query",
+		 "datasource": {"type": "loki", "uid": "logs"},
+		 "targets": [{"expr": "$logquery", "refId": "A"}]},
+		{"id": 6, "type": "timeseries", "title": "Prometheus",
+		 "datasource": {"type": "prometheus", "uid": "prom"},
+		 "targets": [{"expr": "{__name__=~\".*\"}", "refId": "A"}]}
+	]
+}`
+
+func TestB9_UnboundedLogQuery(t *testing.T) {
+	ctx := buildJSONContext(t, lokiFixture)
+	findings := (&rules.UnboundedLogQuery{}).Check(ctx)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%d:%s", f.PanelIDs[0], f.TargetExpr))
+		if f.Severity != rules.Critical {
+			t.Errorf("finding on panel %v: severity %s, want Critical", f.PanelIDs, f.Severity)
+		}
+	}
+	want := []string{
+		`1:{} |= ""`,
+		`2:sum(count_over_time({app=~".*"} |= "error" [5m]))`,
+		`4:{ } |~ "panic"`,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("B9 findings = %q, want %q", got, want)
+	}
+}
+
+func TestB9_DemoDashboards(t *testing.T) {
+	rule := &rules.UnboundedLogQuery{}
+	ctx := buildContext(t, "slow-by-design.json")
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("B9 should not fire on the slow dashboard (no Loki panels), got %d findings", len(findings))
+	}
+	// The demo stack runs no Loki, so the slow Loki panel is added here.
+	ctx.Panels = append(ctx.Panels, extractor.PanelModel{
+		ID:         999,
+		Title:      "Error Logs",
+		Type:       "logs",
+		Datasource: &extractor.DatasourceRef{Type: "loki", UID: "loki"},
+		Targets:    []extractor.TargetModel{{Expr: `{namespace=~".*"} |= "error"`, RefID: "A"}},
+	})
+	if findings := rule.Check(ctx); len(findings) != 1 || findings[0].PanelIDs[0] != 999 {
+		t.Errorf("B9 should flag an unbounded Loki panel on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("B9 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D27: Calendar-rounded range in a DST timezone ---
+
+// timezoneRangeFixture has one panel; timezone and time range are filled in
+// per case.
+const timezoneRangeFixture = `{
+	"uid": "tz-range",
+	"timezone": %q,
+	"time": {"from": %q, "to": %q},
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"}]}
+	]
+}`
+
+func TestD27_TimezoneTruncatedRange(t *testing.T) {
+	cases := []struct {
+		timezone, from, to string
+		want               bool
+	}{
+		{"Europe/Berlin", "now/d", "now/d", true},
+		{"America/New_York", "now-1M/M", "now", true},
+		{"Europe/Berlin", "now-24h", "now", false},
+		{"utc", "now/d", "now/d", false},
+		{"browser", "now/w", "now/w", false},
+		{"", "now/d", "now/d", false},
+	}
+	for _, c := range cases {
+		ctx := buildJSONContext(t, fmt.Sprintf(timezoneRangeFixture, c.timezone, c.from, c.to))
+		findings := (&rules.TimezoneTruncatedRange{}).Check(ctx)
+		if got := len(findings) == 1; got != c.want {
+			t.Errorf("timezone %q range %s..%s: flagged = %v, want %v", c.timezone, c.from, c.to, got, c.want)
+			continue
+		}
+		if c.want && (findings[0].Severity != rules.Low || !strings.Contains(findings[0].Why, c.timezone)) {
+			t.Errorf("timezone %q: want a Low finding naming the timezone, got %s: %s", c.timezone, findings[0].Severity, findings[0].Why)
+		}
+	}
+}
+
+func TestD27_DemoDashboards(t *testing.T) {
+	rule := &rules.TimezoneTruncatedRange{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, `now/d, rounded to calendar units in timezone "Europe/Berlin"`) {
+		t.Fatalf("D27 should flag the slow dashboard's now/d range in Europe/Berlin, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D27 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Rule descriptions (--explain) ---
+
+func TestBuiltinRulesDescribeThemselves(t *testing.T) {
+	for _, r := range analyzer.DefaultEngine().Rules() {
+		describer, ok := r.(rules.Describer)
+		if !ok {
+			t.Errorf("%s does not implement rules.Describer", r.ID())
+			continue
+		}
+		d := describer.Describe()
+		if d.Title == "" || d.Summary == "" || d.Rationale == "" || d.Bad == "" || d.Good == "" {
+			t.Errorf("%s has an incomplete description: %+v", r.ID(), d)
+		}
+	}
+}
+
+// --- Q33: Double-smoothing subquery ---
+
+func TestQ33_DoubleSmoothingSubquery(t *testing.T) {
+	ctx := buildExprContext(t,
+		`avg_over_time(rate(http_requests_total{job="api"}[5m])[1h:])`,                     // 1: flagged
+		`sum_over_time(sum by (code) (irate(http_requests_total{job="api"}[1m]))[30m:1m])`, // 2: flagged, aggregated inner
+		`max_over_time(rate(http_requests_total{job="api"}[5m])[1h:])`,                     // 3: peak, legitimate
+		`avg_over_time(node_load1{job="node"}[1h:1m])`,                                     // 4: subquery of a gauge
+		`avg_over_time(node_load1{job="node"}[1h])`,                                        // 5: plain range vector
+		`rate(http_requests_total{job="api"}[1h])`,                                         // 6: the fix
+	)
+	findings := (&rules.DoubleSmoothingSubquery{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("finding on panel %v: severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q33 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) == 2 {
+		if !strings.Contains(findings[0].Fix, "rate(http_requests_total[1h])") {
+			t.Errorf("Q33 fix for avg_over_time should suggest rate() over 1h, got %q", findings[0].Fix)
+		}
+		if !strings.Contains(findings[1].Fix, "increase(http_requests_total[30m])") {
+			t.Errorf("Q33 fix for sum_over_time should suggest increase() over 30m, got %q", findings[1].Fix)
+		}
+	}
+}
+
+// --- D28: Hidden drill-down targets ---
+
+// drillDownFixture has a panel with one visible and three hidden targets, a
+// panel whose hidden targets feed an expression, a panel with two visible
+// targets, and a panel at the target limit.
+const drillDownFixture = `{
+	"uid": "drill-down",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Requests",
+		 "targets": [
+			{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "A"},
+			{"expr": "sum by (handler) (rate(http_requests_total{job=\"api\"}[5m]))", "refId": "B", "hide": true},
+			{"expr": "sum by (code) (rate(http_requests_total{job=\"api\"}[5m]))", "refId": "C", "hide": true},
+			{"expr": "sum by (instance) (rate(http_requests_total{job=\"api\"}[5m]))", "refId": "D", "hide": true}
+		 ]},
+		{"id": 2, "type": "stat", "title": "Error %",
+		 "targets": [
+			{"expr": "sum(rate(http_requests_total{job=\"api\", code=~\"5..\"}[5m]))", "refId": "A", "hide": true},
+			{"expr": "sum(rate(http_requests_total{job=\"api\", code=~\"4..\"}[5m]))", "refId": "B", "hide": true},
+			{"expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))", "refId": "C", "hide": true},
+			{"refId": "D", "datasource": {"type": "__expr__", "uid": "__expr__"}, "expression": "($A + $B) / $C * 100"}
+		 ]},
+		{"id": 3, "type": "timeseries", "title": "Latency",
+		 "targets": [
+			{"expr": "histogram_quantile(0.5, sum by (le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[5m])))", "refId": "A"},
+			{"expr": "histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job=\"api\"}[5m])))", "refId": "B"},
+			{"expr": "sum(rate(http_request_duration_seconds_count{job=\"api\"}[5m]))", "refId": "C", "hide": true},
+			{"expr": "sum(rate(http_request_duration_seconds_sum{job=\"api\"}[5m]))", "refId": "D", "hide": true}
+		 ]},
+		{"id": 4, "type": "stat", "title": "Up",
+		 "targets": [
+			{"expr": "sum(up{job=\"api\"})", "refId": "A"},
+			{"expr": "up{job=\"api\"}", "refId": "B", "hide": true},
+			{"expr": "up{job=\"db\"}", "refId": "C", "hide": true}
+		 ]}
+	]
+}`
+
+func TestD28_HiddenDrillDownTargets(t *testing.T) {
+	ctx := buildJSONContext(t, drillDownFixture)
+	findings := (&rules.HiddenDrillDownTargets{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("D28 should flag only panel 1, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium || fmt.Sprint(f.PanelIDs) != "[1]" {
+		t.Errorf("finding = %s on %v, want Medium on [1]", f.Severity, f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, "B, C, D are hidden") {
+		t.Errorf("Why should name the hidden RefIDs: %s", f.Why)
+	}
+
+	findings = (&rules.HiddenDrillDownTargets{MaxTargets: 2}).Check(ctx)
+	if len(findings) != 2 {
+		t.Errorf("D28 with MaxTargets 2 should also flag panel 4, got %d findings", len(findings))
+	}
+}
+
+func TestD28_DemoDashboards(t *testing.T) {
+	rule := &rules.HiddenDrillDownTargets{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 58 {
+		t.Fatalf("D28 should flag panel 58 (one of four targets shown) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D28 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q34: quantile() over a large series set ---
+
+func TestQ34_QuantileAggregation(t *testing.T) {
+	ctx := buildExprContext(t,
+		`quantile(0.99, some_metric)`,                                                                       // 1: unfiltered
+		`quantile by (job) (0.9, rate(http_requests_total{job="api"}[5m]))`,                                 // 2: filtered
+		`histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m])))`, // 3: histogram_quantile
+		`max(some_metric)`, // 4: other aggregation
+	)
+	rule := &rules.QuantileAggregation{}
+
+	findings := rule.Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("Q34 should flag only the unfiltered quantile(), got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium || fmt.Sprint(f.PanelIDs) != "[1]" {
+		t.Errorf("finding = %s on %v, want Medium on [1]", f.Severity, f.PanelIDs)
+	}
+	if !strings.Contains(f.Fix, "histogram_quantile(0.99, sum by (le) (rate(some_metric_bucket") {
+		t.Errorf("Fix should suggest histogram_quantile over the buckets, got %q", f.Fix)
+	}
+
+	ctx.Cardinality = &cardinality.CardinalityData{
+		SeriesByMetric: map[string]int{"http_requests_total": 50_000},
+	}
+	findings = rule.Check(ctx)
+	if len(findings) != 2 || findings[1].PanelIDs[0] != 2 {
+		t.Fatalf("Q34 should also flag the 50000-series quantile() with cardinality data, got %d findings", len(findings))
+	}
+	if !strings.Contains(findings[1].Why, "50000 active series") {
+		t.Errorf("Why should give the series count, got %q", findings[1].Why)
+	}
+}
+
+func TestQ34_DemoDashboards(t *testing.T) {
+	rule := &rules.QuantileAggregation{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 59 {
+		t.Fatalf("Q34 should flag panel 59 (unfiltered quantile()) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q34 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q35: $__interval_ms used in arithmetic ---
+
+func TestQ35_IntervalMsArithmetic(t *testing.T) {
+	ctx := buildExprContext(t,
+		`increase(http_requests_total{job="api"}[$__interval]) / $__interval_ms * 1000`,        // 1: flagged
+		`sum(increase(http_requests_total{job="api"}[$__interval])) / (${__interval_ms}/1000)`, // 2: flagged, braced
+		`rate(http_requests_total{job="api"}[$__rate_interval])`,                               // 3: the fix
+		`sum(increase(http_requests_total{job="api"}[$__interval]))`,                           // 4: no arithmetic
+	)
+	findings := (&rules.IntervalMsArithmetic{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+		if !strings.Contains(f.Fix, "$__rate_interval") {
+			t.Errorf("Fix should suggest $__rate_interval, got %q", f.Fix)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q35 flagged panels %v, want [1 2]", got)
+	}
+}
+
+func TestQ35_DemoDashboards(t *testing.T) {
+	rule := &rules.IntervalMsArithmetic{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 60 {
+		t.Fatalf("Q35 should flag panel 60 (/ $__interval_ms * 1000) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q35 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q36: Join repeated across panels ---
+
+// repeatedJoinFixture repeats one group_left join in three panels (with
+// different outer aggregations and whitespace), a plain a / b division in
+// three panels, and a second join in only two.
+const repeatedJoinFixture = `{
+	"uid": "repeated-join",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Load by node",
+		 "targets": [{"expr": "node_load1{job=\"node\"} * on(instance) group_left(nodename) node_uname_info{job=\"node\"}", "refId": "A"}]},
+		{"id": 2, "type": "stat", "title": "Max load",
+		 "targets": [{"expr": "max(node_load1{job=\"node\"} * on (instance) group_left (nodename) node_uname_info{job=\"node\"})", "refId": "A"}]},
+		{"id": 3, "type": "table", "title": "Load table",
+		 "targets": [{"expr": "topk(5, node_load1{job=\"node\"} * on(instance) group_left(nodename) node_uname_info{job=\"node\"})", "refId": "A"}]},
+		{"id": 4, "type": "stat", "title": "Memory used",
+		 "targets": [
+			{"expr": "node_memory_Active_bytes{job=\"node\"} / node_memory_MemTotal_bytes{job=\"node\"}", "refId": "A"},
+			{"expr": "node_memory_Active_bytes{job=\"node\"} / ignoring(mode) node_memory_MemTotal_bytes{job=\"node\"}", "refId": "B"}
+		 ]},
+		{"id": 5, "type": "stat", "title": "Memory used 2",
+		 "targets": [
+			{"expr": "node_memory_Active_bytes{job=\"node\"} / node_memory_MemTotal_bytes{job=\"node\"}", "refId": "A"},
+			{"expr": "node_memory_Active_bytes{job=\"node\"} / ignoring(mode) node_memory_MemTotal_bytes{job=\"node\"}", "refId": "B"}
+		 ]},
+		{"id": 6, "type": "stat", "title": "Memory used 3",
+		 "targets": [{"expr": "node_memory_Active_bytes{job=\"node\"} / node_memory_MemTotal_bytes{job=\"node\"}", "refId": "A"}]}
+	]
+}`
+
+func TestQ36_RepeatedJoin(t *testing.T) {
+	ctx := buildJSONContext(t, repeatedJoinFixture)
+	findings := (&rules.RepeatedJoin{}).Check(ctx)
+
+	if len(findings) != 1 {
+		t.Fatalf("Q36 should flag only the group_left join, got %d findings", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium || fmt.Sprint(f.PanelIDs) != "[1 2 3]" {
+		t.Errorf("finding = %s on %v, want Medium on [1 2 3]", f.Severity, f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, "group_left") {
+		t.Errorf("Why should quote the join, got %q", f.Why)
+	}
+
+	if findings := (&rules.RepeatedJoin{MinPanels: 2}).Check(ctx); len(findings) != 2 {
+		t.Errorf("Q36 with MinPanels 2 should also flag the ignoring() join, got %d findings", len(findings))
+	}
+}
+
+func TestQ36_DemoDashboards(t *testing.T) {
+	rule := &rules.RepeatedJoin{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || fmt.Sprint(findings[0].PanelIDs) != "[61 62 63]" {
+		t.Fatalf("Q36 should flag the kube_pod_info join in panels 61-63 on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q36 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q37: _sum rate without the matching _count ---
+
+func TestQ37_SumWithoutCount(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(http_request_duration_seconds_sum{job="api"}[5m])`,                                                                                                      // 1: flagged
+		`sum(rate(http_request_duration_seconds_sum{job="api"}[5m])) / sum(rate(rpc_duration_seconds_count{job="api"}[5m]))`,                                          // 2: flagged, wrong _count
+		`rate(http_request_duration_seconds_sum{job="api"}[5m]) / rate(http_request_duration_seconds_count{job="api"}[5m])`,                                           // 3: paired
+		`sum by (route) (increase(http_request_duration_seconds_sum{job="api"}[1h])) / sum by (route) (increase(http_request_duration_seconds_count{job="api"}[1h]))`, // 4: paired, aggregated
+		`rate(http_request_duration_seconds_count{job="api"}[5m])`,                                                                                                    // 5: count only
+		`rate(http_requests_total{job="api"}[5m])`,                                                                                                                    // 6: not a _sum
+	)
+	findings := (&rules.SumWithoutCount{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("Q37 flagged panels %v, want [1 2]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Fix, "rate(http_request_duration_seconds_count[5m])") {
+		t.Errorf("Q37 fix should divide by the _count rate, got %q", findings[0].Fix)
+	}
+}
+
+func TestQ37_DemoDashboards(t *testing.T) {
+	if findings := (&rules.SumWithoutCount{}).Check(buildContext(t, "slow-by-design.json")); len(findings) == 0 {
+		t.Error("Q37 should flag the bare _sum rate in the slow dashboard")
+	}
+	if findings := (&rules.SumWithoutCount{}).Check(buildContext(t, "fixed-by-advisor.json")); len(findings) > 0 {
+		t.Errorf("Q37 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D29: maxDataPoints far above panel width ---
+
+// widthFixture has a narrow panel with a huge maxDataPoints, a full-width
+// panel with the same value, a panel without gridPos, and a nested panel
+// inside a collapsed row.
+const widthFixture = `{
+	"uid": "max-data-points",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Narrow", "gridPos": {"h": 8, "w": 6, "x": 0, "y": 0},
+		 "maxDataPoints": 5000, "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Wide", "gridPos": {"h": 8, "w": 24, "x": 0, "y": 8},
+		 "maxDataPoints": 5000, "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "No grid",
+		 "maxDataPoints": 5000, "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+		{"id": 4, "type": "row", "title": "More", "collapsed": true, "gridPos": {"h": 1, "w": 24, "x": 0, "y": 16},
+		 "panels": [
+			{"id": 5, "type": "stat", "title": "Tiny stat", "gridPos": {"h": 4, "w": 3, "x": 0, "y": 17},
+			 "maxDataPoints": 1000, "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]}
+		 ]}
+	]
+}`
+
+func TestD29_OversizedMaxDataPoints(t *testing.T) {
+	ctx := buildJSONContext(t, widthFixture)
+	findings := (&rules.OversizedMaxDataPoints{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("finding on panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	// 6 columns ≈ 480px (limit 1920); 3 columns ≈ 240px (limit 960).
+	if fmt.Sprint(got) != "[1 5]" {
+		t.Errorf("D29 flagged panels %v, want [1 5]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Fix, "e.g. 480") {
+		t.Errorf("Fix should suggest the estimated pixel width, got %q", findings[0].Fix)
+	}
+
+	if findings := (&rules.OversizedMaxDataPoints{DashboardWidth: 3840}).Check(ctx); len(findings) != 1 {
+		t.Errorf("D29 on a 3840px dashboard should flag only panel 1, got %d findings", len(findings))
+	}
+}
+
+func TestD29_DemoDashboards(t *testing.T) {
+	rule := &rules.OversizedMaxDataPoints{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 64 {
+		t.Fatalf("D29 should flag panel 64 (maxDataPoints 10000 at 6 columns) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D29 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D30: too many visible timeseries panels ---
+
+// timeseriesPanelsFixture returns a dashboard with n top-level panels of
+// type vizType, plus a collapsed row holding another n timeseries panels.
+func timeseriesPanelsFixture(n int, vizType string) string {
+	var panels, nested []string
+	for i := 1; i <= n; i++ {
+		panels = append(panels, fmt.Sprintf(`{"id": %d, "type": %q, "title": "Panel %d"}`, i, vizType, i))
+		nested = append(nested, fmt.Sprintf(`{"id": %d, "type": "timeseries", "title": "Detail %d"}`, 1000+i, i))
+	}
+	row := fmt.Sprintf(`{"id": 999, "type": "row", "title": "Details", "collapsed": true, "panels": [%s]}`, strings.Join(nested, ","))
+	return fmt.Sprintf(`{"uid": "many-timeseries", "panels": [%s, %s]}`, strings.Join(panels, ","), row)
+}
+
+func TestD30_TooManyTimeseriesPanels(t *testing.T) {
+	rule := &rules.TooManyTimeseriesPanels{}
+
+	findings := rule.Check(buildJSONContext(t, timeseriesPanelsFixture(21, "timeseries")))
+	if len(findings) != 1 {
+		t.Fatalf("D30 on 21 timeseries panels: got %d findings, want 1", len(findings))
+	}
+	if findings[0].Severity != rules.Medium || !strings.Contains(findings[0].Why, "21 visible") {
+		t.Errorf("unexpected finding: %s %q", findings[0].Severity, findings[0].Why)
+	}
+
+	if findings := rule.Check(buildJSONContext(t, timeseriesPanelsFixture(21, "graph"))); len(findings) != 1 {
+		t.Errorf("D30 should count legacy graph panels, got %d findings", len(findings))
+	}
+	// Collapsed-row children do not count toward the threshold.
+	if findings := rule.Check(buildJSONContext(t, timeseriesPanelsFixture(20, "timeseries"))); len(findings) != 0 {
+		t.Errorf("D30 on 20 timeseries panels: got %d findings, want 0", len(findings))
+	}
+	if findings := rule.Check(buildJSONContext(t, timeseriesPanelsFixture(30, "stat"))); len(findings) != 0 {
+		t.Errorf("D30 should ignore stat panels, got %d findings", len(findings))
+	}
+	if findings := (&rules.TooManyTimeseriesPanels{MaxPanels: 5}).Check(buildJSONContext(t, timeseriesPanelsFixture(6, "timeseries"))); len(findings) != 1 {
+		t.Errorf("D30 with MaxPanels 5 on 6 panels: got %d findings, want 1", len(findings))
+	}
+}
+
+func TestD30_DemoDashboards(t *testing.T) {
+	rule := &rules.TooManyTimeseriesPanels{}
+	if findings := rule.Check(buildContext(t, "slow-by-design.json")); len(findings) != 1 {
+		t.Errorf("D30 should fire on the slow dashboard, got %d findings", len(findings))
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D30 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q38: non-Prometheus macro in PromQL ---
+
+func TestQ38_ForeignMacro(t *testing.T) {
+	ctx := buildExprContext(t,
+		`rate(http_requests_total{job="api"}[5m]) and $timeFilter`,
+		`sum by ($__timeGroupAlias) (up{job="api"})`,
+		`sum(rate(http_requests_total{job="api"}[$__rate_interval]))`,
+		`sum(increase(http_requests_total{job="api"}[$__range])) / ${__to} * 0`,
+	)
+	findings := (&rules.ForeignMacro{}).Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("Q38: got %d findings, want 2", len(findings))
+	}
+	if findings[0].Severity != rules.High || !strings.Contains(findings[0].Why, "$timeFilter") {
+		t.Errorf("unexpected first finding: %s %q", findings[0].Severity, findings[0].Why)
+	}
+	// $__timeGroup must not match inside $__timeGroupAlias.
+	if !strings.Contains(findings[1].Why, "contains $__timeGroupAlias,") {
+		t.Errorf("second finding should name only $__timeGroupAlias, got %q", findings[1].Why)
+	}
+
+	custom := &rules.ForeignMacro{Macros: []string{"$__rate_interval"}}
+	if findings := custom.Check(ctx); len(findings) != 1 || findings[0].PanelIDs[0] != 3 {
+		t.Errorf("Q38 with a custom macro list should flag only panel 3, got %v", findings)
+	}
+}
+
+func TestQ38_SkipsOtherDatasources(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "foreign-macro",
+		"panels": [
+			{"id": 1, "type": "table", "title": "SQL", "datasource": {"type": "mysql", "uid": "db"},
+			 "targets": [{"expr": "SELECT $__timeGroup(created_at, '1m') FROM t WHERE $__timeFilter(created_at)", "refId": "A"}]},
+			{"id": 2, "type": "timeseries", "title": "Prom", "datasource": {"type": "prometheus", "uid": "prom"},
+			 "targets": [{"expr": "up{job=\"api\"} and $timeFilter", "refId": "A"}]}
+		]
+	}`)
+	findings := (&rules.ForeignMacro{}).Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 2 {
+		t.Errorf("Q38 should flag only the Prometheus panel, got %v", findings)
+	}
+}
+
+func TestQ38_DemoDashboards(t *testing.T) {
+	rule := &rules.ForeignMacro{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 65 {
+		t.Fatalf("Q38 should flag panel 65 ($timeFilter) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q38 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D31: legend references a label the query aggregates away ---
+
+// legendFixture pairs each query with a legendFormat. Panels 1, 4 and 6 drop
+// a label their legend uses; the others keep it or cannot be judged.
+const legendFixture = `{
+	"uid": "legend-labels",
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "No grouping",
+		 "targets": [{"expr": "sum(rate(http_requests_total{job=~\"api|web\"}[5m]))", "legendFormat": "{{job}}", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Grouped",
+		 "targets": [{"expr": "sum by (job) (rate(http_requests_total{job=~\"api|web\"}[5m]))", "legendFormat": "{{ job }}", "refId": "A"}]},
+		{"id": 3, "type": "timeseries", "title": "Raw",
+		 "targets": [{"expr": "rate(http_requests_total{job=~\"api|web\"}[5m])", "legendFormat": "{{job}} {{instance}}", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Without",
+		 "targets": [{"expr": "sum without (instance) (rate(http_requests_total{job=~\"api|web\"}[5m]))", "legendFormat": "{{job}} {{instance}}", "refId": "A"}]},
+		{"id": 5, "type": "timeseries", "title": "Relabelled",
+		 "targets": [{"expr": "label_replace(sum(rate(http_requests_total{job=\"api\"}[5m])), \"job\", \"api\", \"\", \"\")", "legendFormat": "{{job}}", "refId": "A"}]},
+		{"id": 6, "type": "timeseries", "title": "Ratio",
+		 "targets": [{"expr": "sum by (job) (rate(errors_total{job=~\"api|web\"}[5m])) / on (job) sum by (job, code) (rate(http_requests_total{job=~\"api|web\"}[5m]))", "legendFormat": "{{job}} {{code}}", "refId": "A"}]},
+		{"id": 7, "type": "timeseries", "title": "Templated grouping",
+		 "targets": [{"expr": "sum by ($group) (rate(http_requests_total{job=~\"api|web\"}[5m]))", "legendFormat": "{{job}}", "refId": "A"}]},
+		{"id": 8, "type": "timeseries", "title": "Top",
+		 "targets": [{"expr": "topk(5, rate(http_requests_total{job=~\"api|web\"}[5m])) * 100", "legendFormat": "{{job}}", "refId": "A"}]}
+	]
+}`
+
+func TestD31_LegendLabelDropped(t *testing.T) {
+	ctx := buildJSONContext(t, legendFixture)
+	findings := (&rules.LegendLabelDropped{}).Check(ctx)
+
+	got := map[int]string{}
+	for _, f := range findings {
+		got[f.PanelIDs[0]] = f.Why
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	want := map[int]string{
+		1: "uses {{job}},",
+		4: "uses {{instance}},",
+		6: "uses {{code}},",
+	}
+	if len(got) != len(want) {
+		t.Errorf("D31 flagged panels %v, want %v", got, want)
+	}
+	for id, fragment := range want {
+		if !strings.Contains(got[id], fragment) {
+			t.Errorf("panel %d: Why %q should contain %q", id, got[id], fragment)
+		}
+	}
+}
+
+func TestD31_DemoDashboards(t *testing.T) {
+	rule := &rules.LegendLabelDropped{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 66 {
+		t.Fatalf("D31 should flag panel 66 ({{job}} after sum()) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D31 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q39: very long query expression ---
+
+func TestQ39_LongExpression(t *testing.T) {
+	long := `sum(rate(http_requests_total{job="api", handler=~"` + strings.Repeat("/v1/resource|", 40) + `/health"}[5m]))`
+	ctx := buildExprContext(t, long, `sum(rate(http_requests_total{job="api"}[5m]))`)
+
+	findings := (&rules.LongExpression{}).Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 1 {
+		t.Fatalf("Q39 should flag only panel 1, got %v", findings)
+	}
+	if want := fmt.Sprintf("is %d characters long", len(long)); !strings.Contains(findings[0].Why, want) {
+		t.Errorf("Why %q should contain %q", findings[0].Why, want)
+	}
+
+	if findings := (&rules.LongExpression{MaxLength: 40}).Check(ctx); len(findings) != 2 {
+		t.Errorf("Q39 with MaxLength 40: got %d findings, want 2", len(findings))
+	}
+}
+
+func TestQ39_DemoDashboards(t *testing.T) {
+	rule := &rules.LongExpression{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 67 {
+		t.Fatalf("Q39 should flag panel 67 (945-character availability query) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q39 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D32: hardcoded datasource UID ---
+
+// datasourceVariableFixture defines a Prometheus datasource variable. Panel
+// 1 and nested panel 6 pin a UID; the others use the variable, a Loki
+// datasource, the mixed pseudo datasource, or none.
+const datasourceVariableFixture = `{
+	"uid": "datasource-variable",
+	"templating": {"list": [
+		{"name": "ds", "type": "datasource", "query": "prometheus"}
+	]},
+	"panels": [
+		{"id": 1, "type": "timeseries", "title": "Pinned", "datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"},
+		 "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+		{"id": 2, "type": "timeseries", "title": "Variable", "datasource": {"type": "prometheus", "uid": "${ds}"},
+		 "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+		{"id": 3, "type": "logs", "title": "Logs", "datasource": {"type": "loki", "uid": "L0K1"},
+		 "targets": [{"expr": "{app=\"api\"}", "refId": "A"}]},
+		{"id": 4, "type": "timeseries", "title": "Mixed", "datasource": {"type": "datasource", "uid": "-- Mixed --"},
+		 "targets": [{"expr": "up{job=\"api\"}", "refId": "A", "datasource": {"type": "prometheus", "uid": "$ds"}}]},
+		{"id": 5, "type": "row", "title": "More", "collapsed": true, "panels": [
+			{"id": 6, "type": "stat", "title": "Pinned nested", "datasource": {"type": "prometheus", "uid": "P1809F7CD0C75ACF3"},
+			 "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]},
+			{"id": 7, "type": "stat", "title": "Default", "targets": [{"expr": "up{job=\"api\"}", "refId": "A"}]}
+		]}
+	]
+}`
+
+func TestD32_HardcodedDatasource(t *testing.T) {
+	ctx := buildJSONContext(t, datasourceVariableFixture)
+	findings := (&rules.HardcodedDatasource{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 6]" {
+		t.Errorf("D32 flagged panels %v, want [1 6]", got)
+	}
+	if len(findings) > 0 && !strings.Contains(findings[0].Fix, `"uid": "${ds}"`) {
+		t.Errorf("Fix should reference the variable, got %q", findings[0].Fix)
+	}
+
+	// Without a datasource variable, pinned UIDs are the norm.
+	noVariable := strings.Replace(datasourceVariableFixture, `"type": "datasource", "query"`, `"type": "custom", "query"`, 1)
+	if findings := (&rules.HardcodedDatasource{}).Check(buildJSONContext(t, noVariable)); len(findings) != 0 {
+		t.Errorf("D32 without a datasource variable: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestD32_DemoDashboards(t *testing.T) {
+	rule := &rules.HardcodedDatasource{}
+	ctx := buildContext(t, "slow-by-design.json")
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("D32 should not fire on the slow dashboard (no datasource variable), got %d findings", len(findings))
+	}
+	// Every slow panel hardcodes its datasource UID, so a $datasource
+	// variable on the dashboard would flag them all; it is added here.
+	ctx.Variables = append(ctx.Variables, extractor.VariableModel{Name: "datasource", Type: "datasource", Query: "prometheus"})
+	findings := rule.Check(ctx)
+	if len(findings) == 0 || !strings.Contains(findings[0].Why, `"Global Request Rate" uses datasource UID "prometheus-main"`) {
+		t.Fatalf("D32 should flag the slow dashboard's hardcoded panels once $datasource exists, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D32 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q40: comparison displayed instead of a threshold ---
+
+func TestQ40_ComparisonAsSeries(t *testing.T) {
+	ctx := buildExprContext(t,
+		`histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))) > 0.5`,
+		`(sum(rate(errors_total{job="api"}[5m])) > 10)`,
+		`sum by (job) (rate(errors_total{job="api"}[5m])) > 0`,
+		`sum(rate(errors_total{job="api"}[5m])) > sum(rate(errors_total{job="api"}[5m] offset 1d))`,
+		`sum(rate(errors_total{job="api"}[5m])) * 100`,
+	)
+	findings := (&rules.ComparisonAsSeries{}).Check(ctx)
+
+	got := map[int]string{}
+	for _, f := range findings {
+		got[f.PanelIDs[0]] = f.Why
+	}
+	if len(got) != 2 {
+		t.Fatalf("Q40 flagged panels %v, want 1 and 2", got)
+	}
+	if !strings.Contains(got[1], "with 0.5") {
+		t.Errorf("panel 1 should be reported as a filtering comparison, got %q", got[1])
+	}
+	if !strings.Contains(got[2], "with 10") || !strings.Contains(got[2], "gaps") {
+		t.Errorf("panel 2 should be reported as a filtering comparison, got %q", got[2])
+	}
+
+	// Only timeseries/graph panels display series over time.
+	for i := range ctx.Panels {
+		ctx.Panels[i].Type = "stat"
+	}
+	if findings := (&rules.ComparisonAsSeries{}).Check(ctx); len(findings) != 0 {
+		t.Errorf("Q40 on stat panels: got %d findings, want 0", len(findings))
+	}
+}
+
+// A bool comparison is Q30's finding alone; Q40 does not report it again.
+func TestQ40_BoolComparisonLeftToQ30(t *testing.T) {
+	ctx := buildExprContext(t, `up{job="api"} == bool 1`, `latency_seconds{job="api"} > bool 0.5`)
+	if findings := (&rules.ComparisonAsSeries{}).Check(ctx); len(findings) != 0 {
+		t.Errorf("Q40 on bool comparisons: got %d findings, want 0", len(findings))
+	}
+	if findings := (&rules.BoolComparisonOnTimeSeries{}).Check(ctx); len(findings) != 2 {
+		t.Errorf("Q30 on bool comparisons: got %d findings, want 2", len(findings))
+	}
+}
+
+func TestQ40_DemoDashboards(t *testing.T) {
+	rule := &rules.ComparisonAsSeries{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 68 {
+		t.Fatalf("Q40 should flag panel 68 (> 500000000) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q40 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q41: grouping by pod/instance where a topology label may do ---
+
+func TestQ41_TopologyGrouping(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum by (pod) (rate(container_cpu_usage_seconds_total{namespace="prod"}[5m]))`,
+		`sum by (pod, node) (rate(container_cpu_usage_seconds_total{namespace="prod"}[5m]))`,
+		`sum by (instance) (rate(node_cpu_seconds_total{mode="idle"}[5m]))`,
+	)
+	rule := &rules.TopologyGrouping{}
+
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("Q41 without cardinality data: got %d findings, want 0", len(findings))
+	}
+
+	// pod has more values than node and deployment; instance has as few
+	// values as node, so grouping by it is as good.
+	ctx.Cardinality = &cardinality.CardinalityData{ValuesByLabel: map[string]int{
+		"pod":        4000,
+		"deployment": 300,
+		"node":       50,
+		"instance":   50,
+	}}
+	findings := rule.Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("Q41: got %d findings, want 2 (panels 1 and 2)", len(findings))
+	}
+	if findings[0].PanelIDs[0] != 1 || !strings.Contains(findings[0].Why, "node (50 values) and deployment (300 values)") {
+		t.Errorf("panel 1 finding should list node then deployment, got %v %q", findings[0].PanelIDs, findings[0].Why)
+	}
+	if findings[0].Severity != rules.Low || findings[0].Impact != "Up to 80x fewer result series" {
+		t.Errorf("unexpected severity/impact: %s %q", findings[0].Severity, findings[0].Impact)
+	}
+	// Already grouped by node: only deployment is suggested.
+	if findings[1].PanelIDs[0] != 2 || strings.Contains(findings[1].Why, "node (") {
+		t.Errorf("panel 2 finding should suggest only deployment, got %v %q", findings[1].PanelIDs, findings[1].Why)
+	}
+
+	// pod with fewer values than its alternatives is left alone.
+	ctx.Cardinality.ValuesByLabel["pod"] = 20
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("Q41 with low pod cardinality: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestQ41_DemoDashboards(t *testing.T) {
+	// The demo exporter emits no node label, so the label cardinality is
+	// supplied here: 10 pods on 2 nodes.
+	card := &cardinality.CardinalityData{ValuesByLabel: map[string]int{
+		"pod":      10,
+		"instance": 2,
+		"node":     2,
+	}}
+	rule := &rules.TopologyGrouping{}
+	ctx := buildContext(t, "slow-by-design.json")
+	ctx.Cardinality = card
+	findings := rule.Check(ctx)
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 4 || !strings.Contains(findings[0].Why, "groups by pod") {
+		t.Fatalf("Q41 should flag panel 4's by(pod) on the slow dashboard, got %v", findings)
+	}
+	ctx = buildContext(t, "fixed-by-advisor.json")
+	ctx.Cardinality = card
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("Q41 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D33: kiosk dashboard refreshing a wide range ---
+
+// kioskFixture returns a dashboard with n visible stat panels and the given
+// refresh and default range start.
+func kioskFixture(n int, refresh, from string) string {
+	var panels []string
+	for i := 1; i <= n; i++ {
+		panels = append(panels, fmt.Sprintf(`{"id": %d, "type": "stat", "title": "Panel %d"}`, i, i))
+	}
+	return fmt.Sprintf(`{"uid": "kiosk", "refresh": %q, "time": {"from": %q, "to": "now"}, "panels": [%s]}`, refresh, from, strings.Join(panels, ","))
+}
+
+func TestD33_KioskLoad(t *testing.T) {
+	rule := &rules.KioskLoad{}
+
+	findings := rule.Check(buildJSONContext(t, kioskFixture(20, "10s", "now-24h")))
+	if len(findings) != 1 {
+		t.Fatalf("D33 on 20 panels, 24h, 10s: got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.High {
+		t.Errorf("severity %s, want High", f.Severity)
+	}
+	if !strings.Contains(f.Why, "re-reads the last 24h for every panel every 10s: about 7200 panel refreshes per hour") {
+		t.Errorf("Why should quantify the load, got %q", f.Why)
+	}
+	if !strings.Contains(f.Impact, "83%") {
+		t.Errorf("Impact should compare the refresh with 1m, got %q", f.Impact)
+	}
+
+	// Each condition alone is not enough.
+	for _, tc := range []struct {
+		name string
+		json string
+	}{
+		{"few panels", kioskFixture(10, "10s", "now-24h")},
+		{"1m refresh", kioskFixture(20, "1m", "now-24h")},
+		{"narrow range", kioskFixture(20, "10s", "now-3h")},
+		{"no refresh", kioskFixture(20, "", "now-24h")},
+	} {
+		if findings := rule.Check(buildJSONContext(t, tc.json)); len(findings) != 0 {
+			t.Errorf("D33 with %s: got %d findings, want 0", tc.name, len(findings))
+		}
+	}
+}
+
+func TestD33_DemoDashboards(t *testing.T) {
+	rule := &rules.KioskLoad{}
+	if findings := rule.Check(buildContext(t, "slow-by-design.json")); len(findings) != 1 {
+		t.Errorf("D33 should fire on the slow dashboard (10s refresh, 7d range), got %d findings", len(findings))
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D33 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q42: redundant clamp_min around rate() ---
+
+func TestQ42_RedundantClampMin(t *testing.T) {
+	ctx := buildExprContext(t,
+		`clamp_min(rate(http_requests_total{job="api"}[5m]), 0)`,
+		`clamp_min(sum by (job) (increase(http_requests_total{job="api"}[1h])), 0)`,
+		`clamp_min(deriv(node_memory_MemAvailable_bytes{job="node"}[5m]), 0)`,
+		`clamp_min(rate(http_requests_total{job="api"}[5m]), 1)`,
+		`clamp_min(sum(rate(a_total{job="api"}[5m])) - sum(rate(b_total{job="api"}[5m])), 0)`,
+	)
+	findings := (&rules.RedundantClampMin{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Fatalf("Q42 flagged panels %v, want [1 2]", got)
+	}
+	if !strings.Contains(findings[1].Why, "wraps increase()") || !strings.Contains(findings[1].Fix, `query sum by (job) (increase(`) {
+		t.Errorf("panel 2 finding should name increase() and the unclamped query, got %q / %q", findings[1].Why, findings[1].Fix)
+	}
+}
+
+func TestQ42_DemoDashboards(t *testing.T) {
+	rule := &rules.RedundantClampMin{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 69 {
+		t.Fatalf("Q42 should flag panel 69 (clamp_min around rate()) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q42 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D34: interval variable defaulting too fine ---
+
+// intervalVariableFixture returns a dashboard with an interval variable
+// $interval (the given variable JSON fields) used as the rate window of one
+// panel.
+func intervalVariableFixture(fields string) string {
+	return `{
+		"uid": "interval",
+		"templating": {"list": [{"name": "interval", "type": "interval", ` + fields + `}]},
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Requests", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[$interval]))"}]},
+			{"id": 2, "type": "stat", "title": "Up", "targets": [{"refId": "A", "expr": "sum(up{job=\"api\"})"}]}
+		]
+	}`
+}
+
+func TestD34_FineIntervalVariable(t *testing.T) {
+	rule := &rules.FineIntervalVariable{}
+
+	findings := rule.Check(buildJSONContext(t, intervalVariableFixture(`"query": "10s,1m,5m", "current": {"text": "10s", "value": "10s"}`)))
+	if len(findings) != 1 {
+		t.Fatalf("D34 on a 10s default: got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.Severity != rules.Medium {
+		t.Errorf("severity %s, want Medium", f.Severity)
+	}
+	if fmt.Sprint(f.PanelIDs) != "[1]" {
+		t.Errorf("PanelIDs = %v, want only the panel using $interval", f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, "$interval defaults to 10s (threshold: 1m)") {
+		t.Errorf("Why should name the default and threshold, got %q", f.Why)
+	}
+	if !strings.Contains(f.Impact, "6x fewer points") {
+		t.Errorf("Impact should compare 10s with 1m, got %q", f.Impact)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		fields string
+		want   int
+	}{
+		{"no saved value, first query option", `"query": "15s,1m"`, 1},
+		{"selected option", `"query": "1m,30s", "options": [{"value": "1m"}, {"value": "30s", "selected": true}]`, 1},
+		{"1m default", `"query": "10s,1m", "current": {"value": "1m"}`, 0},
+		{"auto", `"query": "10s,1m", "auto": true, "current": {"text": "auto", "value": "$__auto_interval_interval"}`, 0},
+	} {
+		if got := len(rule.Check(buildJSONContext(t, intervalVariableFixture(tc.fields)))); got != tc.want {
+			t.Errorf("D34 with %s: got %d findings, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// A variable no target uses costs nothing.
+	unused := strings.Replace(intervalVariableFixture(`"query": "10s"`), "[$interval]", "[5m]", 1)
+	if findings := rule.Check(buildJSONContext(t, unused)); len(findings) != 0 {
+		t.Errorf("D34 on an unused variable: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestD34_DemoDashboards(t *testing.T) {
+	rule := &rules.FineIntervalVariable{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || fmt.Sprint(findings[0].PanelIDs) != "[70]" {
+		t.Fatalf("D34 should flag $resolution (10s) used by panel 70 on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D34 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D35: heatmap query aggregating away le ---
+
+// heatmapFixture returns a dashboard with one heatmap panel per expression,
+// with IDs 1..n. options is the panels' raw options JSON ("" for none).
+func heatmapFixture(options string, exprs ...string) string {
+	var panels []string
+	for i, expr := range exprs {
+		opts := ""
+		if options != "" {
+			opts = `, "options": ` + options
+		}
+		panels = append(panels, fmt.Sprintf(`{"id": %d, "type": "heatmap", "title": "Latency %d"%s, "targets": [{"refId": "A", "format": "heatmap", "expr": %q}]}`, i+1, i+1, opts, expr))
+	}
+	return `{"uid": "heatmap", "panels": [` + strings.Join(panels, ",") + `]}`
+}
+
+func TestD35_HeatmapWithoutLe(t *testing.T) {
+	rule := &rules.HeatmapWithoutLe{}
+	dash := heatmapFixture("",
+		`sum(rate(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		`sum by (job) (increase(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		`sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		`rate(http_request_duration_seconds_bucket{job="api"}[5m])`,
+		`sum without (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m]))`,
+		`histogram_quantile(0.9, sum by (le) (rate(http_request_duration_seconds_bucket{job="api"}[5m])))`,
+		`sum(rate(http_requests_total{job="api"}[5m]))`,
+	)
+	findings := rule.Check(buildJSONContext(t, dash))
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.High {
+			t.Errorf("panel %v: severity %s, want High", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 5]" {
+		t.Fatalf("D35 flagged panels %v, want [1 2 5]", got)
+	}
+	if !strings.Contains(findings[0].Fix, "sum by (le) (rate(http_request_duration_seconds_bucket{...}") {
+		t.Errorf("Fix should show the by (le) form, got %q", findings[0].Fix)
+	}
+
+	// A calculated heatmap buckets the values itself.
+	calculated := heatmapFixture(`{"calculate": true}`, `sum(rate(http_request_duration_seconds_bucket{job="api"}[5m]))`)
+	if findings := rule.Check(buildJSONContext(t, calculated)); len(findings) != 0 {
+		t.Errorf("D35 on a calculated heatmap: got %d findings, want 0", len(findings))
+	}
+
+	// Other panel types are left to the quantile rules.
+	timeseries := strings.Replace(heatmapFixture("", `sum(rate(http_request_duration_seconds_bucket{job="api"}[5m]))`), `"type": "heatmap"`, `"type": "timeseries"`, 1)
+	if findings := rule.Check(buildJSONContext(t, timeseries)); len(findings) != 0 {
+		t.Errorf("D35 on a timeseries panel: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestD35_DemoDashboards(t *testing.T) {
+	rule := &rules.HeatmapWithoutLe{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 71 {
+		t.Fatalf("D35 should flag panel 71 (heatmap summing away le) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D35 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q43: CPU counter without mode handling ---
+
+func TestQ43_CPUModeUnhandled(t *testing.T) {
+	// node_cpu_seconds_total as the demo exporter exposes it: labels
+	// instance, cpu and mode (user, system, iowait, idle).
+	ctx := buildExprContext(t,
+		`rate(node_cpu_seconds_total{instance="node-1"}[5m])`,
+		`sum by (instance) (rate(node_cpu_seconds_total[5m]))`,
+		`sum(sum by (mode) (irate(node_cpu_seconds_total[5m])))`,
+		`1 - avg by (instance) (rate(node_cpu_seconds_total{mode="idle"}[5m]))`,
+		`sum by (instance, mode) (rate(node_cpu_seconds_total[5m]))`,
+		`sum without (cpu) (rate(node_cpu_seconds_total[5m]))`,
+		`rate(node_cpu_seconds_total[5m]) / ignoring(mode) group_left sum without (mode) (rate(node_cpu_seconds_total[5m]))`,
+		`sum by (instance) (rate(node_network_receive_bytes_total[5m]))`,
+	)
+	findings := (&rules.CPUModeUnhandled{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Fatalf("Q43 flagged panels %v, want [1 2 3]", got)
+	}
+	if !strings.Contains(findings[0].Fix, `1 - avg by (instance) (rate(node_cpu_seconds_total{mode="idle", ...}`) {
+		t.Errorf("Fix should suggest the utilization pattern, got %q", findings[0].Fix)
+	}
+}
+
+func TestQ43_DemoDashboards(t *testing.T) {
+	rule := &rules.CPUModeUnhandled{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 72 {
+		t.Fatalf("Q43 should flag panel 72 (CPU rate summed across modes) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q43 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D36: variable returning too many label values ---
+
+func TestD36_HighCardinalityVariable(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "vars",
+		"templating": {"list": [
+			{"name": "instance", "type": "query", "query": "label_values(up, instance)"},
+			{"name": "pod", "type": "query", "query": {"query": "label_values(kube_pod_info{namespace=\"$namespace\"}, pod)", "refId": "A"}},
+			{"name": "job", "type": "query", "query": "label_values(up, job)"},
+			{"name": "node", "type": "query", "query": "label_values(node_uname_info, instance)"},
+			{"name": "custom", "type": "custom", "query": "a,b,c"}
+		]}
+	}`)
+	rule := &rules.HighCardinalityVariable{}
+
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("D36 without cardinality data: got %d findings, want 0", len(findings))
+	}
+
+	// node_uname_info has one series per node, far fewer than the
+	// instance values across every job.
+	ctx.Cardinality = &cardinality.CardinalityData{
+		ValuesByLabel:  map[string]int{"instance": 20000, "pod": 5000, "job": 40},
+		SeriesByMetric: map[string]int{"up": 20000, "node_uname_info": 300},
+	}
+	findings := rule.Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("D36: got %d findings, want 2 ($instance and $pod)", len(findings))
+	}
+	if f := findings[0]; !strings.Contains(f.Why, "$instance") || !strings.Contains(f.Why, "up to 20000 values (threshold: 1000)") || f.Confidence != 0.8 {
+		t.Errorf("unexpected $instance finding: %q (confidence %v)", f.Why, f.Confidence)
+	}
+	if f := findings[1]; !strings.Contains(f.Why, "$pod") || f.Confidence != 0.5 {
+		t.Errorf("$pod is scoped by $namespace, want lower confidence, got %q (confidence %v)", f.Why, f.Confidence)
+	}
+	if findings[0].Severity != rules.Medium {
+		t.Errorf("severity %s, want Medium", findings[0].Severity)
+	}
+}
+
+func TestD36_DemoDashboards(t *testing.T) {
+	// The demo exporter runs 10 pods; a production cluster has thousands,
+	// of which only the scraped ones have an up series.
+	card := &cardinality.CardinalityData{
+		ValuesByLabel:  map[string]int{"pod": 5000},
+		SeriesByMetric: map[string]int{"kube_pod_info": 5000, "up": 200},
+	}
+	rule := &rules.HighCardinalityVariable{}
+	ctx := buildContext(t, "slow-by-design.json")
+	ctx.Cardinality = card
+	findings := rule.Check(ctx)
+	if len(findings) != 1 || !strings.Contains(findings[0].Why, "Variable $pod") {
+		t.Fatalf("D36 should flag $pod on the slow dashboard, got %v", findings)
+	}
+	ctx = buildContext(t, "fixed-by-advisor.json")
+	ctx.Cardinality = card
+	if findings := rule.Check(ctx); len(findings) != 0 {
+		t.Errorf("D36 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q44: rate window longer than the displayed range ---
+
+func TestQ44_WindowExceedsRange(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "short-range",
+		"time": {"from": "now-15m", "to": "now"},
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Hourly", "targets": [{"refId": "A", "expr": "sum(increase(http_requests_total{job=\"api\"}[1h]))"}]},
+			{"id": 2, "type": "timeseries", "title": "5m", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"}]},
+			{"id": 3, "type": "timeseries", "title": "Range var", "targets": [{"refId": "A", "expr": "sum(increase(http_requests_total{job=\"api\"}[$__range]))"}]},
+			{"id": 4, "type": "timeseries", "title": "Override", "timeFrom": "6h", "targets": [{"refId": "A", "expr": "sum(increase(http_requests_total{job=\"api\"}[1h]))"}]},
+			{"id": 5, "type": "timeseries", "title": "Short override", "timeFrom": "30m", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[45m]))"}]}
+		]
+	}`)
+	findings := (&rules.WindowExceedsRange{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Medium {
+			t.Errorf("panel %v: severity %s, want Medium", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 5]" {
+		t.Fatalf("Q44 flagged panels %v, want [1 5]", got)
+	}
+	if !strings.Contains(findings[0].Why, `[1h] window, 4x the 15m (dashboard range "now-15m")`) {
+		t.Errorf("Why should compare the window with the range, got %q", findings[0].Why)
+	}
+	if !strings.Contains(findings[1].Why, `panel time override "30m"`) {
+		t.Errorf("Why should name the panel override, got %q", findings[1].Why)
+	}
+}
+
+func TestQ44_DemoDashboards(t *testing.T) {
+	rule := &rules.WindowExceedsRange{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 73 {
+		t.Fatalf("Q44 should flag panel 73 ([30d] on a 7d dashboard) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q44 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D37: panel duplicated inside a collapsed row ---
+
+func TestD37_DuplicateNestedPanel(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "dup",
+		"panels": [
+			{"id": 1, "type": "stat", "title": "Up", "targets": [{"refId": "A", "expr": "sum(up{job=\"api\"})"}]},
+			{"id": 2, "type": "timeseries", "title": "Requests", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"}]},
+			{"id": 10, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 2, "type": "timeseries", "title": "Requests", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"}]},
+				{"id": 3, "type": "timeseries", "title": "Errors", "targets": [{"refId": "A", "expr": "sum(rate(http_errors_total{job=\"api\"}[5m]))"}]}
+			]},
+			{"id": 11, "type": "row", "title": "More", "collapsed": true, "panels": [
+				{"id": 3, "type": "timeseries", "title": "Errors", "targets": [{"refId": "A", "expr": "sum(rate(http_errors_total{job=\"api\"}[5m]))"}]}
+			]}
+		]
+	}`)
+	if n := len(ctx.Panels); n != 3 {
+		t.Errorf("panels with targets = %d, want 3 (duplicates counted once)", n)
+	}
+
+	findings := (&rules.DuplicateNestedPanel{}).Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("D37: got %d findings, want 2", len(findings))
+	}
+	if f := findings[0]; f.PanelIDs[0] != 2 || !strings.Contains(f.Why, `nested in row "Details", but a panel with the same ID is also listed at the top level`) {
+		t.Errorf("unexpected first finding: %v %q", f.PanelIDs, f.Why)
+	}
+	if f := findings[1]; f.PanelIDs[0] != 3 || !strings.Contains(f.Why, `also listed in row "Details"`) {
+		t.Errorf("unexpected second finding: %v %q", f.PanelIDs, f.Why)
+	}
+	if findings[0].Severity != rules.Low {
+		t.Errorf("severity %s, want Low", findings[0].Severity)
+	}
+}
+
+func TestD37_DemoDashboards(t *testing.T) {
+	rule := &rules.DuplicateNestedPanel{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 66 || !strings.Contains(findings[0].Why, `nested in row "Runtime"`) {
+		t.Fatalf("D37 should flag panel 66's copy in row \"Runtime\" on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D37 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// A nested copy whose queries differ is kept and analyzed; D37 still
+// reports the ID collision.
+func TestD37_DuplicateIDDifferentPanel(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "dup",
+		"panels": [
+			{"id": 2, "type": "timeseries", "title": "Requests", "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{job=\"api\"}[5m]))"}]},
+			{"id": 10, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 2, "type": "timeseries", "title": "Errors", "targets": [{"refId": "A", "expr": "sum(rate(http_errors_total{job=\"api\"}[5m]))"}]}
+			]}
+		]
+	}`)
+	if n := len(ctx.Panels); n != 2 {
+		t.Errorf("panels with targets = %d, want 2 (differing copies both kept)", n)
+	}
+	findings := (&rules.DuplicateNestedPanel{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("D37: got %d findings, want 1", len(findings))
+	}
+	if !strings.Contains(findings[0].Why, "the copies differ, so both were analyzed") {
+		t.Errorf("Why should say both copies were analyzed: %q", findings[0].Why)
+	}
+}
+
+// --- Q45: ignoring() with a long label list ---
+
+func TestQ45_LongIgnoringList(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum by (namespace, pod) (rate(http_errors_total{job="api"}[5m])) / ignoring(pod, instance, container, node, endpoint) group_left sum by (namespace) (rate(http_requests_total{job="api"}[5m]))`,
+		`rate(http_errors_total{job="api"}[5m]) / ignoring(pod, instance, container, node, endpoint) rate(http_requests_total{job="api"}[5m])`,
+		`rate(http_errors_total{job="api"}[5m]) / ignoring(pod, instance, container, node) rate(http_requests_total{job="api"}[5m])`,
+		`rate(http_errors_total{job="api"}[5m]) / on(namespace, service, pod, instance, container) rate(http_requests_total{job="api"}[5m])`,
+	)
+	findings := (&rules.LongIgnoringList{}).Check(ctx)
+
+	var got []int
+	for _, f := range findings {
+		got = append(got, f.PanelIDs[0])
+		if f.Severity != rules.Low {
+			t.Errorf("panel %v: severity %s, want Low", f.PanelIDs, f.Severity)
+		}
+	}
+	if fmt.Sprint(got) != "[1 2]" {
+		t.Fatalf("Q45 flagged panels %v, want [1 2]", got)
+	}
+	if !strings.Contains(findings[0].Why, "ignoring(pod, instance, container, node, endpoint): 5 labels (threshold: 4)") {
+		t.Errorf("Why should list the ignored labels, got %q", findings[0].Why)
+	}
+	if !strings.Contains(findings[0].Fix, "on(namespace)") {
+		t.Errorf("Fix should derive on(namespace) from the aggregations, got %q", findings[0].Fix)
+	}
+	if strings.Contains(findings[1].Fix, "aggregated side") {
+		t.Errorf("Fix without aggregations should stay generic, got %q", findings[1].Fix)
+	}
+
+	if findings := (&rules.LongIgnoringList{MaxLabels: 5}).Check(ctx); len(findings) != 0 {
+		t.Errorf("Q45 with MaxLabels 5: got %d findings, want 0", len(findings))
+	}
+}
+
+func TestQ45_DemoDashboards(t *testing.T) {
+	rule := &rules.LongIgnoringList{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 74 {
+		t.Fatalf("Q45 should flag panel 74 (ignoring five labels) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q45 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- D38: repeat options without a repeat variable ---
+
+func TestD38_StaleRepeatOptions(t *testing.T) {
+	ctx := buildJSONContext(t, `{
+		"uid": "repeat",
+		"templating": {"list": [{"name": "instance", "type": "query", "query": "label_values(up, instance)"}]},
+		"panels": [
+			{"id": 1, "type": "timeseries", "title": "Per instance", "repeat": "instance", "repeatDirection": "h", "maxPerRow": 4, "targets": [{"refId": "A", "expr": "up{instance=\"$instance\"}"}]},
+			{"id": 2, "type": "timeseries", "title": "Copied", "repeatDirection": "h", "maxPerRow": 4, "targets": [{"refId": "A", "expr": "up{job=\"api\"}"}]},
+			{"id": 3, "type": "stat", "title": "Plain", "targets": [{"refId": "A", "expr": "sum(up{job=\"api\"})"}]},
+			{"id": 10, "type": "row", "title": "Details", "collapsed": true, "panels": [
+				{"id": 4, "type": "timeseries", "title": "Nested", "repeatDirection": "v", "targets": [{"refId": "A", "expr": "up{job=\"db\"}"}]}
+			]}
+		]
+	}`)
+	findings := (&rules.StaleRepeatOptions{}).Check(ctx)
+	if len(findings) != 2 {
+		t.Fatalf("D38: got %d findings, want 2", len(findings))
+	}
+	if f := findings[0]; f.PanelIDs[0] != 2 || !strings.Contains(f.Why, `repeatDirection "h" and maxPerRow 4`) {
+		t.Errorf("unexpected first finding: %v %q", f.PanelIDs, f.Why)
+	}
+	if f := findings[1]; f.PanelIDs[0] != 4 || strings.Contains(f.Why, "maxPerRow") {
+		t.Errorf("unexpected second finding: %v %q", f.PanelIDs, f.Why)
+	}
+	if f := findings[0]; f.Severity != rules.Low || !f.AutoFixable {
+		t.Errorf("severity %s autofixable %v, want Low and true", f.Severity, f.AutoFixable)
+	}
+}
+
+func TestD38_DemoDashboards(t *testing.T) {
+	rule := &rules.StaleRepeatOptions{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 75 {
+		t.Fatalf("D38 should flag panel 75 (repeat options without repeat) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D38 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- Q46: long metric name alternation ---
+
+func TestQ46_MetricNameAlternation(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum by (__name__) (rate({__name__=~"m1_total|m2_total|m3_total|m4_total|m5_total|m6_total", job="api"}[5m]))`,
+		`{__name__=~"m1|m2|m3|m4|m5", job="api"}`,
+		`{__name__=~"(m1|m2|m3|m4|m5|m6)_total", job="api"}`,
+		`{__name__=~"m[1|2|3|4|5|6]", job="api"}`,
+		`{__name__=~"node_.*", job="node"}`,
+		`{__name__!~"m1|m2|m3|m4|m5|m6", job="api"}`,
+	)
+	findings := (&rules.MetricNameAlternation{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("Q46: got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if f.PanelIDs[0] != 1 || f.Severity != rules.Medium {
+		t.Errorf("unexpected finding: panel %v severity %s", f.PanelIDs, f.Severity)
+	}
+	if !strings.Contains(f.Why, "unions 6 metric names") || !strings.Contains(f.Fix, "m1_total{...}") {
+		t.Errorf("unexpected text: %q / %q", f.Why, f.Fix)
+	}
+
+	if findings := (&rules.MetricNameAlternation{MaxAlternatives: 4}).Check(ctx); len(findings) != 2 {
+		t.Errorf("MaxAlternatives 4: got %d findings, want 2", len(findings))
+	}
+}
+
+func TestQ46_DemoDashboards(t *testing.T) {
+	rule := &rules.MetricNameAlternation{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || findings[0].PanelIDs[0] != 76 {
+		t.Fatalf("Q46 should flag panel 76 (six metric names in one __name__ regex) on the slow dashboard, got %v", findings)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("Q46 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- B10: expensive dashboard without recording rules ---
+
+func TestB10_NoRecordingRules(t *testing.T) {
+	ctx := buildContext(t, "slow-by-design.json")
+	findings := (&rules.NoRecordingRules{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("B10 on slow-by-design.json: got %d findings, want 1", len(findings))
+	}
+	if f := findings[0]; f.Severity != rules.Medium || !strings.Contains(f.Why, "none reads a recording rule output") {
+		t.Errorf("unexpected finding: %s %q", f.Severity, f.Why)
+	}
+
+	if findings := (&rules.NoRecordingRules{}).Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("B10 on fixed-by-advisor.json: got %d findings, want 0", len(findings))
+	}
+	if findings := (&rules.NoRecordingRules{MaxTotalCost: 1e12}).Check(ctx); len(findings) != 0 {
+		t.Errorf("B10 with MaxTotalCost 1e12: got %d findings, want 0", len(findings))
+	}
+
+	// One recorded series anywhere in the dashboard means recording rules
+	// are already in use.
+	recorded := *ctx
+	recorded.ParsedExprs = make(map[string]parser.Expr, len(ctx.ParsedExprs)+1)
+	for k, v := range ctx.ParsedExprs {
+		recorded.ParsedExprs[k] = v
+	}
+	expr, err := parser.ParseExpr(`sum(job:http_requests:rate5m{job="api"})`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded.ParsedExprs[expr.String()] = expr
+	if findings := (&rules.NoRecordingRules{}).Check(&recorded); len(findings) != 0 {
+		t.Errorf("B10 with a recorded metric: got %d findings, want 0", len(findings))
+	}
+}
+
+// --- D39: metric queried with both rate() and irate() ---
+
+func TestD39_RateIrateMix(t *testing.T) {
+	ctx := buildExprContext(t,
+		`sum(rate(http_requests_total{job="api"}[5m]))`,
+		`sum(irate(http_requests_total{job="api"}[5m]))`,
+		`sum by (code) (rate(http_requests_total{job="api"}[5m])) / sum(irate(http_requests_total{job="api"}[5m]))`,
+		`sum(irate(http_errors_total{job="api"}[5m]))`,
+		`sum(rate(node_network_receive_bytes_total{job="node"}[5m]))`,
+		`sum(increase(node_network_receive_bytes_total{job="node"}[1h]))`,
+	)
+	findings := (&rules.RateIrateMix{}).Check(ctx)
+	if len(findings) != 1 {
+		t.Fatalf("D39: got %d findings, want 1", len(findings))
+	}
+	f := findings[0]
+	if fmt.Sprint(f.PanelIDs) != "[1 3 2]" {
+		t.Errorf("panel IDs = %v, want [1 3 2] (rate panels first)", f.PanelIDs)
+	}
+	if !strings.Contains(f.Why, `http_requests_total is queried with rate() in panels "Panel 1", "Panel 3" and with irate() in panels "Panel 2", "Panel 3"`) {
+		t.Errorf("unexpected Why: %q", f.Why)
+	}
+	if f.Severity != rules.Low {
+		t.Errorf("severity %s, want Low", f.Severity)
+	}
+}
+
+func TestD39_DemoDashboards(t *testing.T) {
+	rule := &rules.RateIrateMix{}
+	findings := rule.Check(buildContext(t, "slow-by-design.json"))
+	if len(findings) != 1 || !strings.HasPrefix(findings[0].Why, "http_requests_total ") {
+		t.Fatalf("D39 should flag http_requests_total (irate() in panel 77, rate() elsewhere) on the slow dashboard, got %v", findings)
+	}
+	if ids := findings[0].PanelIDs; ids[len(ids)-1] != 77 {
+		t.Errorf("D39 panel IDs = %v, want the irate panel 77 last", ids)
+	}
+	if findings := rule.Check(buildContext(t, "fixed-by-advisor.json")); len(findings) != 0 {
+		t.Errorf("D39 should not fire on the fixed dashboard, got %d findings", len(findings))
+	}
+}
+
+// --- IsPanelRule: per-panel rules must not depend on other panels ---
+
+// Every Q rule that IsPanelRule admits must report the same findings for a
+// panel on a context narrowed to that panel as on the whole dashboard. A
+// rule comparing panels fails this and belongs in crossPanelRules.
+func TestIsPanelRule_NarrowedMatchesFull(t *testing.T) {
+	contexts := map[string]*rules.AnalysisContext{
+		"slow-by-design.json":   buildContext(t, "slow-by-design.json"),
+		"fixed-by-advisor.json": buildContext(t, "fixed-by-advisor.json"),
+		"quantile windows":      buildJSONContext(t, quantileWindowFixture),
+	}
+	countFor := func(findings []rules.Finding, id int) int {
+		n := 0
+		for _, f := range findings {
+			for _, pid := range f.PanelIDs {
+				if pid == id {
+					n++
+					break
+				}
+			}
+		}
+		return n
+	}
+	for _, r := range analyzer.DefaultEngine().Rules() {
+		if !rules.IsPanelRule(r) {
+			continue
+		}
+		for name, ctx := range contexts {
+			full := r.Check(ctx)
+			for _, p := range ctx.Panels {
+				want := countFor(full, p.ID)
+				if got := countFor(r.Check(ctx.ForPanels(p.ID)), p.ID); got != want {
+					t.Errorf("%s on %s, panel %d: %d findings narrowed, %d on the full dashboard; add it to crossPanelRules if it compares panels", r.ID(), name, p.ID, got, want)
+				}
+			}
+		}
+	}
+}
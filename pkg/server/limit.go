@@ -0,0 +1,143 @@
+package server
+
+import (
+	"container/list"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRateClients bounds the client addresses clientLimiter tracks.
+const maxRateClients = 10000
+
+// tokenBucket is a minimal rate limiter: it holds up to burst tokens,
+// refills at rate tokens per second, and each allowed request takes one.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// allow reports whether a request may proceed, consuming a token if so.
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// clientLimiter rate-limits each client address with its own tokenBucket,
+// so one busy client cannot use up the rate of the others. It keeps at most
+// maxClients buckets and drops the least recently seen client's first; a
+// dropped client starts again with a full burst. Clients behind the same
+// proxy or NAT share one address and so one bucket.
+type clientLimiter struct {
+	rate       float64
+	burst      int
+	maxClients int
+
+	mu       sync.Mutex
+	byClient map[string]*list.Element // values are *clientBucket
+	lru      *list.List               // most recently seen at the front
+}
+
+type clientBucket struct {
+	client string
+	bucket *tokenBucket
+}
+
+func newClientLimiter(rate float64, burst, maxClients int) *clientLimiter {
+	return &clientLimiter{
+		rate:       rate,
+		burst:      burst,
+		maxClients: maxClients,
+		byClient:   make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// allow reports whether a request from r's client may proceed.
+func (l *clientLimiter) allow(r *http.Request) bool {
+	return l.bucket(clientAddr(r)).allow()
+}
+
+// bucket returns client's bucket, creating it and evicting the least
+// recently seen client if needed.
+func (l *clientLimiter) bucket(client string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.byClient[client]; ok {
+		l.lru.MoveToFront(el)
+		return el.Value.(*clientBucket).bucket
+	}
+	b := newTokenBucket(l.rate, l.burst)
+	l.byClient[client] = l.lru.PushFront(&clientBucket{client: client, bucket: b})
+	for l.lru.Len() > l.maxClients {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.byClient, oldest.Value.(*clientBucket).client)
+	}
+	return b
+}
+
+// clientAddr returns the host part of r.RemoteAddr. Forwarding headers are
+// not trusted, since any client can set them.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limit wraps an API handler with the configured rate and concurrency limits.
+// Both reject with 429 rather than queueing, so a flood of requests cannot
+// pile up goroutines waiting on analysis.
+func (s *srv) limit(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter != nil && !s.limiter.allow(r) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		if s.slots != nil {
+			select {
+			case s.slots <- struct{}{}:
+				defer func() { <-s.slots }()
+			default:
+				http.Error(w, "too many concurrent requests", http.StatusTooManyRequests)
+				return
+			}
+		}
+		h(w, r)
+	}
+}
@@ -1,24 +1,90 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
 
 	"github.com/dashboard-advisor/pkg/analyzer"
 	"github.com/dashboard-advisor/pkg/cardinality"
+	"github.com/dashboard-advisor/pkg/extractor"
 	"github.com/dashboard-advisor/pkg/fixer"
+	"github.com/dashboard-advisor/pkg/output"
+	"github.com/dashboard-advisor/pkg/rules"
 	"github.com/dashboard-advisor/web"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// defaultMaxBodyBytes caps request bodies when Options.MaxBodyBytes is zero.
+const defaultMaxBodyBytes = 10 << 20
+
+// Options configures request limits for the API handlers. The zero value
+// keeps the 10MB body cap and disables every other limit.
+type Options struct {
+	// AnalyzeTimeout bounds rule execution per request. Zero means no limit
+	// beyond the request's own context.
+	AnalyzeTimeout time.Duration
+	// MaxBodyBytes caps the dashboard JSON accepted per request.
+	// Defaults to 10MB if zero.
+	MaxBodyBytes int64
+	// MaxConcurrent caps API requests analyzed at once; excess requests get
+	// 429. Zero means unlimited.
+	MaxConcurrent int
+	// RateLimit is the sustained API request rate allowed per client
+	// address, in requests per second. Zero disables rate limiting.
+	RateLimit float64
+	// RateBurst is the number of requests allowed above RateLimit in a
+	// burst. Defaults to 1 if zero.
+	RateBurst int
+	// MaxExprs caps the targets and annotation queries per dashboard; larger
+	// dashboards get 413. Defaults to analyzer.DefaultMaxExprs if zero;
+	// negative disables the cap.
+	MaxExprs int
+	// NewEngine builds the engine for each request, configured with the
+	// rules and scoring options to use. MaxExprs and cardinality enrichment
+	// are applied on top. Defaults to analyzer.NewEngineWithRegistered if nil.
+	NewEngine func() *analyzer.Engine
+}
+
+func (o Options) maxBodyBytes() int64 {
+	if o.MaxBodyBytes > 0 {
+		return o.MaxBodyBytes
+	}
+	return defaultMaxBodyBytes
+}
+
+func (o Options) maxExprs() int {
+	if o.MaxExprs == 0 {
+		return analyzer.DefaultMaxExprs
+	}
+	return o.MaxExprs
+}
+
 // Handler returns an http.Handler serving the web UI and API endpoints.
 // cardClient and promURL are optional — pass nil/"" for static-only analysis.
-func Handler(cardClient *cardinality.Client, promURL string) http.Handler {
-	s := &srv{cardClient: cardClient, promURL: promURL}
+func Handler(cardClient *cardinality.Client, promURL string, opts Options) http.Handler {
+	s := &srv{cardClient: cardClient, promURL: promURL, opts: opts, reports: output.NewReportCollector()}
+	if opts.MaxConcurrent > 0 {
+		s.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	if opts.RateLimit > 0 {
+		s.limiter = newClientLimiter(opts.RateLimit, opts.RateBurst, maxRateClients)
+	}
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /api/analyze", s.handleAnalyze)
-	mux.HandleFunc("POST /api/fix", s.handleFix)
+	mux.HandleFunc("POST /api/analyze", s.limit(s.handleAnalyze))
+	mux.HandleFunc("POST /api/analyze/panel", s.limit(s.handleAnalyzePanel))
+	mux.HandleFunc("POST /api/fix", s.limit(s.handleFix))
+	mux.Handle("GET /metrics", s.metricsHandler())
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /version", handleVersion)
 	mux.HandleFunc("GET /", handleIndex)
 	return mux
 }
@@ -26,16 +92,99 @@ func Handler(cardClient *cardinality.Client, promURL string) http.Handler {
 type srv struct {
 	cardClient *cardinality.Client
 	promURL    string
+	opts       Options
+	slots      chan struct{}  // concurrency semaphore; nil when unlimited
+	limiter    *clientLimiter // nil when rate limiting is disabled
+	reports    *output.ReportCollector
+}
+
+// metricsHandler serves the latest score and finding counts of every
+// dashboard analyzed via /api/analyze, in Prometheus exposition format.
+func (s *srv) metricsHandler() http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(s.reports)
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
 }
 
 func (s *srv) buildEngine() *analyzer.Engine {
-	engine := analyzer.DefaultEngine()
+	engine := analyzer.NewEngineWithRegistered()
+	if s.opts.NewEngine != nil {
+		engine = s.opts.NewEngine()
+	}
+	engine.WithMaxExprs(s.opts.maxExprs())
 	if s.cardClient != nil {
 		engine.WithCardinality(s.cardClient, s.promURL)
 	}
 	return engine
 }
 
+// analyzeContext derives the per-request analysis context from r, applying
+// the configured timeout.
+func (s *srv) analyzeContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.opts.AnalyzeTimeout > 0 {
+		return context.WithTimeout(r.Context(), s.opts.AnalyzeTimeout)
+	}
+	return context.WithCancel(r.Context())
+}
+
+// analyzeErrorStatus maps an analysis error to the HTTP status answering it.
+func analyzeErrorStatus(err error) int {
+	switch {
+	case analysisStopped(err):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, analyzer.ErrTooManyExprs):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// analysisStopped reports whether err came from the analysis context rather
+// than from parsing the request body.
+func analysisStopped(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// handleHealthz is the liveness check: it does no work beyond answering.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
+
+// versionInfo is the /version response body.
+type versionInfo struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	Revision  string `json:"revision,omitempty"`  // VCS commit the binary was built from
+	BuildTime string `json:"buildTime,omitempty"` // VCS commit time
+	Modified  bool   `json:"modified,omitempty"`  // built from a dirty working tree
+}
+
+// buildVersion is computed once; build info does not change at runtime.
+var buildVersion = sync.OnceValue(func() versionInfo {
+	info := versionInfo{Version: analyzer.Version, GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+})
+
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildVersion())
+}
+
 func handleIndex(w http.ResponseWriter, r *http.Request) {
 	data, err := web.Content.ReadFile("index.html")
 	if err != nil {
@@ -46,51 +195,131 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-func (s *srv) handleAnalyze(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+// readBody reads the request body within the configured size limit. On
+// failure it writes the error response and returns false.
+func (s *srv) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	defer r.Body.Close()
+	// Read one byte past the limit so oversized bodies are rejected instead
+	// of silently truncated into invalid JSON.
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.opts.maxBodyBytes()+1))
 	if err != nil {
 		http.Error(w, "error reading request body", http.StatusBadRequest)
-		return
+		return nil, false
 	}
-	defer r.Body.Close()
-
 	if len(body) == 0 {
 		http.Error(w, "empty request body", http.StatusBadRequest)
+		return nil, false
+	}
+	if int64(len(body)) > s.opts.maxBodyBytes() {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return body, true
+}
+
+func (s *srv) handleAnalyze(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readBody(w, r)
+	if !ok {
 		return
 	}
 
+	ctx, cancel := s.analyzeContext(r)
+	defer cancel()
+
 	engine := s.buildEngine()
-	report, err := engine.AnalyzeBytes(body)
+	report, err := engine.AnalyzeBytesContext(ctx, body)
 	if err != nil {
 		log.Printf("analyze error: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), analyzeErrorStatus(err))
 		return
 	}
 
+	s.reports.Observe(report)
+
 	w.Header().Set("Content-Type", "application/json")
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	enc.Encode(report)
+	(&output.JSONFormatter{Indent: true}).Format(w, report)
 }
 
-func (s *srv) handleFix(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+// panelRequest is the /api/analyze/panel request body: one panel as it
+// appears in dashboard JSON, plus the dashboard's templating block so
+// variable references in the panel's queries resolve as they would in the
+// full dashboard.
+type panelRequest struct {
+	Panel      *extractor.PanelModel     `json:"panel"`
+	Templating extractor.TemplatingModel `json:"templating"`
+}
+
+// panelResponse is the /api/analyze/panel response body.
+type panelResponse struct {
+	PanelID  int             `json:"panelId"`
+	Score    int             `json:"score"` // per-panel score from the returned findings
+	Findings []rules.Finding `json:"findings"`
+}
+
+// handleAnalyzePanel re-checks a single edited panel with the per-panel
+// (Q-series) rules only, so the UI need not re-analyze the whole dashboard
+// on every edit. Dashboard-wide rules and /metrics are not updated.
+func (s *srv) handleAnalyzePanel(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	var req panelRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "parsing panel request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Panel == nil {
+		http.Error(w, `missing "panel"`, http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := s.analyzeContext(r)
+	defer cancel()
+
+	dash := &extractor.DashboardModel{
+		Panels:     []extractor.PanelModel{*req.Panel},
+		Templating: req.Templating,
+	}
+	findings, err := s.buildEngine().AnalyzePanelsContext(ctx, dash, req.Panel.ID)
 	if err != nil {
-		http.Error(w, "error reading request body", http.StatusBadRequest)
+		log.Printf("panel analyze error: %v", err)
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, analyzer.ErrTooManyExprs) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
-	defer r.Body.Close()
+	if findings == nil {
+		findings = []rules.Finding{}
+	}
 
-	if len(body) == 0 {
-		http.Error(w, "empty request body", http.StatusBadRequest)
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(panelResponse{
+		PanelID:  req.Panel.ID,
+		Score:    rules.ComputeScore(findings),
+		Findings: findings,
+	})
+}
+
+func (s *srv) handleFix(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.readBody(w, r)
+	if !ok {
 		return
 	}
 
+	ctx, cancel := s.analyzeContext(r)
+	defer cancel()
+
 	engine := s.buildEngine()
-	report, err := engine.AnalyzeBytes(body)
+	report, err := engine.AnalyzeBytesContext(ctx, body)
 	if err != nil {
 		log.Printf("fix analysis error: %v", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		http.Error(w, err.Error(), analyzeErrorStatus(err))
 		return
 	}
 
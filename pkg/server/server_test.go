@@ -0,0 +1,356 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dashboard-advisor/pkg/analyzer"
+	"github.com/dashboard-advisor/pkg/rules"
+)
+
+func testdataPath(name string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "demo", "dashboards", name)
+}
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(testdataPath(name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+func postAnalyze(h http.Handler, body []byte) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestHandler_RateLimitReturns429(t *testing.T) {
+	body := loadFixture(t, "fixed-by-advisor.json")
+	h := Handler(nil, "", Options{RateLimit: 0.001, RateBurst: 3})
+
+	counts := map[int]int{}
+	for i := 0; i < 10; i++ {
+		counts[postAnalyze(h, body)]++
+	}
+	if counts[http.StatusOK] != 3 {
+		t.Errorf("got %d OK responses, want the burst of 3", counts[http.StatusOK])
+	}
+	if counts[http.StatusTooManyRequests] != 7 {
+		t.Errorf("got %d 429 responses, want 7 once the burst is spent", counts[http.StatusTooManyRequests])
+	}
+}
+
+func TestHandler_RateLimitPerClient(t *testing.T) {
+	body := loadFixture(t, "fixed-by-advisor.json")
+	h := Handler(nil, "", Options{RateLimit: 0.001, RateBurst: 2})
+
+	post := func(addr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(body))
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	// A different port is the same client.
+	for i, addr := range []string{"10.0.0.1:1000", "10.0.0.1:1001"} {
+		if code := post(addr); code != http.StatusOK {
+			t.Fatalf("request %d from 10.0.0.1 = %d, want 200 within the burst", i, code)
+		}
+	}
+	if code := post("10.0.0.1:1002"); code != http.StatusTooManyRequests {
+		t.Errorf("third request from 10.0.0.1 = %d, want 429", code)
+	}
+	if code := post("10.0.0.2:1000"); code != http.StatusOK {
+		t.Errorf("first request from 10.0.0.2 = %d, want 200 despite 10.0.0.1 being limited", code)
+	}
+}
+
+func TestClientLimiter_Bounded(t *testing.T) {
+	l := newClientLimiter(0.001, 1, 2)
+	for _, c := range []string{"a", "b", "c"} {
+		if !l.bucket(c).allow() {
+			t.Fatalf("first request from %s should be allowed", c)
+		}
+	}
+	if len(l.byClient) != 2 || l.lru.Len() != 2 {
+		t.Fatalf("tracked %d clients, want the cap of 2", len(l.byClient))
+	}
+	if _, ok := l.byClient["a"]; ok {
+		t.Error("least recently seen client a should have been evicted")
+	}
+	if l.bucket("c").allow() {
+		t.Error("client c should still be limited")
+	}
+}
+
+func TestLimit_ConcurrencyReturns429(t *testing.T) {
+	s := &srv{slots: make(chan struct{}, 1)}
+	h := s.limit(func(w http.ResponseWriter, r *http.Request) {})
+
+	serve := func() int {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodPost, "/api/analyze", nil))
+		return rec.Code
+	}
+
+	// Simulate an in-flight request holding the only slot.
+	s.slots <- struct{}{}
+	if code := serve(); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want 429 while the slot is held", code)
+	}
+	<-s.slots
+	if code := serve(); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 once the slot is free", code)
+	}
+	if len(s.slots) != 0 {
+		t.Error("handler should release its slot when done")
+	}
+}
+
+func TestHandler_BodyTooLarge(t *testing.T) {
+	body := loadFixture(t, "fixed-by-advisor.json")
+	h := Handler(nil, "", Options{MaxBodyBytes: int64(len(body) - 1)})
+	if code := postAnalyze(h, body); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", code)
+	}
+
+	h = Handler(nil, "", Options{MaxBodyBytes: int64(len(body))})
+	if code := postAnalyze(h, body); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 for a body exactly at the limit", code)
+	}
+}
+
+func TestTokenBucket_Refills(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(2, 1)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	if !b.allow() {
+		t.Fatal("first request should use the burst token")
+	}
+	if b.allow() {
+		t.Fatal("second immediate request should be limited")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !b.allow() {
+		t.Error("a token should have refilled after 1/rate seconds")
+	}
+}
+
+func TestHandler_MetricsAfterAnalyze(t *testing.T) {
+	h := Handler(nil, "", Options{})
+	if code := postAnalyze(h, loadFixture(t, "slow-by-design.json")); code != http.StatusOK {
+		t.Fatalf("analyze status = %d, want 200", code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/metrics status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`dashboard_advisor_score{uid="slow-by-design"}`,
+		`dashboard_advisor_findings_total{rule="Q1",severity="Critical",uid="slow-by-design"}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics output missing %s", want)
+		}
+	}
+}
+
+func TestHandler_AnalyzeIncludesPanelTitles(t *testing.T) {
+	h := Handler(nil, "", Options{})
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(loadFixture(t, "slow-by-design.json")))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("analyze status = %d, want 200", rec.Code)
+	}
+
+	var resp struct {
+		PanelScores     map[string]int
+		PanelTitlesByID map[string]string
+		PanelCosts      map[string]float64
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(resp.PanelTitlesByID) == 0 || len(resp.PanelCosts) == 0 {
+		t.Fatalf("response missing panel titles (%d) or costs (%d)", len(resp.PanelTitlesByID), len(resp.PanelCosts))
+	}
+	for id := range resp.PanelScores {
+		if resp.PanelTitlesByID[id] == "" {
+			t.Errorf("panel %s has a score but no title", id)
+		}
+	}
+}
+
+func TestHandler_Healthz(t *testing.T) {
+	// Rate and concurrency limits apply to the API only; probes must not be
+	// throttled by analysis traffic.
+	h := Handler(nil, "", Options{RateLimit: 0.001, RateBurst: 1, MaxConcurrent: 1})
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok\n" {
+			t.Fatalf("/healthz = %d %q, want 200 \"ok\\n\"", rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_Version(t *testing.T) {
+	h := Handler(nil, "", Options{})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/version status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got versionInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("/version body is not JSON: %v", err)
+	}
+	if got.Version != analyzer.Version {
+		t.Errorf("version = %q, want %q", got.Version, analyzer.Version)
+	}
+	if got.GoVersion != runtime.Version() {
+		t.Errorf("goVersion = %q, want %q", got.GoVersion, runtime.Version())
+	}
+}
+
+func TestHandler_AnalyzePanel(t *testing.T) {
+	h := Handler(nil, "", Options{})
+	body := []byte(`{
+		"panel": {
+			"id": 7,
+			"title": "Requests",
+			"type": "timeseries",
+			"targets": [{"refId": "A", "expr": "sum(rate(http_requests_total[5m]))"}]
+		},
+		"templating": {"list": [{"name": "job", "type": "query", "query": "label_values(up, job)", "multi": true}]}
+	}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/panel", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp panelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.PanelID != 7 {
+		t.Errorf("panelId = %d, want 7", resp.PanelID)
+	}
+	var sawQ1 bool
+	for _, f := range resp.Findings {
+		if !strings.HasPrefix(f.RuleID, "Q") {
+			t.Errorf("dashboard-wide rule %s ran on a single panel", f.RuleID)
+		}
+		if f.RuleID == "Q1" {
+			sawQ1 = true
+			if !strings.Contains(f.Fix, `job=~"$job"`) {
+				t.Errorf("Q1 fix should use the posted $job variable, got %q", f.Fix)
+			}
+		}
+	}
+	if !sawQ1 {
+		t.Error("expected a Q1 finding for the unfiltered selector")
+	}
+	if resp.Score >= 100 {
+		t.Errorf("score = %d, want it lowered by the findings", resp.Score)
+	}
+}
+
+func TestHandler_AnalyzePanelMissingPanel(t *testing.T) {
+	h := Handler(nil, "", Options{})
+	req := httptest.NewRequest(http.MethodPost, "/api/analyze/panel", strings.NewReader(`{"templating": {"list": []}}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandler_TooManyExprs(t *testing.T) {
+	var panels []string
+	for i := 0; i < 5; i++ {
+		panels = append(panels, fmt.Sprintf(`{"id": %d, "type": "timeseries", "targets": [{"refId": "A", "expr": "up{job=\"job-%d\"}"}]}`, i+1, i))
+	}
+	body := []byte(`{"title": "many exprs", "panels": [` + strings.Join(panels, ",") + `]}`)
+
+	h := Handler(nil, "", Options{MaxExprs: 4})
+	if code := postAnalyze(h, body); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413 over the expression cap", code)
+	}
+
+	h = Handler(nil, "", Options{MaxExprs: -1})
+	if code := postAnalyze(h, body); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 with the cap disabled", code)
+	}
+}
+
+func TestHandler_NewEngine(t *testing.T) {
+	var panels []string
+	for i := 0; i < 3; i++ {
+		panels = append(panels, fmt.Sprintf(`{"id": %d, "type": "stat", "targets": [{"refId": "A", "expr": "up{job=\"job-%d\"}"}]}`, i+1, i))
+	}
+	body := []byte(`{"title": "three panels", "panels": [` + strings.Join(panels, ",") + `]}`)
+
+	d1Findings := func(h http.Handler) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/analyze", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("analyze status = %d, want 200", rec.Code)
+		}
+		var resp struct{ Findings []rules.Finding }
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding response: %v", err)
+		}
+		n := 0
+		for _, f := range resp.Findings {
+			if f.RuleID == "D1" {
+				n++
+			}
+		}
+		return n
+	}
+
+	if n := d1Findings(Handler(nil, "", Options{})); n != 0 {
+		t.Errorf("default engine: %d D1 findings on 3 panels, want 0", n)
+	}
+	h := Handler(nil, "", Options{NewEngine: func() *analyzer.Engine {
+		engine := analyzer.NewEngineWithRegistered()
+		engine.ReplaceRule(&rules.TooManyPanels{MaxPanels: 2})
+		return engine
+	}})
+	if n := d1Findings(h); n != 1 {
+		t.Errorf("engine from NewEngine with MaxPanels 2: %d D1 findings, want 1", n)
+	}
+}
+
+func TestOptions_MaxExprsDefault(t *testing.T) {
+	if got := (Options{}).maxExprs(); got != analyzer.DefaultMaxExprs {
+		t.Errorf("maxExprs() = %d, want %d", got, analyzer.DefaultMaxExprs)
+	}
+}